@@ -1,15 +1,9 @@
 package cookies
 
 import (
-	"crypto/aes"
-	"crypto/cipher"
-	"crypto/rand"
 	"encoding/base64"
 	"errors"
-	"fmt"
-	"io"
 	"net/http"
-	"strings"
 )
 
 var (
@@ -42,69 +36,3 @@ func Read(r *http.Request, name string) (string, error) {
 
 	return string(value), nil
 }
-
-func WriteEncrypted(w http.ResponseWriter, cookie http.Cookie, secretKey []byte) error {
-	block, err := aes.NewCipher(secretKey)
-	if err != nil {
-		return err
-	}
-
-	aesGCM, err := cipher.NewGCM(block)
-	if err != nil {
-		return err
-	}
-
-	nonce := make([]byte, aesGCM.NonceSize())
-	_, err = io.ReadFull(rand.Reader, nonce)
-	if err != nil {
-		return err
-	}
-	plaintext := fmt.Sprintf("%s:%s", cookie.Name, cookie.Value)
-	encryptedValue := aesGCM.Seal(nonce, nonce, []byte(plaintext), nil)
-
-	cookie.Value = string(encryptedValue)
-
-	return Write(w, cookie)
-}
-
-func ReadEncrypted(r *http.Request, name string, secretKey []byte) (string, error) {
-	encryptedValue, err := Read(r, name)
-	if err != nil {
-		return "", err
-	}
-
-	block, err := aes.NewCipher(secretKey)
-	if err != nil {
-		return "", err
-	}
-
-	aesGCM, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", err
-	}
-
-	nonceSize := aesGCM.NonceSize()
-
-	if len(encryptedValue) < nonceSize {
-		return "", ErrInvalidValue
-	}
-
-	nonce := encryptedValue[:nonceSize]
-	ciphertext := encryptedValue[nonceSize:]
-
-	plaintext, err := aesGCM.Open(nil, []byte(nonce), []byte(ciphertext), nil)
-	if err != nil {
-		return "", ErrInvalidValue
-	}
-
-	expectedName, value, ok := strings.Cut(string(plaintext), ":")
-	if !ok {
-		return "", ErrInvalidValue
-	}
-
-	if expectedName != name {
-		return "", ErrInvalidValue
-	}
-
-	return value, nil
-}
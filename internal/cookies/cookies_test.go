@@ -83,69 +83,23 @@ func TestReadInvalidBase64(t *testing.T) {
 	}
 }
 
-// Test WriteEncrypted function
-func TestWriteEncrypted(t *testing.T) {
-	w := httptest.NewRecorder()
-	secretKey := generateAESKey()
-	cookie := http.Cookie{Name: "test", Value: "secret"}
-
-	err := WriteEncrypted(w, cookie, secretKey)
-	if err != nil {
-		t.Fatalf("expected no error, got %v", err)
-	}
-
-	cookies := w.Result().Cookies()
-	if len(cookies) != 1 {
-		t.Fatalf("expected 1 cookie, got %d", len(cookies))
-	}
-}
-
-// Test WriteEncrypted with an invalid key length
-func TestWriteEncryptedInvalidKey(t *testing.T) {
-	w := httptest.NewRecorder()
+// Test NewKeyring with an invalid key length
+func TestNewKeyringInvalidKey(t *testing.T) {
 	invalidKey := []byte("short") // Not 16, 24, or 32 bytes
 
-	cookie := http.Cookie{Name: "test", Value: "secret"}
-	err := WriteEncrypted(w, cookie, invalidKey)
-	if err == nil {
-		t.Fatal("expected error for invalid key length, got nil")
-	}
-}
-
-// Test ReadEncrypted function
-func TestReadEncrypted(t *testing.T) {
-	w := httptest.NewRecorder()
-	secretKey := generateAESKey()
-	cookie := http.Cookie{Name: "test", Value: "secret"}
-
-	_ = WriteEncrypted(w, cookie, secretKey)
-
-	req := httptest.NewRequest("GET", "/", nil)
-	for _, c := range w.Result().Cookies() {
-		req.AddCookie(c)
-	}
-
-	value, err := ReadEncrypted(req, "test", secretKey)
-	if err != nil {
-		t.Fatalf("expected no error, got %v", err)
-	}
-	if value != "secret" {
-		t.Fatalf("expected 'secret', got %s", value)
+	if _, err := NewKeyring(invalidKey); !errors.Is(err, ErrInvalidKey) {
+		t.Fatalf("expected ErrInvalidKey for invalid key length, got %v", err)
 	}
 }
 
 // Test ReadEncrypted with missing nonce
 func TestReadEncryptedMissingNonce(t *testing.T) {
-	w := httptest.NewRecorder()
-	secretKey := generateAESKey()
-
-	cookie := http.Cookie{Name: "test", Value: "secret"}
-	_ = WriteEncrypted(w, cookie, secretKey)
+	k, _ := NewKeyring(generateAESKey())
 
 	req := httptest.NewRequest("GET", "/", nil)
 	req.AddCookie(&http.Cookie{Name: "test", Value: "short"})
 
-	_, err := ReadEncrypted(req, "test", secretKey)
+	_, _, err := k.ReadEncrypted(req, "test")
 	if !errors.Is(err, ErrInvalidValue) {
 		t.Fatalf("expected ErrInvalidValue for short nonce, got %v", err)
 	}
@@ -153,57 +107,24 @@ func TestReadEncryptedMissingNonce(t *testing.T) {
 
 // Test ReadEncrypted with wrong key
 func TestReadEncryptedWrongKey(t *testing.T) {
-	w := httptest.NewRecorder()
-	secretKey := generateAESKey()
-	wrongKey := generateAESKey()
-	cookie := http.Cookie{Name: "test", Value: "secret"}
-
-	_ = WriteEncrypted(w, cookie, secretKey)
-
-	req := httptest.NewRequest("GET", "/", nil)
-	for _, c := range w.Result().Cookies() {
-		req.AddCookie(c)
-	}
+	k, _ := NewKeyring(generateAESKey())
+	wrong, _ := NewKeyring(generateAESKey())
+	req := writeWith(t, k, http.Cookie{Name: "test", Value: "secret"})
 
-	_, err := ReadEncrypted(req, "test", wrongKey)
+	_, _, err := wrong.ReadEncrypted(req, "test")
 	if !errors.Is(err, ErrInvalidValue) {
 		t.Fatalf("expected ErrInvalidValue, got %v", err)
 	}
 }
 
-// Test ReadEncrypted with mismatched cookie name
-func TestReadEncryptedWrongName(t *testing.T) {
-	w := httptest.NewRecorder()
-	secretKey := generateAESKey()
-	cookie := http.Cookie{Name: "wrongname", Value: "secret"}
-
-	_ = WriteEncrypted(w, cookie, secretKey)
-
-	req := httptest.NewRequest("GET", "/", nil)
-	for _, c := range w.Result().Cookies() {
-		req.AddCookie(&http.Cookie{Name: "test", Value: c.Value})
-	}
-
-	_, err := ReadEncrypted(req, "test", secretKey)
-	if !errors.Is(err, ErrInvalidValue) {
-		t.Fatalf("expected ErrInvalidValue for mismatched name, got %v", err)
-	}
-}
-
 // Test ReadEncrypted with corrupted data
 func TestReadEncryptedCorruptedData(t *testing.T) {
-	w := httptest.NewRecorder()
-	secretKey := generateAESKey()
-	cookie := http.Cookie{Name: "test", Value: "secret"}
-
-	_ = WriteEncrypted(w, cookie, secretKey)
+	k, _ := NewKeyring(generateAESKey())
 
 	req := httptest.NewRequest("GET", "/", nil)
-	for range w.Result().Cookies() {
-		req.AddCookie(&http.Cookie{Name: "test", Value: "corrupteddata"})
-	}
+	req.AddCookie(&http.Cookie{Name: "test", Value: base64.URLEncoding.EncodeToString([]byte(k.PrimaryID() + ":corrupteddata"))})
 
-	_, err := ReadEncrypted(req, "test", secretKey)
+	_, _, err := k.ReadEncrypted(req, "test")
 	if !errors.Is(err, ErrInvalidValue) {
 		t.Fatalf("expected ErrInvalidValue, got %v", err)
 	}
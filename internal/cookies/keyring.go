@@ -0,0 +1,171 @@
+package cookies
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+var ErrInvalidKey = errors.New("invalid cookie secret key")
+
+// keyIDSize is the number of hex characters of the key's SHA-256 digest used as its ID.
+const keyIDSize = 8
+
+// Keyring seals cookies with a primary key and still opens cookies sealed with previous keys,
+// so the secret can be rotated without signing everybody out.
+type Keyring struct {
+	primary  key
+	previous []key
+}
+
+type key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+func newKey(secret []byte) (key, error) {
+	block, err := aes.NewCipher(secret)
+	if err != nil {
+		return key{}, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+
+	aesGCM, err := cipher.NewGCM(block)
+	if err != nil {
+		return key{}, err
+	}
+
+	digest := sha256.Sum256(secret)
+
+	return key{id: hex.EncodeToString(digest[:])[:keyIDSize], aead: aesGCM}, nil
+}
+
+// NewKeyring returns a keyring that seals new cookies with primary and also accepts previous.
+func NewKeyring(primary []byte, previous ...[]byte) (*Keyring, error) {
+	p, err := newKey(primary)
+	if err != nil {
+		return nil, err
+	}
+
+	k := &Keyring{primary: p}
+	for _, secret := range previous {
+		old, err := newKey(secret)
+		if err != nil {
+			return nil, err
+		}
+		if old.id == p.id {
+			continue
+		}
+		k.previous = append(k.previous, old)
+	}
+
+	return k, nil
+}
+
+// ParseKeyring builds a keyring from a hex encoded primary key and a comma separated
+// list of hex encoded previous keys, as found in RANDOM_HEX and RANDOM_HEX_PREVIOUS.
+func ParseKeyring(primaryHex, previousHex string) (*Keyring, error) {
+	primary, err := hex.DecodeString(strings.TrimSpace(primaryHex))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+	}
+
+	var previous [][]byte
+	for _, h := range strings.Split(previousHex, ",") {
+		h = strings.TrimSpace(h)
+		if h == "" {
+			continue
+		}
+
+		secret, err := hex.DecodeString(h)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidKey, err)
+		}
+		previous = append(previous, secret)
+	}
+
+	return NewKeyring(primary, previous...)
+}
+
+// PrimaryID returns the ID of the key new cookies are sealed with.
+func (k *Keyring) PrimaryID() string {
+	return k.primary.id
+}
+
+// WriteEncrypted seals the cookie with the primary key and prefixes it with the key's ID.
+func (k *Keyring) WriteEncrypted(w http.ResponseWriter, cookie http.Cookie) error {
+	nonce := make([]byte, k.primary.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+
+	plaintext := fmt.Sprintf("%s:%s", cookie.Name, cookie.Value)
+	encryptedValue := k.primary.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+
+	cookie.Value = k.primary.id + ":" + string(encryptedValue)
+
+	return Write(w, cookie)
+}
+
+// ReadEncrypted opens a cookie sealed with any key in the ring. stale is true when the
+// cookie was not sealed with the primary key, and the caller should write it again
+// with WriteEncrypted so it moves over to the current key.
+//
+// Cookies written before key IDs were added carry no prefix; every key is tried on them.
+func (k *Keyring) ReadEncrypted(r *http.Request, name string) (value string, stale bool, err error) {
+	sealed, err := Read(r, name)
+	if err != nil {
+		return "", false, err
+	}
+
+	if id, rest, ok := strings.Cut(sealed, ":"); ok && len(id) == keyIDSize {
+		for _, candidate := range k.keys() {
+			if candidate.id != id {
+				continue
+			}
+			if value, err := open(candidate.aead, name, rest); err == nil {
+				return value, candidate.id != k.primary.id, nil
+			}
+		}
+	}
+
+	for _, candidate := range k.keys() {
+		if value, err := open(candidate.aead, name, sealed); err == nil {
+			return value, true, nil
+		}
+	}
+
+	return "", false, ErrInvalidValue
+}
+
+func (k *Keyring) keys() []key {
+	return append([]key{k.primary}, k.previous...)
+}
+
+func open(aead cipher.AEAD, name, encryptedValue string) (string, error) {
+	nonceSize := aead.NonceSize()
+	if len(encryptedValue) < nonceSize {
+		return "", ErrInvalidValue
+	}
+
+	nonce := encryptedValue[:nonceSize]
+	ciphertext := encryptedValue[nonceSize:]
+
+	plaintext, err := aead.Open(nil, []byte(nonce), []byte(ciphertext), nil)
+	if err != nil {
+		return "", ErrInvalidValue
+	}
+
+	expectedName, value, ok := strings.Cut(string(plaintext), ":")
+	if !ok || expectedName != name {
+		return "", ErrInvalidValue
+	}
+
+	return value, nil
+}
@@ -0,0 +1,147 @@
+package cookies
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// writeWith seals a cookie with the keyring and returns a request carrying it.
+func writeWith(t *testing.T, k *Keyring, cookie http.Cookie) *http.Request {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	if err := k.WriteEncrypted(w, cookie); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, c := range w.Result().Cookies() {
+		req.AddCookie(c)
+	}
+
+	return req
+}
+
+// Test a cookie sealed with the primary key
+func TestKeyringReadEncrypted(t *testing.T) {
+	k, err := NewKeyring(generateAESKey())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	req := writeWith(t, k, http.Cookie{Name: "test", Value: "secret"})
+
+	raw, _ := req.Cookie("test")
+	decoded, _ := base64.URLEncoding.DecodeString(raw.Value)
+	if !strings.HasPrefix(string(decoded), k.PrimaryID()+":") {
+		t.Errorf("expected cookie to carry key ID %s", k.PrimaryID())
+	}
+
+	value, stale, err := k.ReadEncrypted(req, "test")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if value != "secret" {
+		t.Errorf("expected 'secret', got %s", value)
+	}
+	if stale {
+		t.Error("expected a cookie sealed with the primary key not to be stale")
+	}
+}
+
+// Test rotating the primary key keeps old cookies readable until they are re-issued
+func TestKeyringRotation(t *testing.T) {
+	oldKey, newKey := generateAESKey(), generateAESKey()
+
+	before, _ := NewKeyring(oldKey)
+	req := writeWith(t, before, http.Cookie{Name: "test", Value: "secret"})
+
+	after, err := NewKeyring(newKey, oldKey)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	value, stale, err := after.ReadEncrypted(req, "test")
+	if err != nil {
+		t.Fatalf("expected cookie sealed with a previous key to be accepted, got %v", err)
+	}
+	if value != "secret" || !stale {
+		t.Errorf("expected stale 'secret', got %q (stale=%v)", value, stale)
+	}
+
+	reissued := writeWith(t, after, http.Cookie{Name: "test", Value: value})
+	if _, stale, _ := after.ReadEncrypted(reissued, "test"); stale {
+		t.Error("expected re-issued cookie to be sealed with the primary key")
+	}
+
+	// Once the old key is dropped its cookies stop working.
+	dropped, _ := NewKeyring(newKey)
+	if _, _, err := dropped.ReadEncrypted(req, "test"); !errors.Is(err, ErrInvalidValue) {
+		t.Errorf("expected ErrInvalidValue after dropping the old key, got %v", err)
+	}
+}
+
+// Test cookies written before key IDs existed are still accepted
+func TestKeyringReadsLegacyCookies(t *testing.T) {
+	k, _ := NewKeyring(generateAESKey())
+
+	// Legacy cookies are the sealed value alone, without the key ID in front.
+	nonce := make([]byte, k.primary.aead.NonceSize())
+	sealed := k.primary.aead.Seal(nonce, nonce, []byte("test:secret"), nil)
+
+	w := httptest.NewRecorder()
+	_ = Write(w, http.Cookie{Name: "test", Value: string(sealed)})
+
+	req := httptest.NewRequest("GET", "/", nil)
+	for _, c := range w.Result().Cookies() {
+		req.AddCookie(c)
+	}
+
+	value, stale, err := k.ReadEncrypted(req, "test")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if value != "secret" || !stale {
+		t.Errorf("expected stale 'secret', got %q (stale=%v)", value, stale)
+	}
+}
+
+// Test a cookie sealed for another name is rejected
+func TestKeyringReadEncryptedWrongName(t *testing.T) {
+	k, _ := NewKeyring(generateAESKey())
+	req := writeWith(t, k, http.Cookie{Name: "wrongname", Value: "secret"})
+
+	raw, _ := req.Cookie("wrongname")
+	req = httptest.NewRequest("GET", "/", nil)
+	req.AddCookie(&http.Cookie{Name: "test", Value: raw.Value})
+
+	if _, _, err := k.ReadEncrypted(req, "test"); !errors.Is(err, ErrInvalidValue) {
+		t.Fatalf("expected ErrInvalidValue for mismatched name, got %v", err)
+	}
+}
+
+// Test ParseKeyring with environment style values
+func TestParseKeyring(t *testing.T) {
+	primary := hex.EncodeToString(generateAESKey())
+	previous := hex.EncodeToString(generateAESKey())
+
+	k, err := ParseKeyring(primary, " "+previous+", ,"+primary)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if len(k.previous) != 1 {
+		t.Errorf("expected 1 previous key, got %d", len(k.previous))
+	}
+
+	if _, err := ParseKeyring("not hex", ""); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey for bad hex, got %v", err)
+	}
+	if _, err := ParseKeyring(primary, "abcd"); !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("expected ErrInvalidKey for short previous key, got %v", err)
+	}
+}
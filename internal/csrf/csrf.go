@@ -0,0 +1,129 @@
+// Package csrf implements double-submit cookie protection against cross-site request forgery.
+//
+// Every browser gets a random token in a cookie. Pages render the same token into an
+// hx-headers attribute (or a hidden form field), and state-changing requests must send it
+// back. A forged request from another site can make the browser send the cookie, but it
+// cannot read the token to put it in the header or the form.
+package csrf
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"school_management_system/internal/cookies"
+)
+
+const (
+	// CookieName is the cookie holding the browser's token.
+	CookieName = "csrf_token"
+	// HeaderName is the request header HTMX and fetch calls send the token in.
+	HeaderName = "X-CSRF-Token"
+	// FieldName is the form field plain HTML forms send the token in.
+	FieldName = "csrf_token"
+
+	tokenSize = 32
+	maxAge    = 3600 * 24 * 7 * 2
+)
+
+var (
+	ErrMissingToken = errors.New("missing CSRF token")
+	ErrInvalidToken = errors.New("invalid CSRF token")
+)
+
+type contextKey struct{}
+
+// NewToken returns a random hex encoded token.
+func NewToken() (string, error) {
+	b := make([]byte, tokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// Read returns the token stored in the request's cookie.
+func Read(r *http.Request) (string, error) {
+	token, err := cookies.Read(r, CookieName)
+	if err != nil {
+		return "", err
+	}
+	if len(token) != tokenSize*2 {
+		return "", ErrInvalidToken
+	}
+
+	return token, nil
+}
+
+// Write stores the token in a cookie. The cookie lives as long as a session does.
+func Write(w http.ResponseWriter, token string, secure bool) error {
+	return cookies.Write(w, http.Cookie{
+		Name:     CookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   maxAge,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// Submitted returns the token the request sent back, from the header or else the form.
+func Submitted(r *http.Request) string {
+	if token := r.Header.Get(HeaderName); token != "" {
+		return token
+	}
+
+	return r.PostFormValue(FieldName)
+}
+
+// Verify checks that the token the request sent back matches the one in its cookie.
+func Verify(r *http.Request) error {
+	expected, err := Read(r)
+	if err != nil {
+		return ErrMissingToken
+	}
+
+	submitted := Submitted(r)
+	if submitted == "" {
+		return ErrMissingToken
+	}
+
+	if subtle.ConstantTimeCompare([]byte(submitted), []byte(expected)) != 1 {
+		return ErrInvalidToken
+	}
+
+	return nil
+}
+
+// Safe reports whether the method cannot change state and so needs no token.
+func Safe(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	}
+
+	return false
+}
+
+// WithToken returns a copy of ctx carrying the token for templates to render.
+func WithToken(ctx context.Context, token string) context.Context {
+	return context.WithValue(ctx, contextKey{}, token)
+}
+
+// Token returns the token carried by ctx, or an empty string.
+func Token(ctx context.Context) string {
+	token, _ := ctx.Value(contextKey{}).(string)
+	return token
+}
+
+// Headers returns the token as a JSON object for an hx-headers attribute.
+func Headers(ctx context.Context) string {
+	b, _ := json.Marshal(map[string]string{HeaderName: Token(ctx)})
+	return string(b)
+}
@@ -39,17 +39,18 @@ func (q *Queries) CreateAcademicYear(ctx context.Context, arg CreateAcademicYear
 }
 
 const createTerm = `-- name: CreateTerm :one
-INSERT INTO term (academic_year_id, name, start_date, end_date) 
-VALUES ($1, $2, $3, $4)
+INSERT INTO term (academic_year_id, name, start_date, end_date, report_weight) 
+VALUES ($1, $2, $3, $4, $5)
 ON CONFLICT ON CONSTRAINT term_name_on_academic_year DO NOTHING 
 RETURNING term_id
 `
 
 type CreateTermParams struct {
-	AcademicYearID uuid.UUID   `json:"academic_year_id"`
-	Name           string      `json:"name"`
-	StartDate      pgtype.Date `json:"start_date"`
-	EndDate        pgtype.Date `json:"end_date"`
+	AcademicYearID uuid.UUID      `json:"academic_year_id"`
+	Name           string         `json:"name"`
+	StartDate      pgtype.Date    `json:"start_date"`
+	EndDate        pgtype.Date    `json:"end_date"`
+	ReportWeight   pgtype.Numeric `json:"report_weight"`
 }
 
 func (q *Queries) CreateTerm(ctx context.Context, arg CreateTermParams) (uuid.UUID, error) {
@@ -58,6 +59,7 @@ func (q *Queries) CreateTerm(ctx context.Context, arg CreateTermParams) (uuid.UU
 		arg.Name,
 		arg.StartDate,
 		arg.EndDate,
+		arg.ReportWeight,
 	)
 	var term_id uuid.UUID
 	err := row.Scan(&term_id)
@@ -138,15 +140,17 @@ const editTerm = `-- name: EditTerm :exec
 UPDATE term 
 SET name = COALESCE($2, name),
 start_date = COALESCE($3, start_date),
-end_date = COALESCE($4, end_date)
+end_date = COALESCE($4, end_date),
+report_weight = COALESCE($5, report_weight)
 WHERE term_id = $1
 `
 
 type EditTermParams struct {
-	TermID    uuid.UUID   `json:"term_id"`
-	Name      string      `json:"name"`
-	StartDate pgtype.Date `json:"start_date"`
-	EndDate   pgtype.Date `json:"end_date"`
+	TermID       uuid.UUID      `json:"term_id"`
+	Name         string         `json:"name"`
+	StartDate    pgtype.Date    `json:"start_date"`
+	EndDate      pgtype.Date    `json:"end_date"`
+	ReportWeight pgtype.Numeric `json:"report_weight"`
 }
 
 func (q *Queries) EditTerm(ctx context.Context, arg EditTermParams) error {
@@ -155,6 +159,7 @@ func (q *Queries) EditTerm(ctx context.Context, arg EditTermParams) error {
 		arg.Name,
 		arg.StartDate,
 		arg.EndDate,
+		arg.ReportWeight,
 	)
 	return err
 }
@@ -258,7 +263,8 @@ academic_year.name AS Academic_Year,
 term.name AS Academic_Term,
 term.previous_term_id,
 term.start_date AS Opening_date,
-term.end_date AS Closing_date
+term.end_date AS Closing_date,
+term.report_weight
 FROM term
 INNER JOIN academic_year
 ON
@@ -267,13 +273,14 @@ WHERE term_id = $1
 `
 
 type GetTermRow struct {
-	TermID         uuid.UUID   `json:"term_id"`
-	AcademicYearID uuid.UUID   `json:"academic_year_id"`
-	AcademicYear   string      `json:"academic_year"`
-	AcademicTerm   string      `json:"academic_term"`
-	PreviousTermID pgtype.UUID `json:"previous_term_id"`
-	OpeningDate    pgtype.Date `json:"opening_date"`
-	ClosingDate    pgtype.Date `json:"closing_date"`
+	TermID         uuid.UUID      `json:"term_id"`
+	AcademicYearID uuid.UUID      `json:"academic_year_id"`
+	AcademicYear   string         `json:"academic_year"`
+	AcademicTerm   string         `json:"academic_term"`
+	PreviousTermID pgtype.UUID    `json:"previous_term_id"`
+	OpeningDate    pgtype.Date    `json:"opening_date"`
+	ClosingDate    pgtype.Date    `json:"closing_date"`
+	ReportWeight   pgtype.Numeric `json:"report_weight"`
 }
 
 func (q *Queries) GetTerm(ctx context.Context, termID uuid.UUID) (GetTermRow, error) {
@@ -287,6 +294,7 @@ func (q *Queries) GetTerm(ctx context.Context, termID uuid.UUID) (GetTermRow, er
 		&i.PreviousTermID,
 		&i.OpeningDate,
 		&i.ClosingDate,
+		&i.ReportWeight,
 	)
 	return i, err
 }
@@ -325,6 +333,120 @@ func (q *Queries) ListAcademicYear(ctx context.Context) ([]AcademicYear, error)
 	return items, nil
 }
 
+const listAcademicYearTerms = `-- name: ListAcademicYearTerms :many
+SELECT
+    term_id,
+    name AS Academic_Term,
+    report_weight
+FROM term
+WHERE academic_year_id = $1
+ORDER BY start_date
+`
+
+type ListAcademicYearTermsRow struct {
+	TermID       uuid.UUID      `json:"term_id"`
+	AcademicTerm string         `json:"academic_term"`
+	ReportWeight pgtype.Numeric `json:"report_weight"`
+}
+
+func (q *Queries) ListAcademicYearTerms(ctx context.Context, academicYearID uuid.UUID) ([]ListAcademicYearTermsRow, error) {
+	rows, err := q.db.Query(ctx, listAcademicYearTerms, academicYearID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAcademicYearTermsRow{}
+	for rows.Next() {
+		var i ListAcademicYearTermsRow
+		if err := rows.Scan(&i.TermID, &i.AcademicTerm, &i.ReportWeight); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllAcademicYears = `-- name: ListAllAcademicYears :many
+SELECT academic_year_id, graduate_class_id, name, start_date, end_date, active, period FROM academic_year
+ORDER BY start_date DESC
+`
+
+func (q *Queries) ListAllAcademicYears(ctx context.Context) ([]AcademicYear, error) {
+	rows, err := q.db.Query(ctx, listAllAcademicYears)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []AcademicYear{}
+	for rows.Next() {
+		var i AcademicYear
+		if err := rows.Scan(
+			&i.AcademicYearID,
+			&i.GraduateClassID,
+			&i.Name,
+			&i.StartDate,
+			&i.EndDate,
+			&i.Active,
+			&i.Period,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAllTerms = `-- name: ListAllTerms :many
+SELECT
+    term.term_id,
+    academic_year.name AS Academic_Year,
+    term.name AS Academic_Term,
+    term.active
+FROM term
+INNER JOIN academic_year
+ON
+term.academic_year_id = academic_year.academic_year_id
+ORDER BY term.start_date DESC
+`
+
+type ListAllTermsRow struct {
+	TermID       uuid.UUID `json:"term_id"`
+	AcademicYear string    `json:"academic_year"`
+	AcademicTerm string    `json:"academic_term"`
+	Active       bool      `json:"active"`
+}
+
+func (q *Queries) ListAllTerms(ctx context.Context) ([]ListAllTermsRow, error) {
+	rows, err := q.db.Query(ctx, listAllTerms)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListAllTermsRow{}
+	for rows.Next() {
+		var i ListAllTermsRow
+		if err := rows.Scan(
+			&i.TermID,
+			&i.AcademicYear,
+			&i.AcademicTerm,
+			&i.Active,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTerms = `-- name: ListTerms :many
 SELECT
 term.term_id,
@@ -334,7 +456,8 @@ academic_year.active AS status,
 term.name AS Academic_Term,
 term.start_date AS Opening_date,
 term.end_date AS Closing_date,
-term.active
+term.active,
+term.report_weight
 FROM term
 INNER JOIN academic_year
 ON
@@ -344,14 +467,15 @@ ORDER BY term.active DESC
 `
 
 type ListTermsRow struct {
-	TermID         uuid.UUID   `json:"term_id"`
-	AcademicYearID uuid.UUID   `json:"academic_year_id"`
-	AcademicYear   string      `json:"academic_year"`
-	Status         bool        `json:"status"`
-	AcademicTerm   string      `json:"academic_term"`
-	OpeningDate    pgtype.Date `json:"opening_date"`
-	ClosingDate    pgtype.Date `json:"closing_date"`
-	Active         bool        `json:"active"`
+	TermID         uuid.UUID      `json:"term_id"`
+	AcademicYearID uuid.UUID      `json:"academic_year_id"`
+	AcademicYear   string         `json:"academic_year"`
+	Status         bool           `json:"status"`
+	AcademicTerm   string         `json:"academic_term"`
+	OpeningDate    pgtype.Date    `json:"opening_date"`
+	ClosingDate    pgtype.Date    `json:"closing_date"`
+	Active         bool           `json:"active"`
+	ReportWeight   pgtype.Numeric `json:"report_weight"`
 }
 
 func (q *Queries) ListTerms(ctx context.Context, academicYearID uuid.UUID) ([]ListTermsRow, error) {
@@ -372,6 +496,7 @@ func (q *Queries) ListTerms(ctx context.Context, academicYearID uuid.UUID) ([]Li
 			&i.OpeningDate,
 			&i.ClosingDate,
 			&i.Active,
+			&i.ReportWeight,
 		); err != nil {
 			return nil, err
 		}
@@ -82,6 +82,8 @@ INNER JOIN classes
 INNER JOIN subjects
     ON assignments.subject_id = subjects.subject_id
 WHERE teacher_id = $1
+AND classes.deleted_at IS NULL
+AND subjects.deleted_at IS NULL
 ORDER BY classes.name
 `
 
@@ -172,6 +174,9 @@ INNER JOIN subjects
     ON assignments.subject_id = subjects.subject_id
 INNER JOIN users
     ON assignments.teacher_id = users.user_id
+WHERE classes.deleted_at IS NULL
+AND subjects.deleted_at IS NULL
+AND users.deleted_at IS NULL
 ORDER BY classes.name
 `
 
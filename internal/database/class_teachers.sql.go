@@ -20,6 +20,7 @@ select
 from users u
 join roles r on u.role_id = r.role_id
 and r.name = 'classteacher'
+where u.deleted_at is null
 order by u.last_name
 `
 
@@ -66,6 +67,8 @@ from class_teachers ct
 join users u on ct.teacher_id = u.user_id
 join classes c on ct.class_id = c.class_id
 where c.class_id = $1
+and u.deleted_at is null
+and c.deleted_at is null
 `
 
 type GetClassTeacherRow struct {
@@ -89,6 +92,25 @@ func (q *Queries) GetClassTeacher(ctx context.Context, classID uuid.UUID) (GetCl
 	return i, err
 }
 
+const isClassTeacher = `-- name: IsClassTeacher :one
+select exists (
+    select 1 from class_teachers
+    where class_id = $1 and teacher_id = $2
+)
+`
+
+type IsClassTeacherParams struct {
+	ClassID   uuid.UUID `json:"class_id"`
+	TeacherID uuid.UUID `json:"teacher_id"`
+}
+
+func (q *Queries) IsClassTeacher(ctx context.Context, arg IsClassTeacherParams) (bool, error) {
+	row := q.db.QueryRow(ctx, isClassTeacher, arg.ClassID, arg.TeacherID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const listCLassTeachers = `-- name: ListCLassTeachers :many
 select
     ct.id,
@@ -99,6 +121,8 @@ select
 from class_teachers ct
 join users u on ct.teacher_id = u.user_id
 join classes c on ct.class_id = c.class_id
+where u.deleted_at is null
+and c.deleted_at is null
 order by c.name
 `
 
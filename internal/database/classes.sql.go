@@ -9,27 +9,26 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createClass = `-- name: CreateClass :exec
+const createClass = `-- name: CreateClass :one
 INSERT INTO classes (name)
 VALUES ($1)
-ON CONFLICT (name) DO NOTHING
-RETURNING class_id, name
+ON CONFLICT (name) WHERE deleted_at IS NULL DO NOTHING
+RETURNING class_id, name, deleted_at, deleted_by
 `
 
-func (q *Queries) CreateClass(ctx context.Context, name string) error {
-	_, err := q.db.Exec(ctx, createClass, name)
-	return err
-}
-
-const deleteClass = `-- name: DeleteClass :exec
-DELETE FROM classes WHERE class_id = $1
-`
-
-func (q *Queries) DeleteClass(ctx context.Context, classID uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteClass, classID)
-	return err
+func (q *Queries) CreateClass(ctx context.Context, name string) (Class, error) {
+	row := q.db.QueryRow(ctx, createClass, name)
+	var i Class
+	err := row.Scan(
+		&i.ClassID,
+		&i.Name,
+		&i.DeletedAt,
+		&i.DeletedBy,
+	)
+	return i, err
 }
 
 const editClass = `-- name: EditClass :exec
@@ -49,19 +48,25 @@ func (q *Queries) EditClass(ctx context.Context, arg EditClassParams) error {
 }
 
 const getClass = `-- name: GetClass :one
-SELECT class_id, name FROM classes WHERE class_id = $1
+SELECT class_id, name, deleted_at, deleted_by FROM classes WHERE class_id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetClass(ctx context.Context, classID uuid.UUID) (Class, error) {
 	row := q.db.QueryRow(ctx, getClass, classID)
 	var i Class
-	err := row.Scan(&i.ClassID, &i.Name)
+	err := row.Scan(
+		&i.ClassID,
+		&i.Name,
+		&i.DeletedAt,
+		&i.DeletedBy,
+	)
 	return i, err
 }
 
 const listClasses = `-- name: ListClasses :many
-SELECT class_id, name FROM classes
+SELECT class_id, name, deleted_at, deleted_by FROM classes
 WHERE name NOT ILIKE 'Graduates - %'
+AND deleted_at IS NULL
 ORDER BY name
 `
 
@@ -74,7 +79,12 @@ func (q *Queries) ListClasses(ctx context.Context) ([]Class, error) {
 	items := []Class{}
 	for rows.Next() {
 		var i Class
-		if err := rows.Scan(&i.ClassID, &i.Name); err != nil {
+		if err := rows.Scan(
+			&i.ClassID,
+			&i.Name,
+			&i.DeletedAt,
+			&i.DeletedBy,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -85,10 +95,50 @@ func (q *Queries) ListClasses(ctx context.Context) ([]Class, error) {
 	return items, nil
 }
 
+const purgeClasses = `-- name: PurgeClasses :many
+DELETE FROM classes
+WHERE deleted_at < $1
+RETURNING class_id
+`
+
+func (q *Queries) PurgeClasses(ctx context.Context, deletedAt pgtype.Timestamptz) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, purgeClasses, deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var class_id uuid.UUID
+		if err := rows.Scan(&class_id); err != nil {
+			return nil, err
+		}
+		items = append(items, class_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreClass = `-- name: RestoreClass :execrows
+UPDATE classes
+SET deleted_at = NULL,
+deleted_by = NULL
+WHERE class_id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreClass(ctx context.Context, classID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreClass, classID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const setUpClassPromotions = `-- name: SetUpClassPromotions :many
 select 
-    c.class_id,
-    c.name
+    c.class_id, c.name, c.deleted_at, c.deleted_by
 from classes c
 inner join academic_year ay
 on c.class_id = ay.graduate_class_id
@@ -97,10 +147,10 @@ and ay.active = true
 union 
 
 select
-    class_id,
-    name
+    class_id, name, deleted_at, deleted_by
 from classes
 where name not ilike 'Graduates - %'
+and deleted_at is null
 order by name
 `
 
@@ -113,7 +163,12 @@ func (q *Queries) SetUpClassPromotions(ctx context.Context) ([]Class, error) {
 	items := []Class{}
 	for rows.Next() {
 		var i Class
-		if err := rows.Scan(&i.ClassID, &i.Name); err != nil {
+		if err := rows.Scan(
+			&i.ClassID,
+			&i.Name,
+			&i.DeletedAt,
+			&i.DeletedBy,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -123,3 +178,23 @@ func (q *Queries) SetUpClassPromotions(ctx context.Context) ([]Class, error) {
 	}
 	return items, nil
 }
+
+const softDeleteClass = `-- name: SoftDeleteClass :execrows
+UPDATE classes
+SET deleted_at = CURRENT_TIMESTAMP,
+deleted_by = $2
+WHERE class_id = $1 AND deleted_at IS NULL
+`
+
+type SoftDeleteClassParams struct {
+	ClassID   uuid.UUID   `json:"class_id"`
+	DeletedBy pgtype.UUID `json:"deleted_by"`
+}
+
+func (q *Queries) SoftDeleteClass(ctx context.Context, arg SoftDeleteClassParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteClass, arg.ClassID, arg.DeletedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
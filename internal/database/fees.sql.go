@@ -38,7 +38,7 @@ func (q *Queries) CreateFeesRecord(ctx context.Context, arg CreateFeesRecordPara
 	return i, err
 }
 
-const editFeesRecord = `-- name: EditFeesRecord :exec
+const editFeesRecord = `-- name: EditFeesRecord :one
 UPDATE fees
     SET paid = paid + $2
 WHERE fees_id = $1
@@ -50,9 +50,37 @@ type EditFeesRecordParams struct {
 	Paid   pgtype.Numeric `json:"paid"`
 }
 
-func (q *Queries) EditFeesRecord(ctx context.Context, arg EditFeesRecordParams) error {
-	_, err := q.db.Exec(ctx, editFeesRecord, arg.FeesID, arg.Paid)
-	return err
+func (q *Queries) EditFeesRecord(ctx context.Context, arg EditFeesRecordParams) (Fee, error) {
+	row := q.db.QueryRow(ctx, editFeesRecord, arg.FeesID, arg.Paid)
+	var i Fee
+	err := row.Scan(
+		&i.FeesID,
+		&i.FeeStructureID,
+		&i.StudentID,
+		&i.Paid,
+		&i.Arrears,
+		&i.Status,
+	)
+	return i, err
+}
+
+const getFee = `-- name: GetFee :one
+SELECT fees_id, fee_structure_id, student_id, paid, arrears, status FROM fees
+WHERE fees_id = $1
+`
+
+func (q *Queries) GetFee(ctx context.Context, feesID uuid.UUID) (Fee, error) {
+	row := q.db.QueryRow(ctx, getFee, feesID)
+	var i Fee
+	err := row.Scan(
+		&i.FeesID,
+		&i.FeeStructureID,
+		&i.StudentID,
+		&i.Paid,
+		&i.Arrears,
+		&i.Status,
+	)
+	return i, err
 }
 
 const getFeeStructureByTermAndClass = `-- name: GetFeeStructureByTermAndClass :one
@@ -252,6 +280,8 @@ LEFT JOIN fees f
     ON fs.fee_structure_id = f.fee_structure_id
     AND s.student_id = f.student_id
 WHERE t.term_id = $1
+AND c.deleted_at IS NULL
+AND s.deleted_at IS NULL
 `
 
 type ListStudentFeesRecordsRow struct {
@@ -310,12 +340,13 @@ func (q *Queries) ListStudentFeesRecords(ctx context.Context, termID uuid.UUID)
 
 const listStudentsByClassForTerm = `-- name: ListStudentsByClassForTerm :many
 SELECT
-    s.student_id, s.student_no, s.academic_year_id, s.last_name, s.middle_name, s.first_name, s.gender, s.date_of_birth, s.status, s.promoted, s.graduated, s.suspended
+    s.student_id, s.student_no, s.academic_year_id, s.last_name, s.middle_name, s.first_name, s.gender, s.date_of_birth, s.status, s.promoted, s.graduated, s.suspended, s.deleted_at, s.deleted_by
 FROM students s
 INNER JOIN student_classes sc ON s.student_id = sc.student_id
 INNER JOIN term t ON sc.term_id = t.term_id
 INNER JOIN classes c ON sc.class_id = c.class_id
 WHERE c.class_id = $1 AND t.active = TRUE
+AND s.deleted_at IS NULL
 `
 
 func (q *Queries) ListStudentsByClassForTerm(ctx context.Context, classID uuid.UUID) ([]Student, error) {
@@ -340,6 +371,8 @@ func (q *Queries) ListStudentsByClassForTerm(ctx context.Context, classID uuid.U
 			&i.Promoted,
 			&i.Graduated,
 			&i.Suspended,
+			&i.DeletedAt,
+			&i.DeletedBy,
 		); err != nil {
 			return nil, err
 		}
@@ -12,6 +12,66 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const getGrade = `-- name: GetGrade :one
+SELECT grade_id, student_id, subject_id, term_id, score, remark FROM grades
+WHERE student_id = $1 AND subject_id = $2 AND term_id = $3
+`
+
+type GetGradeParams struct {
+	StudentID uuid.UUID `json:"student_id"`
+	SubjectID uuid.UUID `json:"subject_id"`
+	TermID    uuid.UUID `json:"term_id"`
+}
+
+func (q *Queries) GetGrade(ctx context.Context, arg GetGradeParams) (Grade, error) {
+	row := q.db.QueryRow(ctx, getGrade, arg.StudentID, arg.SubjectID, arg.TermID)
+	var i Grade
+	err := row.Scan(
+		&i.GradeID,
+		&i.StudentID,
+		&i.SubjectID,
+		&i.TermID,
+		&i.Score,
+		&i.Remark,
+	)
+	return i, err
+}
+
+const listEnrolledStudentIDs = `-- name: ListEnrolledStudentIDs :many
+SELECT sc.student_id
+FROM student_classes sc
+JOIN students s ON s.student_id = sc.student_id
+WHERE sc.class_id = $1
+AND sc.term_id = $2
+AND s.deleted_at IS NULL
+`
+
+type ListEnrolledStudentIDsParams struct {
+	ClassID uuid.UUID `json:"class_id"`
+	TermID  uuid.UUID `json:"term_id"`
+}
+
+// ListEnrolledStudentIDs returns the students enrolled in a class for a term.
+func (q *Queries) ListEnrolledStudentIDs(ctx context.Context, arg ListEnrolledStudentIDsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listEnrolledStudentIDs, arg.ClassID, arg.TermID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var student_id uuid.UUID
+		if err := rows.Scan(&student_id); err != nil {
+			return nil, err
+		}
+		items = append(items, student_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listGrades = `-- name: ListGrades :many
 SELECT student_id, student_no, last_name, first_name, middle_name, class_id, class_name, grades
 FROM student_grades_view
@@ -63,6 +123,8 @@ LEFT JOIN grades g
   AND g.subject_id = subj.subject_id 
   AND g.term_id = sc.term_id
 WHERE sc.class_id = $1
+AND s.deleted_at IS NULL
+AND subj.deleted_at IS NULL
 `
 
 type ListGradesForClassRow struct {
@@ -101,6 +163,41 @@ func (q *Queries) ListGradesForClass(ctx context.Context, classID uuid.UUID) ([]
 	return items, nil
 }
 
+const listTeacherSubjectIDs = `-- name: ListTeacherSubjectIDs :many
+SELECT a.subject_id
+FROM assignments a
+JOIN subjects s ON s.subject_id = a.subject_id
+WHERE a.teacher_id = $1
+AND a.class_id = $2
+AND s.deleted_at IS NULL
+`
+
+type ListTeacherSubjectIDsParams struct {
+	TeacherID uuid.UUID `json:"teacher_id"`
+	ClassID   uuid.UUID `json:"class_id"`
+}
+
+// ListTeacherSubjectIDs returns the subjects a teacher is assigned to teach in a class.
+func (q *Queries) ListTeacherSubjectIDs(ctx context.Context, arg ListTeacherSubjectIDsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listTeacherSubjectIDs, arg.TeacherID, arg.ClassID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var subject_id uuid.UUID
+		if err := rows.Scan(&subject_id); err != nil {
+			return nil, err
+		}
+		items = append(items, subject_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const retrieveClassRoom = `-- name: RetrieveClassRoom :many
 SELECT
     vc.class_id,
@@ -13,18 +13,24 @@ import (
 )
 
 const createGraduateClass = `-- name: CreateGraduateClass :one
-INSERT INTO classes (name) VALUES ($1) RETURNING class_id, name
+INSERT INTO classes (name) VALUES ($1) RETURNING class_id, name, deleted_at, deleted_by
 `
 
 func (q *Queries) CreateGraduateClass(ctx context.Context, name string) (Class, error) {
 	row := q.db.QueryRow(ctx, createGraduateClass, name)
 	var i Class
-	err := row.Scan(&i.ClassID, &i.Name)
+	err := row.Scan(
+		&i.ClassID,
+		&i.Name,
+		&i.DeletedAt,
+		&i.DeletedBy,
+	)
 	return i, err
 }
 
 const listGraduatesByAcademicYear = `-- name: ListGraduatesByAcademicYear :many
 SELECT
+  s.student_id,
   s.student_no,
   s.first_name,
   s.middle_name,
@@ -38,11 +44,13 @@ JOIN term t ON sc.term_id = t.term_id
 JOIN academic_year ay ON t.academic_year_id = ay.academic_year_id
 LEFT JOIN classes c ON sc.class_id = c.class_id
 WHERE s.graduated = TRUE
+  AND s.deleted_at IS NULL
   AND ay.academic_year_id = $1
   AND c.name ILIKE 'Graduates - %'
 `
 
 type ListGraduatesByAcademicYearRow struct {
+	StudentID         uuid.UUID   `json:"student_id"`
 	StudentNo         string      `json:"student_no"`
 	FirstName         string      `json:"first_name"`
 	MiddleName        pgtype.Text `json:"middle_name"`
@@ -62,6 +70,7 @@ func (q *Queries) ListGraduatesByAcademicYear(ctx context.Context, academicYearI
 	for rows.Next() {
 		var i ListGraduatesByAcademicYearRow
 		if err := rows.Scan(
+			&i.StudentID,
 			&i.StudentNo,
 			&i.FirstName,
 			&i.MiddleName,
@@ -34,6 +34,7 @@ SELECT
 FROM students s
 INNER JOIN student_guardians sg ON s.student_id = sg.student_id
 INNER JOIN guardians g ON sg.guardian_id = g.guardian_id
+WHERE s.deleted_at IS NULL
 ORDER BY s.last_name, s.first_name
 `
 
@@ -136,6 +137,35 @@ func (q *Queries) GetStudentGuardianCount(ctx context.Context, studentID uuid.UU
 	return i, err
 }
 
+const purgeOrphanGuardians = `-- name: PurgeOrphanGuardians :many
+DELETE FROM guardians
+WHERE NOT EXISTS (
+    SELECT 1 FROM student_guardians sg
+    WHERE sg.guardian_id = guardians.guardian_id
+)
+RETURNING guardian_id
+`
+
+func (q *Queries) PurgeOrphanGuardians(ctx context.Context) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, purgeOrphanGuardians)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var guardian_id uuid.UUID
+		if err := rows.Scan(&guardian_id); err != nil {
+			return nil, err
+		}
+		items = append(items, guardian_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const searchStudentGuardian = `-- name: SearchStudentGuardian :many
 SELECT
     g.guardian_id,
@@ -149,7 +179,8 @@ SELECT
 FROM students s
 INNER JOIN student_guardians sg ON s.student_id = sg.student_id
 INNER JOIN guardians g ON sg.guardian_id = g.guardian_id
-WHERE s.first_name ILIKE $1 OR s.last_name ILIKE $1
+WHERE (s.first_name ILIKE $1 OR s.last_name ILIKE $1)
+AND s.deleted_at IS NULL
 ORDER BY s.last_name, s.first_name
 `
 
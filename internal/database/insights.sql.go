@@ -24,6 +24,7 @@ func (q *Queries) GetAverageGradeScore(ctx context.Context) (float64, error) {
 const getStudentGenderBreakdown = `-- name: GetStudentGenderBreakdown :many
 SELECT gender, COUNT(*) AS total_students
 FROM students
+WHERE deleted_at IS NULL
 GROUP BY gender
 `
 
@@ -91,6 +92,7 @@ func (q *Queries) GetTotalGuardians(ctx context.Context) (int64, error) {
 const getTotalStudents = `-- name: GetTotalStudents :one
 SELECT COUNT(*) AS total_students
 FROM students
+WHERE deleted_at IS NULL
 `
 
 func (q *Queries) GetTotalStudents(ctx context.Context) (int64, error) {
@@ -103,6 +105,7 @@ func (q *Queries) GetTotalStudents(ctx context.Context) (int64, error) {
 const getTotalUsers = `-- name: GetTotalUsers :one
 SELECT COUNT(*) AS total_users
 FROM users
+WHERE deleted_at IS NULL
 `
 
 func (q *Queries) GetTotalUsers(ctx context.Context) (int64, error) {
@@ -20,6 +20,57 @@ type AcademicYear struct {
 	Period          pgtype.Range[pgtype.Date] `json:"period"`
 }
 
+type AnnualReportCardsView struct {
+	StudentID         uuid.UUID           `json:"student_id"`
+	StudentNo         string              `json:"student_no"`
+	LastName          string              `json:"last_name"`
+	FirstName         string              `json:"first_name"`
+	MiddleName        pgtype.Text         `json:"middle_name"`
+	Gender            string              `json:"gender"`
+	Status            string              `json:"status"`
+	ClassID           uuid.UUID           `json:"class_id"`
+	ClassName         string              `json:"class_name"`
+	AcademicYearID    uuid.UUID           `json:"academic_year_id"`
+	AcademicYearName  string              `json:"academic_year_name"`
+	AcademicYearStart pgtype.Date         `json:"academic_year_start"`
+	Grades            dto.AnnualGradesMap `json:"grades"`
+	TotalScore        pgtype.Numeric      `json:"total_score"`
+	MeanScore         pgtype.Numeric      `json:"mean_score"`
+	ClassPosition     int32               `json:"class_position"`
+	ClassSize         int32               `json:"class_size"`
+}
+
+type ApiToken struct {
+	ApiTokenID  uuid.UUID          `json:"api_token_id"`
+	UserID      uuid.UUID          `json:"user_id"`
+	Name        string             `json:"name"`
+	Kind        string             `json:"kind"`
+	TokenHash   string             `json:"token_hash"`
+	TokenPrefix string             `json:"token_prefix"`
+	Scopes      []string           `json:"scopes"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	LastUsedAt  pgtype.Timestamptz `json:"last_used_at"`
+	LastUsedIp  pgtype.Text        `json:"last_used_ip"`
+	RevokedAt   pgtype.Timestamptz `json:"revoked_at"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type AssessmentComponent struct {
+	ComponentID uuid.UUID      `json:"component_id"`
+	SubjectID   uuid.UUID      `json:"subject_id"`
+	TermID      uuid.UUID      `json:"term_id"`
+	Name        string         `json:"name"`
+	Weight      pgtype.Numeric `json:"weight"`
+	Position    int32          `json:"position"`
+}
+
+type AssessmentScore struct {
+	AssessmentScoreID uuid.UUID      `json:"assessment_score_id"`
+	ComponentID       uuid.UUID      `json:"component_id"`
+	StudentID         uuid.UUID      `json:"student_id"`
+	Score             pgtype.Numeric `json:"score"`
+}
+
 type Assignment struct {
 	ID        uuid.UUID `json:"id"`
 	ClassID   uuid.UUID `json:"class_id"`
@@ -27,9 +78,28 @@ type Assignment struct {
 	TeacherID uuid.UUID `json:"teacher_id"`
 }
 
+type AuditLog struct {
+	AuditLogID  uuid.UUID          `json:"audit_log_id"`
+	ActorUserID pgtype.UUID        `json:"actor_user_id"`
+	Action      string             `json:"action"`
+	EntityType  string             `json:"entity_type"`
+	EntityID    pgtype.UUID        `json:"entity_id"`
+	Before      []byte             `json:"before"`
+	After       []byte             `json:"after"`
+	RequestID   string             `json:"request_id"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
 type Class struct {
-	ClassID uuid.UUID `json:"class_id"`
-	Name    string    `json:"name"`
+	ClassID   uuid.UUID          `json:"class_id"`
+	Name      string             `json:"name"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+	DeletedBy pgtype.UUID        `json:"deleted_by"`
+}
+
+type ClassGradingScale struct {
+	ClassID        uuid.UUID `json:"class_id"`
+	GradingScaleID uuid.UUID `json:"grading_scale_id"`
 }
 
 type ClassPromotion struct {
@@ -79,6 +149,53 @@ type Grade struct {
 	Remark    pgtype.Text    `json:"remark"`
 }
 
+type GradeEntryUnlock struct {
+	UnlockID   uuid.UUID          `json:"unlock_id"`
+	TermID     uuid.UUID          `json:"term_id"`
+	ClassID    uuid.UUID          `json:"class_id"`
+	SubjectID  pgtype.UUID        `json:"subject_id"`
+	Reason     string             `json:"reason"`
+	UnlockedBy pgtype.UUID        `json:"unlocked_by"`
+	UnlockedAt pgtype.Timestamptz `json:"unlocked_at"`
+	ExpiresAt  pgtype.Timestamptz `json:"expires_at"`
+}
+
+type GradeEntryWindow struct {
+	WindowID  uuid.UUID          `json:"window_id"`
+	TermID    uuid.UUID          `json:"term_id"`
+	ClassID   pgtype.UUID        `json:"class_id"`
+	SubjectID pgtype.UUID        `json:"subject_id"`
+	OpensAt   pgtype.Timestamptz `json:"opens_at"`
+	ClosesAt  pgtype.Timestamptz `json:"closes_at"`
+	CreatedBy pgtype.UUID        `json:"created_by"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
+}
+
+type GradeRevision struct {
+	RevisionID uuid.UUID          `json:"revision_id"`
+	GradeID    uuid.UUID          `json:"grade_id"`
+	Score      pgtype.Numeric     `json:"score"`
+	Remark     pgtype.Text        `json:"remark"`
+	Source     string             `json:"source"`
+	ChangedBy  pgtype.UUID        `json:"changed_by"`
+	ChangedAt  pgtype.Timestamptz `json:"changed_at"`
+}
+
+type GradingScale struct {
+	GradingScaleID uuid.UUID          `json:"grading_scale_id"`
+	Name           string             `json:"name"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
+}
+
+type GradingScaleBand struct {
+	BandID         uuid.UUID      `json:"band_id"`
+	GradingScaleID uuid.UUID      `json:"grading_scale_id"`
+	MinScore       pgtype.Numeric `json:"min_score"`
+	MaxScore       pgtype.Numeric `json:"max_score"`
+	Letter         string         `json:"letter"`
+	Descriptor     string         `json:"descriptor"`
+}
+
 type Guardian struct {
 	GuardianID   uuid.UUID   `json:"guardian_id"`
 	GuardianName string      `json:"guardian_name"`
@@ -88,12 +205,37 @@ type Guardian struct {
 	Profession   pgtype.Text `json:"profession"`
 }
 
+type LoginAttempt struct {
+	AttemptID   uuid.UUID          `json:"attempt_id"`
+	Identifier  string             `json:"identifier"`
+	UserID      pgtype.UUID        `json:"user_id"`
+	IpAddress   string             `json:"ip_address"`
+	Outcome     string             `json:"outcome"`
+	AttemptedAt pgtype.Timestamptz `json:"attempted_at"`
+}
+
 type NumberCounter struct {
 	Type    string `json:"type"`
 	Year    string `json:"year"`
 	LastVal int32  `json:"last_val"`
 }
 
+type PasswordResetCode struct {
+	ResetCodeID uuid.UUID          `json:"reset_code_id"`
+	UserID      uuid.UUID          `json:"user_id"`
+	CodeHash    string             `json:"code_hash"`
+	ExpiresAt   pgtype.Timestamptz `json:"expires_at"`
+	UsedAt      pgtype.Timestamptz `json:"used_at"`
+	CreatedBy   pgtype.UUID        `json:"created_by"`
+	CreatedAt   pgtype.Timestamptz `json:"created_at"`
+}
+
+type Permission struct {
+	PermissionID uuid.UUID   `json:"permission_id"`
+	Name         string      `json:"name"`
+	Description  pgtype.Text `json:"description"`
+}
+
 type PromotionHistory struct {
 	PromotionHistoryID uuid.UUID          `json:"promotion_history_id"`
 	StoredTermID       uuid.UUID          `json:"stored_term_id"`
@@ -110,31 +252,51 @@ type Remark struct {
 	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
 }
 
+type ReportApproval struct {
+	ClassID   uuid.UUID          `json:"class_id"`
+	TermID    uuid.UUID          `json:"term_id"`
+	Status    string             `json:"status"`
+	Comment   pgtype.Text        `json:"comment"`
+	UpdatedBy pgtype.UUID        `json:"updated_by"`
+	UpdatedAt pgtype.Timestamptz `json:"updated_at"`
+}
+
 type Role struct {
 	RoleID      uuid.UUID   `json:"role_id"`
 	Name        string      `json:"name"`
 	Description pgtype.Text `json:"description"`
 }
 
+type RolePermission struct {
+	RoleID       uuid.UUID `json:"role_id"`
+	PermissionID uuid.UUID `json:"permission_id"`
+}
+
 type Session struct {
-	SessionID uuid.UUID          `json:"session_id"`
-	UserID    uuid.UUID          `json:"user_id"`
-	Expires   pgtype.Timestamptz `json:"expires"`
+	SessionID  uuid.UUID          `json:"session_id"`
+	UserID     uuid.UUID          `json:"user_id"`
+	Expires    pgtype.Timestamptz `json:"expires"`
+	UserAgent  pgtype.Text        `json:"user_agent"`
+	IpAddress  pgtype.Text        `json:"ip_address"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	LastSeenAt pgtype.Timestamptz `json:"last_seen_at"`
 }
 
 type Student struct {
-	StudentID      uuid.UUID   `json:"student_id"`
-	StudentNo      string      `json:"student_no"`
-	AcademicYearID uuid.UUID   `json:"academic_year_id"`
-	LastName       string      `json:"last_name"`
-	MiddleName     pgtype.Text `json:"middle_name"`
-	FirstName      string      `json:"first_name"`
-	Gender         string      `json:"gender"`
-	DateOfBirth    pgtype.Date `json:"date_of_birth"`
-	Status         string      `json:"status"`
-	Promoted       bool        `json:"promoted"`
-	Graduated      bool        `json:"graduated"`
-	Suspended      bool        `json:"suspended"`
+	StudentID      uuid.UUID          `json:"student_id"`
+	StudentNo      string             `json:"student_no"`
+	AcademicYearID uuid.UUID          `json:"academic_year_id"`
+	LastName       string             `json:"last_name"`
+	MiddleName     pgtype.Text        `json:"middle_name"`
+	FirstName      string             `json:"first_name"`
+	Gender         string             `json:"gender"`
+	DateOfBirth    pgtype.Date        `json:"date_of_birth"`
+	Status         string             `json:"status"`
+	Promoted       bool               `json:"promoted"`
+	Graduated      bool               `json:"graduated"`
+	Suspended      bool               `json:"suspended"`
+	DeletedAt      pgtype.Timestamptz `json:"deleted_at"`
+	DeletedBy      pgtype.UUID        `json:"deleted_by"`
 }
 
 type StudentClass struct {
@@ -173,9 +335,11 @@ type StudentPromotionHistoryDetail struct {
 }
 
 type Subject struct {
-	SubjectID uuid.UUID `json:"subject_id"`
-	ClassID   uuid.UUID `json:"class_id"`
-	Name      string    `json:"name"`
+	SubjectID uuid.UUID          `json:"subject_id"`
+	ClassID   uuid.UUID          `json:"class_id"`
+	Name      string             `json:"name"`
+	DeletedAt pgtype.Timestamptz `json:"deleted_at"`
+	DeletedBy pgtype.UUID        `json:"deleted_by"`
 }
 
 type Term struct {
@@ -187,20 +351,65 @@ type Term struct {
 	EndDate        pgtype.Date               `json:"end_date"`
 	Active         bool                      `json:"active"`
 	Period         pgtype.Range[pgtype.Date] `json:"period"`
+	ReportWeight   pgtype.Numeric            `json:"report_weight"`
+}
+
+type TermReportCardsView struct {
+	StudentID        uuid.UUID      `json:"student_id"`
+	StudentNo        string         `json:"student_no"`
+	LastName         string         `json:"last_name"`
+	FirstName        string         `json:"first_name"`
+	MiddleName       pgtype.Text    `json:"middle_name"`
+	Gender           string         `json:"gender"`
+	Status           string         `json:"status"`
+	ClassID          uuid.UUID      `json:"class_id"`
+	ClassName        string         `json:"class_name"`
+	TermID           uuid.UUID      `json:"term_id"`
+	TermName         string         `json:"term_name"`
+	AcademicYearID   uuid.UUID      `json:"academic_year_id"`
+	AcademicYearName string         `json:"academic_year_name"`
+	Grades           dto.GradesMap  `json:"grades"`
+	TotalScore       pgtype.Numeric `json:"total_score"`
+	MeanScore        pgtype.Numeric `json:"mean_score"`
+	ClassPosition    int32          `json:"class_position"`
+	ClassSize        int32          `json:"class_size"`
+}
+
+type TotpRecoveryCode struct {
+	RecoveryCodeID uuid.UUID          `json:"recovery_code_id"`
+	UserID         uuid.UUID          `json:"user_id"`
+	CodeHash       string             `json:"code_hash"`
+	UsedAt         pgtype.Timestamptz `json:"used_at"`
+	CreatedAt      pgtype.Timestamptz `json:"created_at"`
 }
 
 type User struct {
-	UserID      uuid.UUID          `json:"user_id"`
-	UserNo      string             `json:"user_no"`
-	LastName    string             `json:"last_name"`
-	FirstName   string             `json:"first_name"`
-	Gender      string             `json:"gender"`
-	Email       pgtype.Text        `json:"email"`
-	PhoneNumber pgtype.Text        `json:"phone_number"`
-	Password    string             `json:"password"`
-	CreatedAt   pgtype.Timestamptz `json:"created_at"`
-	UpdatedAt   pgtype.Timestamptz `json:"updated_at"`
-	RoleID      uuid.UUID          `json:"role_id"`
+	UserID              uuid.UUID          `json:"user_id"`
+	UserNo              string             `json:"user_no"`
+	LastName            string             `json:"last_name"`
+	FirstName           string             `json:"first_name"`
+	Gender              string             `json:"gender"`
+	Email               pgtype.Text        `json:"email"`
+	PhoneNumber         pgtype.Text        `json:"phone_number"`
+	Password            string             `json:"password"`
+	CreatedAt           pgtype.Timestamptz `json:"created_at"`
+	UpdatedAt           pgtype.Timestamptz `json:"updated_at"`
+	RoleID              uuid.UUID          `json:"role_id"`
+	FailedLoginAttempts int32              `json:"failed_login_attempts"`
+	LastFailedLoginAt   pgtype.Timestamptz `json:"last_failed_login_at"`
+	LockedUntil         pgtype.Timestamptz `json:"locked_until"`
+	MustChangePassword  bool               `json:"must_change_password"`
+	TotpSecret          pgtype.Text        `json:"totp_secret"`
+	TotpEnabledAt       pgtype.Timestamptz `json:"totp_enabled_at"`
+	TotpLastCounter     int64              `json:"totp_last_counter"`
+	DeletedAt           pgtype.Timestamptz `json:"deleted_at"`
+	DeletedBy           pgtype.UUID        `json:"deleted_by"`
+}
+
+type UserRole struct {
+	UserID    uuid.UUID          `json:"user_id"`
+	RoleID    uuid.UUID          `json:"role_id"`
+	CreatedAt pgtype.Timestamptz `json:"created_at"`
 }
 
 type VirtualClassroom struct {
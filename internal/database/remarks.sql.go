@@ -12,6 +12,32 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const getRemark = `-- name: GetRemark :one
+SELECT remarks_id, student_id, term_id, content_class_teacher, content_head_teacher, updated_at FROM remarks
+WHERE student_id = $1 AND term_id = $2
+FOR UPDATE
+`
+
+type GetRemarkParams struct {
+	StudentID uuid.UUID `json:"student_id"`
+	TermID    uuid.UUID `json:"term_id"`
+}
+
+// GetRemark locks a student's remark for the term until the transaction ends.
+func (q *Queries) GetRemark(ctx context.Context, arg GetRemarkParams) (Remark, error) {
+	row := q.db.QueryRow(ctx, getRemark, arg.StudentID, arg.TermID)
+	var i Remark
+	err := row.Scan(
+		&i.RemarksID,
+		&i.StudentID,
+		&i.TermID,
+		&i.ContentClassTeacher,
+		&i.ContentHeadTeacher,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const listRemarksByClass = `-- name: ListRemarksByClass :many
 SELECT
   c.name AS class_name,
@@ -40,6 +66,7 @@ INNER JOIN term t
 LEFT JOIN remarks r 
     ON s.student_id = r.student_id 
    AND sc.term_id = $2
+WHERE s.deleted_at IS NULL
 ORDER BY c.name, s.last_name, s.first_name
 `
 
@@ -15,37 +15,64 @@ import (
 
 const getStudentReportCard = `-- name: GetStudentReportCard :one
 SELECT 
-    sgv.student_id,
-    sgv.student_no,
-    sgv.last_name,
-    sgv.first_name,
-    sgv.middle_name,
-    sgv.class_id,
-    sgv.class_name,
-    sgv.grades,
+    rc.student_id,
+    rc.student_no,
+    rc.last_name,
+    rc.first_name,
+    rc.middle_name,
+    rc.class_id,
+    rc.class_name,
+    rc.term_id,
+    rc.term_name,
+    rc.academic_year_name,
+    rc.grades,
+    rc.total_score,
+    rc.mean_score,
+    rc.class_position,
+    rc.class_size,
     r.content_class_teacher AS class_teacher_remark,
     r.content_head_teacher AS head_teacher_remark
-FROM student_grades_view sgv
+FROM term_report_cards_view rc
 LEFT JOIN remarks r 
-    ON r.student_id = sgv.student_id 
-WHERE sgv.student_id = $1
+    ON r.student_id = rc.student_id 
+    AND r.term_id = rc.term_id
+WHERE rc.student_id = $1
+AND rc.term_id = $2
+AND ($3::uuid IS NULL OR rc.class_id = $3)
+ORDER BY rc.class_name, rc.class_id
+LIMIT 1
 `
 
+type GetStudentReportCardParams struct {
+	StudentID uuid.UUID   `json:"student_id"`
+	TermID    uuid.UUID   `json:"term_id"`
+	ClassID   pgtype.UUID `json:"class_id"`
+}
+
 type GetStudentReportCardRow struct {
-	StudentID          uuid.UUID     `json:"student_id"`
-	StudentNo          string        `json:"student_no"`
-	LastName           string        `json:"last_name"`
-	FirstName          string        `json:"first_name"`
-	MiddleName         pgtype.Text   `json:"middle_name"`
-	ClassID            uuid.UUID     `json:"class_id"`
-	ClassName          string        `json:"class_name"`
-	Grades             dto.GradesMap `json:"grades"`
-	ClassTeacherRemark pgtype.Text   `json:"class_teacher_remark"`
-	HeadTeacherRemark  pgtype.Text   `json:"head_teacher_remark"`
+	StudentID          uuid.UUID      `json:"student_id"`
+	StudentNo          string         `json:"student_no"`
+	LastName           string         `json:"last_name"`
+	FirstName          string         `json:"first_name"`
+	MiddleName         pgtype.Text    `json:"middle_name"`
+	ClassID            uuid.UUID      `json:"class_id"`
+	ClassName          string         `json:"class_name"`
+	TermID             uuid.UUID      `json:"term_id"`
+	TermName           string         `json:"term_name"`
+	AcademicYearName   string         `json:"academic_year_name"`
+	Grades             dto.GradesMap  `json:"grades"`
+	TotalScore         pgtype.Numeric `json:"total_score"`
+	MeanScore          pgtype.Numeric `json:"mean_score"`
+	ClassPosition      int32          `json:"class_position"`
+	ClassSize          int32          `json:"class_size"`
+	ClassTeacherRemark pgtype.Text    `json:"class_teacher_remark"`
+	HeadTeacherRemark  pgtype.Text    `json:"head_teacher_remark"`
 }
 
-func (q *Queries) GetStudentReportCard(ctx context.Context, studentID uuid.UUID) (GetStudentReportCardRow, error) {
-	row := q.db.QueryRow(ctx, getStudentReportCard, studentID)
+// GetStudentReportCard returns a student's report card for a term in a class, or in the first of
+// their classes by name when no class is given and they moved class during the term.
+func (q *Queries) GetStudentReportCard(ctx context.Context, arg GetStudentReportCardParams) (GetStudentReportCardRow, error) {
+	row := q.db.QueryRow(ctx, getStudentReportCard, arg.StudentID, arg.TermID, arg.ClassID)
 	var i GetStudentReportCardRow
 	err := row.Scan(
 		&i.StudentID,
@@ -55,46 +82,118 @@ func (q *Queries) GetStudentReportCard(ctx context.Context, studentID uuid.UUID)
 		&i.MiddleName,
 		&i.ClassID,
 		&i.ClassName,
+		&i.TermID,
+		&i.TermName,
+		&i.AcademicYearName,
 		&i.Grades,
+		&i.TotalScore,
+		&i.MeanScore,
+		&i.ClassPosition,
+		&i.ClassSize,
 		&i.ClassTeacherRemark,
 		&i.HeadTeacherRemark,
 	)
 	return i, err
 }
 
+const listReportCardClasses = `-- name: ListReportCardClasses :many
+SELECT DISTINCT
+    rc.class_id,
+    rc.class_name,
+    COALESCE(ra.status, 'draft')::VARCHAR AS approval_status
+FROM term_report_cards_view rc
+LEFT JOIN report_approvals ra
+    ON ra.class_id = rc.class_id
+    AND ra.term_id = rc.term_id
+WHERE rc.term_id = $1
+ORDER BY rc.class_name
+`
+
+type ListReportCardClassesRow struct {
+	ClassID        uuid.UUID `json:"class_id"`
+	ClassName      string    `json:"class_name"`
+	ApprovalStatus string    `json:"approval_status"`
+}
+
+func (q *Queries) ListReportCardClasses(ctx context.Context, termID uuid.UUID) ([]ListReportCardClassesRow, error) {
+	rows, err := q.db.Query(ctx, listReportCardClasses, termID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListReportCardClassesRow{}
+	for rows.Next() {
+		var i ListReportCardClassesRow
+		if err := rows.Scan(&i.ClassID, &i.ClassName, &i.ApprovalStatus); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listStudentReportCards = `-- name: ListStudentReportCards :many
 SELECT 
-    sgv.student_id,
-    sgv.student_no,
-    sgv.last_name,
-    sgv.first_name,
-    sgv.middle_name,
-    sgv.class_id,
-    sgv.class_name,
-    sgv.grades,
+    rc.student_id,
+    rc.student_no,
+    rc.last_name,
+    rc.first_name,
+    rc.middle_name,
+    rc.gender,
+    rc.status,
+    rc.class_id,
+    rc.class_name,
+    rc.term_id,
+    rc.term_name,
+    rc.academic_year_name,
+    rc.grades,
+    rc.total_score,
+    rc.mean_score,
+    rc.class_position,
+    rc.class_size,
     r.content_class_teacher AS class_teacher_remark,
     r.content_head_teacher AS head_teacher_remark
-FROM student_grades_view sgv
+FROM term_report_cards_view rc
 LEFT JOIN remarks r 
-    ON r.student_id = sgv.student_id 
-ORDER BY sgv.class_id = $1
+    ON r.student_id = rc.student_id 
+    AND r.term_id = rc.term_id
+WHERE rc.class_id = $1
+AND rc.term_id = $2
+ORDER BY rc.class_position, rc.last_name, rc.first_name
 `
 
+type ListStudentReportCardsParams struct {
+	ClassID uuid.UUID `json:"class_id"`
+	TermID  uuid.UUID `json:"term_id"`
+}
+
 type ListStudentReportCardsRow struct {
-	StudentID          uuid.UUID     `json:"student_id"`
-	StudentNo          string        `json:"student_no"`
-	LastName           string        `json:"last_name"`
-	FirstName          string        `json:"first_name"`
-	MiddleName         pgtype.Text   `json:"middle_name"`
-	ClassID            uuid.UUID     `json:"class_id"`
-	ClassName          string        `json:"class_name"`
-	Grades             dto.GradesMap `json:"grades"`
-	ClassTeacherRemark pgtype.Text   `json:"class_teacher_remark"`
-	HeadTeacherRemark  pgtype.Text   `json:"head_teacher_remark"`
+	StudentID          uuid.UUID      `json:"student_id"`
+	StudentNo          string         `json:"student_no"`
+	LastName           string         `json:"last_name"`
+	FirstName          string         `json:"first_name"`
+	MiddleName         pgtype.Text    `json:"middle_name"`
+	Gender             string         `json:"gender"`
+	Status             string         `json:"status"`
+	ClassID            uuid.UUID      `json:"class_id"`
+	ClassName          string         `json:"class_name"`
+	TermID             uuid.UUID      `json:"term_id"`
+	TermName           string         `json:"term_name"`
+	AcademicYearName   string         `json:"academic_year_name"`
+	Grades             dto.GradesMap  `json:"grades"`
+	TotalScore         pgtype.Numeric `json:"total_score"`
+	MeanScore          pgtype.Numeric `json:"mean_score"`
+	ClassPosition      int32          `json:"class_position"`
+	ClassSize          int32          `json:"class_size"`
+	ClassTeacherRemark pgtype.Text    `json:"class_teacher_remark"`
+	HeadTeacherRemark  pgtype.Text    `json:"head_teacher_remark"`
 }
 
-func (q *Queries) ListStudentReportCards(ctx context.Context, classID uuid.UUID) ([]ListStudentReportCardsRow, error) {
-	rows, err := q.db.Query(ctx, listStudentReportCards, classID)
+func (q *Queries) ListStudentReportCards(ctx context.Context, arg ListStudentReportCardsParams) ([]ListStudentReportCardsRow, error) {
+	rows, err := q.db.Query(ctx, listStudentReportCards, arg.ClassID, arg.TermID)
 	if err != nil {
 		return nil, err
 	}
@@ -108,9 +207,18 @@ func (q *Queries) ListStudentReportCards(ctx context.Context, classID uuid.UUID)
 			&i.LastName,
 			&i.FirstName,
 			&i.MiddleName,
+			&i.Gender,
+			&i.Status,
 			&i.ClassID,
 			&i.ClassName,
+			&i.TermID,
+			&i.TermName,
+			&i.AcademicYearName,
 			&i.Grades,
+			&i.TotalScore,
+			&i.MeanScore,
+			&i.ClassPosition,
+			&i.ClassSize,
 			&i.ClassTeacherRemark,
 			&i.HeadTeacherRemark,
 		); err != nil {
@@ -13,55 +13,103 @@ import (
 )
 
 const createSession = `-- name: CreateSession :exec
-INSERT INTO sessions (session_id, user_id, expires) 
-  VALUES ($1, $2, $3)
-  ON CONFLICT (user_id) 
-DO UPDATE
-  SET
-    session_id = EXCLUDED.session_id,
-    expires = EXCLUDED.expires
+INSERT INTO sessions (session_id, user_id, expires, user_agent, ip_address)
+  VALUES ($1, $2, $3, $4, $5)
 `
 
 type CreateSessionParams struct {
 	SessionID uuid.UUID          `json:"session_id"`
 	UserID    uuid.UUID          `json:"user_id"`
 	Expires   pgtype.Timestamptz `json:"expires"`
+	UserAgent pgtype.Text        `json:"user_agent"`
+	IpAddress pgtype.Text        `json:"ip_address"`
 }
 
 func (q *Queries) CreateSession(ctx context.Context, arg CreateSessionParams) error {
-	_, err := q.db.Exec(ctx, createSession, arg.SessionID, arg.UserID, arg.Expires)
+	_, err := q.db.Exec(ctx, createSession,
+		arg.SessionID,
+		arg.UserID,
+		arg.Expires,
+		arg.UserAgent,
+		arg.IpAddress,
+	)
 	return err
 }
 
 const deleteSession = `-- name: DeleteSession :exec
 DELETE FROM sessions
+WHERE session_id = $1
+`
+
+func (q *Queries) DeleteSession(ctx context.Context, sessionID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteSession, sessionID)
+	return err
+}
+
+const deleteUserSession = `-- name: DeleteUserSession :exec
+DELETE FROM sessions
+WHERE session_id = $1 AND user_id = $2
+`
+
+type DeleteUserSessionParams struct {
+	SessionID uuid.UUID `json:"session_id"`
+	UserID    uuid.UUID `json:"user_id"`
+}
+
+func (q *Queries) DeleteUserSession(ctx context.Context, arg DeleteUserSessionParams) error {
+	_, err := q.db.Exec(ctx, deleteUserSession, arg.SessionID, arg.UserID)
+	return err
+}
+
+const deleteUserSessions = `-- name: DeleteUserSessions :exec
+DELETE FROM sessions
 WHERE user_id = $1
 `
 
-func (q *Queries) DeleteSession(ctx context.Context, userID uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteSession, userID)
+func (q *Queries) DeleteUserSessions(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, deleteUserSessions, userID)
 	return err
 }
 
 const getSession = `-- name: GetSession :one
-SELECT 
+SELECT
   sessions.user_id,
   sessions.session_id,
   roles.name AS role,
-  sessions.expires
+  sessions.expires,
+  users.must_change_password,
+  users.totp_enabled_at IS NOT NULL AS totp_enabled,
+  ARRAY(
+    SELECT r.name FROM user_roles ur
+    INNER JOIN roles r ON ur.role_id = r.role_id
+    WHERE ur.user_id = sessions.user_id
+    ORDER BY r.name
+  )::text[] AS roles,
+  ARRAY(
+    SELECT DISTINCT p.name FROM user_roles ur
+    INNER JOIN role_permissions rp ON ur.role_id = rp.role_id
+    INNER JOIN permissions p ON rp.permission_id = p.permission_id
+    WHERE ur.user_id = sessions.user_id
+    ORDER BY p.name
+  )::text[] AS permissions
 FROM sessions
 INNER JOIN users
   ON sessions.user_id = users.user_id
-INNER JOIN roles 
+  AND users.deleted_at IS NULL
+INNER JOIN roles
   ON users.role_id = roles.role_id
 WHERE session_id = $1
 `
 
 type GetSessionRow struct {
-	UserID    uuid.UUID          `json:"user_id"`
-	SessionID uuid.UUID          `json:"session_id"`
-	Role      string             `json:"role"`
-	Expires   pgtype.Timestamptz `json:"expires"`
+	UserID             uuid.UUID          `json:"user_id"`
+	SessionID          uuid.UUID          `json:"session_id"`
+	Role               string             `json:"role"`
+	Expires            pgtype.Timestamptz `json:"expires"`
+	MustChangePassword bool               `json:"must_change_password"`
+	TotpEnabled        bool               `json:"totp_enabled"`
+	Roles              []string           `json:"roles"`
+	Permissions        []string           `json:"permissions"`
 }
 
 func (q *Queries) GetSession(ctx context.Context, sessionID uuid.UUID) (GetSessionRow, error) {
@@ -72,24 +120,85 @@ func (q *Queries) GetSession(ctx context.Context, sessionID uuid.UUID) (GetSessi
 		&i.SessionID,
 		&i.Role,
 		&i.Expires,
+		&i.MustChangePassword,
+		&i.TotpEnabled,
+		&i.Roles,
+		&i.Permissions,
 	)
 	return i, err
 }
 
+const listUserSessions = `-- name: ListUserSessions :many
+SELECT session_id, user_agent, ip_address, created_at, last_seen_at, expires
+FROM sessions
+WHERE user_id = $1
+  AND expires > CURRENT_TIMESTAMP
+ORDER BY last_seen_at DESC
+`
+
+type ListUserSessionsRow struct {
+	SessionID  uuid.UUID          `json:"session_id"`
+	UserAgent  pgtype.Text        `json:"user_agent"`
+	IpAddress  pgtype.Text        `json:"ip_address"`
+	CreatedAt  pgtype.Timestamptz `json:"created_at"`
+	LastSeenAt pgtype.Timestamptz `json:"last_seen_at"`
+	Expires    pgtype.Timestamptz `json:"expires"`
+}
+
+func (q *Queries) ListUserSessions(ctx context.Context, userID uuid.UUID) ([]ListUserSessionsRow, error) {
+	rows, err := q.db.Query(ctx, listUserSessions, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListUserSessionsRow{}
+	for rows.Next() {
+		var i ListUserSessionsRow
+		if err := rows.Scan(
+			&i.SessionID,
+			&i.UserAgent,
+			&i.IpAddress,
+			&i.CreatedAt,
+			&i.LastSeenAt,
+			&i.Expires,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const refreshSession = `-- name: RefreshSession :exec
 UPDATE sessions
-  SET expires = COALESCE($2, expires),
-  session_id = COALESCE($3, session_id)
-WHERE user_id = $1
+  SET expires = $1,
+  session_id = $2,
+  last_seen_at = CURRENT_TIMESTAMP
+WHERE session_id = $3
 `
 
 type RefreshSessionParams struct {
-	UserID    uuid.UUID          `json:"user_id"`
-	Expires   pgtype.Timestamptz `json:"expires"`
-	SessionID uuid.UUID          `json:"session_id"`
+	Expires      pgtype.Timestamptz `json:"expires"`
+	NewSessionID uuid.UUID          `json:"new_session_id"`
+	SessionID    uuid.UUID          `json:"session_id"`
 }
 
 func (q *Queries) RefreshSession(ctx context.Context, arg RefreshSessionParams) error {
-	_, err := q.db.Exec(ctx, refreshSession, arg.UserID, arg.Expires, arg.SessionID)
+	_, err := q.db.Exec(ctx, refreshSession, arg.Expires, arg.NewSessionID, arg.SessionID)
+	return err
+}
+
+const touchSession = `-- name: TouchSession :exec
+UPDATE sessions
+  SET last_seen_at = CURRENT_TIMESTAMP
+WHERE session_id = $1
+  AND last_seen_at < CURRENT_TIMESTAMP - INTERVAL '5 minutes'
+`
+
+func (q *Queries) TouchSession(ctx context.Context, sessionID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, touchSession, sessionID)
 	return err
 }
@@ -14,7 +14,8 @@ import (
 
 const editMyPassword = `-- name: EditMyPassword :exec
 UPDATE users
-    set password = COALESCE($2, password)
+    set password = COALESCE($2, password),
+    must_change_password = FALSE
 WHERE user_id = $1
 `
 
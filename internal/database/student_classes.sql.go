@@ -81,3 +81,35 @@ func (q *Queries) GetStudentsTerm(ctx context.Context) (GetStudentsTermRow, erro
 	err := row.Scan(&i.Term, &i.AcademicYear)
 	return i, err
 }
+
+const listStudentTermClassIDs = `-- name: ListStudentTermClassIDs :many
+SELECT DISTINCT class_id FROM student_classes
+WHERE student_id = $1 AND term_id = $2
+ORDER BY class_id
+`
+
+type ListStudentTermClassIDsParams struct {
+	StudentID uuid.UUID `json:"student_id"`
+	TermID    uuid.UUID `json:"term_id"`
+}
+
+// ListStudentTermClassIDs returns the classes a student was in during a term.
+func (q *Queries) ListStudentTermClassIDs(ctx context.Context, arg ListStudentTermClassIDsParams) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, listStudentTermClassIDs, arg.StudentID, arg.TermID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var class_id uuid.UUID
+		if err := rows.Scan(&class_id); err != nil {
+			return nil, err
+		}
+		items = append(items, class_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -12,15 +12,6 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const deleteStudent = `-- name: DeleteStudent :exec
-DELETE FROM students WHERE student_id = $1
-`
-
-func (q *Queries) DeleteStudent(ctx context.Context, studentID uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteStudent, studentID)
-	return err
-}
-
 const editStudent = `-- name: EditStudent :exec
 UPDATE students
 SET last_name = COALESCE($2, last_name),
@@ -73,6 +64,7 @@ LEFT OUTER JOIN student_classes
 LEFT OUTER JOIN classes
     ON student_classes.class_id = classes.class_id
 WHERE students.student_id = $1
+AND students.deleted_at IS NULL
 `
 
 type GetStudentRow struct {
@@ -183,6 +175,7 @@ LEFT OUTER JOIN student_classes
     ON students.student_id = student_classes.student_id
 LEFT OUTER JOIN classes
     ON student_classes.class_id = classes.class_id
+WHERE students.deleted_at IS NULL
 ORDER BY students.student_id, students.last_name ASC
 `
 
@@ -232,6 +225,47 @@ func (q *Queries) ListStudents(ctx context.Context) ([]ListStudentsRow, error) {
 	return items, nil
 }
 
+const purgeStudents = `-- name: PurgeStudents :many
+DELETE FROM students
+WHERE deleted_at < $1
+RETURNING student_id
+`
+
+func (q *Queries) PurgeStudents(ctx context.Context, deletedAt pgtype.Timestamptz) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, purgeStudents, deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var student_id uuid.UUID
+		if err := rows.Scan(&student_id); err != nil {
+			return nil, err
+		}
+		items = append(items, student_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreStudent = `-- name: RestoreStudent :execrows
+UPDATE students
+SET deleted_at = NULL,
+deleted_by = NULL
+WHERE student_id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreStudent(ctx context.Context, studentID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreStudent, studentID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const searchStudentsByName = `-- name: SearchStudentsByName :many
 SELECT 
     student_id, 
@@ -240,7 +274,8 @@ SELECT
     last_name
 FROM students
 WHERE 
-    first_name ILIKE $1 OR last_name ILIKE $1
+    (first_name ILIKE $1 OR last_name ILIKE $1)
+    AND deleted_at IS NULL
 ORDER BY last_name, first_name
 `
 
@@ -276,6 +311,26 @@ func (q *Queries) SearchStudentsByName(ctx context.Context, firstName string) ([
 	return items, nil
 }
 
+const softDeleteStudent = `-- name: SoftDeleteStudent :execrows
+UPDATE students
+SET deleted_at = CURRENT_TIMESTAMP,
+deleted_by = $2
+WHERE student_id = $1 AND deleted_at IS NULL
+`
+
+type SoftDeleteStudentParams struct {
+	StudentID uuid.UUID   `json:"student_id"`
+	DeletedBy pgtype.UUID `json:"deleted_by"`
+}
+
+func (q *Queries) SoftDeleteStudent(ctx context.Context, arg SoftDeleteStudentParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteStudent, arg.StudentID, arg.DeletedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const upsertGuardian = `-- name: UpsertGuardian :one
 INSERT INTO guardians (
     guardian_name, 
@@ -9,13 +9,15 @@ import (
 	"context"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const createSubject = `-- name: CreateSubject :exec
+const createSubject = `-- name: CreateSubject :one
 INSERT INTO
     subjects (class_id, name)
 VALUES ($1, $2)
-ON CONFLICT ON CONSTRAINT unique_subject_name_per_class DO NOTHING
+ON CONFLICT (class_id, name) WHERE deleted_at IS NULL DO NOTHING
+RETURNING subject_id, class_id, name, deleted_at, deleted_by
 `
 
 type CreateSubjectParams struct {
@@ -23,18 +25,17 @@ type CreateSubjectParams struct {
 	Name    string    `json:"name"`
 }
 
-func (q *Queries) CreateSubject(ctx context.Context, arg CreateSubjectParams) error {
-	_, err := q.db.Exec(ctx, createSubject, arg.ClassID, arg.Name)
-	return err
-}
-
-const deleteSubject = `-- name: DeleteSubject :exec
-DELETE FROM subjects WHERE subject_id = $1
-`
-
-func (q *Queries) DeleteSubject(ctx context.Context, subjectID uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteSubject, subjectID)
-	return err
+func (q *Queries) CreateSubject(ctx context.Context, arg CreateSubjectParams) (Subject, error) {
+	row := q.db.QueryRow(ctx, createSubject, arg.ClassID, arg.Name)
+	var i Subject
+	err := row.Scan(
+		&i.SubjectID,
+		&i.ClassID,
+		&i.Name,
+		&i.DeletedAt,
+		&i.DeletedBy,
+	)
+	return i, err
 }
 
 const editSubject = `-- name: EditSubject :exec
@@ -54,13 +55,19 @@ func (q *Queries) EditSubject(ctx context.Context, arg EditSubjectParams) error
 }
 
 const getSubject = `-- name: GetSubject :one
-SELECT subject_id, class_id, name FROM subjects WHERE subject_id = $1
+SELECT subject_id, class_id, name, deleted_at, deleted_by FROM subjects WHERE subject_id = $1 AND deleted_at IS NULL
 `
 
 func (q *Queries) GetSubject(ctx context.Context, subjectID uuid.UUID) (Subject, error) {
 	row := q.db.QueryRow(ctx, getSubject, subjectID)
 	var i Subject
-	err := row.Scan(&i.SubjectID, &i.ClassID, &i.Name)
+	err := row.Scan(
+		&i.SubjectID,
+		&i.ClassID,
+		&i.Name,
+		&i.DeletedAt,
+		&i.DeletedBy,
+	)
 	return i, err
 }
 
@@ -73,6 +80,8 @@ SELECT
 FROM subjects
 INNER JOIN classes
     ON subjects.class_id = classes.class_id
+WHERE subjects.deleted_at IS NULL
+AND classes.deleted_at IS NULL
 ORDER BY subjects.name
 `
 
@@ -117,6 +126,7 @@ FROM subjects
 INNER JOIN classes
     ON subjects.class_id = classes.class_id
 WHERE classes.class_id = $1
+AND subjects.deleted_at IS NULL
 ORDER BY subjects.name
 `
 
@@ -145,3 +155,64 @@ func (q *Queries) ListSubjects(ctx context.Context, classID uuid.UUID) ([]ListSu
 	}
 	return items, nil
 }
+
+const purgeSubjects = `-- name: PurgeSubjects :many
+DELETE FROM subjects
+WHERE deleted_at < $1
+RETURNING subject_id
+`
+
+func (q *Queries) PurgeSubjects(ctx context.Context, deletedAt pgtype.Timestamptz) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, purgeSubjects, deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var subject_id uuid.UUID
+		if err := rows.Scan(&subject_id); err != nil {
+			return nil, err
+		}
+		items = append(items, subject_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreSubject = `-- name: RestoreSubject :execrows
+UPDATE subjects
+SET deleted_at = NULL,
+deleted_by = NULL
+WHERE subject_id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreSubject(ctx context.Context, subjectID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreSubject, subjectID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteSubject = `-- name: SoftDeleteSubject :execrows
+UPDATE subjects
+SET deleted_at = CURRENT_TIMESTAMP,
+deleted_by = $2
+WHERE subject_id = $1 AND deleted_at IS NULL
+`
+
+type SoftDeleteSubjectParams struct {
+	SubjectID uuid.UUID   `json:"subject_id"`
+	DeletedBy pgtype.UUID `json:"deleted_by"`
+}
+
+func (q *Queries) SoftDeleteSubject(ctx context.Context, arg SoftDeleteSubjectParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteSubject, arg.SubjectID, arg.DeletedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -13,7 +13,7 @@ import (
 )
 
 const createUser = `-- name: CreateUser :one
-INSERT INTO users (first_name, last_name, phone_number, email, gender, password, role_id)
+INSERT INTO users (first_name, last_name, phone_number, email, gender, password, role_id, must_change_password)
 VALUES (
     $1,
     $2,
@@ -21,20 +21,22 @@ VALUES (
     $4,
     $5,
     $6,
-    (SELECT role_id FROM roles WHERE name = $7)
+    (SELECT role_id FROM roles WHERE name = $7),
+    $8
 )
-ON CONFLICT (phone_number) DO NOTHING
-RETURNING user_id, user_no, last_name, first_name, gender, email, phone_number, password, created_at, updated_at, role_id
+ON CONFLICT (phone_number) WHERE deleted_at IS NULL DO NOTHING
+RETURNING user_id, user_no, last_name, first_name, gender, email, phone_number, password, created_at, updated_at, role_id, failed_login_attempts, last_failed_login_at, locked_until, must_change_password, totp_secret, totp_enabled_at, totp_last_counter, deleted_at, deleted_by
 `
 
 type CreateUserParams struct {
-	FirstName   string      `json:"first_name"`
-	LastName    string      `json:"last_name"`
-	PhoneNumber pgtype.Text `json:"phone_number"`
-	Email       pgtype.Text `json:"email"`
-	Gender      string      `json:"gender"`
-	Password    string      `json:"password"`
-	Name        string      `json:"name"`
+	FirstName          string      `json:"first_name"`
+	LastName           string      `json:"last_name"`
+	PhoneNumber        pgtype.Text `json:"phone_number"`
+	Email              pgtype.Text `json:"email"`
+	Gender             string      `json:"gender"`
+	Password           string      `json:"password"`
+	Name               string      `json:"name"`
+	MustChangePassword bool        `json:"must_change_password"`
 }
 
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
@@ -46,6 +48,7 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		arg.Gender,
 		arg.Password,
 		arg.Name,
+		arg.MustChangePassword,
 	)
 	var i User
 	err := row.Scan(
@@ -60,23 +63,23 @@ func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, e
 		&i.CreatedAt,
 		&i.UpdatedAt,
 		&i.RoleID,
+		&i.FailedLoginAttempts,
+		&i.LastFailedLoginAt,
+		&i.LockedUntil,
+		&i.MustChangePassword,
+		&i.TotpSecret,
+		&i.TotpEnabledAt,
+		&i.TotpLastCounter,
+		&i.DeletedAt,
+		&i.DeletedBy,
 	)
 	return i, err
 }
 
-const deleteUser = `-- name: DeleteUser :exec
-DELETE FROM users
-WHERE user_id = $1
-`
-
-func (q *Queries) DeleteUser(ctx context.Context, userID uuid.UUID) error {
-	_, err := q.db.Exec(ctx, deleteUser, userID)
-	return err
-}
-
 const editPassword = `-- name: EditPassword :exec
 UPDATE users
-    set password = COALESCE($2, password)
+    set password = COALESCE($2, password),
+    must_change_password = TRUE
 WHERE user_id = $1
 `
 
@@ -128,36 +131,58 @@ func (q *Queries) EditUser(ctx context.Context, arg EditUserParams) (uuid.UUID,
 }
 
 const getUserByPhone = `-- name: GetUserByPhone :one
-SELECT password, user_id FROM users 
-WHERE phone_number = $1
+SELECT password, user_id, failed_login_attempts, last_failed_login_at, locked_until, totp_enabled_at IS NOT NULL AS totp_enabled FROM users 
+WHERE phone_number = $1 AND deleted_at IS NULL
 `
 
 type GetUserByPhoneRow struct {
-	Password string    `json:"password"`
-	UserID   uuid.UUID `json:"user_id"`
+	Password            string             `json:"password"`
+	UserID              uuid.UUID          `json:"user_id"`
+	FailedLoginAttempts int32              `json:"failed_login_attempts"`
+	LastFailedLoginAt   pgtype.Timestamptz `json:"last_failed_login_at"`
+	LockedUntil         pgtype.Timestamptz `json:"locked_until"`
+	TotpEnabled         bool               `json:"totp_enabled"`
 }
 
 func (q *Queries) GetUserByPhone(ctx context.Context, phoneNumber pgtype.Text) (GetUserByPhoneRow, error) {
 	row := q.db.QueryRow(ctx, getUserByPhone, phoneNumber)
 	var i GetUserByPhoneRow
-	err := row.Scan(&i.Password, &i.UserID)
+	err := row.Scan(
+		&i.Password,
+		&i.UserID,
+		&i.FailedLoginAttempts,
+		&i.LastFailedLoginAt,
+		&i.LockedUntil,
+		&i.TotpEnabled,
+	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT password, user_id FROM users
-WHERE user_no = $1
+SELECT password, user_id, failed_login_attempts, last_failed_login_at, locked_until, totp_enabled_at IS NOT NULL AS totp_enabled FROM users
+WHERE user_no = $1 AND deleted_at IS NULL
 `
 
 type GetUserByUsernameRow struct {
-	Password string    `json:"password"`
-	UserID   uuid.UUID `json:"user_id"`
+	Password            string             `json:"password"`
+	UserID              uuid.UUID          `json:"user_id"`
+	FailedLoginAttempts int32              `json:"failed_login_attempts"`
+	LastFailedLoginAt   pgtype.Timestamptz `json:"last_failed_login_at"`
+	LockedUntil         pgtype.Timestamptz `json:"locked_until"`
+	TotpEnabled         bool               `json:"totp_enabled"`
 }
 
 func (q *Queries) GetUserByUsername(ctx context.Context, userNo string) (GetUserByUsernameRow, error) {
 	row := q.db.QueryRow(ctx, getUserByUsername, userNo)
 	var i GetUserByUsernameRow
-	err := row.Scan(&i.Password, &i.UserID)
+	err := row.Scan(
+		&i.Password,
+		&i.UserID,
+		&i.FailedLoginAttempts,
+		&i.LastFailedLoginAt,
+		&i.LockedUntil,
+		&i.TotpEnabled,
+	)
 	return i, err
 }
 
@@ -171,6 +196,7 @@ SELECT
     users.email, 
     users.phone_number,
     users.password, 
+    users.must_change_password,
     roles.name AS role
 FROM 
     users
@@ -180,18 +206,20 @@ ON
     users.role_id = roles.role_id
 WHERE 
     users.user_id = $1
+    AND users.deleted_at IS NULL
 `
 
 type GetUserDetailsRow struct {
-	UserID      uuid.UUID   `json:"user_id"`
-	UserNo      string      `json:"user_no"`
-	LastName    string      `json:"last_name"`
-	FirstName   string      `json:"first_name"`
-	Gender      string      `json:"gender"`
-	Email       pgtype.Text `json:"email"`
-	PhoneNumber pgtype.Text `json:"phone_number"`
-	Password    string      `json:"password"`
-	Role        string      `json:"role"`
+	UserID             uuid.UUID   `json:"user_id"`
+	UserNo             string      `json:"user_no"`
+	LastName           string      `json:"last_name"`
+	FirstName          string      `json:"first_name"`
+	Gender             string      `json:"gender"`
+	Email              pgtype.Text `json:"email"`
+	PhoneNumber        pgtype.Text `json:"phone_number"`
+	Password           string      `json:"password"`
+	MustChangePassword bool        `json:"must_change_password"`
+	Role               string      `json:"role"`
 }
 
 func (q *Queries) GetUserDetails(ctx context.Context, userID uuid.UUID) (GetUserDetailsRow, error) {
@@ -206,11 +234,70 @@ func (q *Queries) GetUserDetails(ctx context.Context, userID uuid.UUID) (GetUser
 		&i.Email,
 		&i.PhoneNumber,
 		&i.Password,
+		&i.MustChangePassword,
 		&i.Role,
 	)
 	return i, err
 }
 
+const listLockedUsers = `-- name: ListLockedUsers :many
+SELECT
+    users.user_id,
+    users.user_no,
+    users.last_name,
+    users.first_name,
+    users.failed_login_attempts,
+    users.last_failed_login_at,
+    users.locked_until,
+    roles.name AS role
+FROM users
+INNER JOIN roles ON users.role_id = roles.role_id
+WHERE users.deleted_at IS NULL
+  AND (users.failed_login_attempts > 0
+   OR users.locked_until > CURRENT_TIMESTAMP)
+ORDER BY users.locked_until DESC NULLS LAST, users.last_failed_login_at DESC
+`
+
+type ListLockedUsersRow struct {
+	UserID              uuid.UUID          `json:"user_id"`
+	UserNo              string             `json:"user_no"`
+	LastName            string             `json:"last_name"`
+	FirstName           string             `json:"first_name"`
+	FailedLoginAttempts int32              `json:"failed_login_attempts"`
+	LastFailedLoginAt   pgtype.Timestamptz `json:"last_failed_login_at"`
+	LockedUntil         pgtype.Timestamptz `json:"locked_until"`
+	Role                string             `json:"role"`
+}
+
+func (q *Queries) ListLockedUsers(ctx context.Context) ([]ListLockedUsersRow, error) {
+	rows, err := q.db.Query(ctx, listLockedUsers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ListLockedUsersRow{}
+	for rows.Next() {
+		var i ListLockedUsersRow
+		if err := rows.Scan(
+			&i.UserID,
+			&i.UserNo,
+			&i.LastName,
+			&i.FirstName,
+			&i.FailedLoginAttempts,
+			&i.LastFailedLoginAt,
+			&i.LockedUntil,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
 SELECT
     users.user_id,
@@ -224,6 +311,7 @@ SELECT
     roles.name AS role
 FROM users
 INNER JOIN roles ON users.role_id = roles.role_id
+WHERE users.deleted_at IS NULL
 ORDER BY last_name
 `
 
@@ -268,3 +356,136 @@ func (q *Queries) ListUsers(ctx context.Context) ([]ListUsersRow, error) {
 	}
 	return items, nil
 }
+
+const purgeUsers = `-- name: PurgeUsers :many
+DELETE FROM users
+WHERE deleted_at < $1
+RETURNING user_id
+`
+
+func (q *Queries) PurgeUsers(ctx context.Context, deletedAt pgtype.Timestamptz) ([]uuid.UUID, error) {
+	rows, err := q.db.Query(ctx, purgeUsers, deletedAt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []uuid.UUID{}
+	for rows.Next() {
+		var user_id uuid.UUID
+		if err := rows.Scan(&user_id); err != nil {
+			return nil, err
+		}
+		items = append(items, user_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const releaseFailedLogin = `-- name: ReleaseFailedLogin :exec
+UPDATE users
+    set failed_login_attempts = GREATEST(failed_login_attempts - 1, 0),
+    locked_until = CASE
+        WHEN failed_login_attempts - 1 >= $1::int THEN locked_until
+        ELSE NULL
+    END
+WHERE user_id = $2
+`
+
+type ReleaseFailedLoginParams struct {
+	MaxFailures int32     `json:"max_failures"`
+	UserID      uuid.UUID `json:"user_id"`
+}
+
+// Takes back a failure reserved for an attempt that turned out right, and the lockout it may have caused.
+func (q *Queries) ReleaseFailedLogin(ctx context.Context, arg ReleaseFailedLoginParams) error {
+	_, err := q.db.Exec(ctx, releaseFailedLogin, arg.MaxFailures, arg.UserID)
+	return err
+}
+
+const reserveFailedLogin = `-- name: ReserveFailedLogin :one
+UPDATE users
+    set failed_login_attempts = failed_login_attempts + 1,
+    last_failed_login_at = CURRENT_TIMESTAMP,
+    locked_until = CASE
+        WHEN failed_login_attempts + 1 >= $1::int THEN $2::timestamptz
+        ELSE locked_until
+    END
+WHERE user_id = $3 AND failed_login_attempts = $4
+RETURNING failed_login_attempts, locked_until
+`
+
+type ReserveFailedLoginParams struct {
+	MaxFailures         int32              `json:"max_failures"`
+	LockedUntil         pgtype.Timestamptz `json:"locked_until"`
+	UserID              uuid.UUID          `json:"user_id"`
+	FailedLoginAttempts int32              `json:"failed_login_attempts"`
+}
+
+type ReserveFailedLoginRow struct {
+	FailedLoginAttempts int32              `json:"failed_login_attempts"`
+	LockedUntil         pgtype.Timestamptz `json:"locked_until"`
+}
+
+// Counts a failure before the password or code is checked. Nothing is counted if another attempt
+// was counted since failed_login_attempts was read, so parallel guesses can't all pass one backoff check.
+func (q *Queries) ReserveFailedLogin(ctx context.Context, arg ReserveFailedLoginParams) (ReserveFailedLoginRow, error) {
+	row := q.db.QueryRow(ctx, reserveFailedLogin,
+		arg.MaxFailures,
+		arg.LockedUntil,
+		arg.UserID,
+		arg.FailedLoginAttempts,
+	)
+	var i ReserveFailedLoginRow
+	err := row.Scan(&i.FailedLoginAttempts, &i.LockedUntil)
+	return i, err
+}
+
+const resetFailedLogins = `-- name: ResetFailedLogins :exec
+UPDATE users
+    set failed_login_attempts = 0,
+    last_failed_login_at = NULL,
+    locked_until = NULL
+WHERE user_id = $1
+`
+
+func (q *Queries) ResetFailedLogins(ctx context.Context, userID uuid.UUID) error {
+	_, err := q.db.Exec(ctx, resetFailedLogins, userID)
+	return err
+}
+
+const restoreUser = `-- name: RestoreUser :execrows
+UPDATE users
+SET deleted_at = NULL,
+deleted_by = NULL
+WHERE user_id = $1 AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) RestoreUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, restoreUser, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const softDeleteUser = `-- name: SoftDeleteUser :execrows
+UPDATE users
+SET deleted_at = CURRENT_TIMESTAMP,
+deleted_by = $2
+WHERE user_id = $1 AND deleted_at IS NULL
+`
+
+type SoftDeleteUserParams struct {
+	UserID    uuid.UUID   `json:"user_id"`
+	DeletedBy pgtype.UUID `json:"deleted_by"`
+}
+
+func (q *Queries) SoftDeleteUser(ctx context.Context, arg SoftDeleteUserParams) (int64, error) {
+	result, err := q.db.Exec(ctx, softDeleteUser, arg.UserID, arg.DeletedBy)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
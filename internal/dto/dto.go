@@ -3,11 +3,25 @@ package dto
 import "github.com/google/uuid"
 
 // Grade represents the grade details for a subject.
+// Position is the student's rank in the subject for the term; it is only set on report cards.
 type Grade struct {
-	Remark  string    `json:"remark"`
-	Score   float64   `json:"score"`
-	GradeID uuid.UUID `json:"grade_id"`
+	Remark   string    `json:"remark"`
+	Score    float64   `json:"score"`
+	GradeID  uuid.UUID `json:"grade_id"`
+	Position int       `json:"position,omitempty"`
 }
 
 // GradesMap maps subject names to their corresponding grade details.
 type GradesMap map[uuid.UUID]Grade
+
+// AnnualGrade represents a subject's result for an academic year.
+// Score is the weighted average of the term scores in Terms, which are keyed by term.
+type AnnualGrade struct {
+	Subject  string                `json:"subject"`
+	Score    float64               `json:"score"`
+	Position int                   `json:"position"`
+	Terms    map[uuid.UUID]float64 `json:"terms"`
+}
+
+// AnnualGradesMap maps subjects to their results for an academic year.
+type AnnualGradesMap map[uuid.UUID]AnnualGrade
@@ -0,0 +1,100 @@
+package grading
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+const (
+	// TotalWeight is what the weights of a subject's components must add up to.
+	TotalWeight = 100
+	// maxComponentNameLength matches the assessment_components.name column.
+	maxComponentNameLength = 50
+)
+
+var ErrInvalidComponents = errors.New("invalid assessment components")
+
+// Component is a weighted part of a subject's final score, such as a mid-term test.
+// Its weight is the percentage of the final score it makes up.
+type Component struct {
+	Name   string
+	Weight float64
+}
+
+// WeightedScore is a score for a component with the component's weight.
+type WeightedScore struct {
+	Score  float64
+	Weight float64
+}
+
+// FinalScore is the weighted average of the component scores, rounded to two decimals.
+// A component without a score should be passed with a zero score.
+func FinalScore(scores []WeightedScore) float64 {
+	var total, weights float64
+	for _, s := range scores {
+		total += s.Score * s.Weight
+		weights += s.Weight
+	}
+
+	if weights == 0 {
+		return 0
+	}
+
+	return math.Round(total/weights*100) / 100
+}
+
+// ParseComponents reads components written one per line as "weight name",
+// for example "30 Continuous Assessment". Blank lines are skipped. Blank text means the subject
+// has no components; otherwise the weights must add up to TotalWeight.
+func ParseComponents(text string) ([]Component, error) {
+	var components []Component
+	names := make(map[string]bool)
+	var total float64
+
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		weightText, name, ok := strings.Cut(line, " ")
+		name = strings.Join(strings.Fields(name), " ")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("%w: line %d must look like 30 Continuous Assessment", ErrInvalidComponents, n+1)
+		}
+		if len(name) > maxComponentNameLength {
+			return nil, fmt.Errorf("%w: the name on line %d is longer than %d characters", ErrInvalidComponents, n+1, maxComponentNameLength)
+		}
+		if names[strings.ToLower(name)] {
+			return nil, fmt.Errorf("%w: %s is listed twice", ErrInvalidComponents, name)
+		}
+		names[strings.ToLower(name)] = true
+
+		weight, err := strconv.ParseFloat(weightText, 64)
+		if err != nil || weight <= 0 || weight > TotalWeight {
+			return nil, fmt.Errorf("%w: the weight of %s must be a number above 0 and at most %d", ErrInvalidComponents, name, TotalWeight)
+		}
+		total += weight
+
+		components = append(components, Component{Name: name, Weight: weight})
+	}
+
+	if len(components) > 0 && math.Abs(total-TotalWeight) > 0.001 {
+		return nil, fmt.Errorf("%w: the weights add up to %s, not %d", ErrInvalidComponents, formatScore(total), TotalWeight)
+	}
+
+	return components, nil
+}
+
+// FormatComponents writes components in the form ParseComponents reads.
+func FormatComponents(components []Component) string {
+	lines := make([]string, len(components))
+	for i, c := range components {
+		lines[i] = formatScore(c.Weight) + " " + c.Name
+	}
+
+	return strings.Join(lines, "\n")
+}
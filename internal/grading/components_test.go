@@ -0,0 +1,61 @@
+package grading
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParseComponents(t *testing.T) {
+	components, err := ParseComponents("\n30 Continuous  Assessment\n20 Mid-term Test\n50 End of Term Exam\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(components) != 3 || components[0].Name != "Continuous Assessment" || components[2].Weight != 50 {
+		t.Fatalf("unexpected components %+v", components)
+	}
+
+	again, err := ParseComponents(FormatComponents(components))
+	if err != nil || FormatComponents(again) != FormatComponents(components) {
+		t.Errorf("expected FormatComponents to round trip; got %+v, %v", again, err)
+	}
+
+	if components, err := ParseComponents("  \n"); err != nil || len(components) != 0 {
+		t.Errorf("expected blank text to mean no components; got %+v, %v", components, err)
+	}
+}
+
+func TestParseComponents_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"no name":        "100",
+		"bad weight":     "thirty Test\n70 Exam",
+		"zero weight":    "0 Test\n100 Exam",
+		"duplicate name": "50 Exam\n50 exam",
+		"under 100":      "30 Test\n60 Exam",
+		"over 100":       "50 Test\n60 Exam",
+	}
+
+	for name, text := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := ParseComponents(text); !errors.Is(err, ErrInvalidComponents) {
+				t.Errorf("expected ErrInvalidComponents; got %v", err)
+			}
+		})
+	}
+}
+
+func TestFinalScore(t *testing.T) {
+	scores := []WeightedScore{{Score: 80, Weight: 30}, {Score: 65, Weight: 20}, {Score: 71.5, Weight: 50}}
+	if got := FinalScore(scores); got != 72.75 {
+		t.Errorf("expected 72.75; got %v", got)
+	}
+
+	// A missing component counts as zero.
+	if got := FinalScore([]WeightedScore{{Score: 90, Weight: 40}, {Weight: 60}}); got != 36 {
+		t.Errorf("expected 36; got %v", got)
+	}
+
+	if got := FinalScore(nil); got != 0 {
+		t.Errorf("expected 0 without components; got %v", got)
+	}
+}
@@ -0,0 +1,206 @@
+// Package grading maps raw scores to the letter grades and descriptors of a grading scale.
+//
+// A scale is a set of bands such as 80-100 = A "Excellent". Bands are inclusive at both ends and
+// must not overlap, but may leave gaps; a score in a gap has no letter grade. The bands of a scale
+// also give the range of scores that may be entered for a class using it.
+//
+// A subject's score may also be made up of weighted assessment components, such as continuous
+// assessment, a mid-term test and an end-of-term exam; see Component.
+package grading
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	// DefaultMinScore and DefaultMaxScore bound scores in classes without a grading scale.
+	DefaultMinScore = 0
+	DefaultMaxScore = 100
+	// maxScore is the largest score a grade can hold (NUMERIC(5,2)).
+	maxScore = 999.99
+	// maxLetterLength and maxDescriptorLength match the grading_scale_bands columns.
+	maxLetterLength     = 5
+	maxDescriptorLength = 100
+)
+
+var ErrInvalidBands = errors.New("invalid grading bands")
+
+// Band is one letter grade of a scale.
+type Band struct {
+	MinScore   float64
+	MaxScore   float64
+	Letter     string
+	Descriptor string
+}
+
+// Contains reports whether score falls inside the band.
+func (b Band) Contains(score float64) bool {
+	return score >= b.MinScore && score <= b.MaxScore
+}
+
+// Scale is a grading scale, its bands ordered from the highest to the lowest scores.
+// A nil Scale means no scale is assigned.
+type Scale []Band
+
+// NewScale orders bands from the highest to the lowest scores and checks they form a valid scale.
+func NewScale(bands []Band) (Scale, error) {
+	scale := make(Scale, len(bands))
+	copy(scale, bands)
+	sort.Slice(scale, func(i, j int) bool { return scale[i].MinScore > scale[j].MinScore })
+
+	if err := scale.validate(); err != nil {
+		return nil, err
+	}
+
+	return scale, nil
+}
+
+func (s Scale) validate() error {
+	if len(s) == 0 {
+		return fmt.Errorf("%w: a scale needs at least one band", ErrInvalidBands)
+	}
+
+	letters := make(map[string]bool, len(s))
+	for i, band := range s {
+		if band.Letter == "" || len(band.Letter) > maxLetterLength {
+			return fmt.Errorf("%w: every band needs a letter of at most %d characters", ErrInvalidBands, maxLetterLength)
+		}
+		if len(band.Descriptor) > maxDescriptorLength {
+			return fmt.Errorf("%w: the descriptor of %s is longer than %d characters", ErrInvalidBands, band.Letter, maxDescriptorLength)
+		}
+		if letters[band.Letter] {
+			return fmt.Errorf("%w: letter %s is used twice", ErrInvalidBands, band.Letter)
+		}
+		letters[band.Letter] = true
+
+		if band.MinScore < 0 || band.MaxScore > maxScore || band.MinScore > band.MaxScore {
+			return fmt.Errorf("%w: %s must span scores between 0 and %g, lowest first", ErrInvalidBands, band.Letter, maxScore)
+		}
+		if i > 0 && band.MaxScore >= s[i-1].MinScore {
+			return fmt.Errorf("%w: %s overlaps %s", ErrInvalidBands, band.Letter, s[i-1].Letter)
+		}
+	}
+
+	return nil
+}
+
+// Grade returns the band score falls in, if any.
+func (s Scale) Grade(score float64) (Band, bool) {
+	for _, band := range s {
+		if band.Contains(score) {
+			return band, true
+		}
+	}
+
+	return Band{}, false
+}
+
+// Letter returns the letter grade for score, or "" if there is none.
+func (s Scale) Letter(score float64) string {
+	band, _ := s.Grade(score)
+	return band.Letter
+}
+
+// Range returns the lowest and highest score that may be entered: the span of the bands,
+// or the default range without a scale.
+func (s Scale) Range() (float64, float64) {
+	if len(s) == 0 {
+		return DefaultMinScore, DefaultMaxScore
+	}
+
+	return s[len(s)-1].MinScore, s[0].MaxScore
+}
+
+// Accepts reports whether score is inside the scale's range.
+func (s Scale) Accepts(score float64) bool {
+	lowest, highest := s.Range()
+	return score >= lowest && score <= highest
+}
+
+// BandCount is how many scores fell in a band.
+type BandCount struct {
+	Band  Band
+	Count int
+}
+
+// Distribution counts the scores in each band, in the order of the scale.
+// Scores in a gap between bands are not counted.
+func (s Scale) Distribution(scores []float64) []BandCount {
+	counts := make([]BandCount, len(s))
+	for i, band := range s {
+		counts[i].Band = band
+	}
+
+	for _, score := range scores {
+		for i, band := range s {
+			if band.Contains(score) {
+				counts[i].Count++
+				break
+			}
+		}
+	}
+
+	return counts
+}
+
+// ParseBands reads bands written one per line as "min-max letter descriptor",
+// for example "80-100 A Excellent". Blank lines are skipped and the descriptor may be empty.
+func ParseBands(text string) (Scale, error) {
+	var bands []Band
+	for n, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%w: line %d must look like 80-100 A Excellent", ErrInvalidBands, n+1)
+		}
+
+		lowest, highest, ok := strings.Cut(fields[0], "-")
+		if !ok {
+			return nil, fmt.Errorf("%w: line %d must start with a score range such as 80-100", ErrInvalidBands, n+1)
+		}
+
+		low, err := strconv.ParseFloat(lowest, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d has an invalid lowest score", ErrInvalidBands, n+1)
+		}
+		high, err := strconv.ParseFloat(highest, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d has an invalid highest score", ErrInvalidBands, n+1)
+		}
+
+		bands = append(bands, Band{
+			MinScore:   low,
+			MaxScore:   high,
+			Letter:     fields[1],
+			Descriptor: strings.Join(fields[2:], " "),
+		})
+	}
+
+	return NewScale(bands)
+}
+
+// String writes the scale in the form ParseBands reads.
+func (s Scale) String() string {
+	lines := make([]string, len(s))
+	for i, band := range s {
+		line := fmt.Sprintf("%s-%s %s", formatScore(band.MinScore), formatScore(band.MaxScore), band.Letter)
+		if band.Descriptor != "" {
+			line += " " + band.Descriptor
+		}
+		lines[i] = line
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func formatScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
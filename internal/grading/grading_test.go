@@ -0,0 +1,93 @@
+package grading
+
+import (
+	"errors"
+	"testing"
+)
+
+const testBands = `
+50-69.99 C Good
+80-100 A Excellent
+70-79.99 B Very good
+0-49.99 F
+`
+
+func TestParseBands(t *testing.T) {
+	scale, err := ParseBands(testBands)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(scale) != 4 || scale[0].Letter != "A" || scale[3].Letter != "F" {
+		t.Fatalf("expected the bands ordered from A to F; got %+v", scale)
+	}
+	if scale[1].Descriptor != "Very good" || scale[3].Descriptor != "" {
+		t.Errorf("unexpected descriptors %q and %q", scale[1].Descriptor, scale[3].Descriptor)
+	}
+
+	again, err := ParseBands(scale.String())
+	if err != nil || again.String() != scale.String() {
+		t.Errorf("expected String to round trip; got %q, %v", again.String(), err)
+	}
+}
+
+func TestParseBands_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"empty":            "",
+		"no letter":        "80-100",
+		"no range":         "80 A",
+		"bad score":        "eighty-100 A",
+		"reversed range":   "100-80 A",
+		"overlap":          "80-100 A\n70-80 B",
+		"duplicate letter": "80-100 A\n70-79 A",
+		"too high":         "80-1000 A",
+		"long letter":      "80-100 ABCDEF",
+	}
+
+	for name, text := range tests {
+		if _, err := ParseBands(text); !errors.Is(err, ErrInvalidBands) {
+			t.Errorf("%s: expected ErrInvalidBands; got %v", name, err)
+		}
+	}
+}
+
+func TestScale_Grade(t *testing.T) {
+	scale, err := ParseBands(testBands)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := map[float64]string{100: "A", 80: "A", 79.99: "B", 55.5: "C", 0: "F"}
+	for score, letter := range tests {
+		if got := scale.Letter(score); got != letter {
+			t.Errorf("expected %v to be %s; got %q", score, letter, got)
+		}
+	}
+
+	if lowest, highest := scale.Range(); lowest != 0 || highest != 100 {
+		t.Errorf("expected a 0-100 range; got %v-%v", lowest, highest)
+	}
+	if scale.Accepts(100.5) || !scale.Accepts(42) {
+		t.Error("expected only scores inside the range to be accepted")
+	}
+
+	var none Scale
+	if none.Letter(75) != "" || !none.Accepts(100) || none.Accepts(101) {
+		t.Error("expected no letters and the default range without a scale")
+	}
+}
+
+func TestScale_Distribution(t *testing.T) {
+	scale, err := ParseBands("50-100 P Pass\n0-40 F Fail")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counts := scale.Distribution([]float64{90, 50, 45, 10, 0})
+	if counts[0].Band.Letter != "P" || counts[0].Count != 2 {
+		t.Errorf("expected two passes; got %+v", counts[0])
+	}
+	if counts[1].Band.Letter != "F" || counts[1].Count != 2 {
+		t.Errorf("expected two fails, the score in the gap not counted; got %+v", counts[1])
+	}
+}
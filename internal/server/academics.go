@@ -1,10 +1,11 @@
 package server
 
 import (
-	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"school_management_system/cmd/web/components"
@@ -23,7 +24,7 @@ func (s *Server) ShowCreateAcademicYear(w http.ResponseWriter, r *http.Request)
 // CreateAcademicYear handler method creates an academic year or school calender.
 func (s *Server) CreateAcademicYear(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "failed to parse form")
+		writeError(w, r, http.StatusUnprocessableEntity, "failed to parse form")
 		return
 	}
 
@@ -33,46 +34,31 @@ func (s *Server) CreateAcademicYear(w http.ResponseWriter, r *http.Request) {
 
 	// validate form
 	if name == "" || start == "" || end == "" {
-		writeError(w, http.StatusBadRequest, "all fields are required")
+		writeError(w, r, http.StatusBadRequest, "all fields are required")
 		return
 	}
 	startDate, err := time.Parse(time.DateOnly, start)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "failed to parse start date")
+		writeError(w, r, http.StatusBadRequest, "failed to parse start date")
 		return
 	}
 
 	endDate, err := time.Parse(time.DateOnly, end)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "failed to parse end date")
+		writeError(w, r, http.StatusBadRequest, "failed to parse end date")
 		return
 	}
 
 	// Restrict creating academic year in the past
 	if endDate.Before(time.Now()) {
-		if r.Header.Get("HX-Request") != "" {
-			w.Header().Set("Content-Type", "text/html")
-			_, _ = w.Write([]byte(`
-					<div id="popover" class="custom-popover show" style="background-color: #dc2626;">
-						<span>❌ Can not create an academic year in the past</span>
-					</div>
-					<script>
-						setTimeout(() => {
-							document.getElementById('popover').classList.add('hide');
-							setTimeout(() => document.getElementById('popover').remove(), 500);
-						}, 3000);
-					</script>
-				`))
-			return
-		}
-		slog.Error("academic year can not be in the past")
+		writeError(w, r, http.StatusUnprocessableEntity, "Can not create an academic year in the past")
 		return
 	}
 
 	ctx := r.Context()
 	tx, err := s.conn.Begin(ctx)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to start transaction")
+		writeError(w, r, http.StatusInternalServerError, "failed to start transaction")
 		return
 	}
 	defer tx.Rollback(ctx)
@@ -83,25 +69,8 @@ func (s *Server) CreateAcademicYear(w http.ResponseWriter, r *http.Request) {
 
 	graduateClass, err := qtx.CreateGraduateClass(ctx, graduateClassName)
 	if err != nil {
-		if r.Header.Get("HX-Request") != "" {
-			w.Header().Set("Content-Type", "text/html")
-			_, _ = w.Write([]byte(`
-					<div id="popover" class="custom-popover show" style="background-color: #dc2626;">
-						<span>
-							❌ Can not create academic year graduate's class.
-							Please Check that the end year of the academic year does not overlap with any available academic year.
-							</span>
-					</div>
-					<script>
-						setTimeout(() => {
-							document.getElementById('popover').classList.add('hide');
-							setTimeout(() => document.getElementById('popover').remove(), 500);
-						}, 3000);
-					</script>
-				`))
-			return
-		}
 		slog.Error("failed to create graduate class", "error", err.Error())
+		writeError(w, r, http.StatusConflict, "Can not create academic year graduate's class. Please Check that the end year of the academic year does not overlap with any available academic year.")
 		return
 	}
 
@@ -118,48 +87,44 @@ func (s *Server) CreateAcademicYear(w http.ResponseWriter, r *http.Request) {
 		GraduateClassID: pgtype.UUID{Bytes: [16]byte(graduateClassBytes), Valid: true},
 	}
 
-	_, err = qtx.CreateAcademicYear(ctx, params)
-	if err != nil {
-		if r.Header.Get("HX-Request") != "" {
-			w.Header().Set("Content-Type", "text/html")
-			_, _ = w.Write([]byte(`
-					<div id="popover" class="custom-popover show" style="background-color: #dc2626;">
-						<p> ❌ Failed to create a new academic year. 
-						</p>
-						<p> HINT: Make sure the Start and End period of an academic year does not overlap with any other</p>
-					</div>
-					<script>
-						setTimeout(() => {
-							document.getElementById('popover').classList.add('hide');
-							setTimeout(() => document.getElementById('popover').remove(), 500);
-						}, 3000);
-					</script>
-				`))
-			return
-		}
+	academicYearID, err := qtx.CreateAcademicYear(ctx, params)
+	if err != nil {
+		slog.Error("failed to create academic year", "error", err.Error())
+		writeAppError(w, r, NewAppError(http.StatusConflict, "Failed to create a new academic year").
+			WithField("start", "the start and end of an academic year must not overlap with any other").
+			Wrap(err))
+		return
+	}
+
+	academicYear, err := qtx.GetAcademicYear(ctx, academicYearID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to create a new academic year")
+		slog.Error("failed to get academic year", "error", err.Error())
+		return
+	}
+
+	if err := recordAudit(r, qtx, auditCreate, auditClass, graduateClass.ClassID, nil, graduateClass); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to create a new academic year")
+		slog.Error("failed to write audit log", "error", err.Error())
+		return
+	}
+
+	if err := recordAudit(r, qtx, auditCreate, auditAcademicYear, academicYearID, nil, academicYear); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to create a new academic year")
+		slog.Error("failed to write audit log", "error", err.Error())
+		return
 	}
 
 	err = tx.Commit(ctx)
 	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to create a new academic year")
 		slog.Error("failed to commit transaction", "error", err.Error())
 		return
 	}
 
 	if r.Header.Get("HX-Request") != "" {
-		w.Header().Set("Content-Type", "text/html")
-		w.WriteHeader(http.StatusOK)
-		_, _ = w.Write([]byte(`
-			<div id="popover" class="custom-popover show" style="background-color: #16a34a;">
-				<span>✅ Academic year created successfully</span>
-			</div>
-			<script>
-				setTimeout(() => {
-					document.getElementById('popover').classList.add('hide');
-					setTimeout(() => document.getElementById('popover').remove(), 500);
-				}, 3000);
-				window.location.reload()
-			</script>
-		`))
+		w.Header().Set("HX-Refresh", "true")
+		writeSuccess(w, r, "Academic year created successfully")
 		return
 	}
 
@@ -191,13 +156,13 @@ func (s *Server) ListAcademicYears(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ShowEditAcademicYear(w http.ResponseWriter, r *http.Request) {
 	academicYearID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid academic year")
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid academic year")
 		return
 	}
 
 	academicYear, err := s.queries.GetAcademicYear(r.Context(), academicYearID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("academic year not found", "message", err.Error())
 		return
 	}
@@ -209,12 +174,12 @@ func (s *Server) ShowEditAcademicYear(w http.ResponseWriter, r *http.Request) {
 func (s *Server) EditAcademicYear(w http.ResponseWriter, r *http.Request) {
 	academic_year_id, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "failed to parse id")
+		writeError(w, r, http.StatusUnprocessableEntity, "failed to parse id")
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "failed to parse form")
+		writeError(w, r, http.StatusUnprocessableEntity, "failed to parse form")
 		return
 	}
 
@@ -224,18 +189,18 @@ func (s *Server) EditAcademicYear(w http.ResponseWriter, r *http.Request) {
 
 	// validate form
 	if name == "" || start == "" || end == "" {
-		writeError(w, http.StatusBadRequest, "all fields are required")
+		writeError(w, r, http.StatusBadRequest, "all fields are required")
 		return
 	}
 	startDate, err := time.Parse(time.DateOnly, start)
 	if err != nil {
-		writeError(w, http.StatusBadGateway, "failed to parse start date")
+		writeError(w, r, http.StatusBadGateway, "failed to parse start date")
 		return
 	}
 
 	endDate, err := time.Parse(time.DateOnly, end)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "failed to parse end date")
+		writeError(w, r, http.StatusBadRequest, "failed to parse end date")
 		return
 	}
 
@@ -246,9 +211,26 @@ func (s *Server) EditAcademicYear(w http.ResponseWriter, r *http.Request) {
 		AcademicYearID: academic_year_id,
 	}
 
-	err = s.queries.EditAcademicYear(r.Context(), params)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		before, err := qtx.GetAcademicYear(r.Context(), academic_year_id)
+		if err != nil {
+			return err
+		}
+
+		if err := qtx.EditAcademicYear(r.Context(), params); err != nil {
+			return err
+		}
+
+		after, err := qtx.GetAcademicYear(r.Context(), academic_year_id)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditUpdate, auditAcademicYear, academic_year_id, before, after)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to edit academic year", "error", err.Error())
 		return
 	}
 
@@ -261,6 +243,26 @@ func (s *Server) EditAcademicYear(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/academics/years", http.StatusFound)
 }
 
+// maxReportWeight is the largest weight a term can count for in the annual report (NUMERIC(5,2)).
+const maxReportWeight = 999.99
+
+// parseReportWeight reads how much a term counts towards the annual report. A blank weight is 1.
+func parseReportWeight(value string) (pgtype.Numeric, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return numericFromFloat(1), nil
+	}
+
+	weight, err := strconv.ParseFloat(value, 64)
+	if err != nil || weight <= 0 || weight > maxReportWeight {
+		return pgtype.Numeric{}, ValidationError("invalid report weight", map[string]string{
+			"report_weight": fmt.Sprintf("must be a number above 0 and at most %g", maxReportWeight),
+		})
+	}
+
+	return numericFromFloat(weight), nil
+}
+
 // CreateTermForm handler method renders the CreateTermForm form
 func (s *Server) CreateTermForm(w http.ResponseWriter, r *http.Request) {
 	academicYearID := r.PathValue("id")
@@ -271,19 +273,19 @@ func (s *Server) CreateTermForm(w http.ResponseWriter, r *http.Request) {
 // CreateTerm handler function
 func (s *Server) CreateTerm(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "failed to parse form")
+		writeError(w, r, http.StatusUnprocessableEntity, "failed to parse form")
 		return
 	}
 
 	academicYearID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid academic year id")
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid academic year id")
 		return
 	}
 
 	academicYear, err := s.queries.GetAcademicYear(r.Context(), academicYearID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
@@ -298,36 +300,60 @@ func (s *Server) CreateTerm(w http.ResponseWriter, r *http.Request) {
 
 	// validate form
 	if name == "" || start == "" || end == "" {
-		writeError(w, http.StatusBadRequest, "all fields are required")
+		writeError(w, r, http.StatusBadRequest, "all fields are required")
 		return
 	}
 	startDate, err := time.Parse(time.DateOnly, start)
 	if err != nil {
-		writeError(w, http.StatusBadGateway, "failed to parse start date")
+		writeError(w, r, http.StatusBadGateway, "failed to parse start date")
 		return
 	}
 
 	endDate, err := time.Parse(time.DateOnly, end)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "failed to parse end date")
+		writeError(w, r, http.StatusBadRequest, "failed to parse end date")
 		return
 	}
 
 	// validate academic year dates with terms dates
 	if startDate.Format(time.DateOnly) > academicYear.EndDate.Time.Format(time.DateOnly) || startDate.Format(time.DateOnly) < academicYear.StartDate.Time.Format(time.DateOnly) || endDate.Format(time.DateOnly) > academicYear.EndDate.Time.Format(time.DateOnly) {
-		writeError(w, http.StatusBadRequest, "bad request")
+		writeError(w, r, http.StatusBadRequest, "bad request")
 		slog.Error("invalid term starting date")
 		return
 	}
 
+	reportWeight, err := parseReportWeight(r.FormValue("report_weight"))
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
 	params := database.CreateTermParams{
 		AcademicYearID: academicYearID,
 		Name:           name,
 		StartDate:      pgtype.Date{Time: startDate, Valid: true},
 		EndDate:        pgtype.Date{Time: endDate, Valid: true},
+		ReportWeight:   reportWeight,
 	}
 
-	_, err = s.queries.CreateTerm(r.Context(), params)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		termID, err := qtx.CreateTerm(r.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		term, err := qtx.GetTerm(r.Context(), termID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditCreate, auditTerm, termID, nil, term)
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to create term")
+		slog.Error("failed to create term", "error", err.Error())
+		return
+	}
 
 	if r.Header.Get("HX-Request") != "" {
 		w.Header().Set("HX-Redirect", "/academics/years")
@@ -342,13 +368,13 @@ func (s *Server) CreateTerm(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ListTerms(w http.ResponseWriter, r *http.Request) {
 	academicYear, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "bad request")
+		writeError(w, r, http.StatusBadRequest, "bad request")
 		slog.Error("failed to parse academic year id")
 	}
 
 	terms, err := s.queries.ListTerms(r.Context(), academicYear)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to retrieve terms")
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve terms")
 		return
 	}
 
@@ -360,14 +386,14 @@ func (s *Server) ListTerms(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ShowEditAcademicTerm(w http.ResponseWriter, r *http.Request) {
 	termID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid academic term")
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid academic term")
 		slog.Error("failed to parse term id", "message:", err.Error())
 		return
 	}
 
 	academicTerm, err := s.queries.GetTerm(r.Context(), termID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("academic term not found", "message:", err.Error())
 		return
 	}
@@ -379,24 +405,24 @@ func (s *Server) ShowEditAcademicTerm(w http.ResponseWriter, r *http.Request) {
 func (s *Server) EditTerm(w http.ResponseWriter, r *http.Request) {
 	termID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "wrong parameters")
+		writeError(w, r, http.StatusUnprocessableEntity, "wrong parameters")
 		return
 	}
 
 	academicTerm, err := s.queries.GetTerm(r.Context(), termID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
 	academicYear, err := s.queries.GetAcademicYear(r.Context(), academicTerm.AcademicYearID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "failed to parse form")
+		writeError(w, r, http.StatusUnprocessableEntity, "failed to parse form")
 		return
 	}
 
@@ -406,38 +432,57 @@ func (s *Server) EditTerm(w http.ResponseWriter, r *http.Request) {
 
 	// validate form
 	if name == "" || start == "" || end == "" {
-		writeError(w, http.StatusBadRequest, "all fields are required")
+		writeError(w, r, http.StatusBadRequest, "all fields are required")
 		return
 	}
 	startDate, err := time.Parse(time.DateOnly, start)
 	if err != nil {
-		writeError(w, http.StatusBadGateway, "failed to parse start date")
+		writeError(w, r, http.StatusBadGateway, "failed to parse start date")
 		return
 	}
 
 	endDate, err := time.Parse(time.DateOnly, end)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "failed to parse end date")
+		writeError(w, r, http.StatusBadRequest, "failed to parse end date")
 		return
 	}
 
 	// validate academic year dates with terms dates
 	if startDate.Format(time.DateOnly) > academicYear.EndDate.Time.Format(time.DateOnly) || startDate.Format(time.DateOnly) < academicYear.StartDate.Time.Format(time.DateOnly) || endDate.Format(time.DateOnly) > academicYear.EndDate.Time.Format(time.DateOnly) {
-		writeError(w, http.StatusBadRequest, "bad request")
+		writeError(w, r, http.StatusBadRequest, "bad request")
 		slog.Error("invalid term starting date")
 		return
 	}
 
+	reportWeight, err := parseReportWeight(r.FormValue("report_weight"))
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
 	params := database.EditTermParams{
-		TermID:    termID,
-		Name:      name,
-		StartDate: pgtype.Date{Time: startDate, Valid: true},
-		EndDate:   pgtype.Date{Time: endDate, Valid: true},
+		TermID:       termID,
+		Name:         name,
+		StartDate:    pgtype.Date{Time: startDate, Valid: true},
+		EndDate:      pgtype.Date{Time: endDate, Valid: true},
+		ReportWeight: reportWeight,
 	}
 
-	err = s.queries.EditTerm(r.Context(), params)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		if err := qtx.EditTerm(r.Context(), params); err != nil {
+			return err
+		}
+
+		after, err := qtx.GetTerm(r.Context(), termID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditUpdate, auditTerm, termID, academicTerm, after)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to edit term", "error", err.Error())
 		return
 	}
 
@@ -451,7 +496,8 @@ func (s *Server) EditTerm(w http.ResponseWriter, r *http.Request) {
 }
 
 // toggleAcademicYear method sets the current academic year
-func (s *Server) toggleAcademicYear(ctx context.Context, academicID uuid.UUID) error {
+func (s *Server) toggleAcademicYear(r *http.Request, academicID uuid.UUID) error {
+	ctx := r.Context()
 	tx, err := s.conn.Begin(ctx)
 	if err != nil {
 		return err
@@ -459,6 +505,11 @@ func (s *Server) toggleAcademicYear(ctx context.Context, academicID uuid.UUID) e
 	defer tx.Rollback(ctx)
 	qtx := s.queries.WithTx(tx)
 
+	before, err := qtx.GetAcademicYear(ctx, academicID)
+	if err != nil {
+		return err
+	}
+
 	// first deactive the active term before deactivating its academic year.
 	if _, err = qtx.DeactivateTerm(ctx); err != nil {
 		if err.Error() != "no rows in result set" {
@@ -487,6 +538,10 @@ func (s *Server) toggleAcademicYear(ctx context.Context, academicID uuid.UUID) e
 		})
 	}
 
+	if err := recordAudit(r, qtx, auditUpdate, auditAcademicYear, academicID, before, activeYear); err != nil {
+		return err
+	}
+
 	return tx.Commit(ctx)
 }
 
@@ -495,30 +550,16 @@ func (s *Server) toggleAcademicYear(ctx context.Context, academicID uuid.UUID) e
 func (s *Server) setActiveYear(w http.ResponseWriter, r *http.Request) {
 	yearID, err := convertStringToUUID(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "wrong academic year")
+		writeError(w, r, http.StatusUnprocessableEntity, "wrong academic year")
 		slog.Error("Failed to parse academic year", "details:", err.Error())
 		return
 	}
 
-	err = s.toggleAcademicYear(r.Context(), yearID)
+	err = s.toggleAcademicYear(r, yearID)
 	if err != nil {
-		if r.Header.Get("HX-Request") != "" {
-			w.Header().Set("Content-Type", "text/html")
-			_, _ = w.Write([]byte(`
-					<div id="popover" class="custom-popover show" style="background-color: #dc2626;">
-						<p> ❌ Failed to activate academic year. 
-						</p>
-					</div>
-					<script>
-						setTimeout(() => {
-							document.getElementById('popover').classList.add('hide');
-							setTimeout(() => document.getElementById('popover').remove(), 500);
-						}, 3000);
-					</script>
-			`))
-		}
-
 		slog.Error("failed to change current academic year", "error", err.Error())
+		writeError(w, r, http.StatusInternalServerError, "Failed to activate academic year.")
+		return
 	}
 
 	if r.Header.Get("HX-Request") != "" {
@@ -531,9 +572,10 @@ func (s *Server) setActiveYear(w http.ResponseWriter, r *http.Request) {
 }
 
 // toggleTerm method sets the current academic year
-func (s *Server) toggleTerm(ctx context.Context, termID uuid.UUID) error {
+func (s *Server) toggleTerm(r *http.Request, termID uuid.UUID) error {
 	var params database.SetCurrentTermParams
 	var previousTermID uuid.UUID
+	ctx := r.Context()
 
 	// begin transaction
 	tx, err := s.conn.Begin(ctx)
@@ -543,6 +585,11 @@ func (s *Server) toggleTerm(ctx context.Context, termID uuid.UUID) error {
 	defer tx.Rollback(ctx)
 	qtx := s.queries.WithTx(tx)
 
+	before, err := qtx.GetTerm(ctx, termID)
+	if err != nil {
+		return err
+	}
+
 	if previousTermID, err = qtx.DeactivateTerm(ctx); err != nil {
 		if err.Error() != "no rows in result set" {
 			return err
@@ -574,6 +621,10 @@ func (s *Server) toggleTerm(ctx context.Context, termID uuid.UUID) error {
 		})
 	}
 
+	if err := recordAudit(r, qtx, auditUpdate, auditTerm, termID, before, active); err != nil {
+		return err
+	}
+
 	return tx.Commit(ctx)
 }
 
@@ -582,7 +633,7 @@ func (s *Server) toggleTerm(ctx context.Context, termID uuid.UUID) error {
 func (s *Server) setActiveTerm(w http.ResponseWriter, r *http.Request) {
 	termID, err := convertStringToUUID(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "wrong academic year")
+		writeError(w, r, http.StatusUnprocessableEntity, "wrong academic year")
 		slog.Error("Failed to parse academic year", "details:", err.Error())
 		return
 	}
@@ -593,7 +644,12 @@ func (s *Server) setActiveTerm(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = s.toggleTerm(r.Context(), termID)
+	err = s.toggleTerm(r, termID)
+	if err != nil {
+		slog.Error("failed to change current term", "error", err.Error())
+		writeError(w, r, http.StatusInternalServerError, "Failed to activate term.")
+		return
+	}
 
 	if r.Header.Get("HX-Request") != "" {
 		w.Header().Set("HX-Redirect", "/academics/years")
@@ -609,14 +665,14 @@ func (s *Server) setActiveTerm(w http.ResponseWriter, r *http.Request) {
 func (s *Server) GetAcademicsDetails(w http.ResponseWriter, r *http.Request) {
 	term, err := s.getCachedTerm()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("Failed to retrive current academic term", "error", err.Error())
 		return
 	}
 
 	academicYear, err := s.getCachedYear()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("Failed to retrive current academic year", "error", err.Error())
 		return
 	}
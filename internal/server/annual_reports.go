@@ -0,0 +1,531 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"school_management_system/cmd/web/dashboard/reports"
+	"school_management_system/internal/database"
+	"school_management_system/internal/dto"
+	"school_management_system/internal/grading"
+
+	"github.com/go-pdf/fpdf"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// reportAcademicYearID returns the academic year whose annual reports are requested: the
+// academic_year_id query parameter, or the current academic year when there is none.
+func (s *Server) reportAcademicYearID(r *http.Request) (uuid.UUID, error) {
+	if v := r.URL.Query().Get("academic_year_id"); v != "" {
+		academicYearID, err := uuid.Parse(v)
+		if err != nil {
+			return uuid.Nil, NewAppError(http.StatusBadRequest, "invalid academic year").WithField("academic_year_id", "must be an academic year ID")
+		}
+		return academicYearID, nil
+	}
+
+	academicYear, err := s.queries.GetCurrentAcademicYear(r.Context())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return uuid.Nil, NewAppError(http.StatusNotFound, "no active academic year, choose an academic year").Wrap(err)
+		}
+		return uuid.Nil, NewAppError(http.StatusInternalServerError, "failed to get the academic year").Wrap(err)
+	}
+
+	return academicYear.AcademicYearID, nil
+}
+
+// gradedTerms returns the terms with scores in annual report cards.
+func gradedTerms(cards ...database.AnnualReportCardsView) map[uuid.UUID]bool {
+	terms := map[uuid.UUID]bool{}
+	for _, card := range cards {
+		for _, grade := range card.Grades {
+			for termID := range grade.Terms {
+				terms[termID] = true
+			}
+		}
+	}
+	return terms
+}
+
+// unreleasedTerm returns the first of termIDs whose report cards for the class the user may not
+// download yet, or uuid.Nil if there is none. Annual results are made up of the term results, so
+// they are only released once the report cards of every term they include are.
+func unreleasedTerm(ctx context.Context, q *database.Queries, user User, classID uuid.UUID, termIDs []uuid.UUID) (uuid.UUID, error) {
+	for _, termID := range termIDs {
+		approval, err := reportApproval(ctx, q, classID, termID)
+		if err != nil {
+			return uuid.Nil, err
+		}
+		if !canDownloadReportCards(user, approval.Status) {
+			return termID, nil
+		}
+	}
+
+	return uuid.Nil, nil
+}
+
+// annualReportsReleased reports whether the user may see the annual results of a class made up of
+// the graded terms of an academic year, writing an error response naming the first unpublished term if not.
+func (s *Server) annualReportsReleased(w http.ResponseWriter, r *http.Request, classID uuid.UUID, terms []database.ListAcademicYearTermsRow, graded map[uuid.UUID]bool) bool {
+	termIDs := make([]uuid.UUID, 0, len(graded))
+	for _, term := range terms {
+		if graded[term.TermID] {
+			termIDs = append(termIDs, term.TermID)
+		}
+	}
+
+	user, _ := r.Context().Value(userContextKey).(User)
+	termID, err := unreleasedTerm(r.Context(), s.queries, user, classID, termIDs)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to retrieve report card approval")
+		slog.Error("Failed to get report card approval", "error", err.Error())
+		return false
+	}
+	if termID == uuid.Nil {
+		return true
+	}
+
+	for _, term := range terms {
+		if term.TermID == termID {
+			writeError(w, r, http.StatusForbidden, fmt.Sprintf("report cards for %s have not been published", term.AcademicTerm))
+			return false
+		}
+	}
+	return false
+}
+
+// ShowAnnualReports renders the academic year selector, the weights of the year's terms and the
+// classes graded in the selected year.
+func (s *Server) ShowAnnualReports(w http.ResponseWriter, r *http.Request) {
+	academicYears, err := s.queries.ListAllAcademicYears(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve academic years")
+		slog.Error("failed to retrieve academic years", "error", err.Error())
+		return
+	}
+
+	academicYearID, err := s.reportAcademicYearID(r)
+	if err != nil {
+		if len(academicYears) == 0 {
+			writeAppError(w, r, err)
+			return
+		}
+		academicYearID = academicYears[0].AcademicYearID
+	}
+
+	terms, err := s.queries.ListAcademicYearTerms(r.Context(), academicYearID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve terms")
+		slog.Error("failed to retrieve academic year terms", "error", err.Error())
+		return
+	}
+
+	classRooms, err := s.queries.ListAnnualReportCardClasses(r.Context(), academicYearID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve classes")
+		slog.Error("failed to retrieve annual report card classes", "error", err.Error())
+		return
+	}
+
+	s.renderComponent(w, r, reports.AnnualReportsList(academicYears, academicYearID, terms, classRooms))
+}
+
+// ShowClassAnnualReports renders the annual reports of a class for the selected academic year,
+// once the report cards of every term graded in it are published.
+func (s *Server) ShowClassAnnualReports(w http.ResponseWriter, r *http.Request) {
+	classID, err := uuid.Parse(r.PathValue("classID"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid class ID")
+		return
+	}
+
+	academicYearID, err := s.reportAcademicYearID(r)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	class, err := s.queries.GetClass(r.Context(), classID)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "class not found")
+		return
+	}
+
+	classReports, err := s.queries.ListAnnualReportCards(r.Context(), database.ListAnnualReportCardsParams{
+		ClassID:        classID,
+		AcademicYearID: academicYearID,
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve annual reports")
+		slog.Error("failed to retrieve annual reports", "error", err.Error())
+		return
+	}
+
+	terms, err := s.queries.ListAcademicYearTerms(r.Context(), academicYearID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve terms")
+		slog.Error("failed to retrieve academic year terms", "error", err.Error())
+		return
+	}
+
+	if !s.annualReportsReleased(w, r, classID, terms, gradedTerms(classReports...)) {
+		return
+	}
+
+	s.renderComponent(w, r, reports.AnnualReportTable(class.Name, classReports))
+}
+
+// createAnnualReportPdf creates a PDF with a student's results for an academic year: each subject's
+// score in every term, its weighted annual score, grade and position, and the student's position in the class.
+func createAnnualReportPdf(card database.AnnualReportCardsView, terms []database.ListAcademicYearTermsRow, studentSubjects []database.ListSubjectsRow, scale grading.Scale) (string, *fpdf.Fpdf) {
+	pdf := fpdf.New(fpdf.OrientationPortrait, "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Arial", "B", 18)
+	pdf.CellFormat(190, 10, "Annual Report Card", "", 0, "C", false, 0, "")
+	pdf.Ln(15)
+
+	// Student details
+	pdf.SetFont("Arial", "", 14)
+	pdf.Cell(190, 10, fmt.Sprintf("Student No: %s", card.StudentNo))
+	pdf.Ln(8)
+	pdf.Cell(190, 10, fmt.Sprintf("Name: %s %s %s", card.FirstName, card.MiddleName.String, card.LastName))
+	pdf.Ln(8)
+	pdf.Cell(190, 10, fmt.Sprintf("Class: %s", card.ClassName))
+	pdf.Ln(8)
+	pdf.Cell(190, 10, fmt.Sprintf("Academic Year: %s", card.AcademicYearName))
+	pdf.Ln(12)
+
+	// The term columns share the space left by the other columns.
+	subjectWidth, termsWidth := 50.0, 70.0
+	if len(terms) == 0 {
+		subjectWidth, termsWidth = 120, 0
+	}
+	termWidth := 0.0
+	if len(terms) > 0 {
+		termWidth = termsWidth / float64(len(terms))
+	}
+
+	// Table Headers
+	pdf.SetFont("Arial", "B", 10)
+	pdf.SetFillColor(200, 200, 200)
+	pdf.CellFormat(subjectWidth, 10, "Subject", "1", 0, "C", true, 0, "")
+	for _, term := range terms {
+		pdf.CellFormat(termWidth, 10, term.AcademicTerm, "1", 0, "C", true, 0, "")
+	}
+	pdf.CellFormat(25, 10, "Annual", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(20, 10, "Grade", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(25, 10, "Position", "1", 0, "C", true, 0, "")
+	pdf.Ln(-1)
+
+	// Table Content
+	pdf.SetFont("Arial", "", 10)
+	for _, studentSubj := range studentSubjects {
+		grade, ok := card.Grades[studentSubj.SubjectID]
+		if !ok {
+			continue
+		}
+
+		letter := scale.Letter(grade.Score)
+		if letter == "" {
+			letter = "-"
+		}
+
+		pdf.CellFormat(subjectWidth, 10, studentSubj.Subjectname, "1", 0, "L", false, 0, "")
+		for _, term := range terms {
+			score := "-"
+			if termScore, ok := grade.Terms[term.TermID]; ok {
+				score = fmt.Sprintf("%.2f", termScore)
+			}
+			pdf.CellFormat(termWidth, 10, score, "1", 0, "R", false, 0, "")
+		}
+		pdf.CellFormat(25, 10, fmt.Sprintf("%.2f", grade.Score), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(20, 10, letter, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(25, 10, reportPosition(grade.Position), "1", 0, "C", false, 0, "")
+		pdf.Ln(-1)
+	}
+
+	// Totals and class position
+	total, _ := card.TotalScore.Float64Value()
+	mean, _ := card.MeanScore.Float64Value()
+	pdf.SetFont("Arial", "B", 10)
+	pdf.CellFormat(subjectWidth+termsWidth, 10, "Total", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(25, 10, fmt.Sprintf("%.2f", total.Float64), "1", 0, "R", true, 0, "")
+	pdf.CellFormat(45, 10, "", "1", 0, "C", true, 0, "")
+	pdf.Ln(-1)
+	pdf.CellFormat(subjectWidth+termsWidth, 10, "Average", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(25, 10, fmt.Sprintf("%.2f", mean.Float64), "1", 0, "R", true, 0, "")
+	pdf.CellFormat(20, 10, scale.Letter(mean.Float64), "1", 0, "C", true, 0, "")
+	pdf.CellFormat(25, 10, "", "1", 0, "C", true, 0, "")
+	pdf.Ln(12)
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(190, 10, fmt.Sprintf("Position in Class: %s out of %d", reportPosition(int(card.ClassPosition)), card.ClassSize))
+	pdf.Ln(8)
+	pdf.SetFont("Arial", "", 10)
+	pdf.MultiCell(190, 6, termWeightsNote(terms), "", "L", false)
+
+	// Footer with space for signature
+	pdf.Ln(15)
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(80, 10, "Class Teacher Signature: ______________")
+	pdf.Ln(10)
+	pdf.Cell(80, 10, "Head Teacher Signature: ______________")
+
+	fileName := safeFileName(card.StudentNo + "_" + card.AcademicYearName)
+
+	return fileName, pdf
+}
+
+// termWeightsNote explains how the terms of an academic year are weighted in the annual scores.
+func termWeightsNote(terms []database.ListAcademicYearTermsRow) string {
+	weights := make([]string, len(terms))
+	for i, term := range terms {
+		weight, _ := term.ReportWeight.Float64Value()
+		weights[i] = fmt.Sprintf("%s: %s", term.AcademicTerm, strconv.FormatFloat(weight.Float64, 'f', -1, 64))
+	}
+
+	return "Annual scores are the average of the term scores, weighted " + strings.Join(weights, ", ") + "."
+}
+
+// GenerateAnnualReportCard generates a PDF annual report card for a student, once the report cards
+// of every term graded in the academic year are published.
+func (s *Server) GenerateAnnualReportCard(w http.ResponseWriter, r *http.Request) {
+	studentID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid student ID")
+		return
+	}
+
+	academicYearID, err := s.reportAcademicYearID(r)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	card, err := s.queries.GetAnnualReportCard(r.Context(), database.GetAnnualReportCardParams{
+		StudentID:      studentID,
+		AcademicYearID: academicYearID,
+	})
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "Annual report not found")
+		slog.Error("Annual report not found", "error", err.Error())
+		return
+	}
+
+	terms, err := s.queries.ListAcademicYearTerms(r.Context(), academicYearID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to retrieve terms")
+		slog.Error("Failed to get academic year terms", "error", err.Error())
+		return
+	}
+
+	if !s.annualReportsReleased(w, r, card.ClassID, terms, gradedTerms(card)) {
+		return
+	}
+
+	studentSubjects, err := s.queries.ListSubjects(r.Context(), card.ClassID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to retrieve student's subjects")
+		slog.Error("Failed to get student's subjects", "error", err.Error())
+		return
+	}
+
+	scale, err := s.classGradingScale(r.Context(), card.ClassID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to retrieve grading scale")
+		slog.Error("Failed to get grading scale", "error", err.Error())
+		return
+	}
+
+	fileName, report := createAnnualReportPdf(card, terms, studentSubjects, scale)
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.pdf", fileName))
+	if err := report.Output(w); err != nil {
+		slog.Error("PDF Generation Error:", "error", err.Error())
+	}
+}
+
+// transcriptSubjects lists the subjects of an annual report by name.
+func transcriptSubjects(grades dto.AnnualGradesMap) []dto.AnnualGrade {
+	subjects := make([]dto.AnnualGrade, 0, len(grades))
+	for _, grade := range grades {
+		subjects = append(subjects, grade)
+	}
+	sort.Slice(subjects, func(i, j int) bool { return subjects[i].Subject < subjects[j].Subject })
+
+	return subjects
+}
+
+// createTranscriptPdf creates a PDF with the classes a student was in and their annual results in every
+// academic year they were graded in, oldest first. Each year is graded with the scale of its class.
+// The results of the withheld academic years, named by the year, are left out until they are released.
+func createTranscriptPdf(student database.GetStudentRow, history []database.ListStudentClassHistoryRow, years []database.AnnualReportCardsView, withheld []string, scales map[uuid.UUID]grading.Scale) (string, *fpdf.Fpdf) {
+	pdf := fpdf.New(fpdf.OrientationPortrait, "mm", "A4", "")
+	pdf.AddPage()
+	pdf.SetFont("Arial", "B", 18)
+	pdf.CellFormat(190, 10, "Academic Transcript", "", 0, "C", false, 0, "")
+	pdf.Ln(15)
+
+	// Student details
+	pdf.SetFont("Arial", "", 14)
+	pdf.Cell(190, 10, fmt.Sprintf("Student No: %s", student.StudentNo))
+	pdf.Ln(8)
+	pdf.Cell(190, 10, fmt.Sprintf("Name: %s %s %s", student.FirstName, student.MiddleName.String, student.LastName))
+	pdf.Ln(8)
+	pdf.Cell(190, 10, fmt.Sprintf("Gender: %s", student.Gender))
+	pdf.Ln(8)
+	if student.DateOfBirth.Valid {
+		pdf.Cell(190, 10, fmt.Sprintf("Date of Birth: %s", student.DateOfBirth.Time.Format("2 January 2006")))
+		pdf.Ln(8)
+	}
+	pdf.Cell(190, 10, fmt.Sprintf("Status: %s", student.Status))
+	pdf.Ln(12)
+
+	// Classes by academic year
+	pdf.SetFont("Arial", "B", 12)
+	pdf.SetFillColor(200, 200, 200)
+	pdf.CellFormat(95, 10, "Academic Year", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(95, 10, "Class", "1", 0, "C", true, 0, "")
+	pdf.Ln(-1)
+	pdf.SetFont("Arial", "", 12)
+	for _, enrolment := range history {
+		pdf.CellFormat(95, 10, enrolment.AcademicYearName, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(95, 10, enrolment.ClassName, "1", 0, "L", false, 0, "")
+		pdf.Ln(-1)
+	}
+
+	if len(years) == 0 && len(withheld) == 0 {
+		pdf.Ln(8)
+		pdf.Cell(190, 10, "No grades have been recorded for this student.")
+	}
+	if len(withheld) > 0 {
+		pdf.Ln(8)
+		pdf.SetFont("Arial", "", 10)
+		pdf.MultiCell(190, 6, fmt.Sprintf("Results for %s are not shown until all of the year's report cards are published.", strings.Join(withheld, ", ")), "", "L", false)
+	}
+
+	// Annual results
+	for _, year := range years {
+		scale := scales[year.ClassID]
+
+		pdf.Ln(8)
+		pdf.SetFont("Arial", "B", 14)
+		pdf.Cell(190, 10, fmt.Sprintf("%s: %s", year.AcademicYearName, year.ClassName))
+		pdf.Ln(10)
+
+		pdf.SetFont("Arial", "B", 12)
+		pdf.CellFormat(80, 10, "Subject", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(40, 10, "Score", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(30, 10, "Grade", "1", 0, "C", true, 0, "")
+		pdf.CellFormat(40, 10, "Position", "1", 0, "C", true, 0, "")
+		pdf.Ln(-1)
+
+		pdf.SetFont("Arial", "", 12)
+		for _, grade := range transcriptSubjects(year.Grades) {
+			letter := scale.Letter(grade.Score)
+			if letter == "" {
+				letter = "-"
+			}
+
+			pdf.CellFormat(80, 10, grade.Subject, "1", 0, "L", false, 0, "")
+			pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", grade.Score), "1", 0, "R", false, 0, "")
+			pdf.CellFormat(30, 10, letter, "1", 0, "C", false, 0, "")
+			pdf.CellFormat(40, 10, reportPosition(grade.Position), "1", 0, "C", false, 0, "")
+			pdf.Ln(-1)
+		}
+
+		mean, _ := year.MeanScore.Float64Value()
+		pdf.SetFont("Arial", "B", 12)
+		pdf.CellFormat(80, 10, "Average", "1", 0, "L", true, 0, "")
+		pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", mean.Float64), "1", 0, "R", true, 0, "")
+		pdf.CellFormat(30, 10, scale.Letter(mean.Float64), "1", 0, "C", true, 0, "")
+		pdf.CellFormat(40, 10, fmt.Sprintf("%s of %d", reportPosition(int(year.ClassPosition)), year.ClassSize), "1", 0, "C", true, 0, "")
+		pdf.Ln(-1)
+	}
+
+	// Footer with space for signature
+	pdf.Ln(15)
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(80, 10, "Head Teacher Signature: ______________")
+
+	fileName := safeFileName(student.StudentNo + "_transcript")
+
+	return fileName, pdf
+}
+
+// GenerateStudentTranscript generates a PDF transcript of a student's results across academic years.
+// Years whose term report cards aren't all published are left out.
+func (s *Server) GenerateStudentTranscript(w http.ResponseWriter, r *http.Request) {
+	studentID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid student ID")
+		return
+	}
+
+	student, err := s.queries.GetStudent(r.Context(), studentID)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "Student not found")
+		slog.Error("Student not found", "error", err.Error())
+		return
+	}
+
+	history, err := s.queries.ListStudentClassHistory(r.Context(), studentID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to retrieve class history")
+		slog.Error("Failed to get student's class history", "error", err.Error())
+		return
+	}
+
+	years, err := s.queries.ListStudentTranscript(r.Context(), studentID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to retrieve annual results")
+		slog.Error("Failed to get student's transcript", "error", err.Error())
+		return
+	}
+
+	// Years with term results that aren't released yet are left out rather than withholding the whole transcript.
+	user, _ := r.Context().Value(userContextKey).(User)
+	released := make([]database.AnnualReportCardsView, 0, len(years))
+	var withheld []string
+	for _, year := range years {
+		termIDs := slices.Collect(maps.Keys(gradedTerms(year)))
+		termID, err := unreleasedTerm(r.Context(), s.queries, user, year.ClassID, termIDs)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, "Failed to retrieve report card approval")
+			slog.Error("Failed to get report card approval", "error", err.Error())
+			return
+		}
+
+		if termID == uuid.Nil {
+			released = append(released, year)
+		} else {
+			withheld = append(withheld, year.AcademicYearName)
+		}
+	}
+
+	scales, err := s.classGradingScales(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to retrieve grading scales")
+		slog.Error("Failed to get grading scales", "error", err.Error())
+		return
+	}
+
+	fileName, transcript := createTranscriptPdf(student, history, released, withheld, scales)
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.pdf", fileName))
+	if err := transcript.Output(w); err != nil {
+		slog.Error("PDF Generation Error:", "error", err.Error())
+	}
+}
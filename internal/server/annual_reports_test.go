@@ -0,0 +1,157 @@
+// annual_reports_test.go
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"school_management_system/internal/database"
+	"school_management_system/internal/dto"
+	"school_management_system/internal/grading"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestReportAcademicYearID(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	s := &Server{queries: database.New(mockConn)}
+	currentYearID, pastYearID := uuid.New(), uuid.New()
+	yearColumns := []string{"academic_year_id", "graduate_class_id", "name", "start_date", "end_date", "active"}
+
+	var appErr *AppError
+	req := httptest.NewRequest("GET", "/reports/annual", nil)
+	mockConn.ExpectQuery("SELECT").WillReturnError(pgx.ErrNoRows)
+	if _, err := s.reportAcademicYearID(req); !errors.As(err, &appErr) || appErr.Status != http.StatusNotFound {
+		t.Errorf("expected not found without an active academic year; got %v", err)
+	}
+
+	mockConn.ExpectQuery("SELECT").WillReturnRows(pgxmock.NewRows(yearColumns).
+		AddRow(currentYearID, pgtype.UUID{}, "2025/2026", pgtype.Date{}, pgtype.Date{}, true))
+	if got, err := s.reportAcademicYearID(req); err != nil || got != currentYearID {
+		t.Errorf("expected the current academic year; got %v, %v", got, err)
+	}
+
+	req = httptest.NewRequest("GET", "/reports/annual?academic_year_id="+pastYearID.String(), nil)
+	if got, err := s.reportAcademicYearID(req); err != nil || got != pastYearID {
+		t.Errorf("expected the requested academic year; got %v, %v", got, err)
+	}
+
+	req = httptest.NewRequest("GET", "/reports/annual?academic_year_id=last", nil)
+	if _, err := s.reportAcademicYearID(req); !errors.As(err, &appErr) || appErr.Status != http.StatusBadRequest {
+		t.Errorf("expected bad request for an invalid academic year; got %v", err)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestParseReportWeight(t *testing.T) {
+	weight, err := parseReportWeight(" ")
+	if value, _ := weight.Float64Value(); err != nil || value.Float64 != 1 {
+		t.Errorf("expected a blank weight to be 1; got %v, %v", value.Float64, err)
+	}
+
+	weight, err = parseReportWeight("2.5")
+	if value, _ := weight.Float64Value(); err != nil || value.Float64 != 2.5 {
+		t.Errorf("expected a weight of 2.5; got %v, %v", value.Float64, err)
+	}
+
+	var appErr *AppError
+	for _, value := range []string{"0", "-1", "1000", "heavy"} {
+		if _, err := parseReportWeight(value); !errors.As(err, &appErr) || appErr.Fields["report_weight"] == "" {
+			t.Errorf("expected weight %q to be rejected; got %v", value, err)
+		}
+	}
+}
+
+func TestTranscriptSubjects(t *testing.T) {
+	grades := dto.AnnualGradesMap{
+		uuid.New(): {Subject: "Mathematics", Score: 70},
+		uuid.New(): {Subject: "English", Score: 60},
+		uuid.New(): {Subject: "Biology", Score: 80},
+	}
+
+	var got []string
+	for _, grade := range transcriptSubjects(grades) {
+		got = append(got, grade.Subject)
+	}
+	if len(got) != 3 || got[0] != "Biology" || got[1] != "English" || got[2] != "Mathematics" {
+		t.Errorf("expected subjects by name; got %v", got)
+	}
+}
+
+func TestCreateTranscriptPdf(t *testing.T) {
+	classID := uuid.New()
+	student := database.GetStudentRow{StudentNo: "STU-001", FirstName: "Chikondi", LastName: "Banda", Status: "graduated"}
+	history := []database.ListStudentClassHistoryRow{
+		{AcademicYearName: "2024/2025", ClassName: "Form 4"},
+		{AcademicYearName: "2025/2026", ClassName: "Graduates - 2025/2026"},
+	}
+	years := []database.AnnualReportCardsView{{
+		ClassID:          classID,
+		ClassName:        "Form 4",
+		AcademicYearName: "2024/2025",
+		Grades:           dto.AnnualGradesMap{uuid.New(): {Subject: "English", Score: 72.5, Position: 2}},
+		MeanScore:        numericFromFloat(72.5),
+		ClassPosition:    2,
+		ClassSize:        30,
+	}}
+	scales := map[uuid.UUID]grading.Scale{classID: {{MinScore: 0, MaxScore: 100, Letter: "P"}}}
+
+	fileName, pdf := createTranscriptPdf(student, history, years, []string{"2025/2026"}, scales)
+	if fileName != "STU-001_transcript" {
+		t.Errorf("unexpected file name %q", fileName)
+	}
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil || !bytes.HasPrefix(buf.Bytes(), []byte("%PDF")) {
+		t.Errorf("expected a PDF; got %v", err)
+	}
+}
+
+func TestUnreleasedTerm(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	q := database.New(mockConn)
+	classID, published, draft := uuid.New(), uuid.New(), uuid.New()
+	approvalColumns := []string{"class_id", "term_id", "status", "comment", "updated_by", "updated_at"}
+	viewer := User{Permissions: []string{"reports.view"}}
+
+	mockConn.ExpectQuery("FROM report_approvals").WithArgs(classID, published).
+		WillReturnRows(pgxmock.NewRows(approvalColumns).AddRow(classID, published, reportPublished, pgtype.Text{}, pgtype.UUID{}, pgtype.Timestamptz{}))
+	mockConn.ExpectQuery("FROM report_approvals").WithArgs(classID, draft).WillReturnError(pgx.ErrNoRows)
+
+	termID, err := unreleasedTerm(context.Background(), q, viewer, classID, []uuid.UUID{published, draft})
+	if err != nil || termID != draft {
+		t.Errorf("expected the draft term to hold back the annual results; got %s, %v", termID, err)
+	}
+
+	// Whoever approves report cards sees them before they are published.
+	mockConn.ExpectQuery("FROM report_approvals").WithArgs(classID, draft).WillReturnError(pgx.ErrNoRows)
+
+	approver := User{Permissions: []string{"reports.view", "reports.approve"}}
+	if termID, err := unreleasedTerm(context.Background(), q, approver, classID, []uuid.UUID{draft}); err != nil || termID != uuid.Nil {
+		t.Errorf("expected an approver to see unpublished results; got %s, %v", termID, err)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
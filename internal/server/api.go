@@ -0,0 +1,209 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	apiDefaultPerPage = 50
+	apiMaxPerPage     = 200
+)
+
+// APIError is the body of every error returned by the JSON API.
+type APIError struct {
+	Error APIErrorDetail `json:"error"`
+}
+
+// APIErrorDetail describes what went wrong. Code is a stable machine readable identifier
+// such as "not_found"; Message is meant for people. Fields maps form or body fields to what is
+// wrong with them.
+type APIErrorDetail struct {
+	Code    string            `json:"code"`
+	Message string            `json:"message"`
+	Fields  map[string]string `json:"fields,omitempty"`
+}
+
+// APIPageMeta describes the page of results returned by a list endpoint.
+type APIPageMeta struct {
+	Page       int `json:"page"`
+	PerPage    int `json:"per_page"`
+	Total      int `json:"total"`
+	TotalPages int `json:"total_pages"`
+}
+
+// APIList is the body returned by list endpoints.
+type APIList[T any] struct {
+	Data []T         `json:"data"`
+	Meta APIPageMeta `json:"meta"`
+}
+
+// APIItem is the body returned by endpoints that return a single resource.
+type APIItem[T any] struct {
+	Data T `json:"data"`
+}
+
+// writeJSON writes v as a JSON response body.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to encode json response", "error", err.Error())
+	}
+}
+
+// writeAPIError writes an error in the JSON API's error format.
+func writeAPIError(w http.ResponseWriter, status int, code, message string) {
+	writeAPIAppError(w, NewAppError(status, message).WithCode(code))
+}
+
+// writeAPIAppError writes an *AppError in the JSON API's error format.
+func writeAPIAppError(w http.ResponseWriter, e *AppError) {
+	writeJSON(w, e.Status, APIError{Error: APIErrorDetail{Code: e.Code, Message: e.Message, Fields: e.Fields}})
+}
+
+// APITokenAuth authenticates JSON API requests with a bearer API token.
+// Unlike APIAuthMiddleware it never falls back to the browser session, so failures are JSON.
+func (s *Server) APITokenAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeAPIError(w, http.StatusUnauthorized, "unauthorized", "an API token is required in the Authorization header")
+			return
+		}
+
+		authed, err := s.authenticateAPIToken(r, token)
+		if err != nil {
+			if !errors.Is(err, errInvalidAPIToken) {
+				slog.Error("failed to look up api token", "error", err.Error())
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			writeAPIError(w, http.StatusUnauthorized, "invalid_token", errInvalidAPIToken.Error())
+			return
+		}
+
+		w.Header().Add("Cache-Control", "no-store")
+		next.ServeHTTP(w, authed)
+	})
+}
+
+// APIRequirePermission is RequirePermission for the JSON API.
+func (s *Server) APIRequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := r.Context().Value(userContextKey).(User)
+			if !ok {
+				writeAPIError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+				return
+			}
+
+			if !user.Can(permission) {
+				writeAPIError(w, http.StatusForbidden, "forbidden", "this token does not grant the "+permission+" permission")
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// apiPage is the page requested through the page and per_page query parameters.
+type apiPage struct {
+	Page    int
+	PerPage int
+}
+
+// parseAPIPage reads the page and per_page query parameters, defaulting to the first page.
+func parseAPIPage(r *http.Request) (apiPage, error) {
+	p := apiPage{Page: 1, PerPage: apiDefaultPerPage}
+
+	if v := r.URL.Query().Get("page"); v != "" {
+		page, err := strconv.Atoi(v)
+		if err != nil || page < 1 {
+			return p, errors.New("page must be a positive integer")
+		}
+		p.Page = page
+	}
+
+	if v := r.URL.Query().Get("per_page"); v != "" {
+		perPage, err := strconv.Atoi(v)
+		if err != nil || perPage < 1 || perPage > apiMaxPerPage {
+			return p, errors.New("per_page must be between 1 and " + strconv.Itoa(apiMaxPerPage))
+		}
+		p.PerPage = perPage
+	}
+
+	return p, nil
+}
+
+// apiPageParam reads the page query parameters, writing a 400 error if they are invalid.
+func apiPageParam(w http.ResponseWriter, r *http.Request) (apiPage, bool) {
+	page, err := parseAPIPage(r)
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return page, false
+	}
+
+	return page, true
+}
+
+// limit is the LIMIT of the page's query.
+func (p apiPage) limit() int32 {
+	return int32(p.PerPage)
+}
+
+// offset is the OFFSET of the page's query. Pages too far out to count start past the end of any table;
+// checking first keeps huge page numbers from overflowing.
+func (p apiPage) offset() int32 {
+	if p.Page-1 > math.MaxInt32/p.PerPage {
+		return math.MaxInt32
+	}
+
+	return int32((p.Page - 1) * p.PerPage)
+}
+
+// newAPIList returns a page of rows out of total matching rows.
+func newAPIList[T any](rows []T, p apiPage, total int64) APIList[T] {
+	if rows == nil {
+		rows = []T{}
+	}
+
+	return APIList[T]{
+		Data: rows,
+		Meta: APIPageMeta{
+			Page:       p.Page,
+			PerPage:    p.PerPage,
+			Total:      int(total),
+			TotalPages: int((total + int64(p.PerPage) - 1) / int64(p.PerPage)),
+		},
+	}
+}
+
+// textParam returns an optional text query parameter, NULL when it is absent or empty.
+func textParam(r *http.Request, name string) pgtype.Text {
+	v := r.URL.Query().Get(name)
+	return pgtype.Text{String: v, Valid: v != ""}
+}
+
+// uuidParam parses an optional UUID query parameter. ok is false when the parameter is absent.
+func uuidParam(r *http.Request, name string) (id uuid.UUID, ok bool, err error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return uuid.Nil, false, nil
+	}
+
+	id, err = uuid.Parse(v)
+	if err != nil {
+		return uuid.Nil, false, errors.New(name + " must be a UUID")
+	}
+
+	return id, true, nil
+}
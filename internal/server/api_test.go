@@ -0,0 +1,227 @@
+// api_test.go
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"school_management_system/internal/database"
+	"school_management_system/internal/dto"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestAPIPage_LimitAndOffset(t *testing.T) {
+	page := apiPage{Page: 3, PerPage: 20}
+	if page.limit() != 20 || page.offset() != 40 {
+		t.Errorf("expected LIMIT 20 OFFSET 40; got LIMIT %d OFFSET %d", page.limit(), page.offset())
+	}
+
+	// A page number whose offset overflows the query parameter is simply past the end.
+	huge := apiPage{Page: math.MaxInt, PerPage: apiMaxPerPage}
+	if huge.offset() != math.MaxInt32 {
+		t.Errorf("expected a huge page number to start past the end; got OFFSET %d", huge.offset())
+	}
+}
+
+func TestNewAPIList(t *testing.T) {
+	list := newAPIList([]int{3, 4}, apiPage{Page: 2, PerPage: 2}, 5)
+	if list.Meta != (APIPageMeta{Page: 2, PerPage: 2, Total: 5, TotalPages: 3}) {
+		t.Errorf("unexpected meta %+v", list.Meta)
+	}
+
+	past := newAPIList[int](nil, apiPage{Page: 9, PerPage: 2}, 5)
+	if past.Data == nil || len(past.Data) != 0 {
+		t.Errorf("expected an empty, non-nil page past the end; got %#v", past.Data)
+	}
+}
+
+func TestParseAPIPage(t *testing.T) {
+	tests := []struct {
+		query   string
+		want    apiPage
+		wantErr bool
+	}{
+		{"", apiPage{Page: 1, PerPage: apiDefaultPerPage}, false},
+		{"?page=3&per_page=10", apiPage{Page: 3, PerPage: 10}, false},
+		{"?page=0", apiPage{}, true},
+		{"?per_page=1000", apiPage{}, true},
+		{"?page=abc", apiPage{}, true},
+		{"?page=9223372036854775807&per_page=200", apiPage{Page: math.MaxInt64, PerPage: 200}, false},
+	}
+
+	for _, tt := range tests {
+		got, err := parseAPIPage(httptest.NewRequest("GET", "/api/v1/students"+tt.query, nil))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%q: expected error %v; got %v", tt.query, tt.wantErr, err)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("%q: expected %+v; got %+v", tt.query, tt.want, got)
+		}
+	}
+}
+
+func TestAPITokenAuth_RequiresToken(t *testing.T) {
+	s := &Server{}
+
+	req := httptest.NewRequest("GET", "/api/v1/students", nil)
+	rec := executeRequest(req, s)
+
+	checkResponseCode(t, http.StatusUnauthorized, rec.Code)
+
+	var body APIError
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("expected a JSON error body: %v", err)
+	}
+	if body.Error.Code != "unauthorized" {
+		t.Errorf("expected code unauthorized; got %q", body.Error.Code)
+	}
+}
+
+// apiRequest returns a request carrying a user with the given permissions, as APITokenAuth would.
+func apiRequest(target string, permissions ...string) *http.Request {
+	req := httptest.NewRequest("GET", target, nil)
+	user := User{UserID: uuid.New(), Role: "admin", Roles: []string{"admin"}, Permissions: permissions}
+	return req.WithContext(context.WithValue(req.Context(), userContextKey, user))
+}
+
+func TestAPIListStudents_FiltersAndPaginates(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	s := &Server{queries: database.New(mockConn)}
+	classID := pgtype.UUID{Bytes: uuid.New(), Valid: true}
+
+	rows := pgxmock.NewRows([]string{"student_id", "student_no", "last_name", "middle_name", "first_name", "gender", "date_of_birth", "status", "academicyear", "class_id", "classname"})
+	for i := range 2 {
+		rows.AddRow(uuid.New(), "STU-"+string(rune('1'+i)), "Banda", pgtype.Text{}, "Chisomo", "F", pgtype.Date{}, "active", "2025", classID, pgtype.Text{String: "Form 1", Valid: true})
+	}
+
+	// The filters and the page go to the database rather than being applied to the whole table.
+	mockConn.ExpectQuery("ListAPIStudents").
+		WithArgs(classID, pgtype.Text{}, pgtype.Text{String: "banda", Valid: true}, int32(2), int32(2)).
+		WillReturnRows(rows)
+	mockConn.ExpectQuery("CountAPIStudents").
+		WithArgs(classID, pgtype.Text{}, pgtype.Text{String: "banda", Valid: true}).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(5)))
+
+	rec := httptest.NewRecorder()
+	s.apiListStudents(rec, apiRequest("/api/v1/students?class_id="+uuid.UUID(classID.Bytes).String()+"&q=banda&page=2&per_page=2", "students.manage"))
+
+	checkResponseCode(t, http.StatusOK, rec.Code)
+
+	var body APIList[database.ListAPIStudentsRow]
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Meta != (APIPageMeta{Page: 2, PerPage: 2, Total: 5, TotalPages: 3}) || len(body.Data) != 2 {
+		t.Errorf("expected the second page of 2 out of 5 students; got meta %+v with %d rows", body.Meta, len(body.Data))
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAPIListStudents_InvalidFilter(t *testing.T) {
+	s := &Server{}
+
+	rec := httptest.NewRecorder()
+	s.apiListStudents(rec, apiRequest("/api/v1/students?class_id=form-one", "students.manage"))
+
+	checkResponseCode(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestAPIGetStudent_NotFound(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	s := &Server{queries: database.New(mockConn)}
+	id := uuid.New()
+
+	mockConn.ExpectQuery("SELECT").WithArgs(id).WillReturnError(pgx.ErrNoRows)
+
+	req := apiRequest("/api/v1/students/"+id.String(), "students.manage")
+	req.SetPathValue("id", id.String())
+	rec := httptest.NewRecorder()
+	s.apiGetStudent(rec, req)
+
+	checkResponseCode(t, http.StatusNotFound, rec.Code)
+
+	var body APIError
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("expected a JSON error body: %v", err)
+	}
+	if body.Error.Code != "not_found" {
+		t.Errorf("expected code not_found; got %q", body.Error.Code)
+	}
+}
+
+func TestAPIRequirePermission(t *testing.T) {
+	s := &Server{}
+	h := s.APIRequirePermission("fees.view")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, apiRequest("/api/v1/fees", "students.manage"))
+	checkResponseCode(t, http.StatusForbidden, rec.Code)
+
+	rec = httptest.NewRecorder()
+	h.ServeHTTP(rec, apiRequest("/api/v1/fees", "fees.view"))
+	checkResponseCode(t, http.StatusOK, rec.Code)
+}
+
+func TestAPIListGrades_OnlyTeachersClasses(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	s := &Server{queries: database.New(mockConn)}
+	taught := uuid.New()
+	req := apiRequest("/api/v1/grades", "grades.submit")
+	user := req.Context().Value(userContextKey).(User)
+
+	// The classes are scoped to the teacher in SQL, so the page and total only cover taught classes.
+	rows := pgxmock.NewRows([]string{"student_id", "student_no", "last_name", "first_name", "middle_name", "class_id", "class_name", "grades"})
+	for range 2 {
+		rows.AddRow(uuid.New(), "STU-1", "Banda", "Chisomo", pgtype.Text{}, taught, "Form 1", dto.GradesMap{})
+	}
+	mockConn.ExpectQuery("ListAPIGrades").
+		WithArgs(user.UserID, pgtype.UUID{}, pgtype.UUID{}, int32(apiDefaultPerPage), int32(0)).
+		WillReturnRows(rows)
+	mockConn.ExpectQuery("CountAPIGrades").
+		WithArgs(user.UserID, pgtype.UUID{}, pgtype.UUID{}).
+		WillReturnRows(pgxmock.NewRows([]string{"count"}).AddRow(int64(2)))
+
+	rec := httptest.NewRecorder()
+	s.apiListGrades(rec, req)
+
+	checkResponseCode(t, http.StatusOK, rec.Code)
+
+	var body APIList[database.StudentGradesView]
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	if body.Meta.Total != 2 || len(body.Data) != 2 {
+		t.Errorf("expected the 2 students of the taught class; got meta %+v with %d rows", body.Meta, len(body.Data))
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
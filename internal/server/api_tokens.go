@@ -0,0 +1,301 @@
+package server
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"school_management_system/cmd/web/settings"
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// apiTokenPrefix marks our tokens so they are easy to spot in scripts and secret scanners.
+	apiTokenPrefix = "sms_"
+	// apiTokenDisplayLength is how much of a token is kept in clear text to tell tokens apart.
+	apiTokenDisplayLength = len(apiTokenPrefix) + 8
+	apiTokenSize          = 32
+
+	apiTokenKindPersonal = "personal"
+	apiTokenKindService  = "service"
+
+	// serviceTokenPermission is the permission needed to create service tokens,
+	// which are meant for integrations and may be created without an expiry date.
+	serviceTokenPermission = "users.manage"
+)
+
+// apiTokenLifetimes are the expiry choices offered when creating a token, in days.
+// Zero means the token never expires and is only allowed for service tokens.
+var apiTokenLifetimes = []int{30, 90, 365, 0}
+
+// generateAPIToken returns a new random bearer token.
+func generateAPIToken() (string, error) {
+	b := make([]byte, apiTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return apiTokenPrefix + base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashAPIToken returns the SHA-256 hex digest stored in place of the token.
+func hashAPIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// bearerToken returns the token from an "Authorization: Bearer" header.
+func bearerToken(r *http.Request) (string, bool) {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// errInvalidAPIToken is returned for tokens that are unknown, revoked or expired.
+var errInvalidAPIToken = errors.New("invalid or expired API token")
+
+// errAPITokenUserPending is returned for tokens whose user still has to change their password or
+// set up two-factor authentication, which the dashboard would make them do before anything else.
+var errAPITokenUserPending = errors.New("the account must change its password or set up two-factor authentication before using API tokens")
+
+// authenticateAPIToken looks up a bearer token and returns the request with the token's user in
+// its context. The user only gets the permissions that are both in the token's scopes and still
+// granted to them, and none while a password change or required two-factor setup is pending.
+func (s *Server) authenticateAPIToken(r *http.Request, token string) (*http.Request, error) {
+	apiToken, err := s.queries.GetAPIToken(r.Context(), hashAPIToken(token))
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errInvalidAPIToken
+		}
+		return nil, err
+	}
+
+	if apiToken.MustChangePassword || (s.twoFactor.Required(apiToken.Roles...) && !apiToken.TotpEnabled) {
+		return nil, errAPITokenUserPending
+	}
+
+	params := database.TouchAPITokenParams{
+		LastUsedIp: pgtype.Text{String: s.clientIP(r), Valid: true},
+		ApiTokenID: apiToken.ApiTokenID,
+	}
+
+	if err := s.queries.TouchAPIToken(r.Context(), params); err != nil {
+		slog.Warn("failed to update api token last used", "error", err.Error())
+	}
+
+	user := User{
+		UserID:      apiToken.UserID,
+		Role:        apiToken.Role,
+		Roles:       apiToken.Roles,
+		Permissions: apiToken.Permissions,
+	}
+
+	ctx := context.WithValue(r.Context(), userContextKey, user)
+	ctx = context.WithValue(ctx, apiTokenIDKey, apiToken.ApiTokenID)
+
+	return r.WithContext(ctx), nil
+}
+
+// APIAuthMiddleware works like AuthMiddleware but also accepts an API token in an
+// "Authorization: Bearer" header.
+func (s *Server) APIAuthMiddleware(next http.Handler) http.Handler {
+	sessionAuth := s.AuthMiddleware(next)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, ok := bearerToken(r)
+		if !ok {
+			sessionAuth.ServeHTTP(w, r)
+			return
+		}
+
+		authed, err := s.authenticateAPIToken(r, token)
+		if errors.Is(err, errAPITokenUserPending) {
+			writeError(w, r, http.StatusForbidden, err.Error())
+			return
+		}
+		if err != nil {
+			if !errors.Is(err, errInvalidAPIToken) {
+				slog.Error("failed to look up api token", "error", err.Error())
+			}
+			w.Header().Set("WWW-Authenticate", `Bearer error="invalid_token"`)
+			writeError(w, r, http.StatusUnauthorized, errInvalidAPIToken.Error())
+			return
+		}
+
+		w.Header().Add("Cache-Control", "no-store")
+		next.ServeHTTP(w, authed)
+	})
+}
+
+// ShowAPITokens lists the logged in user's API tokens with a form for creating new ones.
+func (s *Server) ShowAPITokens(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "not logged in")
+		return
+	}
+
+	tokens, err := s.queries.ListUserAPITokens(r.Context(), user.UserID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get API tokens")
+		slog.Error("failed to list api tokens", "error", err.Error())
+		return
+	}
+
+	s.renderComponent(w, r, settings.APITokens(tokens, user.Permissions, user.Can(serviceTokenPermission), apiTokenLifetimes))
+}
+
+// CreateAPIToken creates a token limited to the ticked scopes and shows it to the user once.
+func (s *Server) CreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "not logged in")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" || len(name) > 100 {
+		writeError(w, r, http.StatusUnprocessableEntity, "Give the token a name of at most 100 characters")
+		return
+	}
+
+	kind := r.FormValue("kind")
+	switch kind {
+	case "", apiTokenKindPersonal:
+		kind = apiTokenKindPersonal
+	case apiTokenKindService:
+		if !user.Can(serviceTokenPermission) {
+			writeError(w, r, http.StatusUnprocessableEntity, "You are not allowed to create service tokens")
+			return
+		}
+	default:
+		writeError(w, r, http.StatusUnprocessableEntity, "Unknown token type")
+		return
+	}
+
+	scopes := r.Form["scope"]
+	if len(scopes) == 0 {
+		writeError(w, r, http.StatusUnprocessableEntity, "Select at least one permission for the token")
+		return
+	}
+	for _, scope := range scopes {
+		if !user.Can(scope) {
+			writeError(w, r, http.StatusUnprocessableEntity, "A token cannot have permissions you do not hold")
+			return
+		}
+	}
+	slices.Sort(scopes)
+	scopes = slices.Compact(scopes)
+
+	days, err := strconv.Atoi(r.FormValue("expires_in_days"))
+	if err != nil || !slices.Contains(apiTokenLifetimes, days) {
+		writeError(w, r, http.StatusUnprocessableEntity, "Choose when the token expires")
+		return
+	}
+	if days == 0 && kind != apiTokenKindService {
+		writeError(w, r, http.StatusUnprocessableEntity, "Only service tokens can be created without an expiry date")
+		return
+	}
+
+	var expiresAt pgtype.Timestamptz
+	if days > 0 {
+		expiresAt = pgtype.Timestamptz{Time: time.Now().AddDate(0, 0, days), Valid: true}
+	}
+
+	token, err := generateAPIToken()
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to generate api token", "error", err.Error())
+		return
+	}
+
+	params := database.CreateAPITokenParams{
+		UserID:      user.UserID,
+		Name:        name,
+		Kind:        kind,
+		TokenHash:   hashAPIToken(token),
+		TokenPrefix: token[:apiTokenDisplayLength],
+		Scopes:      scopes,
+		ExpiresAt:   expiresAt,
+	}
+
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		tokenID, err := qtx.CreateAPIToken(r.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditCreate, auditAPIToken, tokenID, nil, params)
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to create api token", "error", err.Error())
+		return
+	}
+
+	s.renderComponent(w, r, settings.APITokenCreatedModal(name, token))
+}
+
+// RevokeAPIToken stops one of the logged in user's tokens from working.
+func (s *Server) RevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "not logged in")
+		return
+	}
+
+	tokenID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid token id")
+		return
+	}
+
+	params := database.RevokeAPITokenParams{
+		ApiTokenID: tokenID,
+		UserID:     user.UserID,
+	}
+
+	var revoked int64
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		revoked, err = qtx.RevokeAPIToken(r.Context(), params)
+		if err != nil || revoked == 0 {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditDelete, auditAPIToken, tokenID, params, nil)
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to revoke token")
+		slog.Error("failed to revoke api token", "error", err.Error())
+		return
+	}
+
+	if revoked == 0 {
+		writeError(w, r, http.StatusNotFound, "token not found")
+		return
+	}
+
+	s.ShowAPITokens(w, r)
+}
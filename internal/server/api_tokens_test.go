@@ -0,0 +1,208 @@
+// api_tokens_test.go
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestGenerateAPIToken(t *testing.T) {
+	token, err := generateAPIToken()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(token, apiTokenPrefix) || len(token) < apiTokenDisplayLength+16 {
+		t.Errorf("unexpected token format %q", token)
+	}
+
+	other, _ := generateAPIToken()
+	if token == other {
+		t.Error("expected two generated tokens to differ")
+	}
+	if hashAPIToken(token) == hashAPIToken(other) || len(hashAPIToken(token)) != 64 {
+		t.Error("expected distinct 64 character hashes")
+	}
+}
+
+func TestBearerToken(t *testing.T) {
+	tests := []struct {
+		header string
+		token  string
+		ok     bool
+	}{
+		{"Bearer sms_abc", "sms_abc", true},
+		{"bearer sms_abc", "sms_abc", true},
+		{"Basic dXNlcjpwYXNz", "", false},
+		{"Bearer ", "", false},
+		{"", "", false},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest("GET", "/", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+
+		token, ok := bearerToken(req)
+		if token != tt.token || ok != tt.ok {
+			t.Errorf("%q: expected (%q, %v); got (%q, %v)", tt.header, tt.token, tt.ok, token, ok)
+		}
+	}
+}
+
+func TestAPIAuthMiddleware_ValidToken(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	s := &Server{Keys: testKeyring(), queries: database.New(mockConn)}
+	token := "sms_testtoken"
+	tokenID, userID := uuid.New(), uuid.New()
+
+	mockConn.ExpectQuery("SELECT").
+		WithArgs(hashAPIToken(token)).
+		WillReturnRows(pgxmock.NewRows([]string{"api_token_id", "user_id", "role", "roles", "permissions", "must_change_password", "totp_enabled"}).
+			AddRow(tokenID, userID, "accountant", []string{"accountant"}, []string{"fees.view"}, false, false))
+	mockConn.ExpectExec("UPDATE api_tokens").
+		WithArgs(pgxmock.AnyArg(), tokenID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	var got User
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got, _ = r.Context().Value(userContextKey).(User)
+	})
+	h := s.APIAuthMiddleware(csrfProtect(nextHandler))
+
+	// A state-changing request with a token needs no CSRF token.
+	req := httptest.NewRequest("POST", "/fees/create", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d; got %d", http.StatusOK, rec.Code)
+	}
+	if got.UserID != userID || got.Role != "accountant" || !slices.Equal(got.Permissions, []string{"fees.view"}) {
+		t.Errorf("unexpected user in context %+v", got)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestAPIAuthMiddleware_InvalidToken(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	s := &Server{Keys: testKeyring(), queries: database.New(mockConn)}
+
+	mockConn.ExpectQuery("SELECT").
+		WithArgs(hashAPIToken("sms_revoked")).
+		WillReturnError(pgx.ErrNoRows)
+
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "next called")
+	})
+	h := s.APIAuthMiddleware(nextHandler)
+
+	req := httptest.NewRequest("GET", "/fees/", nil)
+	req.Header.Set("Authorization", "Bearer sms_revoked")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected status %d; got %d", http.StatusUnauthorized, rec.Code)
+	}
+	if rec.Header().Get("WWW-Authenticate") == "" {
+		t.Error("expected a WWW-Authenticate header")
+	}
+}
+
+func TestAPIAuthMiddleware_PendingAccountSetup(t *testing.T) {
+	tests := []struct {
+		name               string
+		mustChangePassword bool
+		totpEnabled        bool
+	}{
+		{name: "password change pending", mustChangePassword: true, totpEnabled: true},
+		{name: "two-factor setup pending", mustChangePassword: false, totpEnabled: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockConn, err := pgxmock.NewConn()
+			if err != nil {
+				t.Fatalf("failed to create pgxmock connection: %v", err)
+			}
+			defer mockConn.Close(context.Background())
+
+			s := &Server{
+				Keys:      testKeyring(),
+				queries:   database.New(mockConn),
+				twoFactor: twoFactorPolicy{RequiredRoles: map[string]bool{"admin": true}},
+			}
+			token := "sms_pending"
+
+			// The token is refused before it is marked as used.
+			mockConn.ExpectQuery("SELECT").
+				WithArgs(hashAPIToken(token)).
+				WillReturnRows(pgxmock.NewRows([]string{"api_token_id", "user_id", "role", "roles", "permissions", "must_change_password", "totp_enabled"}).
+					AddRow(uuid.New(), uuid.New(), "admin", []string{"admin"}, []string{"fees.view"}, tt.mustChangePassword, tt.totpEnabled))
+
+			nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "next called")
+			})
+			h := s.APIAuthMiddleware(nextHandler)
+
+			req := httptest.NewRequest("POST", "/fees/create", nil)
+			req.Header.Set("Authorization", "Bearer "+token)
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != http.StatusForbidden {
+				t.Errorf("expected status %d; got %d", http.StatusForbidden, rec.Code)
+			}
+			if rec.Body.String() == "next called" {
+				t.Error("expected the next handler not to be called")
+			}
+
+			if err := mockConn.ExpectationsWereMet(); err != nil {
+				t.Errorf("unfulfilled expectations: %s", err)
+			}
+		})
+	}
+}
+
+func TestAPIAuthMiddleware_FallsBackToSession(t *testing.T) {
+	s := &Server{Keys: testKeyring()}
+
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "next called")
+	})
+	h := s.APIAuthMiddleware(nextHandler)
+
+	req := httptest.NewRequest("GET", "/fees/", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusFound || rec.Header().Get("Location") != "/login" {
+		t.Errorf("expected redirect to /login; got %d %q", rec.Code, rec.Header().Get("Location"))
+	}
+}
@@ -0,0 +1,588 @@
+package server
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// The /api/v1 handlers expose the same data as the HTMX pages as JSON for other systems.
+// List endpoints filter and page in SQL, counting the matching rows with a second query.
+
+// APIUser is a user as returned by the JSON API, without the password hash.
+type APIUser struct {
+	UserID      uuid.UUID   `json:"user_id"`
+	UserNo      string      `json:"user_no"`
+	LastName    string      `json:"last_name"`
+	FirstName   string      `json:"first_name"`
+	Gender      string      `json:"gender"`
+	Email       pgtype.Text `json:"email"`
+	PhoneNumber pgtype.Text `json:"phone_number"`
+	Role        string      `json:"role"`
+}
+
+// apiPathID parses the {id} path parameter, writing a 400 error if it is not a UUID.
+func apiPathID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", "id must be a UUID")
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+// writeAPILookupError writes a 404 for missing rows and a 500 for anything else.
+func writeAPILookupError(w http.ResponseWriter, err error, resource string) {
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeAPIError(w, http.StatusNotFound, "not_found", resource+" not found")
+		return
+	}
+
+	writeAPIError(w, http.StatusInternalServerError, "internal_error", "failed to get "+resource)
+	slog.Error("api lookup failed", "resource", resource, "error", err.Error())
+}
+
+// writeAPIQueryError writes a 500 for a failed list query.
+func writeAPIQueryError(w http.ResponseWriter, err error, resource string) {
+	writeAPIError(w, http.StatusInternalServerError, "internal_error", "failed to list "+resource)
+	slog.Error("api list failed", "resource", resource, "error", err.Error())
+}
+
+// apiTermID returns the term_id query parameter, or the active term when it is absent.
+func (s *Server) apiTermID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	termID, ok, err := uuidParam(r, "term_id")
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return uuid.Nil, false
+	}
+	if ok {
+		return termID, true
+	}
+
+	term, err := s.queries.GetCurrentTerm(r.Context())
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			writeAPIError(w, http.StatusBadRequest, "invalid_parameter", "term_id is required when no term is active")
+			return uuid.Nil, false
+		}
+		writeAPILookupError(w, err, "current term")
+		return uuid.Nil, false
+	}
+
+	return term.TermID, true
+}
+
+// apiListUsers lists users. Filters: role, q (name or user number).
+func (s *Server) apiListUsers(w http.ResponseWriter, r *http.Request) {
+	page, ok := apiPageParam(w, r)
+	if !ok {
+		return
+	}
+
+	role, search := textParam(r, "role"), textParam(r, "q")
+	rows, err := s.queries.ListAPIUsers(r.Context(), database.ListAPIUsersParams{
+		Role:      role,
+		Search:    search,
+		RowLimit:  page.limit(),
+		RowOffset: page.offset(),
+	})
+	if err != nil {
+		writeAPIQueryError(w, err, "users")
+		return
+	}
+
+	total, err := s.queries.CountAPIUsers(r.Context(), database.CountAPIUsersParams{Role: role, Search: search})
+	if err != nil {
+		writeAPIQueryError(w, err, "users")
+		return
+	}
+
+	users := make([]APIUser, 0, len(rows))
+	for _, u := range rows {
+		users = append(users, APIUser{
+			UserID:      u.UserID,
+			UserNo:      u.UserNo,
+			LastName:    u.LastName,
+			FirstName:   u.FirstName,
+			Gender:      u.Gender,
+			Email:       u.Email,
+			PhoneNumber: u.PhoneNumber,
+			Role:        u.Role,
+		})
+	}
+
+	writeJSON(w, http.StatusOK, newAPIList(users, page, total))
+}
+
+// apiGetUser returns a single user.
+func (s *Server) apiGetUser(w http.ResponseWriter, r *http.Request) {
+	id, ok := apiPathID(w, r)
+	if !ok {
+		return
+	}
+
+	u, err := s.queries.GetUserDetails(r.Context(), id)
+	if err != nil {
+		writeAPILookupError(w, err, "user")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIItem[APIUser]{Data: APIUser{
+		UserID:      u.UserID,
+		UserNo:      u.UserNo,
+		LastName:    u.LastName,
+		FirstName:   u.FirstName,
+		Gender:      u.Gender,
+		Email:       u.Email,
+		PhoneNumber: u.PhoneNumber,
+		Role:        u.Role,
+	}})
+}
+
+// apiListStudents lists students. Filters: class_id, status, q (name or student number).
+func (s *Server) apiListStudents(w http.ResponseWriter, r *http.Request) {
+	page, ok := apiPageParam(w, r)
+	if !ok {
+		return
+	}
+
+	classID, byClass, err := uuidParam(r, "class_id")
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+
+	filters := database.CountAPIStudentsParams{
+		ClassID: pgtype.UUID{Bytes: classID, Valid: byClass},
+		Status:  textParam(r, "status"),
+		Search:  textParam(r, "q"),
+	}
+
+	students, err := s.queries.ListAPIStudents(r.Context(), database.ListAPIStudentsParams{
+		ClassID:   filters.ClassID,
+		Status:    filters.Status,
+		Search:    filters.Search,
+		RowLimit:  page.limit(),
+		RowOffset: page.offset(),
+	})
+	if err != nil {
+		writeAPIQueryError(w, err, "students")
+		return
+	}
+
+	total, err := s.queries.CountAPIStudents(r.Context(), filters)
+	if err != nil {
+		writeAPIQueryError(w, err, "students")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAPIList(students, page, total))
+}
+
+// apiGetStudent returns a single student.
+func (s *Server) apiGetStudent(w http.ResponseWriter, r *http.Request) {
+	id, ok := apiPathID(w, r)
+	if !ok {
+		return
+	}
+
+	student, err := s.queries.GetStudent(r.Context(), id)
+	if err != nil {
+		writeAPILookupError(w, err, "student")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIItem[database.GetStudentRow]{Data: student})
+}
+
+// apiListGuardians lists guardians with the students they are linked to.
+// Filters: q (guardian name, phone number or student name).
+func (s *Server) apiListGuardians(w http.ResponseWriter, r *http.Request) {
+	page, ok := apiPageParam(w, r)
+	if !ok {
+		return
+	}
+
+	search := textParam(r, "q")
+	links, err := s.queries.ListAPIGuardians(r.Context(), database.ListAPIGuardiansParams{
+		Search:    search,
+		RowLimit:  page.limit(),
+		RowOffset: page.offset(),
+	})
+	if err != nil {
+		writeAPIQueryError(w, err, "guardians")
+		return
+	}
+
+	total, err := s.queries.CountAPIGuardians(r.Context(), search)
+	if err != nil {
+		writeAPIQueryError(w, err, "guardians")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAPIList(links, page, total))
+}
+
+// apiGetGuardian returns a single guardian.
+func (s *Server) apiGetGuardian(w http.ResponseWriter, r *http.Request) {
+	id, ok := apiPathID(w, r)
+	if !ok {
+		return
+	}
+
+	guardian, err := s.queries.GetGuardianByID(r.Context(), id)
+	if err != nil {
+		writeAPILookupError(w, err, "guardian")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIItem[database.Guardian]{Data: guardian})
+}
+
+// apiListClasses lists classes. Filters: q (class name).
+func (s *Server) apiListClasses(w http.ResponseWriter, r *http.Request) {
+	page, ok := apiPageParam(w, r)
+	if !ok {
+		return
+	}
+
+	search := textParam(r, "q")
+	classes, err := s.queries.ListAPIClasses(r.Context(), database.ListAPIClassesParams{
+		Search:    search,
+		RowLimit:  page.limit(),
+		RowOffset: page.offset(),
+	})
+	if err != nil {
+		writeAPIQueryError(w, err, "classes")
+		return
+	}
+
+	total, err := s.queries.CountAPIClasses(r.Context(), search)
+	if err != nil {
+		writeAPIQueryError(w, err, "classes")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAPIList(classes, page, total))
+}
+
+// apiGetClass returns a single class.
+func (s *Server) apiGetClass(w http.ResponseWriter, r *http.Request) {
+	id, ok := apiPathID(w, r)
+	if !ok {
+		return
+	}
+
+	class, err := s.queries.GetClass(r.Context(), id)
+	if err != nil {
+		writeAPILookupError(w, err, "class")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIItem[database.Class]{Data: class})
+}
+
+// apiListSubjects lists subjects. Filters: class_id, q (subject or class name).
+func (s *Server) apiListSubjects(w http.ResponseWriter, r *http.Request) {
+	page, ok := apiPageParam(w, r)
+	if !ok {
+		return
+	}
+
+	classID, byClass, err := uuidParam(r, "class_id")
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+
+	filters := database.CountAPISubjectsParams{
+		ClassID: pgtype.UUID{Bytes: classID, Valid: byClass},
+		Search:  textParam(r, "q"),
+	}
+
+	subjects, err := s.queries.ListAPISubjects(r.Context(), database.ListAPISubjectsParams{
+		ClassID:   filters.ClassID,
+		Search:    filters.Search,
+		RowLimit:  page.limit(),
+		RowOffset: page.offset(),
+	})
+	if err != nil {
+		writeAPIQueryError(w, err, "subjects")
+		return
+	}
+
+	total, err := s.queries.CountAPISubjects(r.Context(), filters)
+	if err != nil {
+		writeAPIQueryError(w, err, "subjects")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAPIList(subjects, page, total))
+}
+
+// apiGetSubject returns a single subject.
+func (s *Server) apiGetSubject(w http.ResponseWriter, r *http.Request) {
+	id, ok := apiPathID(w, r)
+	if !ok {
+		return
+	}
+
+	subject, err := s.queries.GetSubject(r.Context(), id)
+	if err != nil {
+		writeAPILookupError(w, err, "subject")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIItem[database.Subject]{Data: subject})
+}
+
+// apiListTerms lists terms across academic years, the latest first. Filters: academic_year_id, active.
+func (s *Server) apiListTerms(w http.ResponseWriter, r *http.Request) {
+	page, ok := apiPageParam(w, r)
+	if !ok {
+		return
+	}
+
+	yearID, byYear, err := uuidParam(r, "academic_year_id")
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+
+	active := r.URL.Query().Get("active")
+	if active != "" && active != "true" && active != "false" {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", "active must be true or false")
+		return
+	}
+
+	filters := database.CountAPITermsParams{
+		AcademicYearID: pgtype.UUID{Bytes: yearID, Valid: byYear},
+		Active:         pgtype.Bool{Bool: active == "true", Valid: active != ""},
+	}
+
+	terms, err := s.queries.ListAPITerms(r.Context(), database.ListAPITermsParams{
+		AcademicYearID: filters.AcademicYearID,
+		Active:         filters.Active,
+		RowLimit:       page.limit(),
+		RowOffset:      page.offset(),
+	})
+	if err != nil {
+		writeAPIQueryError(w, err, "terms")
+		return
+	}
+
+	total, err := s.queries.CountAPITerms(r.Context(), filters)
+	if err != nil {
+		writeAPIQueryError(w, err, "terms")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAPIList(terms, page, total))
+}
+
+// apiGetCurrentTerm returns the active term.
+func (s *Server) apiGetCurrentTerm(w http.ResponseWriter, r *http.Request) {
+	term, err := s.queries.GetCurrentTerm(r.Context())
+	if err != nil {
+		writeAPILookupError(w, err, "current term")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIItem[database.GetCurrentTermRow]{Data: term})
+}
+
+// apiListGrades lists students with their grades per subject, keyed by subject ID, in the classes
+// the token's user is assigned to teach.
+// Filters: class_id, student_id.
+func (s *Server) apiListGrades(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeAPIError(w, http.StatusForbidden, "forbidden", "forbidden")
+		return
+	}
+
+	page, ok := apiPageParam(w, r)
+	if !ok {
+		return
+	}
+
+	classID, byClass, err := uuidParam(r, "class_id")
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+
+	studentID, byStudent, err := uuidParam(r, "student_id")
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+
+	filters := database.CountAPIGradesParams{
+		TeacherID: user.UserID,
+		ClassID:   pgtype.UUID{Bytes: classID, Valid: byClass},
+		StudentID: pgtype.UUID{Bytes: studentID, Valid: byStudent},
+	}
+
+	grades, err := s.queries.ListAPIGrades(r.Context(), database.ListAPIGradesParams{
+		TeacherID: filters.TeacherID,
+		ClassID:   filters.ClassID,
+		StudentID: filters.StudentID,
+		RowLimit:  page.limit(),
+		RowOffset: page.offset(),
+	})
+	if err != nil {
+		writeAPIQueryError(w, err, "grades")
+		return
+	}
+
+	total, err := s.queries.CountAPIGrades(r.Context(), filters)
+	if err != nil {
+		writeAPIQueryError(w, err, "grades")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAPIList(grades, page, total))
+}
+
+// apiListFees lists fee records for a term, the active one by default.
+// Filters: term_id, class_id, status.
+func (s *Server) apiListFees(w http.ResponseWriter, r *http.Request) {
+	page, ok := apiPageParam(w, r)
+	if !ok {
+		return
+	}
+
+	termID, ok := s.apiTermID(w, r)
+	if !ok {
+		return
+	}
+
+	classID, byClass, err := uuidParam(r, "class_id")
+	if err != nil {
+		writeAPIError(w, http.StatusBadRequest, "invalid_parameter", err.Error())
+		return
+	}
+
+	filters := database.CountAPIFeesParams{
+		TermID:  termID,
+		ClassID: pgtype.UUID{Bytes: classID, Valid: byClass},
+		Status:  textParam(r, "status"),
+	}
+
+	fees, err := s.queries.ListAPIFees(r.Context(), database.ListAPIFeesParams{
+		TermID:    filters.TermID,
+		ClassID:   filters.ClassID,
+		Status:    filters.Status,
+		RowLimit:  page.limit(),
+		RowOffset: page.offset(),
+	})
+	if err != nil {
+		writeAPIQueryError(w, err, "fees")
+		return
+	}
+
+	total, err := s.queries.CountAPIFees(r.Context(), filters)
+	if err != nil {
+		writeAPIQueryError(w, err, "fees")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAPIList(fees, page, total))
+}
+
+// apiGetFeesRecord returns a single fee record.
+func (s *Server) apiGetFeesRecord(w http.ResponseWriter, r *http.Request) {
+	id, ok := apiPathID(w, r)
+	if !ok {
+		return
+	}
+
+	record, err := s.queries.GetFeesRecord(r.Context(), id)
+	if err != nil {
+		writeAPILookupError(w, err, "fees record")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, APIItem[database.GetFeesRecordRow]{Data: record})
+}
+
+// apiListRemarks lists the remarks for the classes the token's user is class teacher of.
+// Filters: term_id (the active term by default), q (student name or number).
+func (s *Server) apiListRemarks(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeAPIError(w, http.StatusUnauthorized, "unauthorized", "user not authenticated")
+		return
+	}
+
+	page, ok := apiPageParam(w, r)
+	if !ok {
+		return
+	}
+
+	termID, ok := s.apiTermID(w, r)
+	if !ok {
+		return
+	}
+
+	filters := database.CountAPIRemarksParams{
+		UserID: user.UserID,
+		TermID: termID,
+		Search: textParam(r, "q"),
+	}
+
+	remarks, err := s.queries.ListAPIRemarks(r.Context(), database.ListAPIRemarksParams{
+		UserID:    filters.UserID,
+		TermID:    filters.TermID,
+		Search:    filters.Search,
+		RowLimit:  page.limit(),
+		RowOffset: page.offset(),
+	})
+	if err != nil {
+		writeAPIQueryError(w, err, "remarks")
+		return
+	}
+
+	total, err := s.queries.CountAPIRemarks(r.Context(), filters)
+	if err != nil {
+		writeAPIQueryError(w, err, "remarks")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAPIList(remarks, page, total))
+}
+
+// apiListDiscipline lists disciplinary records. Filters: q (student name or offense).
+func (s *Server) apiListDiscipline(w http.ResponseWriter, r *http.Request) {
+	page, ok := apiPageParam(w, r)
+	if !ok {
+		return
+	}
+
+	search := textParam(r, "q")
+	records, err := s.queries.ListAPIDiscipline(r.Context(), database.ListAPIDisciplineParams{
+		Search:    search,
+		RowLimit:  page.limit(),
+		RowOffset: page.offset(),
+	})
+	if err != nil {
+		writeAPIQueryError(w, err, "discipline records")
+		return
+	}
+
+	total, err := s.queries.CountAPIDiscipline(r.Context(), search)
+	if err != nil {
+		writeAPIQueryError(w, err, "discipline records")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, newAPIList(records, page, total))
+}
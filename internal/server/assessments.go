@@ -0,0 +1,258 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"school_management_system/cmd/web/dashboard/classes"
+	"school_management_system/internal/database"
+	"school_management_system/internal/grading"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// ComponentScore is a student's score in one assessment component of a subject.
+type ComponentScore struct {
+	ComponentID string  `json:"component_id"`
+	Score       float64 `json:"score"`
+}
+
+// componentsFromRows converts stored assessment components to grading components.
+func componentsFromRows(rows []database.AssessmentComponent) []grading.Component {
+	components := make([]grading.Component, len(rows))
+	for i, row := range rows {
+		weight, _ := row.Weight.Float64Value()
+		components[i] = grading.Component{Name: row.Name, Weight: weight.Float64}
+	}
+
+	return components
+}
+
+// classAssessmentComponents groups the assessment components of a class's subjects in a term by subject.
+func classAssessmentComponents(ctx context.Context, q *database.Queries, classID, termID uuid.UUID) (map[uuid.UUID][]database.AssessmentComponent, error) {
+	rows, err := q.ListClassAssessmentComponents(ctx, database.ListClassAssessmentComponentsParams{
+		ClassID: classID,
+		TermID:  termID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	components := make(map[uuid.UUID][]database.AssessmentComponent)
+	for _, row := range rows {
+		components[row.SubjectID] = append(components[row.SubjectID], row)
+	}
+
+	return components, nil
+}
+
+// ShowAssessmentComponents renders the assessment components of a subject for the current term.
+func (s *Server) ShowAssessmentComponents(w http.ResponseWriter, r *http.Request) {
+	subjectID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid subject")
+		return
+	}
+
+	term, err := s.getCachedTerm()
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "there is no active term")
+		return
+	}
+
+	subject, err := s.queries.GetSubject(r.Context(), subjectID)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "subject not found")
+		return
+	}
+
+	rows, err := s.queries.ListSubjectAssessmentComponents(r.Context(), database.ListSubjectAssessmentComponentsParams{
+		SubjectID: subjectID,
+		TermID:    term.TermID,
+	})
+	if err != nil {
+		writeAppError(w, r, NewAppError(http.StatusInternalServerError, "failed to get assessment components").Wrap(err))
+		return
+	}
+
+	s.renderComponent(w, r, classes.AssessmentComponentsForm(subject, term.AcademicTerm, grading.FormatComponents(componentsFromRows(rows))))
+}
+
+// SaveAssessmentComponents replaces the assessment components of a subject for the current term and
+// recomputes the final scores of the students already graded in it, adding a revision to each grade
+// that changed. Components are matched by name, so renaming one drops its scores. Components can't
+// change while the class's report cards are submitted or later, unless grade entry is unlocked.
+func (s *Server) SaveAssessmentComponents(w http.ResponseWriter, r *http.Request) {
+	subjectID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid subject")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "failed to parse form")
+		return
+	}
+
+	components, err := grading.ParseComponents(r.FormValue("components"))
+	if err != nil {
+		message := strings.TrimPrefix(err.Error(), grading.ErrInvalidComponents.Error()+": ")
+		writeAppError(w, r, ValidationError("invalid assessment components", map[string]string{"components": message}))
+		return
+	}
+
+	term, err := s.getCachedTerm()
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "there is no active term")
+		return
+	}
+
+	key := database.ListSubjectAssessmentComponentsParams{SubjectID: subjectID, TermID: term.TermID}
+
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		subject, err := qtx.GetSubject(r.Context(), subjectID)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return NewAppError(http.StatusNotFound, "subject not found")
+			}
+			return err
+		}
+
+		// New components recompute the final scores, which report cards under review must keep.
+		lock := database.LockReportApprovalParams{ClassID: subject.ClassID, TermID: term.TermID}
+		if _, err := qtx.LockReportApproval(r.Context(), lock); err != nil {
+			return err
+		}
+		entry, err := loadGradeEntry(r.Context(), qtx, term.TermID, subject.ClassID)
+		if err != nil {
+			return err
+		}
+		if entry.locked(subjectID, time.Now()) != "" {
+			return NewAppError(http.StatusConflict, "Assessment components can't change while the report cards are "+entry.approval)
+		}
+
+		before, err := qtx.ListSubjectAssessmentComponents(r.Context(), key)
+		if err != nil {
+			return err
+		}
+
+		names := make([]string, len(components))
+		for i, component := range components {
+			names[i] = component.Name
+			params := database.UpsertAssessmentComponentParams{
+				SubjectID: subjectID,
+				TermID:    term.TermID,
+				Name:      component.Name,
+				Weight:    numericFromFloat(component.Weight),
+				Position:  int32(i),
+			}
+			if _, err := qtx.UpsertAssessmentComponent(r.Context(), params); err != nil {
+				return err
+			}
+		}
+
+		err = qtx.DeleteRemovedAssessmentComponents(r.Context(), database.DeleteRemovedAssessmentComponentsParams{
+			SubjectID: subjectID,
+			TermID:    term.TermID,
+			Names:     names,
+		})
+		if err != nil {
+			return err
+		}
+
+		if len(components) > 0 {
+			changed, err := qtx.RecomputeSubjectGrades(r.Context(), database.RecomputeSubjectGradesParams(key))
+			if err != nil {
+				return err
+			}
+			for _, grade := range changed {
+				if err := recordGradeRevision(r, qtx, grade, revisionRecompute); err != nil {
+					return err
+				}
+			}
+		}
+
+		after, err := qtx.ListSubjectAssessmentComponents(r.Context(), key)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditUpdate, auditAssessments, subjectID, before, after)
+	})
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "" {
+		w.Header().Set("HX-Redirect", "/academics/classes")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	http.Redirect(w, r, "/academics/classes", http.StatusFound)
+}
+
+// saveAssessmentScores saves a student's submitted component scores for a subject and returns the
+// final score they make up. A component left out of submitted keeps its stored score, or counts as
+// zero if it has none.
+func saveAssessmentScores(r *http.Request, qtx *database.Queries, studentID uuid.UUID, components []database.AssessmentComponent, submitted []ComponentScore) (float64, error) {
+	scores := make(map[uuid.UUID]float64, len(submitted))
+	for _, entry := range submitted {
+		componentID, err := uuid.Parse(entry.ComponentID)
+		if err != nil {
+			return 0, NewAppError(http.StatusBadRequest, "Invalid request format").WithField("component_id", "must be an assessment component ID")
+		}
+		scores[componentID] = entry.Score
+	}
+
+	weighted := make([]grading.WeightedScore, 0, len(components))
+	for _, component := range components {
+		weight, _ := component.Weight.Float64Value()
+		key := database.GetAssessmentScoreParams{ComponentID: component.ComponentID, StudentID: studentID}
+
+		before, err := qtx.GetAssessmentScore(r.Context(), key)
+		existed := err == nil
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return 0, err
+		}
+
+		score, ok := scores[component.ComponentID]
+		delete(scores, component.ComponentID)
+		if !ok {
+			stored, _ := before.Score.Float64Value()
+			weighted = append(weighted, grading.WeightedScore{Score: stored.Float64, Weight: weight.Float64})
+			continue
+		}
+		weighted = append(weighted, grading.WeightedScore{Score: score, Weight: weight.Float64})
+
+		after, err := qtx.UpsertAssessmentScore(r.Context(), database.UpsertAssessmentScoreParams{
+			ComponentID: component.ComponentID,
+			StudentID:   studentID,
+			Score:       numericFromFloat(score),
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		if existed {
+			err = recordAudit(r, qtx, auditUpdate, auditAssessmentScore, after.AssessmentScoreID, before, after)
+		} else {
+			err = recordAudit(r, qtx, auditCreate, auditAssessmentScore, after.AssessmentScoreID, nil, after)
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if len(scores) > 0 {
+		return 0, NewAppError(http.StatusUnprocessableEntity, "Unknown assessment component").
+			WithField("component_id", "must be a component of the subject in this term")
+	}
+
+	return grading.FinalScore(weighted), nil
+}
@@ -0,0 +1,62 @@
+// assessments_test.go
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestSaveAssessmentScores(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	qtx := database.New(mockConn)
+	req := httptest.NewRequest("POST", "/grades/submit", nil)
+	studentID, testID, examID := uuid.New(), uuid.New(), uuid.New()
+	components := []database.AssessmentComponent{
+		{ComponentID: testID, Name: "Test", Weight: numericFromFloat(40)},
+		{ComponentID: examID, Name: "Exam", Weight: numericFromFloat(60)},
+	}
+	scoreColumns := []string{"assessment_score_id", "component_id", "student_id", "score"}
+
+	// The test score is new; the exam score was entered before and is not resubmitted.
+	mockConn.ExpectQuery("SELECT").WithArgs(testID, studentID).WillReturnError(pgx.ErrNoRows)
+	mockConn.ExpectQuery("INSERT INTO assessment_scores").WithArgs(testID, studentID, numericFromFloat(90)).
+		WillReturnRows(pgxmock.NewRows(scoreColumns).AddRow(uuid.New(), testID, studentID, numericFromFloat(90)))
+	mockConn.ExpectExec("INSERT INTO audit_log").
+		WithArgs(pgxmock.AnyArg(), auditCreate, auditAssessmentScore, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockConn.ExpectQuery("SELECT").WithArgs(examID, studentID).
+		WillReturnRows(pgxmock.NewRows(scoreColumns).AddRow(uuid.New(), examID, studentID, numericFromFloat(50)))
+
+	submitted := []ComponentScore{{ComponentID: testID.String(), Score: 90}}
+	final, err := saveAssessmentScores(req, qtx, studentID, components, submitted)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if final != 66 {
+		t.Errorf("expected a final score of 66; got %v", final)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+
+	var appErr *AppError
+	submitted = []ComponentScore{{ComponentID: uuid.NewString(), Score: 50}}
+	if _, err := saveAssessmentScores(req, qtx, studentID, nil, submitted); !errors.As(err, &appErr) || appErr.Status != http.StatusUnprocessableEntity {
+		t.Errorf("expected an unknown component to be rejected; got %v", err)
+	}
+}
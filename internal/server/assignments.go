@@ -14,13 +14,13 @@ import (
 func (s *Server) ShowCreateAssignmentForm(w http.ResponseWriter, r *http.Request) {
 	teachers, err := s.queries.ListUsers(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to retrieve teachers")
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve teachers")
 		return
 	}
 
 	subjects, err := s.queries.ListAllSubjects(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to retrieve subjects")
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve subjects")
 		return
 	}
 
@@ -31,7 +31,7 @@ func (s *Server) ShowCreateAssignmentForm(w http.ResponseWriter, r *http.Request
 // It reads form values for teacher_id, class_id, and subject_id.
 func (s *Server) CreateAssignment(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "failed to parse form")
+		writeError(w, r, http.StatusUnprocessableEntity, "failed to parse form")
 		return
 	}
 
@@ -40,29 +40,29 @@ func (s *Server) CreateAssignment(w http.ResponseWriter, r *http.Request) {
 
 	parts := strings.Split(subjectClass, "=")
 	if len(parts) != 2 {
-		writeError(w, http.StatusBadRequest, "invalid subject and class selection")
+		writeError(w, r, http.StatusBadRequest, "invalid subject and class selection")
 		return
 	}
 
 	if teacherID == "" || subjectClass == "" {
-		writeError(w, http.StatusUnprocessableEntity, "missing required fields")
+		writeError(w, r, http.StatusUnprocessableEntity, "missing required fields")
 		return
 	}
 
 	parsedTeacherID, err := convertStringToUUID(teacherID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid teacher ID")
+		writeError(w, r, http.StatusBadRequest, "invalid teacher ID")
 		return
 	}
 	parsedSubjectID, err := convertStringToUUID(parts[0])
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid subject ID")
+		writeError(w, r, http.StatusBadRequest, "invalid subject ID")
 		return
 	}
 
 	parsedClassID, err := convertStringToUUID(parts[1])
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid class ID")
+		writeError(w, r, http.StatusBadRequest, "invalid class ID")
 		return
 	}
 
@@ -71,9 +71,16 @@ func (s *Server) CreateAssignment(w http.ResponseWriter, r *http.Request) {
 		ClassID:   parsedClassID,
 		SubjectID: parsedSubjectID,
 	}
-	_, err = s.queries.CreateAssignments(r.Context(), params)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		assignment, err := qtx.CreateAssignments(r.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditCreate, auditAssignment, assignment.ID, nil, assignment)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to create assignment", "error", err.Error())
 		return
 	}
@@ -108,7 +115,7 @@ func groupAssignments(assignments []database.ListAssignmentsRow) map[string]map[
 func (s *Server) ListAssignments(w http.ResponseWriter, r *http.Request) {
 	assigns, err := s.queries.ListAssignments(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to list assignments", "error", err.Error())
 		return
 	}
@@ -122,26 +129,26 @@ func (s *Server) ListAssignments(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ShowEditAssignment(w http.ResponseWriter, r *http.Request) {
 	assignmentID, err := convertStringToUUID(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid assignment ID")
+		writeError(w, r, http.StatusBadRequest, "invalid assignment ID")
 		return
 	}
 
 	assignment, err := s.queries.GetAssignment(r.Context(), assignmentID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to retrieve assignment", "error", err.Error())
 		return
 	}
 
 	teachers, err := s.queries.ListUsers(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to retrieve teachers")
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve teachers")
 		return
 	}
 
 	subjects, err := s.queries.ListAllSubjects(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to retrieve subjects")
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve subjects")
 		return
 	}
 
@@ -152,11 +159,11 @@ func (s *Server) ShowEditAssignment(w http.ResponseWriter, r *http.Request) {
 func (s *Server) EditAssignment(w http.ResponseWriter, r *http.Request) {
 	assignmentID, err := convertStringToUUID(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid assignment ID")
+		writeError(w, r, http.StatusBadRequest, "invalid assignment ID")
 		return
 	}
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "failed to parse form")
+		writeError(w, r, http.StatusUnprocessableEntity, "failed to parse form")
 		return
 	}
 
@@ -165,29 +172,29 @@ func (s *Server) EditAssignment(w http.ResponseWriter, r *http.Request) {
 
 	parts := strings.Split(subjectClass, "=")
 	if len(parts) != 2 {
-		writeError(w, http.StatusBadRequest, "invalid subject and class selection")
+		writeError(w, r, http.StatusBadRequest, "invalid subject and class selection")
 		return
 	}
 
 	if teacherID == "" || subjectClass == "" {
-		writeError(w, http.StatusUnprocessableEntity, "missing required fields")
+		writeError(w, r, http.StatusUnprocessableEntity, "missing required fields")
 		return
 	}
 
 	parsedTeacherID, err := convertStringToUUID(teacherID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid teacher ID")
+		writeError(w, r, http.StatusBadRequest, "invalid teacher ID")
 		return
 	}
 	parsedSubjectID, err := convertStringToUUID(parts[0])
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid subject ID")
+		writeError(w, r, http.StatusBadRequest, "invalid subject ID")
 		return
 	}
 
 	parsedClassID, err := convertStringToUUID(parts[1])
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid class ID")
+		writeError(w, r, http.StatusBadRequest, "invalid class ID")
 		return
 	}
 
@@ -197,9 +204,25 @@ func (s *Server) EditAssignment(w http.ResponseWriter, r *http.Request) {
 		ClassID:   parsedClassID,
 		SubjectID: parsedSubjectID,
 	}
-	err = s.queries.EditAssignments(r.Context(), params)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		before, err := qtx.GetAssignment(r.Context(), assignmentID)
+		if err != nil {
+			return err
+		}
+
+		if err := qtx.EditAssignments(r.Context(), params); err != nil {
+			return err
+		}
+
+		after, err := qtx.GetAssignment(r.Context(), assignmentID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditUpdate, auditAssignment, assignmentID, before, after)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to edit assignment", "error", err.Error())
 		return
 	}
@@ -216,12 +239,23 @@ func (s *Server) EditAssignment(w http.ResponseWriter, r *http.Request) {
 func (s *Server) DeleteAssignment(w http.ResponseWriter, r *http.Request) {
 	assignmentID, err := convertStringToUUID(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid assignment ID")
+		writeError(w, r, http.StatusBadRequest, "invalid assignment ID")
 		return
 	}
-	err = s.queries.DeleteAssignments(r.Context(), assignmentID)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		before, err := qtx.GetAssignment(r.Context(), assignmentID)
+		if err != nil {
+			return err
+		}
+
+		if err := qtx.DeleteAssignments(r.Context(), assignmentID); err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditDelete, auditAssignment, assignmentID, before, nil)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to delete assignment", "error", err.Error())
 		return
 	}
@@ -238,14 +272,14 @@ func (s *Server) DeleteAssignment(w http.ResponseWriter, r *http.Request) {
 func (s *Server) getAssignedClasses(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(userContextKey).(User)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "unauthorized")
+		writeError(w, r, http.StatusUnauthorized, "unauthorized")
 		slog.Error("Failed to get user context")
 		return
 	}
 
 	classes, err := s.queries.GetAssignedClasses(r.Context(), user.UserID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("internal server error", "message", err.Error())
 		return
 	}
@@ -0,0 +1,261 @@
+package server
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"school_management_system/cmd/web/dashboard/audit"
+	"school_management_system/internal/database"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Audit log actions.
+const (
+	auditCreate  = "create"
+	auditUpdate  = "update"
+	auditDelete  = "delete"
+	auditRestore = "restore"
+	auditPurge   = "purge"
+)
+
+// Entity types recorded in the audit log.
+const (
+	auditAcademicYear     = "academic_year"
+	auditAPIToken         = "api_token"
+	auditAssessments      = "assessment_components"
+	auditAssessmentScore  = "assessment_score"
+	auditAssignment       = "assignment"
+	auditClass            = "class"
+	auditClassScale       = "class_grading_scale"
+	auditClassTeacher     = "class_teacher"
+	auditDiscipline       = "disciplinary_record"
+	auditFeesRecord       = "fees_record"
+	auditFeesStructure    = "fees_structure"
+	auditGrade            = "grade"
+	auditGradeEntryUnlock = "grade_entry_unlock"
+	auditGradeEntryWindow = "grade_entry_window"
+	auditGradingScale     = "grading_scale"
+	auditGuardian         = "guardian"
+	auditPromotion        = "promotion"
+	auditPromotionRule    = "promotion_rule"
+	auditRemark           = "remark"
+	auditReportApproval   = "report_approval"
+	auditResetCode        = "password_reset_code"
+	auditRole             = "role"
+	auditSession          = "session"
+	auditStudent          = "student"
+	auditSubject          = "subject"
+	auditTerm             = "term"
+	auditUser             = "user"
+)
+
+const (
+	// auditLogPageLimit is how many entries the audit log page shows.
+	auditLogPageLimit = 200
+	// auditLogExportLimit caps the entries in a CSV export.
+	auditLogExportLimit = 10000
+)
+
+// auditEntityTypes are offered in the audit log's entity filter.
+var auditEntityTypes = []string{
+	auditAcademicYear, auditAPIToken, auditAssessments, auditAssessmentScore, auditAssignment, auditClass,
+	auditClassScale, auditClassTeacher, auditDiscipline, auditFeesRecord, auditFeesStructure, auditGrade,
+	auditGradeEntryUnlock, auditGradeEntryWindow, auditGradingScale, auditGuardian, auditPromotion,
+	auditPromotionRule, auditRemark, auditReportApproval, auditResetCode, auditRole, auditSession, auditStudent,
+	auditSubject, auditTerm, auditUser,
+}
+
+// auditRedactedFields are removed from entities before they are written to the audit log.
+var auditRedactedFields = []string{"password", "totp_secret", "token_hash", "code_hash"}
+
+// recordAudit records a change made by the request's user. Pass the queries of the transaction that made
+// the change, so the entry is only kept if the change is.
+// before is nil for creates and after is nil for deletes.
+func recordAudit(r *http.Request, q *database.Queries, action, entityType string, entityID uuid.UUID, before, after any) error {
+	beforeJSON, err := auditJSON(before)
+	if err != nil {
+		return fmt.Errorf("marshal audit before: %w", err)
+	}
+
+	afterJSON, err := auditJSON(after)
+	if err != nil {
+		return fmt.Errorf("marshal audit after: %w", err)
+	}
+
+	params := database.CreateAuditLogParams{
+		ActorUserID: requestUserID(r),
+		Action:      action,
+		EntityType:  entityType,
+		EntityID:    pgtype.UUID{Bytes: entityID, Valid: entityID != uuid.Nil},
+		Before:      beforeJSON,
+		After:       afterJSON,
+		RequestID:   middleware.GetReqID(r.Context()),
+	}
+
+	return q.CreateAuditLog(r.Context(), params)
+}
+
+// auditJSON marshals an entity for the audit log without its secrets.
+func auditJSON(v any) ([]byte, error) {
+	if v == nil {
+		return nil, nil
+	}
+
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		// Not an object, nothing to redact.
+		return b, nil
+	}
+
+	redacted := false
+	for _, name := range auditRedactedFields {
+		if _, ok := fields[name]; ok {
+			delete(fields, name)
+			redacted = true
+		}
+	}
+
+	if !redacted {
+		return b, nil
+	}
+
+	return json.Marshal(fields)
+}
+
+// parseAuditFilter reads the audit log filters from the query string.
+// from and to are dates; to is inclusive.
+func parseAuditFilter(r *http.Request) (database.ListAuditLogParams, error) {
+	var params database.ListAuditLogParams
+	query := r.URL.Query()
+
+	if v := query.Get("user_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return params, NewAppError(http.StatusBadRequest, "invalid user").WithField("user_id", "must be a user ID")
+		}
+		params.ActorUserID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	if v := query.Get("entity_type"); v != "" {
+		params.EntityType = pgtype.Text{String: v, Valid: true}
+	}
+
+	if v := query.Get("entity_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return params, NewAppError(http.StatusBadRequest, "invalid entity").WithField("entity_id", "must be a UUID")
+		}
+		params.EntityID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
+	if v := query.Get("from"); v != "" {
+		from, err := time.ParseInLocation(time.DateOnly, v, time.Local)
+		if err != nil {
+			return params, NewAppError(http.StatusBadRequest, "invalid date").WithField("from", "must be a date")
+		}
+		params.FromTime = pgtype.Timestamptz{Time: from, Valid: true}
+	}
+
+	if v := query.Get("to"); v != "" {
+		to, err := time.ParseInLocation(time.DateOnly, v, time.Local)
+		if err != nil {
+			return params, NewAppError(http.StatusBadRequest, "invalid date").WithField("to", "must be a date")
+		}
+		params.ToTime = pgtype.Timestamptz{Time: to.AddDate(0, 0, 1), Valid: true}
+	}
+
+	return params, nil
+}
+
+// ShowAuditLog lists the latest audit log entries matching the filters.
+func (s *Server) ShowAuditLog(w http.ResponseWriter, r *http.Request) {
+	params, err := parseAuditFilter(r)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+	params.RowLimit = auditLogPageLimit
+
+	entries, err := s.queries.ListAuditLog(r.Context(), params)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get audit log")
+		slog.Error("failed to list audit log", "error", err.Error())
+		return
+	}
+
+	users, err := s.queries.ListUsers(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get users")
+		slog.Error("failed to list users", "error", err.Error())
+		return
+	}
+
+	filter := audit.Filter{
+		UserID:     r.URL.Query().Get("user_id"),
+		EntityType: r.URL.Query().Get("entity_type"),
+		EntityID:   r.URL.Query().Get("entity_id"),
+		From:       r.URL.Query().Get("from"),
+		To:         r.URL.Query().Get("to"),
+		Query:      r.URL.RawQuery,
+	}
+
+	s.renderComponent(w, r, audit.AuditLog(entries, users, auditEntityTypes, filter, auditLogPageLimit))
+}
+
+// ExportAuditLog downloads the audit log entries matching the filters as CSV.
+func (s *Server) ExportAuditLog(w http.ResponseWriter, r *http.Request) {
+	params, err := parseAuditFilter(r)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+	params.RowLimit = auditLogExportLimit
+
+	entries, err := s.queries.ListAuditLog(r.Context(), params)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get audit log")
+		slog.Error("failed to list audit log", "error", err.Error())
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=audit_log_%s.csv", time.Now().Format("20060102")))
+
+	cw := csv.NewWriter(w)
+	_ = cw.Write([]string{"time", "user_no", "user", "action", "entity_type", "entity_id", "request_id", "before", "after"})
+	for _, e := range entries {
+		entityID := ""
+		if e.EntityID.Valid {
+			entityID = uuid.UUID(e.EntityID.Bytes).String()
+		}
+
+		_ = cw.Write([]string{
+			e.CreatedAt.Time.Format(time.RFC3339),
+			e.ActorUserNo,
+			e.ActorName,
+			e.Action,
+			e.EntityType,
+			entityID,
+			e.RequestID,
+			string(e.Before),
+			string(e.After),
+		})
+	}
+
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		slog.Error("failed to write audit log csv", "error", err.Error())
+	}
+}
@@ -0,0 +1,157 @@
+// audit_test.go
+package server
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"school_management_system/internal/database"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestAuditJSON_RedactsSecrets(t *testing.T) {
+	user := database.GetUserDetailsRow{
+		UserID:    uuid.New(),
+		FirstName: "Jane",
+		Password:  "$2a$10$hash",
+	}
+
+	b, err := auditJSON(user)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+
+	var fields map[string]any
+	if err := json.Unmarshal(b, &fields); err != nil {
+		t.Fatalf("expected a JSON object: %v", err)
+	}
+	if _, ok := fields["password"]; ok {
+		t.Error("password must not be written to the audit log")
+	}
+	if fields["first_name"] != "Jane" {
+		t.Errorf("expected the other fields to be kept; got %v", fields)
+	}
+
+	if b, err := auditJSON(nil); err != nil || b != nil {
+		t.Errorf("expected nil for a missing entity; got %q, %v", b, err)
+	}
+}
+
+func TestParseAuditFilter(t *testing.T) {
+	userID := uuid.New()
+	req := httptest.NewRequest("GET", "/audit?user_id="+userID.String()+"&entity_type=grade&from=2025-01-01&to=2025-01-31", nil)
+
+	params, err := parseAuditFilter(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !params.ActorUserID.Valid || params.ActorUserID.Bytes != userID {
+		t.Errorf("expected the user filter; got %+v", params.ActorUserID)
+	}
+	if params.EntityType.String != "grade" || params.EntityID.Valid {
+		t.Errorf("unexpected entity filter %+v %+v", params.EntityType, params.EntityID)
+	}
+	if got := params.ToTime.Time.Format(time.DateOnly); got != "2025-02-01" {
+		t.Errorf("expected to to include the whole day; got %s", got)
+	}
+
+	for _, query := range []string{"user_id=1", "entity_id=abc", "from=01/02/2025", "to=tomorrow"} {
+		if _, err := parseAuditFilter(httptest.NewRequest("GET", "/audit?"+query, nil)); err == nil {
+			t.Errorf("expected %q to be rejected", query)
+		}
+	}
+}
+
+func TestRecordAudit(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	userID, feesID := uuid.New(), uuid.New()
+	req := httptest.NewRequest("PUT", "/fees/edit/"+feesID.String(), nil)
+	ctx := context.WithValue(req.Context(), userContextKey, User{UserID: userID})
+	ctx = context.WithValue(ctx, middleware.RequestIDKey, "host/abc-000001")
+	req = req.WithContext(ctx)
+
+	before := database.Fee{FeesID: feesID, Status: "PARTIAL"}
+	after := database.Fee{FeesID: feesID, Status: "PAID"}
+	beforeJSON, _ := json.Marshal(before)
+	afterJSON, _ := json.Marshal(after)
+
+	mockConn.ExpectExec("INSERT INTO audit_log").
+		WithArgs(
+			pgtype.UUID{Bytes: userID, Valid: true},
+			auditUpdate,
+			auditFeesRecord,
+			pgtype.UUID{Bytes: feesID, Valid: true},
+			beforeJSON,
+			afterJSON,
+			"host/abc-000001",
+		).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	if err := recordAudit(req, database.New(mockConn), auditUpdate, auditFeesRecord, feesID, before, after); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestExportAuditLog(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	s := &Server{queries: database.New(mockConn)}
+	gradeID := uuid.New()
+	created := time.Date(2025, 3, 4, 10, 0, 0, 0, time.UTC)
+
+	rows := pgxmock.NewRows([]string{"audit_log_id", "actor_user_id", "actor_name", "actor_user_no", "action", "entity_type", "entity_id", "before", "after", "request_id", "created_at"}).
+		AddRow(uuid.New(), pgtype.UUID{Bytes: uuid.New(), Valid: true}, "Jane Doe", "T001", auditUpdate, auditGrade,
+			pgtype.UUID{Bytes: gradeID, Valid: true}, []byte(`{"score":"50"}`), []byte(`{"score":"65"}`), "req-1",
+			pgtype.Timestamptz{Time: created, Valid: true})
+	mockConn.ExpectQuery("SELECT").
+		WithArgs(pgtype.UUID{}, pgtype.Text{String: auditGrade, Valid: true}, pgtype.UUID{}, pgtype.Timestamptz{}, pgtype.Timestamptz{}, int32(auditLogExportLimit)).
+		WillReturnRows(rows)
+
+	req := httptest.NewRequest("GET", "/audit/export?entity_type=grade", nil)
+	rec := httptest.NewRecorder()
+	s.ExportAuditLog(rec, req)
+
+	checkResponseCode(t, http.StatusOK, rec.Code)
+	if got := rec.Header().Get("Content-Disposition"); !strings.HasPrefix(got, "attachment; filename=audit_log_") {
+		t.Errorf("expected a CSV attachment; got %q", got)
+	}
+
+	records, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatalf("failed to read CSV: %v", err)
+	}
+	if len(records) != 2 {
+		t.Fatalf("expected a header and one entry; got %d rows", len(records))
+	}
+	if records[1][1] != "T001" || records[1][5] != gradeID.String() || records[1][8] != `{"score":"65"}` {
+		t.Errorf("unexpected entry %v", records[1])
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
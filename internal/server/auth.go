@@ -10,7 +10,6 @@ import (
 	"time"
 
 	"school_management_system/cmd/web"
-	"school_management_system/internal/cookies"
 	"school_management_system/internal/database"
 
 	"github.com/google/uuid"
@@ -18,9 +17,17 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// dummyPasswordHash is compared against when no account matches the identifier,
+// so unknown users take as long to turn away as a wrong password does.
+const dummyPasswordHash = "$2a$10$iAOJagd4x7DKtdB1HvEz0.2IYLLcdhhEWNb2LJyO7oVt7EFoSCAaq"
+
 type LoginUser struct {
-	Password string
-	UserID   uuid.UUID
+	Password            string
+	UserID              uuid.UUID
+	FailedLoginAttempts int32
+	LastFailedLoginAt   pgtype.Timestamptz
+	LockedUntil         pgtype.Timestamptz
+	TotpEnabled         bool
 }
 
 // createSessionCookie function is a helper function that creates a session cookie
@@ -61,8 +68,12 @@ func (s *Server) getUserByIdentifier(ctx context.Context, identifier string) (Lo
 			return loginUser, err
 		}
 		loginUser = LoginUser{
-			Password: returnedUser.Password,
-			UserID:   returnedUser.UserID,
+			Password:            returnedUser.Password,
+			UserID:              returnedUser.UserID,
+			FailedLoginAttempts: returnedUser.FailedLoginAttempts,
+			LastFailedLoginAt:   returnedUser.LastFailedLoginAt,
+			LockedUntil:         returnedUser.LockedUntil,
+			TotpEnabled:         returnedUser.TotpEnabled,
 		}
 	case usernamePattern.MatchString(identifier):
 		returnedUser, err := s.queries.GetUserByUsername(ctx, identifier)
@@ -70,8 +81,12 @@ func (s *Server) getUserByIdentifier(ctx context.Context, identifier string) (Lo
 			return loginUser, err
 		}
 		loginUser = LoginUser{
-			Password: returnedUser.Password,
-			UserID:   returnedUser.UserID,
+			Password:            returnedUser.Password,
+			UserID:              returnedUser.UserID,
+			FailedLoginAttempts: returnedUser.FailedLoginAttempts,
+			LastFailedLoginAt:   returnedUser.LastFailedLoginAt,
+			LockedUntil:         returnedUser.LockedUntil,
+			TotpEnabled:         returnedUser.TotpEnabled,
 		}
 	default:
 		return loginUser, errors.New("invalid identifier")
@@ -81,27 +96,109 @@ func (s *Server) getUserByIdentifier(ctx context.Context, identifier string) (Lo
 }
 
 // LoginHandler authenticates the user and creates a session.
+// Failed attempts are throttled per client IP and per account with exponential backoff,
+// and an account is locked for a while once too many consecutive failures pile up.
+// Users with two-factor authentication enabled are sent on to /login/2fa before a session is created.
 func (s *Server) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusBadRequest, "bad request")
+		writeError(w, r, http.StatusBadRequest, "bad request")
 		return
 	}
 
 	identifier := r.FormValue("identifier")
 	password := r.FormValue("password")
+	ip := s.clientIP(r)
+	now := time.Now()
+
+	ipFailures, err := s.queries.CountRecentFailedLoginsByIP(r.Context(), database.CountRecentFailedLoginsByIPParams{
+		IpAddress:   ip,
+		AttemptedAt: pgtype.Timestamptz{Time: now.Add(-s.throttle.IPWindow), Valid: true},
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to count login attempts", "error", err.Error())
+		return
+	}
+
+	if wait := s.throttle.retryAfter(int(ipFailures.Failures), s.throttle.IPFreeAttempts, ipFailures.LastFailedAt, now); wait > 0 {
+		s.recordLoginAttempt(r.Context(), identifier, uuid.Nil, ip, loginThrottled)
+		writeTooManyAttempts(w, r, wait)
+		return
+	}
 
 	user, err := s.getUserByIdentifier(r.Context(), identifier)
 	if err != nil {
-		slog.Error("login request denied", "user name", identifier, "password", password, "error", err.Error())
-		writeError(w, http.StatusUnauthorized, "invalid credentials")
+		_ = bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
+		slog.Warn("login request denied", "identifier", identifier, "ip", ip, "error", err.Error())
+		s.recordLoginAttempt(r.Context(), identifier, uuid.Nil, ip, loginInvalidCredentials)
+		writeError(w, r, http.StatusUnauthorized, "invalid credentials")
+		return
+	}
+
+	// The attempt is counted as a failure before the password is compared, and cleared once it turns out right.
+	wait, locked, err := s.reserveLoginAttempt(r.Context(), user, now)
+	if errors.Is(err, errAccountLocked) {
+		s.recordLoginAttempt(r.Context(), identifier, user.UserID, ip, loginLocked)
+		writeError(w, r, http.StatusLocked, "account locked after too many failed attempts, try again later or contact an administrator")
+		return
+	}
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to record failed login", "error", err.Error())
+		return
+	}
+	if wait > 0 {
+		s.recordLoginAttempt(r.Context(), identifier, user.UserID, ip, loginThrottled)
+		writeTooManyAttempts(w, r, wait)
 		return
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password)); err != nil {
-		writeError(w, http.StatusUnauthorized, "invalid credentials")
+		s.recordLoginAttempt(r.Context(), identifier, user.UserID, ip, loginInvalidCredentials)
+
+		if locked {
+			writeError(w, r, http.StatusLocked, "account locked after too many failed attempts, try again later or contact an administrator")
+			return
+		}
+
+		writeError(w, r, http.StatusUnauthorized, "invalid credentials")
 		return
 	}
 
+	// The failure counter is only reset once every factor has been checked,
+	// so guesses at the second factor count towards the same lockout.
+	if user.TotpEnabled {
+		if err := s.releaseLoginAttempt(r.Context(), user.UserID); err != nil {
+			slog.Warn("failed to release login attempt", "error", err.Error())
+		}
+
+		if err := s.writePendingLogin(w, identifier, user.UserID, now); err != nil {
+			writeError(w, r, http.StatusInternalServerError, "internal server error")
+			slog.Error("failed to write pending login cookie", "error", err.Error())
+			return
+		}
+
+		if r.Header.Get("HX-Request") != "" {
+			w.Header().Set("HX-Redirect", "/login/2fa")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		http.Redirect(w, r, "/login/2fa", http.StatusFound)
+		return
+	}
+
+	s.completeLogin(w, r, identifier, user, ip)
+}
+
+// completeLogin resets the failure counters, including the failure reserved for the last step,
+// creates a session and writes the session cookie once a user has passed every authentication step.
+func (s *Server) completeLogin(w http.ResponseWriter, r *http.Request, identifier string, user LoginUser, ip string) {
+	if err := s.queries.ResetFailedLogins(r.Context(), user.UserID); err != nil {
+		slog.Warn("failed to reset failed login counter", "error", err.Error())
+	}
+	s.recordLoginAttempt(r.Context(), identifier, user.UserID, ip, loginSuccess)
+
 	// Create a new sessionID and expiry.
 	sessionID := uuid.New()
 	expiry := pgtype.Timestamptz{Time: time.Now().Add(2 * 7 * 24 * time.Hour), Valid: true}
@@ -110,18 +207,20 @@ func (s *Server) LoginHandler(w http.ResponseWriter, r *http.Request) {
 		SessionID: sessionID,
 		UserID:    user.UserID,
 		Expires:   expiry,
+		UserAgent: pgtype.Text{String: r.UserAgent(), Valid: r.UserAgent() != ""},
+		IpAddress: pgtype.Text{String: ip, Valid: true},
 	}
 
 	if err := s.queries.CreateSession(r.Context(), sessionParams); err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("Failed to create session", "error", err.Error())
 		return
 	}
 
 	cookie := createSessionCookie(sessionID)
 
-	if err := cookies.WriteEncrypted(w, cookie, s.SecretKey); err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+	if err := s.Keys.WriteEncrypted(w, cookie); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
@@ -134,15 +233,17 @@ func (s *Server) LoginHandler(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/dashboard", http.StatusFound)
 }
 
-// LogoutHandler to log users out
+// LogoutHandler logs the user out of the current device only.
+// Sessions on other devices stay valid and can be revoked from /settings/sessions.
 func (s *Server) LogoutHandler(w http.ResponseWriter, r *http.Request) {
-	user, ok := r.Context().Value(userContextKey).(User)
+	sessionID, ok := r.Context().Value(sessionIDKey).(uuid.UUID)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "User not authenticated")
+		writeError(w, r, http.StatusUnauthorized, "User not authenticated")
+		return
 	}
 
-	if err := s.queries.DeleteSession(r.Context(), user.UserID); err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+	if err := s.queries.DeleteSession(r.Context(), sessionID); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
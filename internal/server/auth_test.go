@@ -0,0 +1,84 @@
+// auth_test.go
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+// --- LogoutHandler Tests ---
+
+func TestLogoutHandler_DeletesCurrentSessionOnly(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	s := &Server{
+		Keys:    testKeyring(),
+		queries: database.New(mockConn),
+	}
+
+	sessionID := uuid.New()
+	mockConn.ExpectExec("DELETE FROM sessions").
+		WithArgs(sessionID).
+		WillReturnResult(pgxmock.NewResult("DELETE", 1))
+
+	req := httptest.NewRequest("POST", "/logout", nil)
+	ctx := context.WithValue(req.Context(), sessionIDKey, sessionID)
+	ctx = context.WithValue(ctx, userContextKey, User{UserID: uuid.New(), Role: "teacher"})
+	rec := httptest.NewRecorder()
+
+	s.LogoutHandler(rec, req.WithContext(ctx))
+	resp := rec.Result()
+
+	if resp.StatusCode != http.StatusFound {
+		t.Errorf("expected status %d; got %d", http.StatusFound, resp.StatusCode)
+	}
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestClientIP(t *testing.T) {
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/24, 192.0.2.1")
+	s := &Server{proxies: loadTrustedProxies()}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		headers    map[string]string
+		want       string
+	}{
+		{"direct", "203.0.113.9:51234", nil, "203.0.113.9"},
+		{"spoofed headers from a client", "203.0.113.9:51234", map[string]string{"X-Real-IP": "1.2.3.4", "X-Forwarded-For": "1.2.3.4"}, "203.0.113.9"},
+		{"real ip from a proxy", "10.0.0.5:51234", map[string]string{"X-Real-IP": "198.51.100.4", "X-Forwarded-For": "1.2.3.4"}, "198.51.100.4"},
+		{"nearest untrusted forwarded hop", "10.0.0.5:51234", map[string]string{"X-Forwarded-For": "1.2.3.4, 203.0.113.7, 192.0.2.1"}, "203.0.113.7"},
+		{"invalid forwarded hop", "10.0.0.5:51234", map[string]string{"X-Forwarded-For": "1.2.3.4, " + strings.Repeat("a", 60)}, "10.0.0.5"},
+		{"over-long real ip", "10.0.0.5:51234", map[string]string{"X-Real-IP": strings.Repeat("1", 60)}, "10.0.0.5"},
+		{"ipv6", "[2001:db8::1]:443", nil, "2001:db8::1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for name, value := range tt.headers {
+				req.Header.Set(name, value)
+			}
+
+			if ip := s.clientIP(req); ip != tt.want {
+				t.Errorf("expected %s; got %s", tt.want, ip)
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 
@@ -8,6 +9,7 @@ import (
 	"school_management_system/internal/database"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // ShowCreateClassForm renders the form to create a new class.
@@ -18,19 +20,31 @@ func (s *Server) ShowCreateClassForm(w http.ResponseWriter, r *http.Request) {
 // CreateClass creates a new class.
 func (s *Server) CreateClass(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "wrong parameters")
+		writeError(w, r, http.StatusUnprocessableEntity, "wrong parameters")
 		return
 	}
 
 	name := r.FormValue("class_name")
 	if name == "" {
-		writeError(w, http.StatusBadRequest, "class name required")
+		writeError(w, r, http.StatusBadRequest, "class name required")
 		return
 	}
 
-	err := s.queries.CreateClass(r.Context(), name)
+	err := s.withTx(r.Context(), func(qtx *database.Queries) error {
+		class, err := qtx.CreateClass(r.Context(), name)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// The class already exists.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditCreate, auditClass, class.ClassID, nil, class)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to create class", "error", err.Error())
 		return
 	}
 
@@ -47,7 +61,7 @@ func (s *Server) CreateClass(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ListClasses(w http.ResponseWriter, r *http.Request) {
 	classesList, err := s.queries.ListClasses(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
@@ -64,14 +78,14 @@ func (s *Server) ListClasses(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ShowEditClass(w http.ResponseWriter, r *http.Request) {
 	classID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid class")
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid class")
 		slog.Error("failed to parse class id", "message:", err.Error())
 		return
 	}
 
 	class, err := s.queries.GetClass(r.Context(), classID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
@@ -82,18 +96,18 @@ func (s *Server) ShowEditClass(w http.ResponseWriter, r *http.Request) {
 func (s *Server) EditClass(w http.ResponseWriter, r *http.Request) {
 	classID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "wrong parameters")
+		writeError(w, r, http.StatusUnprocessableEntity, "wrong parameters")
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "wrong form params")
+		writeError(w, r, http.StatusUnprocessableEntity, "wrong form params")
 		return
 	}
 
 	name := r.FormValue("class_name")
 	if name == "" {
-		writeError(w, http.StatusBadRequest, "name cannot be empty")
+		writeError(w, r, http.StatusBadRequest, "name cannot be empty")
 		return
 	}
 
@@ -102,9 +116,26 @@ func (s *Server) EditClass(w http.ResponseWriter, r *http.Request) {
 		Name:    name,
 	}
 
-	err = s.queries.EditClass(r.Context(), params)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		before, err := qtx.GetClass(r.Context(), classID)
+		if err != nil {
+			return err
+		}
+
+		if err := qtx.EditClass(r.Context(), params); err != nil {
+			return err
+		}
+
+		after, err := qtx.GetClass(r.Context(), classID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditUpdate, auditClass, classID, before, after)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to edit class", "error", err.Error())
 		return
 	}
 
@@ -117,17 +148,30 @@ func (s *Server) EditClass(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/academics/classes", http.StatusFound)
 }
 
-// DeleteClass removes a class.
+// DeleteClass moves a class to the recycle bin.
 func (s *Server) DeleteClass(w http.ResponseWriter, r *http.Request) {
 	classID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "wrong parameters")
+		writeError(w, r, http.StatusUnprocessableEntity, "wrong parameters")
 		return
 	}
 
-	err = s.queries.DeleteClass(r.Context(), classID)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		before, err := qtx.GetClass(r.Context(), classID)
+		if err != nil {
+			return err
+		}
+
+		params := database.SoftDeleteClassParams{ClassID: classID, DeletedBy: requestUserID(r)}
+		if _, err := qtx.SoftDeleteClass(r.Context(), params); err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditDelete, auditClass, classID, before, nil)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to delete class", "error", err.Error())
 		return
 	}
 
@@ -149,26 +193,26 @@ func (s *Server) ShowCreateSubjectForm(w http.ResponseWriter, r *http.Request) {
 // CreateSubject creates a new subject for a given class.
 func (s *Server) CreateSubject(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "wrong parameters")
+		writeError(w, r, http.StatusUnprocessableEntity, "wrong parameters")
 		return
 	}
 
 	classID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid class")
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid class")
 		slog.Error("Invalid class id", "message:", err.Error())
 		return
 	}
 
 	subjectName := r.FormValue("subject_name")
 	if subjectName == "" {
-		writeError(w, http.StatusBadRequest, "fields cannot be empty")
+		writeError(w, r, http.StatusBadRequest, "fields cannot be empty")
 		return
 	}
 
 	class, err := s.queries.GetClass(r.Context(), classID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("Failed to get a class", "Message", err.Error())
 		return
 	}
@@ -178,9 +222,21 @@ func (s *Server) CreateSubject(w http.ResponseWriter, r *http.Request) {
 		Name:    subjectName,
 	}
 
-	err = s.queries.CreateSubject(r.Context(), params)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		subject, err := qtx.CreateSubject(r.Context(), params)
+		if errors.Is(err, pgx.ErrNoRows) {
+			// The class already has this subject.
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditCreate, auditSubject, subject.SubjectID, nil, subject)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to create subject", "error", err.Error())
 		return
 	}
 
@@ -197,14 +253,14 @@ func (s *Server) CreateSubject(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ListSubjects(w http.ResponseWriter, r *http.Request) {
 	classID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "bad request")
+		writeError(w, r, http.StatusBadRequest, "bad request")
 		slog.Error("failed to parse class id")
 		return
 	}
 
 	subjectsList, err := s.queries.ListSubjects(r.Context(), classID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to retrieve subjects", "Message", err.Error())
 		return
 	}
@@ -217,14 +273,14 @@ func (s *Server) ListSubjects(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ShowEditSubject(w http.ResponseWriter, r *http.Request) {
 	subjectID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid subject")
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid subject")
 		slog.Error("failed to parse subject id", "message:", err.Error())
 		return
 	}
 
 	subject, err := s.queries.GetSubject(r.Context(), subjectID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
@@ -235,17 +291,17 @@ func (s *Server) ShowEditSubject(w http.ResponseWriter, r *http.Request) {
 func (s *Server) EditSubject(w http.ResponseWriter, r *http.Request) {
 	subjectID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "wrong parameters")
+		writeError(w, r, http.StatusUnprocessableEntity, "wrong parameters")
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "wrong parameters")
+		writeError(w, r, http.StatusUnprocessableEntity, "wrong parameters")
 		return
 	}
 	name := r.FormValue("subject_name")
 	if name == "" {
-		writeError(w, http.StatusBadRequest, "subject name can't be empty")
+		writeError(w, r, http.StatusBadRequest, "subject name can't be empty")
 		return
 	}
 
@@ -254,9 +310,25 @@ func (s *Server) EditSubject(w http.ResponseWriter, r *http.Request) {
 		Name:      name,
 	}
 
-	err = s.queries.EditSubject(r.Context(), params)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		before, err := qtx.GetSubject(r.Context(), subjectID)
+		if err != nil {
+			return err
+		}
+
+		if err := qtx.EditSubject(r.Context(), params); err != nil {
+			return err
+		}
+
+		after, err := qtx.GetSubject(r.Context(), subjectID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditUpdate, auditSubject, subjectID, before, after)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("Error updating a subject", "message", err.Error())
 		return
 	}
@@ -270,17 +342,29 @@ func (s *Server) EditSubject(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/academics/classes", http.StatusFound)
 }
 
-// DeleteSubject removes a subject.
+// DeleteSubject moves a subject to the recycle bin.
 func (s *Server) DeleteSubject(w http.ResponseWriter, r *http.Request) {
 	subjectID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "wrong parameters")
+		writeError(w, r, http.StatusBadRequest, "wrong parameters")
 		return
 	}
 
-	err = s.queries.DeleteSubject(r.Context(), subjectID)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		before, err := qtx.GetSubject(r.Context(), subjectID)
+		if err != nil {
+			return err
+		}
+
+		params := database.SoftDeleteSubjectParams{SubjectID: subjectID, DeletedBy: requestUserID(r)}
+		if _, err := qtx.SoftDeleteSubject(r.Context(), params); err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditDelete, auditSubject, subjectID, before, nil)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to delete subject", "message", err.Error())
 		return
 	}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 
@@ -8,6 +9,7 @@ import (
 	"school_management_system/internal/database"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // showClassTeachers method renders ClassTeachers Component
@@ -15,7 +17,7 @@ func (s *Server) showClassTeachers(w http.ResponseWriter, r *http.Request) {
 	classID := r.PathValue("class_id")
 	parsedClassID, err := uuid.Parse(classID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "bad request")
+		writeError(w, r, http.StatusBadRequest, "bad request")
 		return
 	}
 
@@ -44,20 +46,20 @@ func (s *Server) showCreateClassTeacher(w http.ResponseWriter, r *http.Request)
 func (s *Server) assignClassTeacher(w http.ResponseWriter, r *http.Request) {
 	classID, err := uuid.Parse(r.PathValue("class_id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "bad request")
+		writeError(w, r, http.StatusBadRequest, "bad request")
 		return
 	}
 
 	err = r.ParseForm()
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "bad request")
+		writeError(w, r, http.StatusBadRequest, "bad request")
 		return
 	}
 
 	teacherID := r.FormValue("teacher_id")
 	parsedTeacherID, err := uuid.Parse(teacherID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "failed to parse form")
+		writeError(w, r, http.StatusBadRequest, "failed to parse form")
 		return
 	}
 
@@ -66,9 +68,9 @@ func (s *Server) assignClassTeacher(w http.ResponseWriter, r *http.Request) {
 		ClassID:   classID,
 	}
 
-	_, err = s.queries.UpSertClassTeacher(r.Context(), params)
+	err = s.upsertClassTeacher(r, params)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to classteacher to a class", "error", err.Error())
 		return
 	}
@@ -82,25 +84,48 @@ func (s *Server) assignClassTeacher(w http.ResponseWriter, r *http.Request) {
 	http.Redirect(w, r, "/academics/classes", http.StatusFound)
 }
 
+// upsertClassTeacher assigns a class teacher and records the change in the audit log.
+func (s *Server) upsertClassTeacher(r *http.Request, params database.UpSertClassTeacherParams) error {
+	return s.withTx(r.Context(), func(qtx *database.Queries) error {
+		action := auditUpdate
+		before, err := qtx.GetClassTeacher(r.Context(), params.ClassID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			action = auditCreate
+		} else if err != nil {
+			return err
+		}
+
+		id, err := qtx.UpSertClassTeacher(r.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		if action == auditCreate {
+			return recordAudit(r, qtx, action, auditClassTeacher, id, nil, params)
+		}
+		return recordAudit(r, qtx, action, auditClassTeacher, id, before, params)
+	})
+}
+
 // showEditClassTeacher method renders the EditClassTeacher component
 func (s *Server) showEditClassTeacher(w http.ResponseWriter, r *http.Request) {
 	classID := r.PathValue("class_id")
 	parsedClassID, err := uuid.Parse(classID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "bad request")
+		writeError(w, r, http.StatusBadRequest, "bad request")
 		return
 	}
 
 	current, err := s.queries.GetClassTeacher(r.Context(), parsedClassID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("internal server error", "error", err.Error())
 		return
 	}
 
 	teachers, err := s.queries.GetAllDBClassTeachers(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("internal server error", "error", err.Error())
 		return
 	}
@@ -113,20 +138,20 @@ func (s *Server) showEditClassTeacher(w http.ResponseWriter, r *http.Request) {
 func (s *Server) editClassTeacher(w http.ResponseWriter, r *http.Request) {
 	classID, err := uuid.Parse(r.PathValue("class_id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "bad request")
+		writeError(w, r, http.StatusBadRequest, "bad request")
 		return
 	}
 
 	err = r.ParseForm()
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "bad request")
+		writeError(w, r, http.StatusBadRequest, "bad request")
 		return
 	}
 
 	teacherID := r.FormValue("teacher_id")
 	parsedTeacherID, err := uuid.Parse(teacherID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "failed to parse form")
+		writeError(w, r, http.StatusBadRequest, "failed to parse form")
 		return
 	}
 
@@ -135,9 +160,9 @@ func (s *Server) editClassTeacher(w http.ResponseWriter, r *http.Request) {
 		ClassID:   classID,
 	}
 
-	_, err = s.queries.UpSertClassTeacher(r.Context(), params)
+	err = s.upsertClassTeacher(r, params)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to classteacher to a class", "error", err.Error())
 		return
 	}
@@ -1,9 +1,11 @@
 package server
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
 
 	"school_management_system/cmd/web/dashboard/myclasses"
 	"school_management_system/internal/database"
@@ -79,14 +81,14 @@ func PivotClassRoom(rows []database.RetrieveClassRoomRow) []myclasses.GradeEntry
 func (s *Server) MyClasses(w http.ResponseWriter, r *http.Request) {
 	teacher, ok := r.Context().Value(userContextKey).(User)
 	if !ok {
-		writeError(w, http.StatusForbidden, "forbidden")
+		writeError(w, r, http.StatusForbidden, "forbidden")
 		slog.Error("failed to read user ID from context")
 		return
 	}
 
 	classRoom, err := s.queries.RetrieveClassRoom(r.Context(), teacher.UserID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to get classroom data", "error", err.Error())
 		return
 	}
@@ -99,46 +101,123 @@ func (s *Server) MyClasses(w http.ResponseWriter, r *http.Request) {
 // GetClassForm serves the grade entry form for a specific class.
 // It parses the classID from the URL, validates the teacher's context, and retrieves both classroom data and the current myclasses for the class.
 // If a matching class is found, it renders the grade entry form pre-populated with existing grade data; otherwise, it returns a 404 error.
+// Subjects whose grade entry window is closed are shown read-only with the reason.
 func (s *Server) GetClassForm(w http.ResponseWriter, r *http.Request) {
 	classID, err := uuid.Parse(r.PathValue("classID"))
 	if err != nil {
-		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Invalid class ID")
 		return
 	}
 
 	teacher, ok := r.Context().Value(userContextKey).(User)
 	if !ok {
-		writeError(w, http.StatusForbidden, "forbidden")
+		writeError(w, r, http.StatusForbidden, "forbidden")
 		slog.Error("failed to read user ID from context")
 		return
 	}
 
 	if teacher.Role == "admin" || teacher.Role == "accountant" || teacher.Role == "headteacher" {
-		writeError(w, http.StatusForbidden, "user does not teach any class")
+		writeError(w, r, http.StatusForbidden, "user does not teach any class")
 		return
 	}
 
-	classRoom, err := s.queries.RetrieveClassRoom(r.Context(), teacher.UserID)
+	class, err := s.teacherClass(r.Context(), teacher.UserID, classID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
-		slog.Error("failed to get classroom data", "error", err.Error())
+		writeAppError(w, r, err)
 		return
 	}
 
 	currentmyclasses, err := s.queries.ListGradesForClass(r.Context(), classID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to get myclasses", "error", err.Error())
 		return
 	}
 
-	gradeEntryData := PivotClassRoom(classRoom)
-	for _, class := range gradeEntryData {
-		if class.ClassID == classID {
-			s.renderComponent(w, r, myclasses.MyClassesGradesFormSingle(class, currentmyclasses))
-			return
+	scale, err := s.classGradingScale(r.Context(), classID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to get grading scale", "error", err.Error())
+		return
+	}
+
+	s.renderComponent(w, r, myclasses.MyClassesGradesFormSingle(class, currentmyclasses, scale))
+}
+
+// teacherClass returns the grade entry data of one of the teacher's classes: the subjects they
+// teach in it, its students, the assessment components and their scores, and the subjects whose
+// grade entry is closed.
+func (s *Server) teacherClass(ctx context.Context, teacherID, classID uuid.UUID) (myclasses.GradeEntryData, error) {
+	classRoom, err := s.queries.RetrieveClassRoom(ctx, teacherID)
+	if err != nil {
+		return myclasses.GradeEntryData{}, NewAppError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+
+	for _, class := range PivotClassRoom(classRoom) {
+		if class.ClassID != classID {
+			continue
+		}
+
+		if err := s.loadAssessmentComponents(ctx, &class); err != nil {
+			return class, NewAppError(http.StatusInternalServerError, "internal server error").Wrap(err)
+		}
+
+		entry, err := loadGradeEntry(ctx, s.queries, class.TermID, classID)
+		if err != nil {
+			return class, NewAppError(http.StatusInternalServerError, "internal server error").Wrap(err)
+		}
+
+		now := time.Now()
+		class.Closed = make(map[uuid.UUID]string)
+		for _, subject := range class.Subjects {
+			if reason := entry.closed(subject.SubjectID, now); reason != "" {
+				class.Closed[subject.SubjectID] = reason
+			}
+		}
+
+		return class, nil
+	}
+
+	return myclasses.GradeEntryData{}, NewAppError(http.StatusNotFound, "class not found")
+}
+
+// loadAssessmentComponents adds the assessment components of the class's subjects in its term,
+// and the scores already entered for them, to the grade entry data.
+func (s *Server) loadAssessmentComponents(ctx context.Context, class *myclasses.GradeEntryData) error {
+	components, err := s.queries.ListClassAssessmentComponents(ctx, database.ListClassAssessmentComponentsParams{
+		ClassID: class.ClassID,
+		TermID:  class.TermID,
+	})
+	if err != nil {
+		return err
+	}
+
+	scores, err := s.queries.ListClassAssessmentScores(ctx, database.ListClassAssessmentScoresParams{
+		ClassID: class.ClassID,
+		TermID:  class.TermID,
+	})
+	if err != nil {
+		return err
+	}
+
+	class.Components = make(map[uuid.UUID][]myclasses.Component)
+	for _, component := range components {
+		weight, _ := component.Weight.Float64Value()
+		class.Components[component.SubjectID] = append(class.Components[component.SubjectID], myclasses.Component{
+			ComponentID: component.ComponentID,
+			Name:        component.Name,
+			Weight:      weight.Float64,
+		})
+	}
+
+	class.ComponentScores = make(map[uuid.UUID]map[uuid.UUID]float64)
+	for _, score := range scores {
+		if class.ComponentScores[score.ComponentID] == nil {
+			class.ComponentScores[score.ComponentID] = make(map[uuid.UUID]float64)
 		}
+		value, _ := score.Score.Float64Value()
+		class.ComponentScores[score.ComponentID][score.StudentID] = value.Float64
 	}
 
-	writeError(w, http.StatusNotFound, "class not found")
+	return nil
 }
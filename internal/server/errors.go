@@ -1,7 +1,157 @@
 package server
 
-import "net/http"
+import (
+	"errors"
+	"log/slog"
+	"maps"
+	"net/http"
+	"slices"
+	"strings"
 
-func writeError(w http.ResponseWriter, statusCode int, message string) {
-	http.Error(w, message, statusCode)
+	"school_management_system/cmd/web"
+	"school_management_system/cmd/web/components"
+
+	"github.com/a-h/templ"
+)
+
+// AppError is an error that is safe to show to the user.
+// Err holds the underlying cause; it is logged but never sent to the client.
+type AppError struct {
+	Status  int
+	Code    string
+	Message string
+	Fields  map[string]string
+	Err     error
+}
+
+// errorCodes are the default machine readable codes for each HTTP status.
+var errorCodes = map[int]string{
+	http.StatusBadRequest:          "bad_request",
+	http.StatusUnauthorized:        "unauthorized",
+	http.StatusForbidden:           "forbidden",
+	http.StatusNotFound:            "not_found",
+	http.StatusMethodNotAllowed:    "method_not_allowed",
+	http.StatusConflict:            "conflict",
+	http.StatusLocked:              "locked",
+	http.StatusUnprocessableEntity: "validation_failed",
+	http.StatusTooManyRequests:     "too_many_requests",
+	http.StatusInternalServerError: "internal_error",
+}
+
+// NewAppError returns an error with the default code for status.
+func NewAppError(status int, message string) *AppError {
+	code, ok := errorCodes[status]
+	if !ok {
+		code = strings.ToLower(strings.ReplaceAll(http.StatusText(status), " ", "_"))
+	}
+
+	return &AppError{Status: status, Code: code, Message: message}
+}
+
+// ValidationError returns a 422 error listing the problem with each form field.
+func ValidationError(message string, fields map[string]string) *AppError {
+	e := NewAppError(http.StatusUnprocessableEntity, message)
+	e.Fields = fields
+	return e
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// WithCode replaces the default code.
+func (e *AppError) WithCode(code string) *AppError {
+	e.Code = code
+	return e
+}
+
+// WithField adds a problem with a single form field.
+func (e *AppError) WithField(field, message string) *AppError {
+	if e.Fields == nil {
+		e.Fields = map[string]string{}
+	}
+	e.Fields[field] = message
+	return e
+}
+
+// Wrap records the underlying cause of the error.
+func (e *AppError) Wrap(err error) *AppError {
+	e.Err = err
+	return e
+}
+
+// details returns the field errors as sorted "field: message" lines.
+func (e *AppError) details() []string {
+	details := make([]string, 0, len(e.Fields))
+	for _, field := range slices.Sorted(maps.Keys(e.Fields)) {
+		details = append(details, field+": "+e.Fields[field])
+	}
+	return details
+}
+
+// wantsJSON reports whether the client is a program rather than a browser:
+// requests carrying an API token, calls to the JSON API, and requests that only accept JSON.
+func wantsJSON(r *http.Request) bool {
+	if _, ok := bearerToken(r); ok || strings.HasPrefix(r.URL.Path, "/api/") {
+		return true
+	}
+
+	accept := r.Header.Get("Accept")
+	return r.Header.Get("HX-Request") == "" && strings.Contains(accept, "application/json") && !strings.Contains(accept, "text/html")
+}
+
+// writeAppError sends err in the form the client expects: JSON for API clients, a toast for
+// HTMX requests and an error page otherwise. Errors that are not an *AppError are reported as
+// internal server errors without revealing their message.
+func writeAppError(w http.ResponseWriter, r *http.Request, err error) {
+	var appErr *AppError
+	if !errors.As(err, &appErr) {
+		appErr = NewAppError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+
+	if appErr.Err != nil && appErr.Status >= http.StatusInternalServerError {
+		slog.Error(appErr.Message, "error", appErr.Err.Error(), "path", r.URL.Path)
+	}
+
+	switch {
+	case wantsJSON(r):
+		writeAPIAppError(w, appErr)
+	case r.Header.Get("HX-Request") != "":
+		writeToast(w, r, appErr.Status, components.Toast(components.ToastError, appErr.Message, appErr.details()))
+	default:
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(appErr.Status)
+		if err := web.ErrorPage(appErr.Status, appErr.Message, appErr.details()).Render(r.Context(), w); err != nil {
+			slog.Error("failed to render error page", "error", err.Error())
+		}
+	}
+}
+
+// writeError is shorthand for writeAppError with a new error.
+func writeError(w http.ResponseWriter, r *http.Request, statusCode int, message string) {
+	writeAppError(w, r, NewAppError(statusCode, message))
+}
+
+// writeSuccess shows a success toast in response to an HTMX request.
+func writeSuccess(w http.ResponseWriter, r *http.Request, message string) {
+	writeToast(w, r, http.StatusOK, components.Toast(components.ToastSuccess, message, nil))
+}
+
+// writeToast renders a toast into the page's toast container, whatever the request targeted.
+func writeToast(w http.ResponseWriter, r *http.Request, status int, toast templ.Component) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("HX-Retarget", "#toast-container")
+	w.Header().Set("HX-Reswap", "beforeend")
+	w.WriteHeader(status)
+
+	if err := toast.Render(r.Context(), w); err != nil {
+		slog.Error("failed to render toast", "error", err.Error())
+	}
 }
@@ -0,0 +1,86 @@
+// errors_test.go
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWriteAppError_JSON(t *testing.T) {
+	req := httptest.NewRequest("POST", "/grades/submit", nil)
+	req.Header.Set("Authorization", "Bearer sms_test")
+	rec := httptest.NewRecorder()
+
+	writeAppError(rec, req, ValidationError("invalid grades", map[string]string{"score": "must be between 0 and 100"}))
+
+	checkResponseCode(t, http.StatusUnprocessableEntity, rec.Code)
+
+	var body APIError
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("expected a JSON error body: %v", err)
+	}
+	if body.Error.Code != "validation_failed" || body.Error.Fields["score"] == "" {
+		t.Errorf("unexpected error body %+v", body.Error)
+	}
+}
+
+func TestWriteAppError_HTMXToast(t *testing.T) {
+	req := httptest.NewRequest("POST", "/promotions", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+
+	writeError(rec, req, http.StatusConflict, "Promotion Event already done")
+
+	checkResponseCode(t, http.StatusConflict, rec.Code)
+	if got := rec.Header().Get("HX-Retarget"); got != "#toast-container" {
+		t.Errorf("expected the toast to be retargeted to #toast-container; got %q", got)
+	}
+	if body := rec.Body.String(); !strings.Contains(body, "toast-error") || !strings.Contains(body, "Promotion Event already done") {
+		t.Errorf("expected an error toast; got %q", body)
+	}
+}
+
+func TestWriteAppError_Page(t *testing.T) {
+	req := httptest.NewRequest("GET", "/students/123", nil)
+	rec := httptest.NewRecorder()
+
+	writeError(rec, req, http.StatusNotFound, "student not found")
+
+	checkResponseCode(t, http.StatusNotFound, rec.Code)
+	body := rec.Body.String()
+	if !strings.Contains(strings.ToLower(body), "<!doctype html>") {
+		t.Errorf("expected a full error page; got %q", body)
+	}
+	if !strings.Contains(body, "student not found") {
+		t.Errorf("expected the message on the error page; got %q", body)
+	}
+}
+
+func TestWriteAppError_HidesUnexpectedErrors(t *testing.T) {
+	req := httptest.NewRequest("GET", "/api/v1/students", nil)
+	rec := httptest.NewRecorder()
+
+	writeAppError(rec, req, errors.New("pq: connection refused"))
+
+	checkResponseCode(t, http.StatusInternalServerError, rec.Code)
+	if strings.Contains(rec.Body.String(), "connection refused") {
+		t.Errorf("internal error details leaked to the client: %q", rec.Body.String())
+	}
+}
+
+func TestWriteSuccess(t *testing.T) {
+	req := httptest.NewRequest("PUT", "/roles/1", nil)
+	req.Header.Set("HX-Request", "true")
+	rec := httptest.NewRecorder()
+
+	writeSuccess(rec, req, "Permissions updated")
+
+	checkResponseCode(t, http.StatusOK, rec.Code)
+	if body := rec.Body.String(); !strings.Contains(body, "toast-success") || !strings.Contains(body, "Permissions updated") {
+		t.Errorf("expected a success toast; got %q", body)
+	}
+}
@@ -1,6 +1,7 @@
 package server
 
 import (
+	"errors"
 	"log/slog"
 	"net/http"
 	"sort"
@@ -11,6 +12,7 @@ import (
 	"school_management_system/internal/database"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 )
 
 // selectFeeRecordsPerTerm method allows us to view fee records for a given term
@@ -68,20 +70,20 @@ func getFeesData(records []database.ListStudentFeesRecordsRow) []fees.ClassRoomD
 func (s *Server) ShowClassFees(w http.ResponseWriter, r *http.Request) {
 	classID, err := uuid.Parse(r.PathValue("classID"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid class")
+		writeError(w, r, http.StatusBadRequest, "invalid class")
 		return
 	}
 
 	selectedTermID, err := s.selectFeeRecordsPerTerm(r)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to get term")
+		writeError(w, r, http.StatusInternalServerError, "failed to get term")
 		slog.Error("Failed to get term ", "error", err.Error())
 		return
 	}
 
 	records, err := s.queries.ListStudentFeesRecords(r.Context(), selectedTermID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to retrieve fees records")
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve fees records")
 		slog.Error("failed to retrieve fee records", "error", err.Error())
 		return
 	}
@@ -94,21 +96,21 @@ func (s *Server) ShowClassFees(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	writeError(w, http.StatusNotFound, "class not found or has no fee records")
+	writeError(w, r, http.StatusNotFound, "class not found or has no fee records")
 }
 
 // ShowFeesList renders fee records for all classes.
 func (s *Server) ShowFeesList(w http.ResponseWriter, r *http.Request) {
 	selectedTermID, err := s.selectFeeRecordsPerTerm(r)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to get term")
+		writeError(w, r, http.StatusInternalServerError, "failed to get term")
 		slog.Error("Failed to get term ", "error", err.Error())
 		return
 	}
 
 	records, err := s.queries.ListStudentFeesRecords(r.Context(), selectedTermID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to retrieve fee records")
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve fee records")
 		slog.Error("failed to retrieve fee records", "error", err.Error())
 		return
 	}
@@ -121,7 +123,7 @@ func (s *Server) ShowFeesList(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ShowSetTuition(w http.ResponseWriter, r *http.Request) {
 	classes, err := s.queries.ListClasses(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to get class list")
+		writeError(w, r, http.StatusInternalServerError, "failed to get class list")
 		slog.Error("failed to get class list", "error", err.Error())
 		return
 	}
@@ -133,7 +135,7 @@ func (s *Server) ShowSetTuition(w http.ResponseWriter, r *http.Request) {
 func (s *Server) SetFeesStructure(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "wrong parameters")
+		writeError(w, r, http.StatusUnprocessableEntity, "wrong parameters")
 		slog.Error("failed to parse form data", "error", err.Error())
 		return
 	}
@@ -143,34 +145,60 @@ func (s *Server) SetFeesStructure(w http.ResponseWriter, r *http.Request) {
 
 	parsedClassID, err := uuid.Parse(classID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "bad class ID")
+		writeError(w, r, http.StatusBadRequest, "bad class ID")
 		slog.Error("failed to parse class ID", "error", err.Error())
 		return
 	}
 
 	parsedRequired, err := strconv.ParseFloat(required, 64)
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "failed to parse tuition")
+		writeError(w, r, http.StatusUnprocessableEntity, "failed to parse tuition")
 		return
 	}
 
 	term, err := s.getCachedTerm()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "active current term not set")
+		writeError(w, r, http.StatusInternalServerError, "active current term not set")
 		slog.Error(err.Error())
 		return
 	}
 
-	const query = `
-		INSERT INTO fee_structure (term_id, class_id, required)
-			VALUES ($1, $2, $3)
-		ON CONFLICT (term_id, class_id)
-  			DO UPDATE SET required = EXCLUDED.required
-  	`
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		key := database.GetFeeStructureByTermAndClassParams{
+			ClassID: parsedClassID,
+			TermID:  term.TermID,
+		}
+
+		action := auditUpdate
+		before, err := qtx.GetFeeStructureByTermAndClass(r.Context(), key)
+		if errors.Is(err, pgx.ErrNoRows) {
+			action = auditCreate
+		} else if err != nil {
+			return err
+		}
+
+		params := database.UpsertFeeStructureParams{
+			TermID:   term.TermID,
+			ClassID:  parsedClassID,
+			Required: numericFromFloat(parsedRequired),
+		}
+
+		if _, err := qtx.UpsertFeeStructure(r.Context(), params); err != nil {
+			return err
+		}
 
-	_, err = s.conn.Exec(r.Context(), query, term.TermID, parsedClassID, parsedRequired)
+		after, err := qtx.GetFeeStructureByTermAndClass(r.Context(), key)
+		if err != nil {
+			return err
+		}
+
+		if action == auditCreate {
+			return recordAudit(r, qtx, action, auditFeesStructure, after.FeeStructureID, nil, after)
+		}
+		return recordAudit(r, qtx, action, auditFeesStructure, after.FeeStructureID, before, after)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to create fee structure")
+		writeError(w, r, http.StatusInternalServerError, "failed to create fee structure")
 		slog.Error("failed to save grade", "termID", term.TermID, "classID", parsedClassID, "required tuition", parsedRequired, "error", err.Error())
 		return
 	}
@@ -187,25 +215,25 @@ func (s *Server) SetFeesStructure(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ShowCreateFeesRecordForStudent(w http.ResponseWriter, r *http.Request) {
 	classID, err := uuid.Parse(r.PathValue("classID"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid class ID")
+		writeError(w, r, http.StatusBadRequest, "invalid class ID")
 		return
 	}
 	studentID, err := uuid.Parse(r.FormValue("student_id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid student ID")
+		writeError(w, r, http.StatusBadRequest, "invalid student ID")
 		return
 	}
 
 	students, err := s.queries.ListStudentsByClassForTerm(r.Context(), classID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to get students for class")
+		writeError(w, r, http.StatusInternalServerError, "failed to get students for class")
 		slog.Error("failed to get students for class", "classID", classID, "error", err.Error())
 		return
 	}
 
 	currentTerm, err := s.getCachedTerm()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to find active term")
+		writeError(w, r, http.StatusInternalServerError, "failed to find active term")
 		slog.Error(err.Error())
 		return
 	}
@@ -217,7 +245,7 @@ func (s *Server) ShowCreateFeesRecordForStudent(w http.ResponseWriter, r *http.R
 
 	feeStructure, err := s.queries.GetFeeStructureByTermAndClass(r.Context(), params)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to find fee structure for this class")
+		writeError(w, r, http.StatusInternalServerError, "failed to find fee structure for this class")
 		slog.Error("failed to find fee structure for this class", "error", err.Error())
 		return
 	}
@@ -229,7 +257,7 @@ func (s *Server) ShowCreateFeesRecordForStudent(w http.ResponseWriter, r *http.R
 func (s *Server) SaveFeesRecord(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "wrong parameters")
+		writeError(w, r, http.StatusUnprocessableEntity, "wrong parameters")
 		slog.Error("failed to parse form data", "error", err.Error())
 		return
 	}
@@ -240,20 +268,20 @@ func (s *Server) SaveFeesRecord(w http.ResponseWriter, r *http.Request) {
 
 	parsedFeeStructureID, err := uuid.Parse(feeStructureID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "bad class ID")
+		writeError(w, r, http.StatusBadRequest, "bad class ID")
 		slog.Error("failed to parse class ID", "error", err.Error())
 		return
 	}
 	parsedStudentID, err := uuid.Parse(studentID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "bad student ID")
+		writeError(w, r, http.StatusBadRequest, "bad student ID")
 		slog.Error("failed to parse student ID", "error", err.Error())
 		return
 	}
 
 	parsedPaid, err := strconv.ParseFloat(paid, 64)
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid paid amount")
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid paid amount")
 		slog.Error("failed to parse paid amount", "error", err.Error())
 		return
 	}
@@ -261,7 +289,7 @@ func (s *Server) SaveFeesRecord(w http.ResponseWriter, r *http.Request) {
 	ctx := r.Context()
 	tx, err := s.conn.Begin(ctx)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to start transaction")
+		writeError(w, r, http.StatusInternalServerError, "failed to start transaction")
 		return
 	}
 	defer tx.Rollback(ctx)
@@ -280,7 +308,7 @@ func (s *Server) SaveFeesRecord(w http.ResponseWriter, r *http.Request) {
 		&i.Status,
 	)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to create fees record")
+		writeError(w, r, http.StatusInternalServerError, "failed to create fees record")
 		slog.Error("failed to create fees record", "parsedFeeStructureID", parsedFeeStructureID, "studentID", parsedStudentID, "paid", parsedPaid, "error", err.Error())
 		return
 	}
@@ -289,7 +317,7 @@ func (s *Server) SaveFeesRecord(w http.ResponseWriter, r *http.Request) {
 	var previousTermID uuid.UUID
 	currentTerm, err := s.getCachedTerm()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to find active term")
+		writeError(w, r, http.StatusInternalServerError, "failed to find active term")
 		slog.Error(err.Error())
 		return
 	}
@@ -312,7 +340,7 @@ func (s *Server) SaveFeesRecord(w http.ResponseWriter, r *http.Request) {
 		}
 		lastFeeRecord, err := qtx.GetStudentPreviousFeeRecord(r.Context(), params)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, "failed to find student previous fees record")
+			writeError(w, r, http.StatusInternalServerError, "failed to find student previous fees record")
 			slog.Error("failed to find student previous fees record", "error", err.Error())
 			return
 		}
@@ -333,16 +361,29 @@ func (s *Server) SaveFeesRecord(w http.ResponseWriter, r *http.Request) {
 			`
 			_, err = tx.Exec(r.Context(), updateQuery, paidArrears, i.FeesID)
 			if err != nil {
-				writeError(w, http.StatusInternalServerError, "failed to edit fees record")
+				writeError(w, r, http.StatusInternalServerError, "failed to edit fees record")
 				slog.Error("failed to edit fees record", "Additional Amount", paidArrears, "feesID", i.FeesID, "error", err.Error())
 				return
 			}
 		}
 	}
 
+	created, err := qtx.GetFee(ctx, i.FeesID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to create fees record")
+		slog.Error("failed to get fees record", "feesID", i.FeesID, "error", err.Error())
+		return
+	}
+
+	if err := recordAudit(r, qtx, auditCreate, auditFeesRecord, created.FeesID, nil, created); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to create fees record")
+		slog.Error("failed to write audit log", "error", err.Error())
+		return
+	}
+
 	err = tx.Commit(ctx)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to commit transaction")
+		writeError(w, r, http.StatusInternalServerError, "failed to commit transaction")
 		return
 	}
 
@@ -359,7 +400,7 @@ func (s *Server) SaveFeesRecord(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ShowEditFeesRecord(w http.ResponseWriter, r *http.Request) {
 	feesID, err := uuid.Parse(r.PathValue("feesID"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid fees ID")
+		writeError(w, r, http.StatusBadRequest, "invalid fees ID")
 		return
 	}
 
@@ -372,7 +413,7 @@ func (s *Server) ShowEditFeesRecord(w http.ResponseWriter, r *http.Request) {
 func (s *Server) EditFeesRecord(w http.ResponseWriter, r *http.Request) {
 	feesID, err := uuid.Parse(r.PathValue("feesID"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "wrong feesID")
+		writeError(w, r, http.StatusBadRequest, "wrong feesID")
 		slog.Error("failed to parse ", "error", err.Error())
 		return
 	}
@@ -382,44 +423,60 @@ func (s *Server) EditFeesRecord(w http.ResponseWriter, r *http.Request) {
 	additionalAmount := r.FormValue("additional_amount")
 
 	if arrearsAmount == "" || availableAmount == "" || additionalAmount == "" {
-		writeError(w, http.StatusBadRequest, "missing fields")
+		writeError(w, r, http.StatusBadRequest, "missing fields")
 		return
 	}
 
 	parsedArrearsAmount, err := strconv.ParseFloat(arrearsAmount, 64)
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid paid amount")
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid paid amount")
 		slog.Error("failed to parse paid amount", "error", err.Error())
 		return
 	}
 
 	parsedAvailableAmount, err := strconv.ParseFloat(availableAmount, 64)
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid paid amount")
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid paid amount")
 		slog.Error("failed to parse paid amount", "error", err.Error())
 		return
 	}
 
 	parsedAmount, err := strconv.ParseFloat(additionalAmount, 64)
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid paid amount")
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid paid amount")
 		slog.Error("failed to parse paid amount", "error", err.Error())
 		return
 	}
 
 	if parsedAvailableAmount+parsedAmount+parsedArrearsAmount < 0 {
-		writeError(w, http.StatusBadRequest, "The additional amount is too low")
+		writeError(w, r, http.StatusBadRequest, "The additional amount is too low")
 		return
 	}
 
-	const query = `
-		UPDATE fees
-    		SET paid = paid + $1
-		WHERE fees_id = $2;
-	`
-	_, err = s.conn.Exec(r.Context(), query, parsedAmount, feesID)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		before, err := qtx.GetFee(r.Context(), feesID)
+		if err != nil {
+			return err
+		}
+
+		params := database.EditFeesRecordParams{
+			FeesID: feesID,
+			Paid:   numericFromFloat(parsedAmount),
+		}
+
+		after, err := qtx.EditFeesRecord(r.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditUpdate, auditFeesRecord, feesID, before, after)
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		writeError(w, r, http.StatusNotFound, "fees record not found")
+		return
+	}
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to edit fees record")
+		writeError(w, r, http.StatusInternalServerError, "failed to edit fees record")
 		slog.Error("failed to edit fees record", "Additional Amount", parsedAmount, "feesID", feesID, "error", err.Error())
 		return
 	}
@@ -0,0 +1,452 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"school_management_system/cmd/web/dashboard/academics"
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// gradeEntryTimeLayout is how grade entry windows are entered and shown.
+	gradeEntryTimeLayout = "Jan 2, 2006 15:04"
+	// maxUnlockHours is the longest an unlock can reopen grade entry for.
+	maxUnlockHours = 168
+	// maxUnlockReason is the longest reason an unlock can be given.
+	maxUnlockReason = 500
+)
+
+// gradesLockedBy are the approval statuses of report cards that stop their grades from changing,
+// so report cards under review or handed out can't drift from what was approved.
+var gradesLockedBy = []string{reportSubmitted, reportApproved, reportPublished}
+
+// gradeEntry holds the grade entry windows, active unlocks and report card approval status that
+// apply to a class in a term.
+type gradeEntry struct {
+	windows  []database.GradeEntryWindow
+	unlocks  []database.GradeEntryUnlock
+	approval string
+}
+
+// loadGradeEntry returns the grade entry windows, active unlocks and report card approval status of
+// a class in a term.
+func loadGradeEntry(ctx context.Context, q *database.Queries, termID, classID uuid.UUID) (gradeEntry, error) {
+	windows, err := q.ListClassGradeEntryWindows(ctx, database.ListClassGradeEntryWindowsParams{
+		TermID:  termID,
+		ClassID: pgtype.UUID{Bytes: classID, Valid: true},
+	})
+	if err != nil {
+		return gradeEntry{}, err
+	}
+
+	unlocks, err := q.ListActiveGradeEntryUnlocks(ctx, database.ListActiveGradeEntryUnlocksParams{
+		TermID:  termID,
+		ClassID: classID,
+	})
+	if err != nil {
+		return gradeEntry{}, err
+	}
+
+	approval, err := reportApproval(ctx, q, classID, termID)
+	if err != nil {
+		return gradeEntry{}, err
+	}
+
+	return gradeEntry{windows: windows, unlocks: unlocks, approval: approval.Status}, nil
+}
+
+// window returns the window that applies to a subject of the class: the subject's own window, else
+// the class's, else the whole term's. It reports false if there is none.
+func (e gradeEntry) window(subjectID uuid.UUID) (database.GradeEntryWindow, bool) {
+	var found database.GradeEntryWindow
+	rank := -1
+	for _, window := range e.windows {
+		var r int
+		switch {
+		case window.SubjectID.Valid && window.SubjectID.Bytes != subjectID:
+			continue
+		case window.SubjectID.Valid:
+			r = 2
+		case window.ClassID.Valid:
+			r = 1
+		}
+
+		if r > rank {
+			found, rank = window, r
+		}
+	}
+
+	return found, rank >= 0
+}
+
+// unlocked reports whether an unlock reopens grade entry for a subject at now.
+func (e gradeEntry) unlocked(subjectID uuid.UUID, now time.Time) bool {
+	for _, unlock := range e.unlocks {
+		if (!unlock.SubjectID.Valid || unlock.SubjectID.Bytes == subjectID) && now.Before(unlock.ExpiresAt.Time) {
+			return true
+		}
+	}
+	return false
+}
+
+// locked returns why the report card approval stops grades of a subject from changing at now, such as
+// "closed while the report cards are published", or "" if it doesn't. An unlock lifts the lock.
+func (e gradeEntry) locked(subjectID uuid.UUID, now time.Time) string {
+	if !slices.Contains(gradesLockedBy, e.approval) || e.unlocked(subjectID, now) {
+		return ""
+	}
+	return "closed while the report cards are " + e.approval
+}
+
+// closed returns why grades of a subject cannot be entered at now, such as "closed Mar 6, 2026 17:00",
+// or "" if they can. Subjects without a window are open unless the class's report cards have been
+// submitted, and an unlock reopens a closed window.
+func (e gradeEntry) closed(subjectID uuid.UUID, now time.Time) string {
+	if e.unlocked(subjectID, now) {
+		return ""
+	}
+	if reason := e.locked(subjectID, now); reason != "" {
+		return reason
+	}
+
+	window, ok := e.window(subjectID)
+	switch {
+	case !ok:
+		return ""
+	case now.Before(window.OpensAt.Time):
+		return "opens " + window.OpensAt.Time.Local().Format(gradeEntryTimeLayout)
+	case !now.Before(window.ClosesAt.Time):
+		return "closed " + window.ClosesAt.Time.Local().Format(gradeEntryTimeLayout)
+	}
+
+	return ""
+}
+
+// checkGradeEntry rejects a grade submission that includes a subject whose grade entry is closed.
+func checkGradeEntry(ctx context.Context, q *database.Queries, termID, classID uuid.UUID, submitted []StudentGrades, now time.Time) error {
+	entry, err := loadGradeEntry(ctx, q, termID, classID)
+	if err != nil {
+		return err
+	}
+
+	for _, student := range submitted {
+		for _, grade := range student.Grades {
+			subjectID, err := uuid.Parse(grade.SubjectID)
+			if err != nil {
+				return NewAppError(http.StatusBadRequest, "Invalid request format").WithField("subject_id", "must be a subject ID")
+			}
+
+			reason := entry.closed(subjectID, now)
+			if reason == "" {
+				continue
+			}
+
+			subject, err := q.GetSubject(ctx, subjectID)
+			if err != nil {
+				return err
+			}
+			return NewAppError(http.StatusForbidden, fmt.Sprintf("Grade entry for %s %s", subject.Name, reason)).
+				WithField("subject_id", "grade entry "+reason)
+		}
+	}
+
+	return nil
+}
+
+// parseGradeEntryScope reads what a window or unlock applies to from the scope form field: empty for
+// the whole term, a class ID, or a class ID and a subject ID separated by a colon.
+func parseGradeEntryScope(scope string) (classID, subjectID pgtype.UUID, err error) {
+	invalid := ValidationError("invalid scope", map[string]string{"scope": "must be the whole term, a class or a subject"})
+	if scope == "" {
+		return classID, subjectID, nil
+	}
+
+	classPart, subjectPart, hasSubject := strings.Cut(scope, ":")
+	class, err := uuid.Parse(classPart)
+	if err != nil {
+		return classID, subjectID, invalid
+	}
+	classID = pgtype.UUID{Bytes: class, Valid: true}
+
+	if hasSubject {
+		subject, err := uuid.Parse(subjectPart)
+		if err != nil {
+			return classID, subjectID, invalid
+		}
+		subjectID = pgtype.UUID{Bytes: subject, Valid: true}
+	}
+
+	return classID, subjectID, nil
+}
+
+// checkScopeSubject checks the subject of a scope, if any, belongs to its class.
+func checkScopeSubject(ctx context.Context, q *database.Queries, classID, subjectID pgtype.UUID) error {
+	if !subjectID.Valid {
+		return nil
+	}
+
+	subject, err := q.GetSubject(ctx, subjectID.Bytes)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && subject.ClassID != classID.Bytes) {
+		return ValidationError("invalid scope", map[string]string{"scope": "the subject must belong to the class"})
+	}
+
+	return err
+}
+
+// parseGradeEntryWindow reads the scope and the opening and closing times of a window from the form.
+func parseGradeEntryWindow(r *http.Request, termID uuid.UUID) (database.UpsertGradeEntryWindowParams, error) {
+	if err := r.ParseForm(); err != nil {
+		return database.UpsertGradeEntryWindowParams{}, NewAppError(http.StatusUnprocessableEntity, "failed to parse form")
+	}
+
+	classID, subjectID, err := parseGradeEntryScope(r.FormValue("scope"))
+	if err != nil {
+		return database.UpsertGradeEntryWindowParams{}, err
+	}
+
+	fields := map[string]string{}
+	opensAt, err := time.ParseInLocation("2006-01-02T15:04", r.FormValue("opens_at"), time.Local)
+	if err != nil {
+		fields["opens_at"] = "must be a date and time"
+	}
+	closesAt, err := time.ParseInLocation("2006-01-02T15:04", r.FormValue("closes_at"), time.Local)
+	if err != nil {
+		fields["closes_at"] = "must be a date and time"
+	} else if len(fields) == 0 && !closesAt.After(opensAt) {
+		fields["closes_at"] = "must be after the opening time"
+	}
+	if len(fields) > 0 {
+		return database.UpsertGradeEntryWindowParams{}, ValidationError("invalid grade entry window", fields)
+	}
+
+	return database.UpsertGradeEntryWindowParams{
+		TermID:    termID,
+		ClassID:   classID,
+		SubjectID: subjectID,
+		OpensAt:   pgtype.Timestamptz{Time: opensAt, Valid: true},
+		ClosesAt:  pgtype.Timestamptz{Time: closesAt, Valid: true},
+		CreatedBy: requestUserID(r),
+	}, nil
+}
+
+// parseGradeEntryUnlock reads the class, optional subject, reason and length of an unlock from the form.
+// The unlock lasts 24 hours unless hours is given.
+func parseGradeEntryUnlock(r *http.Request, termID uuid.UUID, now time.Time) (database.CreateGradeEntryUnlockParams, error) {
+	if err := r.ParseForm(); err != nil {
+		return database.CreateGradeEntryUnlockParams{}, NewAppError(http.StatusUnprocessableEntity, "failed to parse form")
+	}
+
+	classID, subjectID, err := parseGradeEntryScope(r.FormValue("scope"))
+	if err != nil {
+		return database.CreateGradeEntryUnlockParams{}, err
+	}
+
+	fields := map[string]string{}
+	if !classID.Valid {
+		fields["scope"] = "must be a class or a subject"
+	}
+
+	reason := strings.TrimSpace(r.FormValue("reason"))
+	if reason == "" || len(reason) > maxUnlockReason {
+		fields["reason"] = fmt.Sprintf("must be between 1 and %d characters", maxUnlockReason)
+	}
+
+	hours := 24
+	if value := strings.TrimSpace(r.FormValue("hours")); value != "" {
+		if hours, err = strconv.Atoi(value); err != nil || hours < 1 || hours > maxUnlockHours {
+			fields["hours"] = fmt.Sprintf("must be between 1 and %d", maxUnlockHours)
+		}
+	}
+
+	if len(fields) > 0 {
+		return database.CreateGradeEntryUnlockParams{}, ValidationError("invalid unlock", fields)
+	}
+
+	return database.CreateGradeEntryUnlockParams{
+		TermID:     termID,
+		ClassID:    classID.Bytes,
+		SubjectID:  subjectID,
+		Reason:     reason,
+		UnlockedBy: requestUserID(r),
+		ExpiresAt:  pgtype.Timestamptz{Time: now.Add(time.Duration(hours) * time.Hour), Valid: true},
+	}, nil
+}
+
+// ShowGradeEntry renders the grade entry windows and unlocks of a term.
+func (s *Server) ShowGradeEntry(w http.ResponseWriter, r *http.Request) {
+	termID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid term")
+		return
+	}
+
+	s.renderGradeEntry(w, r, termID)
+}
+
+// renderGradeEntry renders the grade entry windows and unlocks of a term with the forms to change them.
+func (s *Server) renderGradeEntry(w http.ResponseWriter, r *http.Request, termID uuid.UUID) {
+	term, err := s.queries.GetTerm(r.Context(), termID)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "term not found")
+		return
+	}
+
+	windows, err := s.queries.ListTermGradeEntryWindows(r.Context(), termID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get grade entry windows")
+		slog.Error("failed to list grade entry windows", "error", err.Error())
+		return
+	}
+
+	unlocks, err := s.queries.ListTermGradeEntryUnlocks(r.Context(), termID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get grade entry unlocks")
+		slog.Error("failed to list grade entry unlocks", "error", err.Error())
+		return
+	}
+
+	classes, err := s.queries.ListClasses(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get classes")
+		slog.Error("failed to list classes", "error", err.Error())
+		return
+	}
+
+	subjects, err := s.queries.ListAllSubjects(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get subjects")
+		slog.Error("failed to list subjects", "error", err.Error())
+		return
+	}
+
+	s.renderComponent(w, r, academics.GradeEntry(term, windows, unlocks, classes, subjects, time.Now()))
+}
+
+// SaveGradeEntryWindow sets when grades can be entered for a term, a class or a subject. A window
+// replaces the one already set for the same scope.
+func (s *Server) SaveGradeEntryWindow(w http.ResponseWriter, r *http.Request) {
+	termID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid term")
+		return
+	}
+
+	params, err := parseGradeEntryWindow(r, termID)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		if err := checkScopeSubject(r.Context(), qtx, params.ClassID, params.SubjectID); err != nil {
+			return err
+		}
+
+		before, err := qtx.GetGradeEntryWindowByScope(r.Context(), database.GetGradeEntryWindowByScopeParams{
+			TermID:    termID,
+			ClassID:   params.ClassID,
+			SubjectID: params.SubjectID,
+		})
+		existed := err == nil
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		after, err := qtx.UpsertGradeEntryWindow(r.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		if existed {
+			return recordAudit(r, qtx, auditUpdate, auditGradeEntryWindow, after.WindowID, before, after)
+		}
+		return recordAudit(r, qtx, auditCreate, auditGradeEntryWindow, after.WindowID, nil, after)
+	})
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	s.renderGradeEntry(w, r, termID)
+}
+
+// DeleteGradeEntryWindow removes a grade entry window, so its scope falls back to the next wider window.
+func (s *Server) DeleteGradeEntryWindow(w http.ResponseWriter, r *http.Request) {
+	windowID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid grade entry window")
+		return
+	}
+
+	var termID uuid.UUID
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		before, err := qtx.GetGradeEntryWindow(r.Context(), windowID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return NewAppError(http.StatusNotFound, "grade entry window not found")
+		}
+		if err != nil {
+			return err
+		}
+		termID = before.TermID
+
+		if err := qtx.DeleteGradeEntryWindow(r.Context(), windowID); err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditDelete, auditGradeEntryWindow, windowID, before, nil)
+	})
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	s.renderGradeEntry(w, r, termID)
+}
+
+// UnlockGradeEntry reopens grade entry for a class, or one subject of it, for a number of hours
+// whatever its window says. The reason is required and kept with the unlock.
+func (s *Server) UnlockGradeEntry(w http.ResponseWriter, r *http.Request) {
+	termID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid term")
+		return
+	}
+
+	params, err := parseGradeEntryUnlock(r, termID, time.Now())
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		classID := pgtype.UUID{Bytes: params.ClassID, Valid: true}
+		if err := checkScopeSubject(r.Context(), qtx, classID, params.SubjectID); err != nil {
+			return err
+		}
+
+		unlock, err := qtx.CreateGradeEntryUnlock(r.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditCreate, auditGradeEntryUnlock, unlock.UnlockID, nil, unlock)
+	})
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	s.renderGradeEntry(w, r, termID)
+}
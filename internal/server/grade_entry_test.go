@@ -0,0 +1,184 @@
+// grade_entry_test.go
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+// testWindow is a grade entry window of the given scope open between opens and closes.
+func testWindow(classID, subjectID uuid.UUID, opens, closes time.Time) database.GradeEntryWindow {
+	return database.GradeEntryWindow{
+		ClassID:   pgtype.UUID{Bytes: classID, Valid: classID != uuid.Nil},
+		SubjectID: pgtype.UUID{Bytes: subjectID, Valid: subjectID != uuid.Nil},
+		OpensAt:   pgtype.Timestamptz{Time: opens, Valid: true},
+		ClosesAt:  pgtype.Timestamptz{Time: closes, Valid: true},
+	}
+}
+
+func TestGradeEntryClosed(t *testing.T) {
+	now := time.Date(2026, 4, 1, 12, 0, 0, 0, time.Local)
+	classID, maths, english, biology := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+
+	entry := gradeEntry{windows: []database.GradeEntryWindow{
+		testWindow(uuid.Nil, uuid.Nil, now.AddDate(0, -1, 0), now.AddDate(0, 0, -1)),
+		testWindow(classID, uuid.Nil, now.AddDate(0, 0, -7), now.AddDate(0, 0, 7)),
+		testWindow(classID, maths, now.AddDate(0, 0, 1), now.AddDate(0, 0, 3)),
+	}}
+
+	if reason := entry.closed(english, now); reason != "" {
+		t.Errorf("expected the class's window to keep English open; got %q", reason)
+	}
+	if reason := entry.closed(maths, now); !strings.HasPrefix(reason, "opens ") {
+		t.Errorf("expected the subject's window to keep Mathematics shut until it opens; got %q", reason)
+	}
+
+	entry.windows = entry.windows[:1]
+	if reason := entry.closed(biology, now); !strings.HasPrefix(reason, "closed ") {
+		t.Errorf("expected the term's window to have closed; got %q", reason)
+	}
+
+	entry.unlocks = []database.GradeEntryUnlock{{
+		ClassID:   classID,
+		SubjectID: pgtype.UUID{Bytes: biology, Valid: true},
+		ExpiresAt: pgtype.Timestamptz{Time: now.Add(time.Hour), Valid: true},
+	}}
+	if reason := entry.closed(biology, now); reason != "" {
+		t.Errorf("expected the unlock to reopen Biology; got %q", reason)
+	}
+	if reason := entry.closed(english, now); reason == "" {
+		t.Error("expected the unlock to leave other subjects closed")
+	}
+	if reason := entry.closed(biology, now.Add(2*time.Hour)); reason == "" {
+		t.Error("expected the unlock to expire")
+	}
+
+	if reason := (gradeEntry{}).closed(maths, now); reason != "" {
+		t.Errorf("expected grade entry to be open without windows; got %q", reason)
+	}
+	if reason := (gradeEntry{approval: reportReturned}).closed(maths, now); reason != "" {
+		t.Errorf("expected returned report cards to leave grade entry open; got %q", reason)
+	}
+
+	// Report cards under review or handed out keep their grades, window or not, until an unlock.
+	entry = gradeEntry{approval: reportPublished, unlocks: entry.unlocks}
+	if reason := entry.closed(maths, now); reason != "closed while the report cards are published" {
+		t.Errorf("expected published report cards to close grade entry; got %q", reason)
+	}
+	if reason := entry.closed(biology, now); reason != "" {
+		t.Errorf("expected the unlock to reopen Biology; got %q", reason)
+	}
+}
+
+func TestCheckGradeEntry(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	q := database.New(mockConn)
+	now := time.Now()
+	termID, classID, subjectID := uuid.New(), uuid.New(), uuid.New()
+	windowColumns := []string{"window_id", "term_id", "class_id", "subject_id", "opens_at", "closes_at", "created_by", "created_at"}
+	unlockColumns := []string{"unlock_id", "term_id", "class_id", "subject_id", "reason", "unlocked_by", "unlocked_at", "expires_at"}
+
+	mockConn.ExpectQuery("SELECT").WithArgs(termID, pgtype.UUID{Bytes: classID, Valid: true}).
+		WillReturnRows(pgxmock.NewRows(windowColumns).AddRow(uuid.New(), termID, pgtype.UUID{}, pgtype.UUID{},
+			pgtype.Timestamptz{Time: now.AddDate(0, 0, -14), Valid: true}, pgtype.Timestamptz{Time: now.AddDate(0, 0, -1), Valid: true},
+			pgtype.UUID{}, pgtype.Timestamptz{}))
+	mockConn.ExpectQuery("SELECT").WithArgs(termID, classID).WillReturnRows(pgxmock.NewRows(unlockColumns))
+	mockConn.ExpectQuery("FROM report_approvals").WithArgs(classID, termID).WillReturnError(pgx.ErrNoRows)
+	mockConn.ExpectQuery("SELECT").WithArgs(subjectID).
+		WillReturnRows(pgxmock.NewRows([]string{"subject_id", "class_id", "name", "deleted_at", "deleted_by"}).
+			AddRow(subjectID, classID, "Mathematics", pgtype.Timestamptz{}, pgtype.UUID{}))
+
+	submitted := []StudentGrades{{StudentID: uuid.NewString(), Grades: []GradeEntry{{SubjectID: subjectID.String(), Score: 70}}}}
+
+	var appErr *AppError
+	err = checkGradeEntry(context.Background(), q, termID, classID, submitted, now)
+	if !errors.As(err, &appErr) || appErr.Status != http.StatusForbidden || !strings.HasPrefix(appErr.Message, "Grade entry for Mathematics closed") {
+		t.Errorf("expected grades after the window to be rejected; got %v", err)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCheckGradeEntry_SubmittedReportCards(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	q := database.New(mockConn)
+	termID, classID, subjectID := uuid.New(), uuid.New(), uuid.New()
+	windowColumns := []string{"window_id", "term_id", "class_id", "subject_id", "opens_at", "closes_at", "created_by", "created_at"}
+	unlockColumns := []string{"unlock_id", "term_id", "class_id", "subject_id", "reason", "unlocked_by", "unlocked_at", "expires_at"}
+
+	// The term has no window, so only the approval of the report cards closes grade entry.
+	mockConn.ExpectQuery("SELECT").WithArgs(termID, pgtype.UUID{Bytes: classID, Valid: true}).WillReturnRows(pgxmock.NewRows(windowColumns))
+	mockConn.ExpectQuery("SELECT").WithArgs(termID, classID).WillReturnRows(pgxmock.NewRows(unlockColumns))
+	mockConn.ExpectQuery("FROM report_approvals").WithArgs(classID, termID).
+		WillReturnRows(pgxmock.NewRows([]string{"class_id", "term_id", "status", "comment", "updated_by", "updated_at"}).
+			AddRow(classID, termID, reportSubmitted, pgtype.Text{}, pgtype.UUID{}, pgtype.Timestamptz{}))
+	mockConn.ExpectQuery("SELECT").WithArgs(subjectID).
+		WillReturnRows(pgxmock.NewRows([]string{"subject_id", "class_id", "name", "deleted_at", "deleted_by"}).
+			AddRow(subjectID, classID, "Mathematics", pgtype.Timestamptz{}, pgtype.UUID{}))
+
+	submitted := []StudentGrades{{StudentID: uuid.NewString(), Grades: []GradeEntry{{SubjectID: subjectID.String(), Score: 70}}}}
+
+	var appErr *AppError
+	err = checkGradeEntry(context.Background(), q, termID, classID, submitted, time.Now())
+	if !errors.As(err, &appErr) || appErr.Status != http.StatusForbidden || appErr.Message != "Grade entry for Mathematics closed while the report cards are submitted" {
+		t.Errorf("expected grades of submitted report cards to be rejected; got %v", err)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestParseGradeEntryUnlock(t *testing.T) {
+	now := time.Now()
+	termID, classID, subjectID := uuid.New(), uuid.New(), uuid.New()
+
+	form := url.Values{"scope": {classID.String() + ":" + subjectID.String()}, "reason": {" Exam remarked "}, "hours": {"2"}}
+	req := httptest.NewRequest("POST", "/academics/terms/unlocks", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	params, err := parseGradeEntryUnlock(req, termID, now)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if params.ClassID != classID || params.SubjectID.Bytes != subjectID || params.Reason != "Exam remarked" {
+		t.Errorf("unexpected unlock %+v", params)
+	}
+	if !params.ExpiresAt.Time.Equal(now.Add(2 * time.Hour)) {
+		t.Errorf("expected the unlock to last 2 hours; got %v", params.ExpiresAt.Time)
+	}
+
+	var appErr *AppError
+	form = url.Values{"scope": {""}, "reason": {""}, "hours": {"500"}}
+	req = httptest.NewRequest("POST", "/academics/terms/unlocks", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if _, err := parseGradeEntryUnlock(req, termID, now); !errors.As(err, &appErr) ||
+		appErr.Fields["scope"] == "" || appErr.Fields["reason"] == "" || appErr.Fields["hours"] == "" {
+		t.Errorf("expected the scope, reason and hours to be rejected; got %v", err)
+	}
+}
@@ -0,0 +1,430 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"school_management_system/cmd/web/dashboard/myclasses"
+	"school_management_system/internal/database"
+	"school_management_system/internal/grading"
+	"school_management_system/internal/spreadsheet"
+
+	"github.com/google/uuid"
+)
+
+// gradeImportMaxBytes is the largest grade sheet that can be uploaded.
+const gradeImportMaxBytes = 2 << 20
+
+// The headers of the columns identifying the student on each row of a grade sheet.
+const (
+	sheetStudentNo   = "Student No"
+	sheetStudentName = "Student Name"
+)
+
+// gradeColumn is a score column of a grade sheet. Subjects with assessment components have a
+// column per component, headed "Subject: Component", instead of one for the subject's score.
+type gradeColumn struct {
+	Header      string
+	SubjectID   uuid.UUID
+	ComponentID uuid.UUID
+}
+
+// gradeColumns returns the score columns of the subjects the teacher teaches in the class, or of
+// one of them if subjectID is set.
+func gradeColumns(class myclasses.GradeEntryData, subjectID uuid.UUID) []gradeColumn {
+	var columns []gradeColumn
+	for _, subject := range class.Subjects {
+		if subjectID != uuid.Nil && subject.SubjectID != subjectID {
+			continue
+		}
+
+		components := class.Components[subject.SubjectID]
+		if len(components) == 0 {
+			columns = append(columns, gradeColumn{Header: subject.SubjectName, SubjectID: subject.SubjectID})
+			continue
+		}
+		for _, component := range components {
+			columns = append(columns, gradeColumn{
+				Header:      subject.SubjectName + ": " + component.Name,
+				SubjectID:   subject.SubjectID,
+				ComponentID: component.ComponentID,
+			})
+		}
+	}
+	return columns
+}
+
+// classScores holds the stored scores and remarks of a class's students, with the grades keyed by
+// student ID and subject ID.
+type classScores struct {
+	class  myclasses.GradeEntryData
+	grades map[[2]uuid.UUID]database.ListGradesForClassRow
+}
+
+func newClassScores(class myclasses.GradeEntryData, current []database.ListGradesForClassRow) classScores {
+	grades := make(map[[2]uuid.UUID]database.ListGradesForClassRow, len(current))
+	for _, grade := range current {
+		grades[[2]uuid.UUID{grade.StudentID, grade.SubjectID}] = grade
+	}
+	return classScores{class: class, grades: grades}
+}
+
+// score returns the student's stored score in a column, if there is one.
+func (c classScores) score(studentID uuid.UUID, column gradeColumn) (float64, bool) {
+	if column.ComponentID != uuid.Nil {
+		score, ok := c.class.ComponentScores[column.ComponentID][studentID]
+		return score, ok
+	}
+
+	grade, ok := c.grades[[2]uuid.UUID{studentID, column.SubjectID}]
+	if !ok || !grade.Score.Valid {
+		return 0, false
+	}
+	score, _ := grade.Score.Float64Value()
+	return score.Float64, true
+}
+
+// remark returns the student's stored remark in a subject.
+func (c classScores) remark(studentID, subjectID uuid.UUID) string {
+	return c.grades[[2]uuid.UUID{studentID, subjectID}].Remark.String
+}
+
+// formatSheetScore formats a score for a grade sheet cell or the import preview.
+func formatSheetScore(score float64) string {
+	return strconv.FormatFloat(score, 'f', -1, 64)
+}
+
+// gradeSheet lays out a grade sheet: a header row, then a row per student with their stored scores.
+func gradeSheet(scores classScores, columns []gradeColumn) [][]string {
+	header := []string{sheetStudentNo, sheetStudentName}
+	for _, column := range columns {
+		header = append(header, column.Header)
+	}
+
+	rows := [][]string{header}
+	for _, student := range scores.class.Students {
+		row := []string{student.StudentNo, student.StudentName}
+		for _, column := range columns {
+			cell := ""
+			if score, ok := scores.score(student.StudentID, column); ok {
+				cell = formatSheetScore(score)
+			}
+			row = append(row, cell)
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// sheetFileName keeps the letters, digits and dashes of a class or subject name for a file name.
+var sheetFileName = regexp.MustCompile(`[^A-Za-z0-9-]+`)
+
+// DownloadGradeTemplate downloads a grade sheet of the teacher's class as CSV or XLSX, with a
+// column for each subject they teach in it, or only the one in subject_id, filled with the
+// scores already entered. Teachers fill it in and upload it with ImportGrades.
+func (s *Server) DownloadGradeTemplate(w http.ResponseWriter, r *http.Request) {
+	classID, err := uuid.Parse(r.PathValue("classID"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid class ID")
+		return
+	}
+
+	format, err := spreadsheet.ParseFormat(r.URL.Query().Get("format"))
+	if err != nil {
+		writeAppError(w, r, NewAppError(http.StatusBadRequest, "Unsupported format").WithField("format", "must be csv or xlsx"))
+		return
+	}
+
+	subjectID := uuid.Nil
+	if value := r.URL.Query().Get("subject_id"); value != "" {
+		if subjectID, err = uuid.Parse(value); err != nil {
+			writeAppError(w, r, NewAppError(http.StatusBadRequest, "Invalid subject").WithField("subject_id", "must be a subject ID"))
+			return
+		}
+	}
+
+	class, current, err := s.gradeImportClass(r, classID)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	columns := gradeColumns(class, subjectID)
+	if len(columns) == 0 {
+		writeError(w, r, http.StatusNotFound, "subject not found")
+		return
+	}
+
+	fileName := class.ClassName + " " + class.TermName
+	for _, subject := range class.Subjects {
+		if subject.SubjectID == subjectID {
+			fileName += " " + subject.SubjectName
+		}
+	}
+	fileName = strings.Trim(sheetFileName.ReplaceAllString(fileName, "_"), "_")
+
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", fileName, format))
+	if err := spreadsheet.Write(w, format, gradeSheet(newClassScores(class, current), columns)); err != nil {
+		slog.Error("failed to write grade sheet", "error", err.Error())
+	}
+}
+
+// ImportGrades reads an uploaded grade sheet, checks every row and shows the scores it would
+// change. Nothing is saved until the teacher confirms the preview with ConfirmGradeImport.
+func (s *Server) ImportGrades(w http.ResponseWriter, r *http.Request) {
+	classID, err := uuid.Parse(r.PathValue("classID"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid class ID")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, gradeImportMaxBytes)
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			writeAppError(w, r, ValidationError("File too large", map[string]string{"file": "must be at most 2 MB"}))
+			return
+		}
+		writeAppError(w, r, ValidationError("No file uploaded", map[string]string{"file": "choose a CSV or XLSX file"}))
+		return
+	}
+	defer file.Close()
+
+	format, err := spreadsheet.ParseFormat(header.Filename)
+	if err != nil {
+		writeAppError(w, r, ValidationError("Unsupported file", map[string]string{"file": "must be a .csv or .xlsx file"}))
+		return
+	}
+
+	rows, err := spreadsheet.Read(file, format)
+	if err != nil {
+		writeAppError(w, r, ValidationError("Could not read the file", map[string]string{"file": "must be a CSV or XLSX file saved from a spreadsheet program"}))
+		return
+	}
+
+	class, current, err := s.gradeImportClass(r, classID)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	scale, err := s.classGradingScale(r.Context(), classID)
+	if err != nil {
+		writeAppError(w, r, NewAppError(http.StatusInternalServerError, "Failed to get grading scale").Wrap(err))
+		return
+	}
+
+	submission, changes, problems := parseGradeSheet(newClassScores(class, current), rows, scale)
+
+	payload, err := json.Marshal(submission)
+	if err != nil {
+		writeAppError(w, r, NewAppError(http.StatusInternalServerError, "internal server error").Wrap(err))
+		return
+	}
+
+	s.renderComponent(w, r, myclasses.ImportPreview(class, header.Filename, changes, problems, string(payload)))
+}
+
+// ConfirmGradeImport saves the grades of a previewed import the same way SubmitGrades does, then
+// shows the class's grade entry form with the new scores.
+func (s *Server) ConfirmGradeImport(w http.ResponseWriter, r *http.Request) {
+	classID, err := uuid.Parse(r.PathValue("classID"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid class ID")
+		return
+	}
+
+	var submission GradeSubmission
+	if err := json.Unmarshal([]byte(r.FormValue("submission")), &submission); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid request format")
+		return
+	}
+
+	// The preview was sent back by the browser; saveGradeSubmission checks the teacher may submit
+	// each of its grades, and the form must only save grades for the class it shows.
+	if submission.ClassID != classID.String() {
+		writeAppError(w, r, NewAppError(http.StatusBadRequest, "The import is for another class").WithField("class_id", "must be "+classID.String()))
+		return
+	}
+
+	if err := s.saveGradeSubmission(r, submission); err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	s.GetClassForm(w, r)
+}
+
+// gradeImportClass returns the request user's class with its stored grades.
+func (s *Server) gradeImportClass(r *http.Request, classID uuid.UUID) (myclasses.GradeEntryData, []database.ListGradesForClassRow, error) {
+	teacher, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		return myclasses.GradeEntryData{}, nil, NewAppError(http.StatusForbidden, "forbidden")
+	}
+
+	class, err := s.teacherClass(r.Context(), teacher.UserID, classID)
+	if err != nil {
+		return class, nil, err
+	}
+
+	current, err := s.queries.ListGradesForClass(r.Context(), classID)
+	if err != nil {
+		return class, nil, NewAppError(http.StatusInternalServerError, "internal server error").Wrap(err)
+	}
+
+	return class, current, nil
+}
+
+// parseGradeSheet checks the rows of an uploaded grade sheet against the class and its grading
+// scale, and returns the grades to submit for the scores that differ from the stored ones, with a
+// description of each change. The first row must hold the headers: "Student No", then the score
+// columns of subjects the teacher teaches in the class, as in the downloaded template. Empty cells
+// and a "Student Name" column are ignored. Problems lists everything wrong with the sheet; the
+// submission must not be saved unless it is empty.
+func parseGradeSheet(scores classScores, rows [][]string, scale grading.Scale) (submission GradeSubmission, changes []myclasses.GradeChange, problems []string) {
+	class := scores.class
+	submission = GradeSubmission{ClassID: class.ClassID.String(), TermID: class.TermID.String()}
+
+	if len(rows) == 0 || len(rows[0]) == 0 || !strings.EqualFold(strings.TrimSpace(rows[0][0]), sheetStudentNo) {
+		return submission, nil, []string{fmt.Sprintf("The first column must be headed %q, as in the downloaded template", sheetStudentNo)}
+	}
+
+	known := make(map[string]gradeColumn)
+	for _, column := range gradeColumns(class, uuid.Nil) {
+		known[strings.ToLower(column.Header)] = column
+	}
+
+	columns := make(map[int]gradeColumn)
+	seenHeaders := make(map[string]bool)
+	for i, header := range rows[0][1:] {
+		header = strings.TrimSpace(header)
+		key := strings.ToLower(header)
+		if header == "" || strings.EqualFold(header, sheetStudentName) {
+			continue
+		}
+
+		column, ok := known[key]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("Column %q is not a subject you teach in %s", header, class.ClassName))
+		case seenHeaders[key]:
+			problems = append(problems, fmt.Sprintf("Column %q appears more than once", header))
+		case class.Closed[column.SubjectID] != "":
+			problems = append(problems, fmt.Sprintf("Column %q: grade entry %s", header, class.Closed[column.SubjectID]))
+		default:
+			columns[i+1] = column
+		}
+		seenHeaders[key] = true
+	}
+
+	students := make(map[string]myclasses.Student, len(class.Students))
+	for _, student := range class.Students {
+		students[strings.ToLower(student.StudentNo)] = student
+	}
+
+	minScore, maxScore := scale.Range()
+	grades := make(map[uuid.UUID]map[uuid.UUID]*GradeEntry)
+	seenStudents := make(map[uuid.UUID]int)
+	for i, row := range rows[1:] {
+		line := i + 2
+		if blankRow(row) {
+			continue
+		}
+
+		studentNo := strings.TrimSpace(row[0])
+		student, ok := students[strings.ToLower(studentNo)]
+		switch {
+		case studentNo == "":
+			problems = append(problems, fmt.Sprintf("Row %d: the student number is missing", line))
+			continue
+		case !ok:
+			problems = append(problems, fmt.Sprintf("Row %d: %s is not a student in %s", line, studentNo, class.ClassName))
+			continue
+		case seenStudents[student.StudentID] > 0:
+			problems = append(problems, fmt.Sprintf("Row %d: %s is already on row %d", line, studentNo, seenStudents[student.StudentID]))
+			continue
+		}
+		seenStudents[student.StudentID] = line
+
+		for j := 1; j < len(row); j++ {
+			column, ok := columns[j]
+			value := strings.TrimSpace(row[j])
+			if !ok || value == "" {
+				continue
+			}
+
+			score, err := strconv.ParseFloat(value, 64)
+			if err != nil || math.IsNaN(score) || math.IsInf(score, 0) {
+				problems = append(problems, fmt.Sprintf("Row %d, %s: %q is not a number", line, column.Header, value))
+				continue
+			}
+			if !scale.Accepts(score) {
+				problems = append(problems, fmt.Sprintf("Row %d, %s: %g must be between %g and %g", line, column.Header, score, minScore, maxScore))
+				continue
+			}
+
+			// Scores are stored to two decimal places.
+			score = math.Round(score*100) / 100
+			stored, hasStored := scores.score(student.StudentID, column)
+			if hasStored && stored == score {
+				continue
+			}
+
+			change := myclasses.GradeChange{Row: line, StudentNo: student.StudentNo, StudentName: student.StudentName, Column: column.Header, To: formatSheetScore(score)}
+			if hasStored {
+				change.From = formatSheetScore(stored)
+			}
+			changes = append(changes, change)
+
+			if grades[student.StudentID] == nil {
+				grades[student.StudentID] = make(map[uuid.UUID]*GradeEntry)
+			}
+			grade := grades[student.StudentID][column.SubjectID]
+			if grade == nil {
+				grade = &GradeEntry{SubjectID: column.SubjectID.String(), Remark: scores.remark(student.StudentID, column.SubjectID)}
+				grades[student.StudentID][column.SubjectID] = grade
+			}
+			if column.ComponentID == uuid.Nil {
+				grade.Score = score
+			} else {
+				grade.Components = append(grade.Components, ComponentScore{ComponentID: column.ComponentID.String(), Score: score})
+			}
+		}
+	}
+
+	// Submit the grades in the order of the class's students and subjects.
+	for _, student := range class.Students {
+		if grades[student.StudentID] == nil {
+			continue
+		}
+
+		entry := StudentGrades{StudentID: student.StudentID.String()}
+		for _, subject := range class.Subjects {
+			if grade := grades[student.StudentID][subject.SubjectID]; grade != nil {
+				entry.Grades = append(entry.Grades, *grade)
+			}
+		}
+		submission.Grades = append(submission.Grades, entry)
+	}
+
+	return submission, changes, problems
+}
+
+// blankRow reports whether every cell of a row is empty.
+func blankRow(row []string) bool {
+	for _, cell := range row {
+		if strings.TrimSpace(cell) != "" {
+			return false
+		}
+	}
+	return true
+}
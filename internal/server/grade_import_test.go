@@ -0,0 +1,151 @@
+// grade_import_test.go
+package server
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"school_management_system/cmd/web/dashboard/myclasses"
+	"school_management_system/internal/database"
+	"school_management_system/internal/grading"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// testImportClass is a class with Mathematics, English with two assessment components, and two students.
+func testImportClass() myclasses.GradeEntryData {
+	maths, english := uuid.New(), uuid.New()
+	test, exam := uuid.New(), uuid.New()
+	return myclasses.GradeEntryData{
+		ClassID:   uuid.New(),
+		ClassName: "Form 1",
+		TermID:    uuid.New(),
+		Subjects:  []myclasses.Subject{{SubjectID: maths, SubjectName: "Mathematics"}, {SubjectID: english, SubjectName: "English"}},
+		Students: []myclasses.Student{
+			{StudentID: uuid.New(), StudentNo: "STU-001", StudentName: "Chikondi Banda"},
+			{StudentID: uuid.New(), StudentNo: "STU-002", StudentName: "Tadala Phiri"},
+		},
+		Components: map[uuid.UUID][]myclasses.Component{english: {
+			{ComponentID: test, Name: "Test", Weight: 40},
+			{ComponentID: exam, Name: "Exam", Weight: 60},
+		}},
+		ComponentScores: map[uuid.UUID]map[uuid.UUID]float64{},
+		Closed:          map[uuid.UUID]string{},
+	}
+}
+
+func TestGradeSheet(t *testing.T) {
+	class := testImportClass()
+	maths := class.Subjects[0].SubjectID
+	test := class.Components[class.Subjects[1].SubjectID][0].ComponentID
+	first, second := class.Students[0], class.Students[1]
+	class.ComponentScores[test] = map[uuid.UUID]float64{second.StudentID: 55}
+
+	scores := newClassScores(class, []database.ListGradesForClassRow{
+		{StudentID: first.StudentID, SubjectID: maths, Score: numericFromFloat(72.5)},
+		{StudentID: second.StudentID, SubjectID: maths},
+	})
+
+	want := [][]string{
+		{"Student No", "Student Name", "Mathematics", "English: Test", "English: Exam"},
+		{"STU-001", "Chikondi Banda", "72.5", "", ""},
+		{"STU-002", "Tadala Phiri", "", "55", ""},
+	}
+	if got := gradeSheet(scores, gradeColumns(class, uuid.Nil)); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q; got %q", want, got)
+	}
+
+	if got := gradeColumns(class, maths); len(got) != 1 || got[0].Header != "Mathematics" {
+		t.Errorf("expected only the Mathematics column; got %+v", got)
+	}
+}
+
+func TestParseGradeSheet(t *testing.T) {
+	class := testImportClass()
+	maths, english := class.Subjects[0].SubjectID, class.Subjects[1].SubjectID
+	exam := class.Components[english][1].ComponentID
+	first, second := class.Students[0], class.Students[1]
+	remark := pgtype.Text{String: "Good work", Valid: true}
+
+	scores := newClassScores(class, []database.ListGradesForClassRow{
+		{StudentID: first.StudentID, SubjectID: maths, Score: numericFromFloat(60)},
+		{StudentID: second.StudentID, SubjectID: maths, Score: numericFromFloat(45), Remark: remark},
+	})
+
+	rows := [][]string{
+		{"student no", "Student Name", "Mathematics", "English: Exam"},
+		{"STU-001", "Chikondi Banda", "60", " 70.456"},
+		{},
+		{"stu-002", "", "51", ""},
+	}
+
+	submission, changes, problems := parseGradeSheet(scores, rows, nil)
+	if len(problems) > 0 {
+		t.Fatalf("unexpected problems: %q", problems)
+	}
+
+	wantChanges := []myclasses.GradeChange{
+		{Row: 2, StudentNo: "STU-001", StudentName: "Chikondi Banda", Column: "English: Exam", To: "70.46"},
+		{Row: 4, StudentNo: "STU-002", StudentName: "Tadala Phiri", Column: "Mathematics", From: "45", To: "51"},
+	}
+	if !reflect.DeepEqual(changes, wantChanges) {
+		t.Errorf("expected changes %+v; got %+v", wantChanges, changes)
+	}
+
+	// The unchanged Mathematics score of the first student is left out, and remarks are kept.
+	want := GradeSubmission{
+		ClassID: class.ClassID.String(),
+		TermID:  class.TermID.String(),
+		Grades: []StudentGrades{
+			{StudentID: first.StudentID.String(), Grades: []GradeEntry{{
+				SubjectID:  english.String(),
+				Components: []ComponentScore{{ComponentID: exam.String(), Score: 70.46}},
+			}}},
+			{StudentID: second.StudentID.String(), Grades: []GradeEntry{{SubjectID: maths.String(), Score: 51, Remark: "Good work"}}},
+		},
+	}
+	if !reflect.DeepEqual(submission, want) {
+		t.Errorf("expected submission %+v; got %+v", want, submission)
+	}
+}
+
+func TestParseGradeSheet_Problems(t *testing.T) {
+	class := testImportClass()
+	english := class.Subjects[1].SubjectID
+	class.Closed[english] = "closed Mar 6, 2026 17:00"
+	scores := newClassScores(class, nil)
+
+	scale := grading.Scale{{Letter: "A", MinScore: 50, MaxScore: 100}, {Letter: "F", MinScore: 0, MaxScore: 49.99}}
+	rows := [][]string{
+		{"Student No", "Mathematics", "Biology", "English: Test", "Mathematics"},
+		{"STU-001", "abc"},
+		{"STU-009", "50"},
+		{"", "50"},
+		{"STU-002", "120"},
+		{"STU-002", "80"},
+	}
+
+	submission, changes, problems := parseGradeSheet(scores, rows, scale)
+	want := []string{
+		`Column "Biology" is not a subject you teach in Form 1`,
+		`Column "English: Test": grade entry closed Mar 6, 2026 17:00`,
+		`Column "Mathematics" appears more than once`,
+		`Row 2, Mathematics: "abc" is not a number`,
+		`Row 3: STU-009 is not a student in Form 1`,
+		`Row 4: the student number is missing`,
+		`Row 5, Mathematics: 120 must be between 0 and 100`,
+		`Row 6: STU-002 is already on row 5`,
+	}
+	if !reflect.DeepEqual(problems, want) {
+		t.Errorf("expected problems:\n%s\ngot:\n%s", strings.Join(want, "\n"), strings.Join(problems, "\n"))
+	}
+	if len(changes) != 0 || len(submission.Grades) != 0 {
+		t.Errorf("expected nothing to submit; got %+v", submission.Grades)
+	}
+
+	if _, _, problems := parseGradeSheet(scores, [][]string{{"Name", "Mathematics"}}, scale); len(problems) != 1 {
+		t.Errorf("expected a sheet without a student number column to be rejected; got %q", problems)
+	}
+}
@@ -1,18 +1,31 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
+	"time"
 
 	"school_management_system/cmd/web/dashboard/grades"
 	"school_management_system/internal/database"
+	"school_management_system/internal/grading"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// GradeEntry is a student's grade in one subject. For a subject with assessment components the
+// score is computed from Components and any submitted score is ignored.
 type GradeEntry struct {
-	Remark    string  `json:"remark"`
-	SubjectID string  `json:"subject_id"`
-	Score     float64 `json:"score"`
+	Remark     string           `json:"remark"`
+	SubjectID  string           `json:"subject_id"`
+	Score      float64          `json:"score"`
+	Components []ComponentScore `json:"components,omitempty"`
 }
 
 type StudentGrades struct {
@@ -27,55 +40,333 @@ type GradeSubmission struct {
 }
 
 // SubmitGrades handles the HTTP request for submitting student grades.
-// It decodes the incoming JSON payload, then inserts or updates the grade record for each student-subject combination.
-// The function uses a transaction to ensure atomicity. On success, it returns a 201 Created status.
-// On failure, it writes an appropriate error message and logs the error.
+// It decodes the incoming JSON payload and saves it with saveGradeSubmission. On success, it returns a 201 Created status.
+// On failure, it writes an appropriate error message.
 func (s *Server) SubmitGrades(w http.ResponseWriter, r *http.Request) {
 	var submission GradeSubmission
 
 	if err := json.NewDecoder(r.Body).Decode(&submission); err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid request format")
+		writeError(w, r, http.StatusBadRequest, "Invalid request format")
 		return
 	}
 
-	// Begin transaction
-	tx, err := s.conn.Begin(r.Context())
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to start transaction")
-		slog.Error("failed to begin transaction", "error", err.Error())
+	if err := s.saveGradeSubmission(r, submission); err != nil {
+		writeAppError(w, r, err)
 		return
 	}
-	defer tx.Rollback(r.Context())
-
-	for _, student := range submission.Grades {
-		for _, grade := range student.Grades {
-			_, err := tx.Exec(r.Context(),
-				`INSERT INTO grades (student_id, subject_id, term_id, score, remark)
-                 VALUES ($1, $2, $3, $4, $5)
-                 ON CONFLICT (student_id, subject_id, term_id)
-                 DO UPDATE SET score = EXCLUDED.score, remark = EXCLUDED.remark`,
-				student.StudentID, grade.SubjectID, submission.TermID, grade.Score, grade.Remark,
-			)
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+// saveGradeSubmission checks the teacher may submit every grade, that the scores fit the class's grading scale
+// and that grade entry is open for every subject submitted, then inserts or updates the grade record for each student-subject combination.
+// Subjects with assessment components get the weighted average of the component scores.
+// The grades are saved in one transaction, so either all of them are saved or none are.
+func (s *Server) saveGradeSubmission(r *http.Request, submission GradeSubmission) error {
+	term, err := s.getCachedTerm()
+	if err != nil {
+		return NewAppError(http.StatusNotFound, "there is no active term")
+	}
+
+	teacher, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		return NewAppError(http.StatusForbidden, "forbidden")
+	}
+
+	// The checks run in the transaction that saves the grades, after locking the class's report card
+	// approval, so the report cards can't be submitted in between.
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		termID, classID, err := checkGradeSubmission(r.Context(), qtx, teacher.UserID, term.TermID, submission)
+		if err != nil {
+			return err
+		}
+
+		if _, err := qtx.LockReportApproval(r.Context(), database.LockReportApprovalParams{ClassID: classID, TermID: termID}); err != nil {
+			return NewAppError(http.StatusInternalServerError, "Failed to get report card approval").Wrap(err)
+		}
+
+		scale, err := loadClassGradingScale(r.Context(), qtx, classID)
+		if err != nil {
+			return NewAppError(http.StatusInternalServerError, "Failed to get grading scale").Wrap(err)
+		}
+
+		if err := validateScores(scale, submission.Grades); err != nil {
+			return err
+		}
+
+		if err := checkGradeEntry(r.Context(), qtx, termID, classID, submission.Grades, time.Now()); err != nil {
+			return err
+		}
+
+		subjectComponents, err := classAssessmentComponents(r.Context(), qtx, classID, termID)
+		if err != nil {
+			return NewAppError(http.StatusInternalServerError, "Failed to get assessment components").Wrap(err)
+		}
+
+		for i, student := range submission.Grades {
+			studentID, err := uuid.Parse(student.StudentID)
 			if err != nil {
-				writeError(w, http.StatusInternalServerError, "Failed to save grade")
-				slog.Error("failed to save grade",
-					"student_id", student.StudentID,
-					"subject_id", grade.SubjectID,
-					"error", err.Error(),
-				)
-				return
+				return NewAppError(http.StatusBadRequest, "Invalid request format").
+					WithField(fmt.Sprintf("grades[%d].student_id", i), "must be a student ID")
+			}
+
+			for j, grade := range student.Grades {
+				subjectID, err := uuid.Parse(grade.SubjectID)
+				if err != nil {
+					return NewAppError(http.StatusBadRequest, "Invalid request format").
+						WithField(fmt.Sprintf("grades[%d].grades[%d].subject_id", i, j), "must be a subject ID")
+				}
+
+				if components := subjectComponents[subjectID]; len(components) > 0 {
+					if grade.Score, err = saveAssessmentScores(r, qtx, studentID, components, grade.Components); err != nil {
+						return entryError(err, i, j)
+					}
+				}
+
+				if err := saveGrade(r, qtx, studentID, subjectID, termID, grade); err != nil {
+					return NewAppError(http.StatusInternalServerError, "Failed to save grade").Wrap(err)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		var appErr *AppError
+		if !errors.As(err, &appErr) {
+			return NewAppError(http.StatusInternalServerError, "Failed to save grades").Wrap(err)
+		}
+		return err
+	}
+
+	return nil
+}
+
+// checkGradeSubmission checks the submission is for the active term, and that every entry is for a
+// student enrolled in the class for it and a subject the teacher is assigned in the class. The
+// problems with all entries are returned together, keyed by the entry's position in the
+// submission such as "grades[2].grades[0].subject_id".
+func checkGradeSubmission(ctx context.Context, q *database.Queries, teacherID, activeTermID uuid.UUID, submission GradeSubmission) (termID, classID uuid.UUID, err error) {
+	termID, err = uuid.Parse(submission.TermID)
+	if err != nil {
+		return termID, classID, NewAppError(http.StatusBadRequest, "Invalid request format").WithField("term_id", "must be a term ID")
+	}
+
+	classID, err = uuid.Parse(submission.ClassID)
+	if err != nil {
+		return termID, classID, NewAppError(http.StatusBadRequest, "Invalid request format").WithField("class_id", "must be a class ID")
+	}
+
+	if termID != activeTermID {
+		return termID, classID, NewAppError(http.StatusForbidden, "Grades can only be submitted for the current term").
+			WithField("term_id", "must be the current term")
+	}
+
+	subjectIDs, err := q.ListTeacherSubjectIDs(ctx, database.ListTeacherSubjectIDsParams{TeacherID: teacherID, ClassID: classID})
+	if err != nil {
+		return termID, classID, NewAppError(http.StatusInternalServerError, "Failed to get teacher assignments").Wrap(err)
+	}
+
+	if len(subjectIDs) == 0 {
+		return termID, classID, NewAppError(http.StatusForbidden, "You are not assigned to teach this class").
+			WithField("class_id", "must be a class you teach")
+	}
+
+	studentIDs, err := q.ListEnrolledStudentIDs(ctx, database.ListEnrolledStudentIDsParams{ClassID: classID, TermID: termID})
+	if err != nil {
+		return termID, classID, NewAppError(http.StatusInternalServerError, "Failed to get class students").Wrap(err)
+	}
+
+	assigned := make(map[uuid.UUID]bool, len(subjectIDs))
+	for _, id := range subjectIDs {
+		assigned[id] = true
+	}
+	enrolled := make(map[uuid.UUID]bool, len(studentIDs))
+	for _, id := range studentIDs {
+		enrolled[id] = true
+	}
+
+	problems := make(map[string]string)
+	for i, student := range submission.Grades {
+		field := fmt.Sprintf("grades[%d]", i)
+		studentID, err := uuid.Parse(student.StudentID)
+		switch {
+		case err != nil:
+			problems[field+".student_id"] = "must be a student ID"
+		case !enrolled[studentID]:
+			problems[field+".student_id"] = "is not enrolled in this class for the term"
+		}
+
+		for j, grade := range student.Grades {
+			field := fmt.Sprintf("grades[%d].grades[%d].subject_id", i, j)
+			subjectID, err := uuid.Parse(grade.SubjectID)
+			switch {
+			case err != nil:
+				problems[field] = "must be a subject ID"
+			case !assigned[subjectID]:
+				problems[field] = "is not a subject you teach in this class"
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return termID, classID, ValidationError("Some grades can't be submitted", problems)
+	}
+	return termID, classID, nil
+}
+
+// validateScores checks every submitted score and component score falls inside the range of the
+// class's grading scale. Every score outside it is reported, keyed by its position in the
+// submission such as "grades[2].grades[0].score" or "grades[2].grades[0].components[1].score".
+func validateScores(scale grading.Scale, submitted []StudentGrades) error {
+	minScore, maxScore := scale.Range()
+	outside := fmt.Sprintf("must be between %g and %g", minScore, maxScore)
+
+	problems := make(map[string]string)
+	for i, student := range submitted {
+		for j, grade := range student.Grades {
+			field := fmt.Sprintf("grades[%d].grades[%d]", i, j)
+			if !scale.Accepts(grade.Score) {
+				problems[field+".score"] = outside
 			}
+
+			for k, component := range grade.Components {
+				if !scale.Accepts(component.Score) {
+					problems[fmt.Sprintf("%s.components[%d].score", field, k)] = outside
+				}
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return ValidationError("Scores outside the grading scale", problems)
+	}
+	return nil
+}
+
+// entryError keys the field problems of err by the position of the grade entry they were found in,
+// such as "grades[2].grades[0].component_id", as checkGradeSubmission and validateScores do.
+func entryError(err error, i, j int) error {
+	var appErr *AppError
+	if !errors.As(err, &appErr) || len(appErr.Fields) == 0 {
+		return err
+	}
+
+	fields := make(map[string]string, len(appErr.Fields))
+	for field, problem := range appErr.Fields {
+		fields[fmt.Sprintf("grades[%d].grades[%d].%s", i, j, field)] = problem
+	}
+	appErr.Fields = fields
+	return appErr
+}
+
+// saveGrade inserts or updates a single grade and records the change in the audit log, and in the
+// grade's revisions if its score or remark changed.
+func saveGrade(r *http.Request, qtx *database.Queries, studentID, subjectID, termID uuid.UUID, grade GradeEntry) error {
+	key := database.GetGradeParams{
+		StudentID: studentID,
+		SubjectID: subjectID,
+		TermID:    termID,
+	}
+
+	before, err := qtx.GetGrade(r.Context(), key)
+	existed := err == nil
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+
+	params := database.UpsertGradeParams{
+		StudentID: studentID,
+		SubjectID: subjectID,
+		TermID:    termID,
+		Score:     numericFromFloat(grade.Score),
+		Remark:    pgtype.Text{String: grade.Remark, Valid: true},
+	}
+
+	after, err := qtx.UpsertGrade(r.Context(), params)
+	if err != nil {
+		return err
+	}
+
+	if !existed || gradeChanged(before, after) {
+		if err := recordGradeRevision(r, qtx, after, revisionEntry); err != nil {
+			return err
+		}
+	}
+
+	if existed {
+		return recordAudit(r, qtx, auditUpdate, auditGrade, after.GradeID, before, after)
+	}
+	return recordAudit(r, qtx, auditCreate, auditGrade, after.GradeID, nil, after)
+}
+
+// Sources of grade revisions.
+const (
+	revisionEntry     = "entry"
+	revisionRecompute = "recompute"
+)
+
+// gradeChanged reports whether a grade's score or remark differs from before.
+func gradeChanged(before, after database.Grade) bool {
+	beforeScore, _ := before.Score.Float64Value()
+	afterScore, _ := after.Score.Float64Value()
+	return beforeScore != afterScore || before.Remark != after.Remark
+}
+
+// recordGradeRevision appends the score and remark of a grade to its revisions, made by the
+// request's user.
+func recordGradeRevision(r *http.Request, qtx *database.Queries, grade database.Grade, source string) error {
+	return qtx.CreateGradeRevision(r.Context(), database.CreateGradeRevisionParams{
+		GradeID:   grade.GradeID,
+		Score:     grade.Score,
+		Remark:    grade.Remark,
+		Source:    source,
+		ChangedBy: requestUserID(r),
+	})
+}
+
+// ShowGradeHistory renders the revisions of a grade, newest first, with who made each and when.
+// Teachers only see the history of grades in subjects they are assigned to teach; users who can read
+// the audit log, which records the same changes, see every grade's.
+func (s *Server) ShowGradeHistory(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	gradeID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid grade")
+		return
+	}
+
+	subject, err := s.queries.GetGradeRevisionSubject(r.Context(), gradeID)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "grade not found")
+		return
+	}
+
+	if !user.Can("audit.view") {
+		subjectIDs, err := s.queries.ListTeacherSubjectIDs(r.Context(), database.ListTeacherSubjectIDsParams{TeacherID: user.UserID, ClassID: subject.ClassID})
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, "failed to get grade history")
+			slog.Error("failed to list teacher subjects", "error", err.Error())
+			return
+		}
+		if !slices.Contains(subjectIDs, subject.SubjectID) {
+			writeError(w, r, http.StatusNotFound, "grade not found")
+			return
 		}
 	}
 
-	// Commit transaction
-	if err := tx.Commit(r.Context()); err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to commit transaction")
-		slog.Error("failed to commit transaction", "error", err.Error())
+	revisions, err := s.queries.ListGradeRevisions(r.Context(), gradeID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get grade history")
+		slog.Error("failed to list grade revisions", "error", err.Error())
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
+	s.renderComponent(w, r, grades.GradeHistory(subject, revisions))
 }
 
 // ListGrades handles HTTP requests for displaying student grades.
@@ -84,14 +375,14 @@ func (s *Server) SubmitGrades(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ListGrades(w http.ResponseWriter, r *http.Request) {
 	classSubjects, err := s.queries.ListAllSubjects(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to fetch classes", ":", err.Error())
 		return
 	}
 
 	students, err := s.queries.ListGrades(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to fetch grades", "error", err.Error())
 		return
 	}
@@ -106,12 +397,30 @@ func (s *Server) ListGrades(w http.ResponseWriter, r *http.Request) {
 		classStudentMap[student.ClassName] = append(classStudentMap[student.ClassName], student)
 	}
 
+	scales, err := s.classGradingScales(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to fetch grading scales", "error", err.Error())
+		return
+	}
+
 	var classData []grades.ClassGradesData
 	for class, stuList := range classStudentMap {
+		scale := scales[stuList[0].ClassID]
+
+		var scores []float64
+		for _, student := range stuList {
+			for _, grade := range student.Grades {
+				scores = append(scores, grade.Score)
+			}
+		}
+
 		classData = append(classData, grades.ClassGradesData{
-			ClassName: class,
-			Subjects:  classSubjectMap[class],
-			Students:  stuList,
+			ClassName:    class,
+			Subjects:     classSubjectMap[class],
+			Students:     stuList,
+			Scale:        scale,
+			Distribution: scale.Distribution(scores),
 		})
 	}
 
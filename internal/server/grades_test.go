@@ -0,0 +1,183 @@
+// grades_test.go
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestSaveGradeRevisions(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	qtx := database.New(mockConn)
+	teacher := User{UserID: uuid.New()}
+	req := httptest.NewRequest("POST", "/grades/submit", nil)
+	req = req.WithContext(context.WithValue(req.Context(), userContextKey, teacher))
+	gradeID, studentID, subjectID, termID := uuid.New(), uuid.New(), uuid.New(), uuid.New()
+	gradeColumns := []string{"grade_id", "student_id", "subject_id", "term_id", "score", "remark"}
+	remark := pgtype.Text{String: "Good work", Valid: true}
+
+	// A new grade gets its first revision, made by the submitting teacher.
+	mockConn.ExpectQuery("SELECT").WithArgs(studentID, subjectID, termID).WillReturnError(pgx.ErrNoRows)
+	mockConn.ExpectQuery("INSERT INTO grades").WithArgs(studentID, subjectID, termID, numericFromFloat(45), remark).
+		WillReturnRows(pgxmock.NewRows(gradeColumns).AddRow(gradeID, studentID, subjectID, termID, numericFromFloat(45), remark))
+	mockConn.ExpectExec("INSERT INTO grade_revisions").
+		WithArgs(gradeID, numericFromFloat(45), remark, revisionEntry, pgtype.UUID{Bytes: teacher.UserID, Valid: true}).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockConn.ExpectExec("INSERT INTO audit_log").
+		WithArgs(pgxmock.AnyArg(), auditCreate, auditGrade, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	if err := saveGrade(req, qtx, studentID, subjectID, termID, GradeEntry{Score: 45, Remark: "Good work"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Resubmitting the same score and remark adds no revision.
+	mockConn.ExpectQuery("SELECT").WithArgs(studentID, subjectID, termID).
+		WillReturnRows(pgxmock.NewRows(gradeColumns).AddRow(gradeID, studentID, subjectID, termID, numericFromFloat(45), remark))
+	mockConn.ExpectQuery("INSERT INTO grades").WithArgs(studentID, subjectID, termID, numericFromFloat(45), remark).
+		WillReturnRows(pgxmock.NewRows(gradeColumns).AddRow(gradeID, studentID, subjectID, termID, numericFromFloat(45), remark))
+	mockConn.ExpectExec("INSERT INTO audit_log").
+		WithArgs(pgxmock.AnyArg(), auditUpdate, auditGrade, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	if err := saveGrade(req, qtx, studentID, subjectID, termID, GradeEntry{Score: 45, Remark: "Good work"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// Changing the score adds a revision.
+	mockConn.ExpectQuery("SELECT").WithArgs(studentID, subjectID, termID).
+		WillReturnRows(pgxmock.NewRows(gradeColumns).AddRow(gradeID, studentID, subjectID, termID, numericFromFloat(45), remark))
+	mockConn.ExpectQuery("INSERT INTO grades").WithArgs(studentID, subjectID, termID, numericFromFloat(62), remark).
+		WillReturnRows(pgxmock.NewRows(gradeColumns).AddRow(gradeID, studentID, subjectID, termID, numericFromFloat(62), remark))
+	mockConn.ExpectExec("INSERT INTO grade_revisions").
+		WithArgs(gradeID, numericFromFloat(62), remark, revisionEntry, pgtype.UUID{Bytes: teacher.UserID, Valid: true}).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+	mockConn.ExpectExec("INSERT INTO audit_log").
+		WithArgs(pgxmock.AnyArg(), auditUpdate, auditGrade, pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg(), pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	if err := saveGrade(req, qtx, studentID, subjectID, termID, GradeEntry{Score: 62, Remark: "Good work"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestCheckGradeSubmission(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	q := database.New(mockConn)
+	teacherID, termID, classID := uuid.New(), uuid.New(), uuid.New()
+	maths, english, enrolled := uuid.New(), uuid.New(), uuid.New()
+
+	submission := GradeSubmission{
+		ClassID: classID.String(),
+		TermID:  termID.String(),
+		Grades: []StudentGrades{
+			{StudentID: enrolled.String(), Grades: []GradeEntry{{SubjectID: maths.String()}, {SubjectID: english.String()}}},
+			{StudentID: uuid.NewString(), Grades: []GradeEntry{{SubjectID: "maths"}}},
+		},
+	}
+
+	var appErr *AppError
+	if _, _, err := checkGradeSubmission(context.Background(), q, teacherID, uuid.New(), submission); !errors.As(err, &appErr) ||
+		appErr.Status != http.StatusForbidden || appErr.Fields["term_id"] == "" {
+		t.Errorf("expected a past term to be rejected; got %v", err)
+	}
+
+	// Every entry is checked, and all the problems are reported together.
+	mockConn.ExpectQuery("SELECT a.subject_id").WithArgs(teacherID, classID).
+		WillReturnRows(pgxmock.NewRows([]string{"subject_id"}).AddRow(maths))
+	mockConn.ExpectQuery("SELECT sc.student_id").WithArgs(classID, termID).
+		WillReturnRows(pgxmock.NewRows([]string{"student_id"}).AddRow(enrolled))
+
+	_, _, err = checkGradeSubmission(context.Background(), q, teacherID, termID, submission)
+	want := map[string]string{
+		"grades[0].grades[1].subject_id": "is not a subject you teach in this class",
+		"grades[1].student_id":           "is not enrolled in this class for the term",
+		"grades[1].grades[0].subject_id": "must be a subject ID",
+	}
+	if !errors.As(err, &appErr) || appErr.Status != http.StatusUnprocessableEntity || !reflect.DeepEqual(appErr.Fields, want) {
+		t.Errorf("expected the problems %v; got %v", want, err)
+	}
+
+	// A teacher without any subject in the class can't submit to it at all.
+	mockConn.ExpectQuery("SELECT a.subject_id").WithArgs(teacherID, classID).
+		WillReturnRows(pgxmock.NewRows([]string{"subject_id"}))
+
+	if _, _, err := checkGradeSubmission(context.Background(), q, teacherID, termID, submission); !errors.As(err, &appErr) ||
+		appErr.Status != http.StatusForbidden || appErr.Fields["class_id"] == "" {
+		t.Errorf("expected a class the teacher doesn't teach to be rejected; got %v", err)
+	}
+
+	// A valid submission passes.
+	submission.Grades = submission.Grades[:1]
+	submission.Grades[0].Grades = submission.Grades[0].Grades[:1]
+	mockConn.ExpectQuery("SELECT a.subject_id").WithArgs(teacherID, classID).
+		WillReturnRows(pgxmock.NewRows([]string{"subject_id"}).AddRow(maths))
+	mockConn.ExpectQuery("SELECT sc.student_id").WithArgs(classID, termID).
+		WillReturnRows(pgxmock.NewRows([]string{"student_id"}).AddRow(enrolled))
+
+	if gotTerm, gotClass, err := checkGradeSubmission(context.Background(), q, teacherID, termID, submission); err != nil || gotTerm != termID || gotClass != classID {
+		t.Errorf("unexpected result %v, %v, %v", gotTerm, gotClass, err)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestShowGradeHistory_OnlyAssignedTeachers(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	s := &Server{queries: database.New(mockConn)}
+	teacher := User{UserID: uuid.New(), Permissions: []string{"grades.submit"}}
+	gradeID, subjectID, classID := uuid.New(), uuid.New(), uuid.New()
+
+	// The teacher teaches another subject of the class, so the grade is not theirs to see.
+	mockConn.ExpectQuery("GetGradeRevisionSubject").WithArgs(gradeID).
+		WillReturnRows(pgxmock.NewRows([]string{"grade_id", "subject_id", "class_id", "student_name", "subject_name", "term_name"}).
+			AddRow(gradeID, subjectID, classID, "Chikondi Banda", "Mathematics", "Term 1"))
+	mockConn.ExpectQuery("SELECT a.subject_id").WithArgs(teacher.UserID, classID).
+		WillReturnRows(pgxmock.NewRows([]string{"subject_id"}).AddRow(uuid.New()))
+
+	req := httptest.NewRequest("GET", "/grades/"+gradeID.String()+"/history", nil)
+	req.SetPathValue("id", gradeID.String())
+	req = req.WithContext(context.WithValue(req.Context(), userContextKey, teacher))
+	rec := httptest.NewRecorder()
+	s.ShowGradeHistory(rec, req)
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status %d; got %d", http.StatusNotFound, rec.Code)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
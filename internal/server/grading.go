@@ -0,0 +1,382 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"school_management_system/cmd/web/dashboard/gradingscales"
+	"school_management_system/internal/database"
+	"school_management_system/internal/grading"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// gradingScaleRecord is a grading scale with its bands, as written to the audit log.
+type gradingScaleRecord struct {
+	database.GradingScale
+	Bands string `json:"bands"`
+}
+
+// bandFromRow converts the scores of a stored band to a grading.Band.
+func bandFromRow(minScore, maxScore pgtype.Numeric, letter, descriptor string) grading.Band {
+	lowest, _ := minScore.Float64Value()
+	highest, _ := maxScore.Float64Value()
+
+	return grading.Band{
+		MinScore:   lowest.Float64,
+		MaxScore:   highest.Float64,
+		Letter:     letter,
+		Descriptor: descriptor,
+	}
+}
+
+// gradingScaleBands groups every stored band by its grading scale.
+func gradingScaleBands(ctx context.Context, q *database.Queries) (map[uuid.UUID]grading.Scale, error) {
+	rows, err := q.ListGradingBands(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scales := make(map[uuid.UUID]grading.Scale)
+	for _, row := range rows {
+		scales[row.GradingScaleID] = append(scales[row.GradingScaleID], bandFromRow(row.MinScore, row.MaxScore, row.Letter, row.Descriptor))
+	}
+
+	return scales, nil
+}
+
+// classGradingScales returns the grading scale of every class that has one.
+func (s *Server) classGradingScales(ctx context.Context) (map[uuid.UUID]grading.Scale, error) {
+	rows, err := s.queries.ListClassGradingBands(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scales := make(map[uuid.UUID]grading.Scale)
+	for _, row := range rows {
+		scales[row.ClassID] = append(scales[row.ClassID], bandFromRow(row.MinScore, row.MaxScore, row.Letter, row.Descriptor))
+	}
+
+	return scales, nil
+}
+
+// classGradingScale returns the grading scale of a class, or nil if it has none.
+func (s *Server) classGradingScale(ctx context.Context, classID uuid.UUID) (grading.Scale, error) {
+	return loadClassGradingScale(ctx, s.queries, classID)
+}
+
+// loadClassGradingScale returns the grading scale of a class read with q, or nil if it has none.
+func loadClassGradingScale(ctx context.Context, q *database.Queries, classID uuid.UUID) (grading.Scale, error) {
+	rows, err := q.GetClassGradingBands(ctx, classID)
+	if err != nil {
+		return nil, err
+	}
+
+	var scale grading.Scale
+	for _, row := range rows {
+		scale = append(scale, bandFromRow(row.MinScore, row.MaxScore, row.Letter, row.Descriptor))
+	}
+
+	return scale, nil
+}
+
+// ShowGradingScales lists the grading scales and the scale assigned to each class.
+func (s *Server) ShowGradingScales(w http.ResponseWriter, r *http.Request) {
+	scales, err := s.queries.ListGradingScales(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get grading scales")
+		slog.Error("failed to list grading scales", "error", err.Error())
+		return
+	}
+
+	bands, err := gradingScaleBands(r.Context(), s.queries)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get grading scales")
+		slog.Error("failed to list grading bands", "error", err.Error())
+		return
+	}
+
+	classes, err := s.queries.ListClassGradingScales(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get classes")
+		slog.Error("failed to list class grading scales", "error", err.Error())
+		return
+	}
+
+	s.renderComponent(w, r, gradingscales.GradingScales(scales, bands, classes))
+}
+
+// ShowCreateGradingScale renders the form for a new grading scale.
+func (s *Server) ShowCreateGradingScale(w http.ResponseWriter, r *http.Request) {
+	s.renderComponent(w, r, gradingscales.GradingScaleForm(database.GradingScale{}, ""))
+}
+
+// ShowEditGradingScale renders the form for editing a grading scale and its bands.
+func (s *Server) ShowEditGradingScale(w http.ResponseWriter, r *http.Request) {
+	scaleID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid grading scale")
+		return
+	}
+
+	record, err := getGradingScaleRecord(r.Context(), s.queries, scaleID)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	s.renderComponent(w, r, gradingscales.GradingScaleForm(record.GradingScale, record.Bands))
+}
+
+// getGradingScaleRecord returns a grading scale with its bands, or a not found error.
+func getGradingScaleRecord(ctx context.Context, q *database.Queries, scaleID uuid.UUID) (gradingScaleRecord, error) {
+	scale, err := q.GetGradingScale(ctx, scaleID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return gradingScaleRecord{}, NewAppError(http.StatusNotFound, "grading scale not found")
+	}
+	if err != nil {
+		return gradingScaleRecord{}, err
+	}
+
+	bands, err := gradingScaleBands(ctx, q)
+	if err != nil {
+		return gradingScaleRecord{}, err
+	}
+
+	return gradingScaleRecord{GradingScale: scale, Bands: bands[scaleID].String()}, nil
+}
+
+// parseGradingScaleForm reads the name and bands of a grading scale from the form.
+func parseGradingScaleForm(r *http.Request) (string, grading.Scale, error) {
+	if err := r.ParseForm(); err != nil {
+		return "", nil, NewAppError(http.StatusUnprocessableEntity, "failed to parse form")
+	}
+
+	fields := map[string]string{}
+
+	name := strings.TrimSpace(r.FormValue("name"))
+	if name == "" || len(name) > 100 {
+		fields["name"] = "must be between 1 and 100 characters"
+	}
+
+	scale, err := grading.ParseBands(r.FormValue("bands"))
+	if err != nil {
+		fields["bands"] = strings.TrimPrefix(err.Error(), grading.ErrInvalidBands.Error()+": ")
+	}
+
+	if len(fields) > 0 {
+		return "", nil, ValidationError("invalid grading scale", fields)
+	}
+
+	return name, scale, nil
+}
+
+// saveGradingBands replaces the bands of a grading scale.
+func saveGradingBands(ctx context.Context, qtx *database.Queries, scaleID uuid.UUID, scale grading.Scale) error {
+	if err := qtx.DeleteGradingBands(ctx, scaleID); err != nil {
+		return err
+	}
+
+	for _, band := range scale {
+		params := database.CreateGradingBandParams{
+			GradingScaleID: scaleID,
+			MinScore:       numericFromFloat(band.MinScore),
+			MaxScore:       numericFromFloat(band.MaxScore),
+			Letter:         band.Letter,
+			Descriptor:     band.Descriptor,
+		}
+		if _, err := qtx.CreateGradingBand(ctx, params); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// gradingScaleNameTaken converts a duplicate scale name into a conflict error.
+func gradingScaleNameTaken(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return NewAppError(http.StatusConflict, "a grading scale with this name already exists").WithField("name", "must be unique")
+	}
+
+	return err
+}
+
+// CreateGradingScale saves a new grading scale and its bands.
+func (s *Server) CreateGradingScale(w http.ResponseWriter, r *http.Request) {
+	name, scale, err := parseGradingScaleForm(r)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		created, err := qtx.CreateGradingScale(r.Context(), name)
+		if err != nil {
+			return gradingScaleNameTaken(err)
+		}
+
+		if err := saveGradingBands(r.Context(), qtx, created.GradingScaleID, scale); err != nil {
+			return err
+		}
+
+		after := gradingScaleRecord{GradingScale: created, Bands: scale.String()}
+		return recordAudit(r, qtx, auditCreate, auditGradingScale, created.GradingScaleID, nil, after)
+	})
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "" {
+		w.Header().Set("HX-Redirect", "/grading")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	http.Redirect(w, r, "/grading", http.StatusFound)
+}
+
+// EditGradingScale renames a grading scale and replaces its bands.
+func (s *Server) EditGradingScale(w http.ResponseWriter, r *http.Request) {
+	scaleID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid grading scale")
+		return
+	}
+
+	name, scale, err := parseGradingScaleForm(r)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		before, err := getGradingScaleRecord(r.Context(), qtx, scaleID)
+		if err != nil {
+			return err
+		}
+
+		edited, err := qtx.EditGradingScale(r.Context(), database.EditGradingScaleParams{GradingScaleID: scaleID, Name: name})
+		if err != nil {
+			return gradingScaleNameTaken(err)
+		}
+
+		if err := saveGradingBands(r.Context(), qtx, scaleID, scale); err != nil {
+			return err
+		}
+
+		after := gradingScaleRecord{GradingScale: edited, Bands: scale.String()}
+		return recordAudit(r, qtx, auditUpdate, auditGradingScale, scaleID, before, after)
+	})
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "" {
+		w.Header().Set("HX-Redirect", "/grading")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	http.Redirect(w, r, "/grading", http.StatusFound)
+}
+
+// DeleteGradingScale deletes a grading scale. Classes that used it go back to raw scores.
+func (s *Server) DeleteGradingScale(w http.ResponseWriter, r *http.Request) {
+	scaleID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid grading scale")
+		return
+	}
+
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		before, err := getGradingScaleRecord(r.Context(), qtx, scaleID)
+		if err != nil {
+			return err
+		}
+
+		if err := qtx.DeleteGradingScale(r.Context(), scaleID); err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditDelete, auditGradingScale, scaleID, before, nil)
+	})
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	s.ShowGradingScales(w, r)
+}
+
+// AssignClassGradingScale sets the grading scale used by a class. An empty grading_scale_id
+// removes the class's scale.
+func (s *Server) AssignClassGradingScale(w http.ResponseWriter, r *http.Request) {
+	classID, err := uuid.Parse(r.PathValue("classID"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid class")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "failed to parse form")
+		return
+	}
+
+	var scaleID uuid.UUID
+	if value := r.FormValue("grading_scale_id"); value != "" {
+		if scaleID, err = uuid.Parse(value); err != nil {
+			writeAppError(w, r, ValidationError("invalid grading scale", map[string]string{"grading_scale_id": "must be a grading scale ID"}))
+			return
+		}
+	}
+
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		var before, after any
+		if current, err := qtx.GetClassGradingScale(r.Context(), classID); err == nil {
+			before = current
+		} else if !errors.Is(err, pgx.ErrNoRows) {
+			return err
+		}
+
+		if scaleID == uuid.Nil {
+			if before == nil {
+				return nil
+			}
+			if err := qtx.RemoveClassGradingScale(r.Context(), classID); err != nil {
+				return err
+			}
+			return recordAudit(r, qtx, auditDelete, auditClassScale, classID, before, nil)
+		}
+
+		params := database.SetClassGradingScaleParams{ClassID: classID, GradingScaleID: scaleID}
+		if err := qtx.SetClassGradingScale(r.Context(), params); err != nil {
+			var pgErr *pgconn.PgError
+			if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+				return NewAppError(http.StatusNotFound, "class or grading scale not found")
+			}
+			return err
+		}
+		after = database.ClassGradingScale(params)
+
+		if before == nil {
+			return recordAudit(r, qtx, auditCreate, auditClassScale, classID, nil, after)
+		}
+		return recordAudit(r, qtx, auditUpdate, auditClassScale, classID, before, after)
+	})
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	writeSuccess(w, r, "Grading scale updated")
+}
@@ -0,0 +1,89 @@
+// grading_test.go
+package server
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+
+	"school_management_system/internal/grading"
+)
+
+func TestValidateScores(t *testing.T) {
+	submitted := func(scores ...float64) []StudentGrades {
+		var entries []GradeEntry
+		for _, score := range scores {
+			entries = append(entries, GradeEntry{Score: score})
+		}
+		return []StudentGrades{{Grades: entries}}
+	}
+
+	if err := validateScores(nil, submitted(0, 55.5, 100)); err != nil {
+		t.Errorf("expected scores from 0 to 100 without a scale; got %v", err)
+	}
+
+	var appErr *AppError
+	if err := validateScores(nil, submitted(101)); !errors.As(err, &appErr) || appErr.Status != http.StatusUnprocessableEntity || appErr.Fields["grades[0].grades[0].score"] == "" {
+		t.Errorf("expected a score field error above 100; got %v", err)
+	}
+
+	scale := grading.Scale{
+		{MinScore: 10, MaxScore: 20, Letter: "A"},
+		{MinScore: 1, MaxScore: 9.99, Letter: "B"},
+	}
+	if err := validateScores(scale, submitted(1, 20)); err != nil {
+		t.Errorf("expected the scale's range to be accepted; got %v", err)
+	}
+	if err := validateScores(scale, submitted(0)); !errors.As(err, &appErr) || appErr.Fields["grades[0].grades[0].score"] != "must be between 1 and 20" {
+		t.Errorf("expected scores below the scale to be rejected; got %v", err)
+	}
+
+	// Every score outside the scale is reported where it was submitted, component scores included.
+	grades := []StudentGrades{
+		{Grades: []GradeEntry{{Score: 5}, {Score: 30}}},
+		{Grades: []GradeEntry{{Score: 15, Components: []ComponentScore{{Score: 12}, {Score: -1}}}}},
+	}
+	want := map[string]string{
+		"grades[0].grades[1].score":               "must be between 1 and 20",
+		"grades[1].grades[0].components[1].score": "must be between 1 and 20",
+	}
+	if err := validateScores(scale, grades); !errors.As(err, &appErr) || !reflect.DeepEqual(appErr.Fields, want) {
+		t.Errorf("expected fields %v; got %v", want, err)
+	}
+}
+
+func TestEntryError(t *testing.T) {
+	err := entryError(NewAppError(http.StatusUnprocessableEntity, "Unknown assessment component").WithField("component_id", "must be a component"), 3, 1)
+
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.Fields["grades[3].grades[1].component_id"] != "must be a component" || len(appErr.Fields) != 1 {
+		t.Errorf("expected the field to be keyed by the entry; got %v", err)
+	}
+}
+
+func TestParseGradingScaleForm(t *testing.T) {
+	form := url.Values{"name": {" Primary "}, "bands": {"50-100 P Pass\n0-49.99 F Fail"}}
+	req := httptest.NewRequest("POST", "/grading", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	name, scale, err := parseGradingScaleForm(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if name != "Primary" || len(scale) != 2 || scale.Letter(75) != "P" {
+		t.Errorf("unexpected scale %q: %v", name, scale)
+	}
+
+	form = url.Values{"name": {""}, "bands": {"0-60 A\n50-100 B"}}
+	req = httptest.NewRequest("POST", "/grading", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	var appErr *AppError
+	if _, _, err := parseGradingScaleForm(req); !errors.As(err, &appErr) || appErr.Fields["name"] == "" || appErr.Fields["bands"] != "A overlaps B" {
+		t.Errorf("expected name and overlapping bands to be reported; got %#v", err)
+	}
+}
@@ -13,7 +13,7 @@ import (
 func (s *Server) ShowGraduatePage(w http.ResponseWriter, r *http.Request) {
 	academicYears, err := s.queries.ListAcademicYear(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to retrieve academic years")
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve academic years")
 		slog.Error("failed to retrieve academic year records", "message", err.Error())
 		return
 	}
@@ -25,7 +25,7 @@ func (s *Server) ShowGraduatePage(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ShowGraduatesList(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "failed to parse form data")
+		writeError(w, r, http.StatusBadRequest, "failed to parse form data")
 		slog.Error("failed to parse academic year", "error", err.Error())
 		return
 	}
@@ -33,14 +33,14 @@ func (s *Server) ShowGraduatesList(w http.ResponseWriter, r *http.Request) {
 	academicYearID := r.FormValue("academic_year_id")
 	parsedAcademicID, err := uuid.Parse(academicYearID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to parse ", "academic year ID", academicYearID, "error", err.Error())
 		return
 	}
 
 	graduatesList, err := s.queries.ListGraduatesByAcademicYear(r.Context(), parsedAcademicID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to retrieve academic years")
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve academic years")
 		slog.Error("failed to retrieve academic year records", "message", err.Error())
 	}
 
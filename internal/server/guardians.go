@@ -18,7 +18,7 @@ import (
 func (s *Server) ListGuardians(w http.ResponseWriter, r *http.Request) {
 	guardians, err := s.queries.GetAllStudentGuardianLinks(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to retrieve guardians list", ":", err.Error())
 		return
 	}
@@ -30,7 +30,7 @@ func (s *Server) ListGuardians(w http.ResponseWriter, r *http.Request) {
 // matching the search pattern
 func (s *Server) SearchGuardian(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "failed to parse form")
+		writeError(w, r, http.StatusUnprocessableEntity, "failed to parse form")
 		return
 	}
 
@@ -39,7 +39,7 @@ func (s *Server) SearchGuardian(w http.ResponseWriter, r *http.Request) {
 
 	searchedStudents, err := s.queries.SearchStudentGuardian(r.Context(), parsedSearch)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to retrieve students", ":", err.Error())
 		return
 	}
@@ -51,13 +51,13 @@ func (s *Server) SearchGuardian(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ShowEditGuardian(w http.ResponseWriter, r *http.Request) {
 	guardianID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "wrong params")
+		writeError(w, r, http.StatusUnprocessableEntity, "wrong params")
 		return
 	}
 
 	guardian, err := s.queries.GetGuardianByID(r.Context(), guardianID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to get guardian", ":", err.Error())
 		return
 	}
@@ -70,7 +70,7 @@ func (s *Server) ShowEditGuardian(w http.ResponseWriter, r *http.Request) {
 func (s *Server) EditGuardian(w http.ResponseWriter, r *http.Request) {
 	guardianID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "wrong parameters")
+		writeError(w, r, http.StatusUnprocessableEntity, "wrong parameters")
 		return
 	}
 
@@ -81,7 +81,7 @@ func (s *Server) EditGuardian(w http.ResponseWriter, r *http.Request) {
 	profession := r.FormValue("profession")
 
 	if guardianName == "" || phoneOne == "" || guardianGender == "" {
-		writeError(w, http.StatusBadRequest, "missing some fields")
+		writeError(w, r, http.StatusBadRequest, "missing some fields")
 		return
 	}
 
@@ -109,9 +109,25 @@ func (s *Server) EditGuardian(w http.ResponseWriter, r *http.Request) {
 		Profession:   validProfession,
 	}
 
-	err = s.queries.UpdateGuardian(r.Context(), params)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		before, err := qtx.GetGuardianByID(r.Context(), guardianID)
+		if err != nil {
+			return err
+		}
+
+		if err := qtx.UpdateGuardian(r.Context(), params); err != nil {
+			return err
+		}
+
+		after, err := qtx.GetGuardianByID(r.Context(), guardianID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditUpdate, auditGuardian, guardianID, before, after)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to update guardian", ":", err.Error())
 		return
 	}
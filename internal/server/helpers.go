@@ -1,15 +1,21 @@
 package server
 
 import (
+	"context"
 	"crypto/rand"
 	"errors"
 	"fmt"
 	"log/slog"
 	"math/big"
+	"net"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 
 	"school_management_system/cmd/web"
 	"school_management_system/cmd/web/dashboard"
+	"school_management_system/internal/database"
 
 	"github.com/a-h/templ"
 	"github.com/google/uuid"
@@ -76,17 +82,18 @@ func (s *Server) getCachedTerm() (CachedTerm, error) {
 func (s *Server) renderComponent(w http.ResponseWriter, r *http.Request, children templ.Component) {
 	if r.Header.Get("HX-Request") == "true" {
 		if err := children.Render(r.Context(), w); err != nil {
-			writeError(w, http.StatusBadRequest, err.Error())
+			writeError(w, r, http.StatusBadRequest, err.Error())
 			slog.Error("Failed to render dashboard component", "error", err)
 		}
 	} else {
 		userRole, ok := r.Context().Value(userContextKey).(User)
 		if !ok {
-			writeError(w, http.StatusUnauthorized, "unauthorised")
+			writeError(w, r, http.StatusUnauthorized, "unauthorised")
 			return
 		}
 		user := dashboard.DashboardUserRole{
-			Role: userRole.Role,
+			Role:        userRole.Role,
+			Permissions: userRole.Permissions,
 		}
 
 		term, _ := s.getCachedTerm()
@@ -97,7 +104,7 @@ func (s *Server) renderComponent(w http.ResponseWriter, r *http.Request, childre
 
 		ctx := templ.WithChildren(r.Context(), children)
 		if err := web.Dashboard(user, termArg).Render(ctx, w); err != nil {
-			writeError(w, http.StatusBadRequest, err.Error())
+			writeError(w, r, http.StatusBadRequest, err.Error())
 			slog.Error("Failed to render dashboard layout", "error", err)
 		}
 	}
@@ -131,3 +138,116 @@ func convertStringToUUID(id string) (uuid.UUID, error) {
 
 	return result, nil
 }
+
+// trustedProxies are the reverse proxies whose forwarding headers are believed.
+type trustedProxies []*net.IPNet
+
+// loadTrustedProxies reads the addresses of the reverse proxies in front of the app from
+// TRUSTED_PROXIES, a comma separated list of IP addresses and CIDR ranges such as
+// "172.16.0.0/12". Without it, forwarding headers are ignored.
+func loadTrustedProxies() trustedProxies {
+	var proxies trustedProxies
+	for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			if ip := net.ParseIP(entry); ip != nil && ip.To4() != nil {
+				entry += "/32"
+			} else {
+				entry += "/128"
+			}
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			slog.Warn("ignoring invalid TRUSTED_PROXIES entry", "entry", entry)
+			continue
+		}
+		proxies = append(proxies, network)
+	}
+
+	return proxies
+}
+
+// trusts reports whether ip is one of the trusted proxies.
+func (p trustedProxies) trusts(ip net.IP) bool {
+	for _, network := range p {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// clientIP returns the address of the client that made the request.
+// Forwarding headers are only believed when the request comes from a trusted proxy: X-Real-IP,
+// as set by the Caddy reverse proxy, or else the nearest X-Forwarded-For hop that isn't a trusted
+// proxy itself. Every address is checked to be an IP address, so what is stored is never longer
+// than one; the peer's address is used otherwise.
+func (s *Server) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+
+	peer := net.ParseIP(host)
+	if peer == nil {
+		return ""
+	}
+	if !s.proxies.trusts(peer) {
+		return peer.String()
+	}
+
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+
+	if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+		hops := strings.Split(forwarded, ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				break
+			}
+			if !s.proxies.trusts(ip) {
+				return ip.String()
+			}
+		}
+	}
+
+	return peer.String()
+}
+
+// requestUserID returns the ID of the user making the request, or a NULL UUID if there is none.
+func requestUserID(r *http.Request) pgtype.UUID {
+	if user, ok := r.Context().Value(userContextKey).(User); ok {
+		return pgtype.UUID{Bytes: user.UserID, Valid: true}
+	}
+
+	return pgtype.UUID{}
+}
+
+// withTx runs fn in a transaction, committing if it returns nil.
+func (s *Server) withTx(ctx context.Context, fn func(qtx *database.Queries) error) error {
+	tx, err := s.conn.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if err := fn(s.queries.WithTx(tx)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// numericFromFloat converts a float to a NUMERIC query parameter.
+func numericFromFloat(f float64) pgtype.Numeric {
+	var n pgtype.Numeric
+	_ = n.ScanScientific(strconv.FormatFloat(f, 'f', -1, 64))
+	return n
+}
@@ -15,11 +15,12 @@ import (
 func (s *Server) Dashboard(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(userContextKey).(User)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "unauthorized")
+		writeError(w, r, http.StatusUnauthorized, "unauthorized")
 	}
 
 	userRole := dashboard.DashboardUserRole{
-		Role: user.Role,
+		Role:        user.Role,
+		Permissions: user.Permissions,
 	}
 
 	contents := dashboard.DashboardCards(userRole)
@@ -29,7 +30,7 @@ func (s *Server) Dashboard(w http.ResponseWriter, r *http.Request) {
 func (s *Server) GetTotalUsers(w http.ResponseWriter, r *http.Request) {
 	totalUsers, err := s.queries.GetTotalUsers(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 	}
 
 	s.renderComponent(w, r, dashboard.TotalCount(strconv.Itoa(int(totalUsers))))
@@ -38,7 +39,7 @@ func (s *Server) GetTotalUsers(w http.ResponseWriter, r *http.Request) {
 func (s *Server) GetStudentsTotal(w http.ResponseWriter, r *http.Request) {
 	totalStudents, err := s.queries.GetTotalStudents(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 	}
 
 	s.renderComponent(w, r, dashboard.TotalCount(strconv.Itoa(int(totalStudents))))
@@ -47,13 +48,13 @@ func (s *Server) GetStudentsTotal(w http.ResponseWriter, r *http.Request) {
 func (s *Server) GetFees(w http.ResponseWriter, r *http.Request) {
 	totalAmount, err := s.queries.GetTotalFeesPaid(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
 	totalFees, ok := totalAmount.(float64)
 	if !ok {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("Failed to convert to float")
 		return
 	}
@@ -70,13 +71,13 @@ func (s *Server) showCalendarPage(w http.ResponseWriter, r *http.Request) {
 func (s *Server) academicEvents(w http.ResponseWriter, r *http.Request) {
 	currentYear, err := s.getCachedYear()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("Failed to retrive current academic year", "error", err.Error())
 	}
 
 	term, err := s.getCachedTerm()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("Failed to retrive current academic term", "error", err.Error())
 		return
 	}
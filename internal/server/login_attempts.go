@@ -0,0 +1,214 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"school_management_system/cmd/web/dashboard/userlist"
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Outcomes recorded in the login_attempts table.
+const (
+	loginSuccess            = "success"
+	loginInvalidCredentials = "invalid_credentials"
+	loginThrottled          = "throttled"
+	loginLocked             = "locked"
+)
+
+// loginThrottle holds the limits applied to failed login attempts.
+// Failures are tracked per account (on the users row) and per client IP (from login_attempts).
+type loginThrottle struct {
+	// FreeAttempts is the number of consecutive failures allowed on an account before backoff starts.
+	FreeAttempts int
+	// MaxFailures is the number of consecutive failures after which the account is locked.
+	MaxFailures int
+	// LockoutPeriod is how long an account stays locked once MaxFailures is reached.
+	LockoutPeriod time.Duration
+	// IPFreeAttempts is the number of failures allowed from one IP within IPWindow before backoff starts.
+	IPFreeAttempts int
+	// IPWindow is how far back failures from an IP are counted.
+	IPWindow time.Duration
+	// BaseDelay is the first backoff delay, doubled on every further failure up to MaxDelay.
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// loadLoginThrottle reads the throttling limits from the environment, falling back to defaults.
+func loadLoginThrottle() loginThrottle {
+	throttle := loginThrottle{
+		FreeAttempts:   3,
+		MaxFailures:    10,
+		LockoutPeriod:  15 * time.Minute,
+		IPFreeAttempts: 20,
+		IPWindow:       15 * time.Minute,
+		BaseDelay:      time.Second,
+		MaxDelay:       5 * time.Minute,
+	}
+
+	if v, err := strconv.Atoi(os.Getenv("LOGIN_MAX_FAILURES")); err == nil && v > 0 {
+		throttle.MaxFailures = v
+	}
+	if v, err := strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_MINUTES")); err == nil && v > 0 {
+		throttle.LockoutPeriod = time.Duration(v) * time.Minute
+	}
+	if v, err := strconv.Atoi(os.Getenv("LOGIN_IP_MAX_FAILURES")); err == nil && v > 0 {
+		throttle.IPFreeAttempts = v
+	}
+
+	return throttle
+}
+
+// backoff returns how long a client must wait after its latest failure,
+// doubling for every failure beyond the free attempts.
+func (t loginThrottle) backoff(failures, freeAttempts int) time.Duration {
+	if failures < freeAttempts || t.BaseDelay <= 0 {
+		return 0
+	}
+
+	exponent := float64(failures - freeAttempts)
+	delay := time.Duration(float64(t.BaseDelay) * math.Pow(2, exponent))
+	if delay <= 0 || delay > t.MaxDelay {
+		return t.MaxDelay
+	}
+
+	return delay
+}
+
+// retryAfter returns the time left before another attempt is allowed, or zero if one is allowed now.
+func (t loginThrottle) retryAfter(failures, freeAttempts int, lastFailure pgtype.Timestamptz, now time.Time) time.Duration {
+	if !lastFailure.Valid {
+		return 0
+	}
+
+	wait := lastFailure.Time.Add(t.backoff(failures, freeAttempts)).Sub(now)
+	if wait < 0 {
+		return 0
+	}
+
+	return wait
+}
+
+// recordLoginAttempt persists a login attempt for auditing.
+// Failing to record an attempt is logged but never blocks the login itself.
+func (s *Server) recordLoginAttempt(ctx context.Context, identifier string, userID uuid.UUID, ip, outcome string) {
+	if runes := []rune(identifier); len(runes) > 50 {
+		identifier = string(runes[:50])
+	}
+
+	params := database.CreateLoginAttemptParams{
+		Identifier: identifier,
+		UserID:     pgtype.UUID{Bytes: userID, Valid: userID != uuid.Nil},
+		IpAddress:  ip,
+		Outcome:    outcome,
+	}
+
+	if err := s.queries.CreateLoginAttempt(ctx, params); err != nil {
+		slog.Warn("failed to record login attempt", "error", err.Error())
+	}
+}
+
+// errAccountLocked is returned by reserveLoginAttempt while an account is locked.
+var errAccountLocked = errors.New("account locked after too many failed attempts")
+
+// reserveLoginAttempt counts an authentication step against an account before the password or code
+// is checked, so that a burst of parallel guesses can't all pass the backoff check on the same counter.
+// The attempt is only counted if the counter hasn't moved since the user was read; a client that
+// loses that race waits like a throttled one. It returns errAccountLocked or how long to wait when
+// the attempt isn't allowed, and otherwise whether the reserved failure locked the account.
+// A reserved attempt that turns out right is cleared by completeLogin or given back with releaseLoginAttempt.
+func (s *Server) reserveLoginAttempt(ctx context.Context, user LoginUser, now time.Time) (time.Duration, bool, error) {
+	if user.LockedUntil.Valid && user.LockedUntil.Time.After(now) {
+		return 0, false, errAccountLocked
+	}
+
+	if wait := s.throttle.retryAfter(int(user.FailedLoginAttempts), s.throttle.FreeAttempts, user.LastFailedLoginAt, now); wait > 0 {
+		return wait, false, nil
+	}
+
+	failed, err := s.queries.ReserveFailedLogin(ctx, database.ReserveFailedLoginParams{
+		MaxFailures:         int32(s.throttle.MaxFailures),
+		LockedUntil:         pgtype.Timestamptz{Time: now.Add(s.throttle.LockoutPeriod), Valid: true},
+		UserID:              user.UserID,
+		FailedLoginAttempts: user.FailedLoginAttempts,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Second, false, nil
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	return 0, failed.LockedUntil.Valid && failed.LockedUntil.Time.After(now), nil
+}
+
+// releaseLoginAttempt gives back the failure reserved for a password that was right,
+// while the user goes on to the second factor.
+func (s *Server) releaseLoginAttempt(ctx context.Context, userID uuid.UUID) error {
+	return s.queries.ReleaseFailedLogin(ctx, database.ReleaseFailedLoginParams{
+		MaxFailures: int32(s.throttle.MaxFailures),
+		UserID:      userID,
+	})
+}
+
+// writeTooManyAttempts responds with 429 and a Retry-After header.
+func writeTooManyAttempts(w http.ResponseWriter, r *http.Request, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	writeError(w, r, http.StatusTooManyRequests, fmt.Sprintf("too many login attempts, try again in %d seconds", seconds))
+}
+
+// ShowLockedAccounts renders the accounts with failed logins alongside the latest login attempts.
+func (s *Server) ShowLockedAccounts(w http.ResponseWriter, r *http.Request) {
+	lockedUsers, err := s.queries.ListLockedUsers(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get locked accounts")
+		slog.Error("failed to list locked accounts", "error", err.Error())
+		return
+	}
+
+	attempts, err := s.queries.ListRecentLoginAttempts(r.Context(), 50)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get login attempts")
+		slog.Error("failed to list login attempts", "error", err.Error())
+		return
+	}
+
+	s.renderComponent(w, r, userlist.LockedAccounts(lockedUsers, attempts))
+}
+
+// UnlockUser clears the failed login counter and any lockout on an account.
+func (s *Server) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid user id")
+		return
+	}
+
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		if err := qtx.ResetFailedLogins(r.Context(), userID); err != nil {
+			return err
+		}
+
+		after := map[string]any{"failed_login_attempts": 0, "locked_until": nil}
+		return recordAudit(r, qtx, auditUpdate, auditUser, userID, nil, after)
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to unlock account")
+		slog.Error("failed to unlock account", "error", err.Error())
+		return
+	}
+
+	s.ShowLockedAccounts(w, r)
+}
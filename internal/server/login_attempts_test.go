@@ -0,0 +1,145 @@
+// login_attempts_test.go
+package server
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+	"golang.org/x/crypto/bcrypt"
+)
+
+func TestLoginThrottle_Backoff(t *testing.T) {
+	throttle := loginThrottle{
+		BaseDelay: time.Second,
+		MaxDelay:  time.Minute,
+	}
+
+	tests := []struct {
+		failures int
+		want     time.Duration
+	}{
+		{failures: 0, want: 0},
+		{failures: 2, want: 0},
+		{failures: 3, want: time.Second},
+		{failures: 4, want: 2 * time.Second},
+		{failures: 6, want: 8 * time.Second},
+		{failures: 20, want: time.Minute},
+		{failures: 2000, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		if got := throttle.backoff(tt.failures, 3); got != tt.want {
+			t.Errorf("backoff(%d) = %s; want %s", tt.failures, got, tt.want)
+		}
+	}
+}
+
+func TestLoginThrottle_RetryAfter(t *testing.T) {
+	throttle := loginThrottle{
+		BaseDelay: time.Second,
+		MaxDelay:  time.Minute,
+	}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	if wait := throttle.retryAfter(5, 3, pgtype.Timestamptz{}, now); wait != 0 {
+		t.Errorf("expected no wait without a previous failure; got %s", wait)
+	}
+
+	lastFailure := pgtype.Timestamptz{Time: now.Add(-time.Second), Valid: true}
+	if wait := throttle.retryAfter(5, 3, lastFailure, now); wait != 3*time.Second {
+		t.Errorf("expected 3s wait; got %s", wait)
+	}
+
+	lastFailure = pgtype.Timestamptz{Time: now.Add(-time.Hour), Valid: true}
+	if wait := throttle.retryAfter(5, 3, lastFailure, now); wait != 0 {
+		t.Errorf("expected backoff to have elapsed; got %s", wait)
+	}
+}
+
+func TestReserveLoginAttempt(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	s := &Server{
+		queries:  database.New(mockConn),
+		throttle: loginThrottle{FreeAttempts: 3, MaxFailures: 10, LockoutPeriod: time.Minute, BaseDelay: time.Second, MaxDelay: time.Minute},
+	}
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	lockedUntil := pgtype.Timestamptz{Time: now.Add(time.Minute), Valid: true}
+	user := LoginUser{UserID: uuid.New(), FailedLoginAttempts: 9, LastFailedLoginAt: pgtype.Timestamptz{Time: now.Add(-time.Hour), Valid: true}}
+
+	// The failure is counted before the password is checked, and may lock the account.
+	mockConn.ExpectQuery("UPDATE users").
+		WithArgs(int32(10), lockedUntil, user.UserID, int32(9)).
+		WillReturnRows(pgxmock.NewRows([]string{"failed_login_attempts", "locked_until"}).AddRow(int32(10), lockedUntil))
+
+	if wait, locked, err := s.reserveLoginAttempt(context.Background(), user, now); err != nil || wait != 0 || !locked {
+		t.Errorf("expected the attempt to be reserved and lock the account; got wait=%s locked=%v err=%v", wait, locked, err)
+	}
+
+	// A parallel attempt that already moved the counter wins; this one has to wait.
+	mockConn.ExpectQuery("UPDATE users").
+		WithArgs(int32(10), lockedUntil, user.UserID, int32(9)).
+		WillReturnError(pgx.ErrNoRows)
+
+	if wait, _, err := s.reserveLoginAttempt(context.Background(), user, now); err != nil || wait <= 0 {
+		t.Errorf("expected a lost race to wait; got wait=%s err=%v", wait, err)
+	}
+
+	// Locked and backed off accounts are refused without counting anything.
+	if _, _, err := s.reserveLoginAttempt(context.Background(), LoginUser{UserID: user.UserID, LockedUntil: lockedUntil}, now); !errors.Is(err, errAccountLocked) {
+		t.Errorf("expected the account to be locked; got %v", err)
+	}
+
+	user.LastFailedLoginAt = pgtype.Timestamptz{Time: now, Valid: true}
+	if wait, _, err := s.reserveLoginAttempt(context.Background(), user, now); err != nil || wait <= 0 {
+		t.Errorf("expected a backoff; got wait=%s err=%v", wait, err)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestRecordLoginAttempt_TruncatesByRunes(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	s := &Server{queries: database.New(mockConn)}
+	identifier := strings.Repeat("é", 60)
+
+	mockConn.ExpectExec("INSERT INTO login_attempts").
+		WithArgs(strings.Repeat("é", 50), pgtype.UUID{}, "203.0.113.9", loginInvalidCredentials).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	s.recordLoginAttempt(context.Background(), identifier, uuid.Nil, "203.0.113.9", loginInvalidCredentials)
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestDummyPasswordHash_MatchesDefaultCost(t *testing.T) {
+	cost, err := bcrypt.Cost([]byte(dummyPasswordHash))
+	if err != nil {
+		t.Fatalf("dummy password hash is not a bcrypt hash: %v", err)
+	}
+	if cost != bcrypt.DefaultCost {
+		t.Errorf("expected the dummy hash to cost %d like real passwords; got %d", bcrypt.DefaultCost, cost)
+	}
+}
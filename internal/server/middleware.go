@@ -5,9 +5,12 @@ import (
 	"errors"
 	"log/slog"
 	"net/http"
+	"os"
+	"slices"
 	"time"
 
 	"school_management_system/internal/cookies"
+	"school_management_system/internal/csrf"
 	"school_management_system/internal/database"
 
 	"github.com/google/uuid"
@@ -19,23 +22,52 @@ type contextKey string
 const (
 	sessionIDKey   contextKey = "session_id"
 	userContextKey contextKey = "user"
+	apiTokenIDKey  contextKey = "api_token_id"
 )
 
-// User represents the authenticated user along with their role.
+// passwordChangeExempt lists the paths a user who must change their password can still reach.
+var passwordChangeExempt = map[string]bool{
+	"/settings/password": true,
+	"/profile":           true,
+	"/logout":            true,
+	"/logout/confirm":    true,
+	"/logout/cancel":     true,
+}
+
+// twoFactorSetupExempt lists the paths a user whose role requires two-factor authentication
+// can reach before enrolling.
+var twoFactorSetupExempt = map[string]bool{
+	"/settings/user":     true,
+	"/settings/user/2fa": true,
+	"/profile":           true,
+	"/logout":            true,
+	"/logout/confirm":    true,
+	"/logout/cancel":     true,
+}
+
+// User represents the authenticated user along with their roles and the permissions they grant.
+// Role is the primary role shown around the app; Roles includes it.
 type User struct {
-	Role   string
-	UserID uuid.UUID
+	Role        string
+	UserID      uuid.UUID
+	Roles       []string
+	Permissions []string
+}
+
+// Can reports whether the user holds the given permission through any of their roles.
+func (u User) Can(permission string) bool {
+	return slices.Contains(u.Permissions, permission)
 }
 
-// refreshSession method updates a near session in the database if its near expiry
+// refreshSession method rotates a session in the database if its near expiry
 func (s *Server) refreshSession(ctx context.Context, session database.GetSessionRow) (uuid.UUID, error) {
 	newExpiry := pgtype.Timestamptz{Time: time.Now().Add(2 * 7 * 24 * time.Hour), Valid: true}
 	newSessionID := uuid.New()
 
 	refreshParams := database.RefreshSessionParams{
-		UserID:    session.UserID,
-		Expires:   newExpiry,
-		SessionID: newSessionID,
+		Expires:      newExpiry,
+		NewSessionID: newSessionID,
+		SessionID:    session.SessionID,
 	}
 
 	if err := s.queries.RefreshSession(ctx, refreshParams); err != nil {
@@ -48,19 +80,19 @@ func (s *Server) refreshSession(ctx context.Context, session database.GetSession
 // AuthMiddleware ensures the user is authenticated and loads the user (with role) into the context.
 func (s *Server) AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		sessionID, err := cookies.ReadEncrypted(r, "sessionid", s.SecretKey)
+		sessionID, stale, err := s.Keys.ReadEncrypted(r, "sessionid")
 		if err != nil {
 			if errors.Is(err, http.ErrNoCookie) || errors.Is(err, cookies.ErrInvalidValue) {
 				http.Redirect(w, r, "/login", http.StatusFound)
 				return
 			}
-			writeError(w, http.StatusInternalServerError, "internal server error")
+			writeError(w, r, http.StatusInternalServerError, "internal server error")
 			return
 		}
 
 		parsedSessionID, err := uuid.Parse(sessionID)
 		if err != nil {
-			writeError(w, http.StatusBadRequest, "invalid session ID")
+			writeError(w, r, http.StatusBadRequest, "invalid session ID")
 			return
 		}
 
@@ -80,52 +112,85 @@ func (s *Server) AuthMiddleware(next http.Handler) http.Handler {
 		if timeLeft < 24*time.Hour {
 			newSessionID, err := s.refreshSession(r.Context(), session)
 			if err != nil {
-				writeError(w, http.StatusInternalServerError, "internal server error")
+				writeError(w, r, http.StatusInternalServerError, "internal server error")
 				return
 			}
 
 			cookie := createSessionCookie(newSessionID)
 
-			if err := cookies.WriteEncrypted(w, cookie, s.SecretKey); err != nil {
-				writeError(w, http.StatusInternalServerError, "internal server error")
+			if err := s.Keys.WriteEncrypted(w, cookie); err != nil {
+				writeError(w, r, http.StatusInternalServerError, "internal server error")
 				return
 			}
 
 			r = r.WithContext(context.WithValue(r.Context(), sessionIDKey, newSessionID))
 		} else {
+			if err := s.queries.TouchSession(r.Context(), session.SessionID); err != nil {
+				slog.Warn("failed to update session last seen", "error", err.Error())
+			}
+
+			// Move cookies sealed with a previous secret key over to the current one.
+			if stale {
+				if err := s.Keys.WriteEncrypted(w, createSessionCookie(session.SessionID)); err != nil {
+					writeError(w, r, http.StatusInternalServerError, "internal server error")
+					return
+				}
+			}
 			r = r.WithContext(context.WithValue(r.Context(), sessionIDKey, session.SessionID))
 		}
 
 		user := User{
-			UserID: session.UserID,
-			Role:   session.Role,
+			UserID:      session.UserID,
+			Role:        session.Role,
+			Roles:       session.Roles,
+			Permissions: session.Permissions,
 		}
 
 		r = r.WithContext(context.WithValue(r.Context(), userContextKey, user))
 
 		w.Header().Add("Cache-Control", "no-store")
+
+		if session.MustChangePassword && !passwordChangeExempt[r.URL.Path] {
+			if r.Header.Get("HX-Request") != "" {
+				w.Header().Set("HX-Redirect", "/settings/password")
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			http.Redirect(w, r, "/settings/password", http.StatusFound)
+			return
+		}
+
+		// A pending password change comes first: the pages it leaves open can't send the user on
+		// to set up two-factor authentication, or the two would send them back and forth.
+		if !session.MustChangePassword && s.twoFactor.Required(session.Roles...) && !session.TotpEnabled && !twoFactorSetupExempt[r.URL.Path] {
+			if r.Header.Get("HX-Request") != "" {
+				w.Header().Set("HX-Redirect", "/settings/user")
+				w.WriteHeader(http.StatusOK)
+				return
+			}
+
+			http.Redirect(w, r, "/settings/user", http.StatusFound)
+			return
+		}
+
 		next.ServeHTTP(w, r)
 	})
 }
 
-// RequireRoles returns a middleware that allows access only if the user's role
-// is in the provided list of allowed roles.
-func (s *Server) RequireRoles(allowedRoles ...string) func(http.Handler) http.Handler {
-	allowed := make(map[string]struct{})
-	for _, role := range allowedRoles {
-		allowed[role] = struct{}{}
-	}
-
+// RequirePermission returns a middleware that allows access only if one of the user's roles
+// grants the given permission.
+func (s *Server) RequirePermission(permission string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 			user, ok := r.Context().Value(userContextKey).(User)
 			if !ok {
-				writeError(w, http.StatusUnauthorized, "user not authenticated")
+				writeError(w, r, http.StatusUnauthorized, "user not authenticated")
 				return
 			}
 
-			if _, exists := allowed[user.Role]; !exists {
-				writeError(w, http.StatusForbidden, "forbidden")
+			if !user.Can(permission) {
+				writeError(w, r, http.StatusForbidden, "forbidden")
 				return
 			}
 
@@ -135,10 +200,48 @@ func (s *Server) RequireRoles(allowedRoles ...string) func(http.Handler) http.Ha
 	}
 }
 
+// csrfProtect issues each browser a CSRF token and rejects state-changing requests
+// that do not send it back in the X-CSRF-Token header or the csrf_token form field.
+// Requests authenticated with an API token are left alone since browsers never send those on their own.
+func csrfProtect(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Value(apiTokenIDKey).(uuid.UUID); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		token, err := csrf.Read(r)
+		if err != nil {
+			token, err = csrf.NewToken()
+			if err != nil {
+				writeError(w, r, http.StatusInternalServerError, "internal server error")
+				slog.Error("failed to generate csrf token", "error", err.Error())
+				return
+			}
+
+			if err := csrf.Write(w, token, os.Getenv("ENV") == "production"); err != nil {
+				writeError(w, r, http.StatusInternalServerError, "internal server error")
+				slog.Error("failed to write csrf cookie", "error", err.Error())
+				return
+			}
+		}
+
+		if !csrf.Safe(r.Method) {
+			if err := csrf.Verify(r); err != nil {
+				slog.Warn("rejected request without a valid csrf token", "path", r.URL.Path, "error", err.Error())
+				writeError(w, r, http.StatusForbidden, "invalid or missing CSRF token, reload the page and try again")
+				return
+			}
+		}
+
+		next.ServeHTTP(w, r.WithContext(csrf.WithToken(r.Context(), token)))
+	})
+}
+
 // RedirectIfAuthenticated checks if a user is already logged in and redirects them to the home page
 func (s *Server) RedirectIfAuthenticated(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		sessionID, err := cookies.ReadEncrypted(r, "sessionid", s.SecretKey)
+		sessionID, _, err := s.Keys.ReadEncrypted(r, "sessionid")
 		if err == nil {
 			parsedSessionID, parseErr := uuid.Parse(sessionID)
 			if parseErr == nil {
@@ -3,15 +3,24 @@ package server
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
+	"school_management_system/internal/cookies"
+	"school_management_system/internal/csrf"
 	"school_management_system/internal/database"
 
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 	"github.com/pashagolub/pgxmock/v4"
 )
 
@@ -28,6 +37,15 @@ func generateKey() []byte {
 	return secretKey
 }
 
+// testKeyring returns a cookie keyring holding only the test secret key.
+func testKeyring() *cookies.Keyring {
+	keys, err := cookies.NewKeyring(generateKey())
+	if err != nil {
+		panic(err)
+	}
+	return keys
+}
+
 // --- AuthMiddleware Tests ---
 
 func TestAuthMiddleware_NoSessionCookie(t *testing.T) {
@@ -39,8 +57,8 @@ func TestAuthMiddleware_NoSessionCookie(t *testing.T) {
 
 	queries := database.New(mockConn)
 	s := &Server{
-		SecretKey: generateKey(),
-		queries:   queries,
+		Keys:    testKeyring(),
+		queries: queries,
 	}
 
 	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -65,6 +83,61 @@ func TestAuthMiddleware_NoSessionCookie(t *testing.T) {
 	}
 }
 
+// TestAuthMiddleware_PasswordChangeBeforeTwoFactor checks a new user who must both change their
+// password and set up two-factor authentication is sent to change the password first, and can
+// reach that page instead of being sent back and forth between the two.
+func TestAuthMiddleware_PasswordChangeBeforeTwoFactor(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	keys := testKeyring()
+	s := &Server{
+		Keys:      keys,
+		queries:   database.New(mockConn),
+		twoFactor: twoFactorPolicy{RequiredRoles: map[string]bool{"admin": true}},
+	}
+
+	h := s.AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "next called")
+	}))
+
+	sessionID, userID := uuid.New(), uuid.New()
+	rec := httptest.NewRecorder()
+	if err := keys.WriteEncrypted(rec, createSessionCookie(sessionID)); err != nil {
+		t.Fatalf("failed to write session cookie: %v", err)
+	}
+	cookie := rec.Result().Cookies()[0]
+
+	for path, want := range map[string]string{"/dashboard": "/settings/password", "/settings/user": "/settings/password", "/settings/password": ""} {
+		mockConn.ExpectQuery("SELECT").WithArgs(sessionID).
+			WillReturnRows(pgxmock.NewRows([]string{"user_id", "session_id", "role", "expires", "must_change_password", "totp_enabled", "roles", "permissions"}).
+				AddRow(userID, sessionID, "admin", pgtype.Timestamptz{Time: time.Now().Add(7 * 24 * time.Hour), Valid: true}, true, false, []string{"admin"}, []string{}))
+		mockConn.ExpectExec("UPDATE sessions").WithArgs(sessionID).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+		req := httptest.NewRequest("GET", path, nil)
+		req.AddCookie(cookie)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+
+		if want == "" {
+			if rec.Code != http.StatusOK || rec.Body.String() != "next called" {
+				t.Errorf("%s: expected the page to be served; got %d %q", path, rec.Code, rec.Header().Get("Location"))
+			}
+			continue
+		}
+		if loc := rec.Header().Get("Location"); rec.Code != http.StatusFound || loc != want {
+			t.Errorf("%s: expected a redirect to %s; got %d %q", path, want, rec.Code, loc)
+		}
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
 func TestRedirectIfAuthenticated_NoSession(t *testing.T) {
 	mockConn, err := pgxmock.NewConn()
 	if err != nil {
@@ -74,8 +147,8 @@ func TestRedirectIfAuthenticated_NoSession(t *testing.T) {
 
 	queries := database.New(mockConn)
 	s := &Server{
-		SecretKey: generateKey(),
-		queries:   queries,
+		Keys:    testKeyring(),
+		queries: queries,
 	}
 
 	nextCalled := false
@@ -125,3 +198,182 @@ func TestSecureHeaders(t *testing.T) {
 		}
 	}
 }
+
+// --- RequirePermission Tests ---
+
+func TestRequirePermission(t *testing.T) {
+	s := &Server{}
+
+	tests := []struct {
+		name           string
+		user           *User
+		expectedStatus int
+	}{
+		{
+			name:           "unauthenticated",
+			user:           nil,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "role without the permission",
+			user:           &User{Role: "teacher", Roles: []string{"teacher"}, Permissions: []string{"dashboard.view", "grades.submit"}},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "secondary role grants the permission",
+			user:           &User{Role: "teacher", Roles: []string{"accountant", "teacher"}, Permissions: []string{"dashboard.view", "fees.edit", "fees.view", "grades.submit"}},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "next called")
+			})
+			h := s.RequirePermission("fees.edit")(nextHandler)
+
+			req := httptest.NewRequest("PUT", "/fees/edit/1", nil)
+			if tt.user != nil {
+				req = req.WithContext(context.WithValue(req.Context(), userContextKey, *tt.user))
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d; got %d", tt.expectedStatus, rec.Code)
+			}
+		})
+	}
+}
+
+// --- csrfProtect Tests ---
+
+func TestCSRFProtect_IssuesToken(t *testing.T) {
+	var rendered string
+	nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rendered = csrf.Token(r.Context())
+	})
+	h := csrfProtect(nextHandler)
+
+	req := httptest.NewRequest("GET", "/login", nil)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status %d; got %d", http.StatusOK, rec.Code)
+	}
+
+	var issued *http.Cookie
+	for _, c := range rec.Result().Cookies() {
+		if c.Name == csrf.CookieName {
+			issued = c
+		}
+	}
+	if issued == nil {
+		t.Fatal("expected a csrf cookie to be issued")
+	}
+	if !issued.HttpOnly || issued.SameSite != http.SameSiteStrictMode {
+		t.Errorf("expected an HttpOnly, SameSite=Strict cookie; got %+v", issued)
+	}
+
+	value, err := base64.URLEncoding.DecodeString(issued.Value)
+	if err != nil {
+		t.Fatalf("failed to decode cookie: %v", err)
+	}
+	if rendered == "" || rendered != string(value) {
+		t.Errorf("expected templates to receive the cookie token %q; got %q", value, rendered)
+	}
+}
+
+func TestCSRFProtect_StateChangingRequests(t *testing.T) {
+	token, err := csrf.NewToken()
+	if err != nil {
+		t.Fatalf("failed to generate token: %v", err)
+	}
+	other, _ := csrf.NewToken()
+
+	tests := []struct {
+		name           string
+		method         string
+		cookie         string
+		header         string
+		form           string
+		expectedStatus int
+	}{
+		{
+			name:           "safe method without token",
+			method:         "GET",
+			cookie:         token,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "no cookie",
+			method:         "POST",
+			header:         token,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "no submitted token",
+			method:         "PUT",
+			cookie:         token,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "mismatched header",
+			method:         "DELETE",
+			cookie:         token,
+			header:         other,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "matching header",
+			method:         "DELETE",
+			cookie:         token,
+			header:         token,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "matching form field",
+			method:         "POST",
+			cookie:         token,
+			form:           token,
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			nextHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				fmt.Fprint(w, "next called")
+			})
+			h := csrfProtect(nextHandler)
+
+			var body io.Reader
+			if tt.form != "" {
+				body = strings.NewReader(url.Values{csrf.FieldName: {tt.form}}.Encode())
+			}
+			req := httptest.NewRequest(tt.method, "/logout", body)
+			if tt.form != "" {
+				req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			}
+			if tt.header != "" {
+				req.Header.Set(csrf.HeaderName, tt.header)
+			}
+			if tt.cookie != "" {
+				req.AddCookie(&http.Cookie{Name: csrf.CookieName, Value: base64.URLEncoding.EncodeToString([]byte(tt.cookie))})
+			}
+			rec := httptest.NewRecorder()
+
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.expectedStatus {
+				t.Errorf("expected status %d; got %d", tt.expectedStatus, rec.Code)
+			}
+			if tt.expectedStatus == http.StatusForbidden && !strings.Contains(rec.Body.String(), "CSRF token") {
+				t.Errorf("expected a CSRF error message; got %q", rec.Body.String())
+			}
+		})
+	}
+}
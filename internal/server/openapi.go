@@ -0,0 +1,322 @@
+package server
+
+import (
+	"net/http"
+	"os"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// apiOperation describes one JSON endpoint in the OpenAPI document.
+// Request and Response hold zero values of the Go types sent and returned; their schemas are
+// generated from the types so the document follows the code.
+type apiOperation struct {
+	Method     string
+	Path       string
+	Tag        string
+	Summary    string
+	Permission string
+	Query      []apiQueryParam
+	Request    any
+	Response   any
+	Status     int
+	// Session is true for endpoints that also accept the browser session cookie.
+	Session bool
+}
+
+// apiQueryParam is an optional query string parameter.
+type apiQueryParam struct {
+	Name        string
+	Description string
+	Format      string
+}
+
+var (
+	classIDFilter   = apiQueryParam{Name: "class_id", Description: "Only include this class.", Format: "uuid"}
+	termIDFilter    = apiQueryParam{Name: "term_id", Description: "The term to list; defaults to the active term.", Format: "uuid"}
+	textQueryFilter = apiQueryParam{Name: "q", Description: "Case-insensitive text search."}
+)
+
+func listOp[T any](path, tag, summary, permission string, filters ...apiQueryParam) apiOperation {
+	return apiOperation{Method: http.MethodGet, Path: path, Tag: tag, Summary: summary, Permission: permission, Query: filters, Response: APIList[T]{}, Status: http.StatusOK}
+}
+
+func getOp[T any](path, tag, summary, permission string) apiOperation {
+	return apiOperation{Method: http.MethodGet, Path: path, Tag: tag, Summary: summary, Permission: permission, Response: APIItem[T]{}, Status: http.StatusOK}
+}
+
+// apiOperations lists every JSON endpoint. TestOpenAPICoversRoutes fails when a route
+// registered under /api/v1 is missing here.
+var apiOperations = []apiOperation{
+	listOp[APIUser]("/api/v1/users", "Users", "List users", "users.manage",
+		apiQueryParam{Name: "role", Description: "Only include users whose primary role has this name."}, textQueryFilter),
+	getOp[APIUser]("/api/v1/users/{id}", "Users", "Get a user", "users.manage"),
+
+	listOp[database.ListAPIStudentsRow]("/api/v1/students", "Students", "List students", "students.manage",
+		classIDFilter, apiQueryParam{Name: "status", Description: "Only include students with this status."}, textQueryFilter),
+	getOp[database.GetStudentRow]("/api/v1/students/{id}", "Students", "Get a student", "students.manage"),
+
+	listOp[database.ListAPIGuardiansRow]("/api/v1/guardians", "Guardians", "List guardians with their students", "guardians.manage", textQueryFilter),
+	getOp[database.Guardian]("/api/v1/guardians/{id}", "Guardians", "Get a guardian", "guardians.manage"),
+
+	listOp[database.Class]("/api/v1/classes", "Academics", "List classes", "academics.manage", textQueryFilter),
+	getOp[database.Class]("/api/v1/classes/{id}", "Academics", "Get a class", "academics.manage"),
+	listOp[database.ListAPISubjectsRow]("/api/v1/subjects", "Academics", "List subjects", "academics.manage", classIDFilter, textQueryFilter),
+	getOp[database.Subject]("/api/v1/subjects/{id}", "Academics", "Get a subject", "academics.manage"),
+	listOp[database.ListAPITermsRow]("/api/v1/terms", "Academics", "List terms", "academics.manage",
+		apiQueryParam{Name: "academic_year_id", Description: "Only include terms of this academic year.", Format: "uuid"},
+		apiQueryParam{Name: "active", Description: "true or false."}),
+	getOp[database.GetCurrentTermRow]("/api/v1/terms/current", "Academics", "Get the active term", "academics.manage"),
+
+	listOp[database.StudentGradesView]("/api/v1/grades", "Grades", "List students with their grades keyed by subject ID, in the classes the user teaches", "grades.submit",
+		classIDFilter, apiQueryParam{Name: "student_id", Description: "Only include this student.", Format: "uuid"}),
+	{
+		Method:     http.MethodPost,
+		Path:       "/grades/submit",
+		Tag:        "Grades",
+		Summary:    "Save grades for a class and term, replacing existing scores",
+		Permission: "grades.submit",
+		Request:    GradeSubmission{},
+		Status:     http.StatusCreated,
+		Session:    true,
+	},
+
+	listOp[database.ListAPIFeesRow]("/api/v1/fees", "Fees", "List fee records for a term", "fees.view",
+		termIDFilter, classIDFilter, apiQueryParam{Name: "status", Description: "Only include records with this status."}),
+	getOp[database.GetFeesRecordRow]("/api/v1/fees/{id}", "Fees", "Get a fee record", "fees.view"),
+
+	listOp[database.ListAPIRemarksRow]("/api/v1/remarks", "Remarks", "List remarks for the classes the caller teaches", "remarks.submit", termIDFilter, textQueryFilter),
+
+	listOp[database.ListAPIDisciplineRow]("/api/v1/discipline", "Discipline", "List disciplinary records", "discipline.manage", textQueryFilter),
+}
+
+var pathParamPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+// openAPIDocument is built once, the first time it is requested.
+var openAPIDocument = sync.OnceValue(func() map[string]any {
+	return buildOpenAPI(apiOperations)
+})
+
+// buildOpenAPI returns an OpenAPI 3 document for the operations.
+func buildOpenAPI(operations []apiOperation) map[string]any {
+	schemas := newSchemaRegistry()
+	errorResponse := map[string]any{
+		"description": "Error",
+		"content": map[string]any{
+			"application/json": map[string]any{"schema": schemas.schemaFor(reflect.TypeOf(APIError{}))},
+		},
+	}
+
+	paths := map[string]any{}
+	for _, op := range operations {
+		var parameters []any
+		for _, match := range pathParamPattern.FindAllStringSubmatch(op.Path, -1) {
+			parameters = append(parameters, map[string]any{
+				"name": match[1], "in": "path", "required": true,
+				"schema": map[string]any{"type": "string", "format": "uuid"},
+			})
+		}
+		if op.Response != nil && strings.HasPrefix(reflect.TypeOf(op.Response).String(), "server.APIList[") {
+			parameters = append(parameters,
+				map[string]any{"name": "page", "in": "query", "description": "Page number, starting at 1.", "schema": map[string]any{"type": "integer", "minimum": 1, "default": 1}},
+				map[string]any{"name": "per_page", "in": "query", "description": "Results per page.", "schema": map[string]any{"type": "integer", "minimum": 1, "maximum": apiMaxPerPage, "default": apiDefaultPerPage}},
+			)
+		}
+		for _, q := range op.Query {
+			schema := map[string]any{"type": "string"}
+			if q.Format != "" {
+				schema["format"] = q.Format
+			}
+			parameters = append(parameters, map[string]any{"name": q.Name, "in": "query", "description": q.Description, "schema": schema})
+		}
+
+		success := map[string]any{"description": http.StatusText(op.Status)}
+		if op.Response != nil {
+			success["content"] = map[string]any{
+				"application/json": map[string]any{"schema": schemas.schemaFor(reflect.TypeOf(op.Response))},
+			}
+		}
+
+		security := []any{map[string]any{"bearerAuth": []any{}}}
+		if op.Session {
+			security = append(security, map[string]any{"sessionCookie": []any{}})
+		}
+
+		operation := map[string]any{
+			"tags":        []string{op.Tag},
+			"summary":     op.Summary,
+			"description": "Requires the `" + op.Permission + "` permission.",
+			"security":    security,
+			"responses": map[string]any{
+				strconv.Itoa(op.Status): success,
+				"400":                   errorResponse,
+				"401":                   errorResponse,
+				"403":                   errorResponse,
+			},
+		}
+		if len(parameters) > 0 {
+			operation["parameters"] = parameters
+		}
+		if len(pathParamPattern.FindAllString(op.Path, -1)) > 0 {
+			operation["responses"].(map[string]any)["404"] = errorResponse
+		}
+		if op.Request != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					"application/json": map[string]any{"schema": schemas.schemaFor(reflect.TypeOf(op.Request))},
+				},
+			}
+		}
+
+		item, _ := paths[op.Path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[op.Path] = item
+		}
+		item[strings.ToLower(op.Method)] = operation
+	}
+
+	title := os.Getenv("PROJECT_NAME")
+	if title == "" {
+		title = "School Management System"
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       strings.Trim(title, `'"`) + " API",
+			"version":     "1.0.0",
+			"description": "JSON API for integrating other systems. Create an API token from the settings page and send it as `Authorization: Bearer <token>`.",
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas.components,
+			"securitySchemes": map[string]any{
+				"bearerAuth":    map[string]any{"type": "http", "scheme": "bearer"},
+				"sessionCookie": map[string]any{"type": "apiKey", "in": "cookie", "name": "sessionid"},
+			},
+		},
+	}
+}
+
+// schemaRegistry turns Go types into OpenAPI schemas. Named structs become components
+// referenced by name; everything else is inlined.
+type schemaRegistry struct {
+	components map[string]any
+	names      map[reflect.Type]string
+}
+
+func newSchemaRegistry() *schemaRegistry {
+	return &schemaRegistry{components: map[string]any{}, names: map[reflect.Type]string{}}
+}
+
+// wellKnownSchemas maps types that marshal to JSON scalars to their schemas.
+var wellKnownSchemas = map[reflect.Type]map[string]any{
+	reflect.TypeOf(uuid.UUID{}):          {"type": "string", "format": "uuid"},
+	reflect.TypeOf(pgtype.UUID{}):        {"type": "string", "format": "uuid", "nullable": true},
+	reflect.TypeOf(pgtype.Text{}):        {"type": "string", "nullable": true},
+	reflect.TypeOf(pgtype.Date{}):        {"type": "string", "format": "date", "nullable": true},
+	reflect.TypeOf(pgtype.Timestamptz{}): {"type": "string", "format": "date-time", "nullable": true},
+	reflect.TypeOf(pgtype.Numeric{}):     {"type": "number", "nullable": true},
+	reflect.TypeOf(pgtype.Int4{}):        {"type": "integer", "nullable": true},
+	reflect.TypeOf(pgtype.Bool{}):        {"type": "boolean", "nullable": true},
+	reflect.TypeOf(time.Time{}):          {"type": "string", "format": "date-time"},
+}
+
+func (g *schemaRegistry) schemaFor(t reflect.Type) map[string]any {
+	if schema, ok := wellKnownSchemas[t]; ok {
+		return schema
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.schemaFor(t.Elem())
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": g.schemaFor(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": g.schemaFor(t.Elem())}
+	case reflect.Struct:
+		// Generic instantiations such as APIList[T] are inlined rather than named.
+		if t.Name() == "" || strings.Contains(t.Name(), "[") {
+			return g.structSchema(t)
+		}
+
+		name, ok := g.names[t]
+		if !ok {
+			name = g.componentName(t)
+			g.names[t] = name
+			g.components[name] = g.structSchema(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + name}
+	}
+
+	return map[string]any{}
+}
+
+// componentName returns the type's name, qualified with its package if another package
+// already uses the name.
+func (g *schemaRegistry) componentName(t reflect.Type) string {
+	name := t.Name()
+	if _, taken := g.components[name]; taken {
+		pkg := t.PkgPath()[strings.LastIndex(t.PkgPath(), "/")+1:]
+		name = strings.ToUpper(pkg[:1]) + pkg[1:] + name
+	}
+
+	return name
+}
+
+func (g *schemaRegistry) structSchema(t reflect.Type) map[string]any {
+	properties := map[string]any{}
+	var required []string
+
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		properties[name] = g.schemaFor(field.Type)
+		if !strings.Contains(opts, "omitempty") {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]any{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+
+	return schema
+}
+
+// OpenAPISpec serves the OpenAPI document for the JSON API.
+func (s *Server) OpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, openAPIDocument())
+}
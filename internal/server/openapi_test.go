@@ -0,0 +1,151 @@
+// openapi_test.go
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// registeredRoutes maps every "METHOD path" registered by RegisterRoutes to the name of its handler.
+func registeredRoutes(t *testing.T) map[string]string {
+	t.Helper()
+
+	router, ok := (&Server{}).RegisterRoutes().(chi.Routes)
+	if !ok {
+		t.Fatal("RegisterRoutes did not return a chi router")
+	}
+
+	routes := map[string]string{}
+	err := chi.Walk(router, func(method, route string, handler http.Handler, _ ...func(http.Handler) http.Handler) error {
+		if chain, ok := handler.(*chi.ChainHandler); ok {
+			handler = chain.Endpoint
+		}
+		routes[method+" "+strings.TrimSuffix(route, "/")] = handlerName(handler)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk routes: %v", err)
+	}
+
+	return routes
+}
+
+// handlerName returns the name of the function behind a handler, or "" for handlers that aren't plain funcs.
+func handlerName(handler any) string {
+	if h, ok := handler.(http.HandlerFunc); ok {
+		handler = (func(http.ResponseWriter, *http.Request))(h)
+	}
+
+	v := reflect.ValueOf(handler)
+	if v.Kind() != reflect.Func {
+		return ""
+	}
+
+	return runtime.FuncForPC(v.Pointer()).Name()
+}
+
+// jsonHandlers returns the names of the handlers that take or return JSON, all of which belong in the OpenAPI document.
+func jsonHandlers() map[string]bool {
+	s := &Server{}
+	handlers := []http.HandlerFunc{
+		s.apiListUsers, s.apiGetUser,
+		s.apiListStudents, s.apiGetStudent,
+		s.apiListGuardians, s.apiGetGuardian,
+		s.apiListClasses, s.apiGetClass,
+		s.apiListSubjects, s.apiGetSubject,
+		s.apiListTerms, s.apiGetCurrentTerm,
+		s.apiListGrades, s.SubmitGrades,
+		s.apiListFees, s.apiGetFeesRecord,
+		s.apiListRemarks,
+		s.apiListDiscipline,
+	}
+
+	names := map[string]bool{}
+	for _, h := range handlers {
+		names[handlerName(h)] = true
+	}
+
+	return names
+}
+
+func TestOpenAPICoversRoutes(t *testing.T) {
+	routes := registeredRoutes(t)
+	handlers := jsonHandlers()
+
+	documented := map[string]bool{}
+	for _, op := range apiOperations {
+		documented[op.Method+" "+op.Path] = true
+	}
+
+	registered := map[string]bool{}
+	for route, handler := range routes {
+		registered[handler] = true
+
+		_, path, _ := strings.Cut(route, " ")
+		if (handlers[handler] || strings.HasPrefix(path, "/api/v1/")) && !documented[route] {
+			t.Errorf("JSON route %s is registered but missing from the OpenAPI document", route)
+		}
+	}
+
+	for handler := range handlers {
+		if !registered[handler] {
+			t.Errorf("JSON handler %s is not registered on any route", handler)
+		}
+	}
+
+	for op := range documented {
+		if _, ok := routes[op]; !ok {
+			t.Errorf("OpenAPI document describes %s, which is not registered", op)
+		}
+	}
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	s := &Server{}
+
+	req := httptest.NewRequest("GET", "/api/openapi.json", nil)
+	rec := executeRequest(req, s)
+
+	checkResponseCode(t, http.StatusOK, rec.Code)
+
+	var spec struct {
+		OpenAPI    string                               `json:"openapi"`
+		Paths      map[string]map[string]map[string]any `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]any `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&spec); err != nil {
+		t.Fatalf("failed to decode spec: %v", err)
+	}
+
+	if !strings.HasPrefix(spec.OpenAPI, "3.") {
+		t.Errorf("expected an OpenAPI 3 document; got %q", spec.OpenAPI)
+	}
+	if _, ok := spec.Paths["/grades/submit"]["post"]["requestBody"]; !ok {
+		t.Error("expected /grades/submit to document its request body")
+	}
+
+	submission, ok := spec.Components.Schemas["GradeSubmission"]
+	if !ok {
+		t.Fatal("expected a GradeSubmission schema")
+	}
+	for _, field := range []string{"class_id", "term_id", "grades"} {
+		if _, ok := submission.Properties[field]; !ok {
+			t.Errorf("expected GradeSubmission to have a %q property", field)
+		}
+	}
+
+	if _, ok := spec.Components.Schemas["APIUser"].Properties["password"]; ok {
+		t.Error("APIUser must not expose a password")
+	}
+}
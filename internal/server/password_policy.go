@@ -0,0 +1,54 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// passwordPolicy describes the rules a password chosen by a user must satisfy.
+// Generated passwords handed out by admins are exempt since they must be changed on first login.
+type passwordPolicy struct {
+	MinLength        int
+	AllowNumericOnly bool
+	AllowPhoneNumber bool
+}
+
+// loadPasswordPolicy reads the password policy from the environment, falling back to defaults.
+func loadPasswordPolicy() passwordPolicy {
+	policy := passwordPolicy{
+		MinLength: 8,
+	}
+
+	if v, err := strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH")); err == nil && v > 0 {
+		policy.MinLength = v
+	}
+	if v, err := strconv.ParseBool(os.Getenv("PASSWORD_ALLOW_NUMERIC_ONLY")); err == nil {
+		policy.AllowNumericOnly = v
+	}
+	if v, err := strconv.ParseBool(os.Getenv("PASSWORD_ALLOW_PHONE_NUMBER")); err == nil {
+		policy.AllowPhoneNumber = v
+	}
+
+	return policy
+}
+
+// Validate returns an error describing the first rule the password breaks, if any.
+func (p passwordPolicy) Validate(password, phoneNumber string) error {
+	if len([]rune(password)) < p.MinLength {
+		return fmt.Errorf("password must be at least %d characters long", p.MinLength)
+	}
+
+	if !p.AllowNumericOnly && strings.IndexFunc(password, func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+		return errors.New("password must not contain only numbers")
+	}
+
+	if !p.AllowPhoneNumber && phoneNumber != "" && strings.Contains(password, phoneNumber) {
+		return errors.New("password must not contain your phone number")
+	}
+
+	return nil
+}
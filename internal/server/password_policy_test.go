@@ -0,0 +1,38 @@
+// password_policy_test.go
+package server
+
+import "testing"
+
+func TestPasswordPolicy_Validate(t *testing.T) {
+	policy := passwordPolicy{MinLength: 8}
+
+	tests := []struct {
+		name     string
+		password string
+		phone    string
+		wantErr  bool
+	}{
+		{name: "valid", password: "blue-river-42", phone: "265123456789", wantErr: false},
+		{name: "too short", password: "ab12", phone: "", wantErr: true},
+		{name: "numeric only", password: "12345678", phone: "", wantErr: true},
+		{name: "phone number", password: "265123456789", phone: "265123456789", wantErr: true},
+		{name: "contains phone number", password: "x265123456789", phone: "265123456789", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Validate(tt.password, tt.phone)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Validate(%q) error = %v; wantErr %v", tt.password, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPasswordPolicy_Relaxed(t *testing.T) {
+	policy := passwordPolicy{MinLength: 6, AllowNumericOnly: true, AllowPhoneNumber: true}
+
+	if err := policy.Validate("123456", "123456"); err != nil {
+		t.Errorf("expected relaxed policy to accept numeric password; got %v", err)
+	}
+}
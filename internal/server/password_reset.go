@@ -0,0 +1,228 @@
+package server
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"school_management_system/cmd/web/dashboard/userlist"
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// resetCodeAlphabet leaves out characters that are easily confused when read aloud or copied by hand.
+const resetCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+
+const resetCodeLength = 8
+
+// resetCodeTTL returns how long a reset code stays valid, configurable via RESET_CODE_TTL_MINUTES.
+func resetCodeTTL() time.Duration {
+	if v, err := strconv.Atoi(os.Getenv("RESET_CODE_TTL_MINUTES")); err == nil && v > 0 {
+		return time.Duration(v) * time.Minute
+	}
+
+	return 30 * time.Minute
+}
+
+// generateResetCode returns a random code formatted as XXXX-XXXX.
+func generateResetCode() (string, error) {
+	var code strings.Builder
+	for i := 0; i < resetCodeLength; i++ {
+		if i == resetCodeLength/2 {
+			code.WriteByte('-')
+		}
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(resetCodeAlphabet))))
+		if err != nil {
+			return "", err
+		}
+		code.WriteByte(resetCodeAlphabet[n.Int64()])
+	}
+
+	return code.String(), nil
+}
+
+// hashResetCode normalises a code as typed by the user and returns its SHA-256 hex digest.
+func hashResetCode(code string) string {
+	normalised := strings.ToUpper(strings.NewReplacer("-", "", " ", "").Replace(code))
+	sum := sha256.Sum256([]byte(normalised))
+	return hex.EncodeToString(sum[:])
+}
+
+// IssueResetCode generates a one-time reset code for a user and shows it to the admin once.
+// Any earlier unused code for the user stops working.
+func (s *Server) IssueResetCode(w http.ResponseWriter, r *http.Request) {
+	admin, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	userID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid user id")
+		return
+	}
+
+	user, err := s.queries.GetUserDetails(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "user not found")
+		slog.Error("user not found", "error", err.Error())
+		return
+	}
+
+	code, err := generateResetCode()
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to generate reset code", "error", err.Error())
+		return
+	}
+
+	expiresAt := time.Now().Add(resetCodeTTL())
+
+	params := database.CreateResetCodeParams{
+		UserID:    userID,
+		CodeHash:  hashResetCode(code),
+		ExpiresAt: pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		CreatedBy: pgtype.UUID{Bytes: admin.UserID, Valid: true},
+	}
+
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		if err := qtx.DeleteUnusedResetCodes(r.Context(), userID); err != nil {
+			return err
+		}
+
+		resetCodeID, err := qtx.CreateResetCode(r.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditCreate, auditResetCode, resetCodeID, nil, params)
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to store reset code", "error", err.Error())
+		return
+	}
+
+	s.renderComponent(w, r, userlist.ResetCodeModal(user, code, expiresAt))
+}
+
+// ResetPasswordWithCode lets a user choose a new password with a code issued by an admin.
+// A successful reset signs the user out of every device.
+func (s *Server) ResetPasswordWithCode(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	username := strings.ToUpper(strings.TrimSpace(r.FormValue("username")))
+	code := r.FormValue("code")
+	newPassword := r.FormValue("new_password")
+	confirmPassword := r.FormValue("confirm_password")
+	ip := s.clientIP(r)
+	now := time.Now()
+
+	// Reset codes are credentials too, so guesses count towards the same per-IP throttle as logins.
+	ipFailures, err := s.queries.CountRecentFailedLoginsByIP(r.Context(), database.CountRecentFailedLoginsByIPParams{
+		IpAddress:   ip,
+		AttemptedAt: pgtype.Timestamptz{Time: now.Add(-s.throttle.IPWindow), Valid: true},
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to count login attempts", "error", err.Error())
+		return
+	}
+
+	if wait := s.throttle.retryAfter(int(ipFailures.Failures), s.throttle.IPFreeAttempts, ipFailures.LastFailedAt, now); wait > 0 {
+		s.recordLoginAttempt(r.Context(), username, uuid.Nil, ip, loginThrottled)
+		writeTooManyAttempts(w, r, wait)
+		return
+	}
+
+	if newPassword != confirmPassword {
+		writeError(w, r, http.StatusUnprocessableEntity, "new password does not match confirmed password")
+		return
+	}
+
+	user, err := s.queries.GetUserByUsername(r.Context(), username)
+	if err != nil {
+		s.recordLoginAttempt(r.Context(), username, uuid.Nil, ip, loginInvalidCredentials)
+		writeError(w, r, http.StatusUnauthorized, "invalid username or reset code")
+		return
+	}
+
+	// The code is checked before anything about the account is revealed.
+	// If the new password is then rejected the transaction rolls back and the code stays usable.
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		consumeParams := database.ConsumeResetCodeParams{
+			UserID:   user.UserID,
+			CodeHash: hashResetCode(code),
+		}
+
+		if _, err := qtx.ConsumeResetCode(r.Context(), consumeParams); err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				s.recordLoginAttempt(r.Context(), username, user.UserID, ip, loginInvalidCredentials)
+				return NewAppError(http.StatusUnauthorized, "invalid username or reset code")
+			}
+			return fmt.Errorf("consume reset code: %w", err)
+		}
+
+		userDetails, err := qtx.GetUserDetails(r.Context(), user.UserID)
+		if err != nil {
+			return fmt.Errorf("get user: %w", err)
+		}
+
+		if err := s.passwords.Validate(newPassword, userDetails.PhoneNumber.String); err != nil {
+			return NewAppError(http.StatusUnprocessableEntity, err.Error())
+		}
+
+		hashedPassword, err := hashPassword(newPassword)
+		if err != nil {
+			return err
+		}
+
+		resetParams := database.ResetPasswordParams{
+			UserID:   user.UserID,
+			Password: string(hashedPassword),
+		}
+
+		if err := qtx.ResetPassword(r.Context(), resetParams); err != nil {
+			return fmt.Errorf("reset password: %w", err)
+		}
+
+		if err := qtx.DeleteUserSessions(r.Context(), user.UserID); err != nil {
+			return fmt.Errorf("revoke sessions after reset: %w", err)
+		}
+
+		after, err := qtx.GetUserDetails(r.Context(), user.UserID)
+		if err != nil {
+			return fmt.Errorf("get user: %w", err)
+		}
+
+		return recordAudit(r, qtx, auditUpdate, auditUser, user.UserID, userDetails, after)
+	})
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "" {
+		w.Header().Set("HX-Redirect", "/login")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
@@ -0,0 +1,41 @@
+// password_reset_test.go
+package server
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestGenerateResetCode(t *testing.T) {
+	format := regexp.MustCompile(`^[A-HJ-NP-Z2-9]{4}-[A-HJ-NP-Z2-9]{4}$`)
+
+	seen := make(map[string]bool)
+	for i := 0; i < 50; i++ {
+		code, err := generateResetCode()
+		if err != nil {
+			t.Fatalf("generateResetCode returned error: %v", err)
+		}
+		if !format.MatchString(code) {
+			t.Errorf("unexpected reset code format %q", code)
+		}
+		seen[code] = true
+	}
+
+	if len(seen) < 45 {
+		t.Errorf("expected reset codes to be random; got %d distinct codes out of 50", len(seen))
+	}
+}
+
+func TestHashResetCode_NormalisesInput(t *testing.T) {
+	want := hashResetCode("ABCD-EF23")
+
+	for _, typed := range []string{"abcd-ef23", "ABCDEF23", " abcd ef23 "} {
+		if got := hashResetCode(typed); got != want {
+			t.Errorf("hashResetCode(%q) did not match the issued code", typed)
+		}
+	}
+
+	if hashResetCode("ABCD-EF24") == want {
+		t.Error("different codes must not hash to the same value")
+	}
+}
@@ -15,7 +15,7 @@ import (
 func (s *Server) ShowSetupPromotionPage(w http.ResponseWriter, r *http.Request) {
 	schoolClasses, err := s.queries.SetUpClassPromotions(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to get classes")
+		writeError(w, r, http.StatusInternalServerError, "failed to get classes")
 		slog.Error("failed to retrieve classes", "error", err.Error())
 		return
 	}
@@ -27,7 +27,7 @@ func (s *Server) ShowSetupPromotionPage(w http.ResponseWriter, r *http.Request)
 // It expects form fields: class_id, and next_class_id.
 func (s *Server) SubmitPromotions(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid form submission")
+		writeError(w, r, http.StatusBadRequest, "invalid form submission")
 		return
 	}
 
@@ -35,27 +35,27 @@ func (s *Server) SubmitPromotions(w http.ResponseWriter, r *http.Request) {
 	nextClassID := r.FormValue("next_class_id")
 
 	if classID == "" || nextClassID == "" {
-		writeError(w, http.StatusBadRequest, "invalid form data")
+		writeError(w, r, http.StatusBadRequest, "invalid form data")
 		return
 	}
 
 	parsedClassID, err := uuid.Parse(classID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "failed to parse class ID")
+		writeError(w, r, http.StatusBadRequest, "failed to parse class ID")
 		slog.Error("failed to parse class ID", "error", err.Error())
 		return
 	}
 
 	parsedNextClassID, err := uuid.Parse(nextClassID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "failed to parse class ID")
+		writeError(w, r, http.StatusBadRequest, "failed to parse class ID")
 		slog.Error("failed to parse class ID", "error", err.Error())
 		return
 	}
 
 	currentPromotions, err := s.queries.ListClassPromotions(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("internal server error", "error", err.Error())
 		return
 	}
@@ -63,39 +63,14 @@ func (s *Server) SubmitPromotions(w http.ResponseWriter, r *http.Request) {
 	// validations
 	for _, existing := range currentPromotions {
 		if parsedClassID == existing.NextClassID.Bytes && parsedNextClassID == existing.ClassID {
-			w.Header().Set("Content-Type", "text/html")
-			_, _ = w.Write([]byte(`
-					<div id="popover" class="custom-popover show" style="background-color: #dc2626;">
-						<span>❌ A reverse of an available promotion rule not is not allowed</span>
-					</div>
-					<script>
-						setTimeout(() => {
-							document.getElementById('popover').classList.add('hide');
-							setTimeout(() => document.getElementById('popover').remove(), 500);
-						}, 3000);
-					</script>
-				`))
+			writeError(w, r, http.StatusConflict, "A reverse of an available promotion rule not is not allowed")
 			return
-
 		}
 	}
 
 	if parsedClassID == parsedNextClassID {
-		if r.Header.Get("HX-Request") != "" {
-			w.Header().Set("Content-Type", "text/html")
-			_, _ = w.Write([]byte(`
-					<div id="popover" class="custom-popover show" style="background-color: #dc2626;">
-						<span>❌ Invalid promotion rule selection</span>
-					</div>
-					<script>
-						setTimeout(() => {
-							document.getElementById('popover').classList.add('hide');
-							setTimeout(() => document.getElementById('popover').remove(), 500);
-						}, 3000);
-					</script>
-				`))
-			return
-		}
+		writeError(w, r, http.StatusUnprocessableEntity, "Invalid promotion rule selection")
+		return
 	}
 
 	bytesNextClassID, err := parsedNextClassID.MarshalBinary()
@@ -109,24 +84,18 @@ func (s *Server) SubmitPromotions(w http.ResponseWriter, r *http.Request) {
 		NextClassID: pgtype.UUID{Bytes: [16]byte(bytesNextClassID), Valid: true},
 	}
 
-	_, err = s.queries.CreateClassPromotions(r.Context(), params)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		rule, err := qtx.CreateClassPromotions(r.Context(), params)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditCreate, auditPromotionRule, rule.ClassID, nil, rule)
+	})
 	if err != nil {
 		slog.Error("failed to create a class promotion rule", "classID", parsedClassID, "nextClassID", parsedNextClassID, "error", err.Error())
-		if r.Header.Get("HX-Request") != "" {
-			w.Header().Set("Content-Type", "text/html")
-			_, _ = w.Write([]byte(`
-					<div id="popover" class="custom-popover show" style="background-color: #dc2626;">
-						<span>❌ Failed to save a promotion rule</span>
-					</div>
-					<script>
-						setTimeout(() => {
-							document.getElementById('popover').classList.add('hide');
-							setTimeout(() => document.getElementById('popover').remove(), 500);
-						}, 3000);
-					</script>
-				`))
-			return
-		}
+		writeError(w, r, http.StatusInternalServerError, "Failed to save a promotion rule")
+		return
 	}
 
 	if r.Header.Get("HX-Request") != "" {
@@ -142,27 +111,27 @@ func (s *Server) SubmitPromotions(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ShowPromotionPage(w http.ResponseWriter, r *http.Request) {
 	term, err := s.getCachedTerm()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("Failed to retrive current academic term", "error", err.Error())
 		return
 	}
 
 	academicYear, err := s.getCachedYear()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("Failed to retrive current academic year", "error", err.Error())
 		return
 	}
 
 	promotionClasses, err := s.queries.ListClassPromotions(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to retrieve class promotions")
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve class promotions")
 		slog.Error("failed to retrieve class promotions", "error", err.Error())
 	}
 
 	schoolClasses, err := s.queries.ListClasses(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to get classes")
+		writeError(w, r, http.StatusInternalServerError, "failed to get classes")
 		slog.Error("failed to retrieve classes", "error", err.Error())
 		return
 	}
@@ -200,8 +169,20 @@ func (s *Server) ShowResetPromotion(w http.ResponseWriter, r *http.Request) {
 
 // ResetPromotionRules clears all custom class promotion rules.
 func (s *Server) ResetPromotionRules(w http.ResponseWriter, r *http.Request) {
-	if err := s.queries.ResetPromotions(r.Context()); err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to reset promotion rules")
+	err := s.withTx(r.Context(), func(qtx *database.Queries) error {
+		before, err := qtx.ListClassPromotions(r.Context())
+		if err != nil {
+			return err
+		}
+
+		if err := qtx.ResetPromotions(r.Context()); err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditDelete, auditPromotionRule, uuid.Nil, before, nil)
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to reset promotion rules")
 		slog.Error("failed to reset promotion rules", "error", err.Error())
 		return
 	}
@@ -218,12 +199,12 @@ func (s *Server) ResetPromotionRules(w http.ResponseWriter, r *http.Request) {
 // ShowUndoPromotion confirmation modal
 func (s *Server) ShowPromoteStudents(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid form submission")
+		writeError(w, r, http.StatusBadRequest, "invalid form submission")
 		return
 	}
 	termID := r.FormValue("new_term_id")
 	if termID == "" {
-		writeError(w, http.StatusBadRequest, "term ID is required")
+		writeError(w, r, http.StatusBadRequest, "term ID is required")
 		return
 	}
 
@@ -234,33 +215,21 @@ func (s *Server) ShowPromoteStudents(w http.ResponseWriter, r *http.Request) {
 func (s *Server) PromoteStudents(w http.ResponseWriter, r *http.Request) {
 	parsedTermID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid term ID format")
+		writeError(w, r, http.StatusBadRequest, "invalid term ID format")
 		slog.Error("failed to parse term ID", "error", err.Error())
 		return
 	}
 
 	retrievedTerm, err := s.queries.GetTerm(r.Context(), parsedTermID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to find that term")
+		writeError(w, r, http.StatusInternalServerError, "failed to find that term")
 		slog.Error("failed to find term", "error", err.Error())
 		return
 	}
 
 	if !retrievedTerm.PreviousTermID.Valid {
-		w.Header().Set("Content-Type", "text/html")
-		_, _ = w.Write([]byte(`
-				<div id="popover" class="custom-popover show" style="background-color: #dc2626;">
-						<span>❌ Switch to the new academic term first to promote students</span>
-					</div>
-					<script>
-						setTimeout(() => {
-							document.getElementById('popover').classList.add('hide');
-							setTimeout(() => document.getElementById('popover').remove(), 500);
-						}, 3000);
-					</script>
-				`))
+		writeError(w, r, http.StatusUnprocessableEntity, "Switch to the new academic term first to promote students")
 		return
-
 	}
 
 	previousPromotion, err := s.queries.ShowLastPromotion(r.Context())
@@ -269,25 +238,13 @@ func (s *Server) PromoteStudents(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if retrievedTerm.PreviousTermID.Bytes == previousPromotion.StoredTermID {
-		w.Header().Set("Content-Type", "text/html")
-		_, _ = w.Write([]byte(`
-				<div id="popover" class="custom-popover show" style="background-color: #dc2626;">
-						<span>❌ Promotion Event already done</span>
-					</div>
-					<script>
-						setTimeout(() => {
-							document.getElementById('popover').classList.add('hide');
-							setTimeout(() => document.getElementById('popover').remove(), 500);
-						}, 3000);
-					</script>
-				`))
+		writeError(w, r, http.StatusConflict, "Promotion Event already done")
 		return
-
 	}
 	ctx := r.Context()
 	tx, err := s.conn.Begin(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to start transaction")
+		writeError(w, r, http.StatusInternalServerError, "failed to start transaction")
 		return
 	}
 	defer tx.Rollback(ctx)
@@ -300,27 +257,28 @@ func (s *Server) PromoteStudents(w http.ResponseWriter, r *http.Request) {
 	}
 	err = qtx.PromoteStudents(r.Context(), params)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to promote students")
+		writeError(w, r, http.StatusInternalServerError, "failed to promote students")
 		slog.Error("failed to promote students", "error", err.Error())
 		return
 	}
 
-	err = tx.Commit(ctx)
+	promotion, err := qtx.ShowLastPromotion(ctx)
 	if err != nil {
-		w.Header().Set("Content-Type", "text/html")
-		_, _ = w.Write([]byte(`
-				<div id="popover" class="custom-popover show" style="background-color: #dc2626;">
-						<span>❌ Failed to promote students, Try again</span>
-					</div>
-					<script>
-						setTimeout(() => {
-							document.getElementById('popover').classList.add('hide');
-							setTimeout(() => document.getElementById('popover').remove(), 500);
-						}, 3000);
-					</script>
-				`))
+		writeError(w, r, http.StatusInternalServerError, "failed to promote students")
+		slog.Error("failed to find promotion event", "error", err.Error())
+		return
+	}
+
+	if err := recordAudit(r, qtx, auditCreate, auditPromotion, promotion.StoredTermID, nil, promotion); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to promote students")
+		slog.Error("failed to write audit log", "error", err.Error())
 		return
+	}
 
+	err = tx.Commit(ctx)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to promote students, Try again")
+		return
 	}
 
 	if r.Header.Get("HX-Request") != "" {
@@ -334,12 +292,12 @@ func (s *Server) PromoteStudents(w http.ResponseWriter, r *http.Request) {
 // ShowUndoPromotion confirmation modal
 func (s *Server) ShowUndoPromotion(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid form submission")
+		writeError(w, r, http.StatusBadRequest, "invalid form submission")
 		return
 	}
 	termID := r.FormValue("previous_term_id")
 	if termID == "" {
-		writeError(w, http.StatusBadRequest, "term ID is required")
+		writeError(w, r, http.StatusBadRequest, "term ID is required")
 		return
 	}
 
@@ -350,7 +308,7 @@ func (s *Server) ShowUndoPromotion(w http.ResponseWriter, r *http.Request) {
 func (s *Server) UndoPromotion(w http.ResponseWriter, r *http.Request) {
 	parsedTermID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid term ID format")
+		writeError(w, r, http.StatusBadRequest, "invalid term ID format")
 		slog.Error("failed to parse term ID", "error", err.Error())
 		return
 	}
@@ -361,25 +319,14 @@ func (s *Server) UndoPromotion(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if parsedTermID != previousPromotion.StoredTermID || previousPromotion.IsUndone {
-		w.Header().Set("Content-Type", "text/html")
-		_, _ = w.Write([]byte(`
-				<div id="popover" class="custom-popover show" style="background-color: #dc2626;">
-						<span>❌ Undo operation failed</span>
-					</div>
-					<script>
-						setTimeout(() => {
-							document.getElementById('popover').classList.add('hide');
-							setTimeout(() => document.getElementById('popover').remove(), 500);
-						}, 3000);
-					</script>
-				`))
+		writeError(w, r, http.StatusUnprocessableEntity, "Undo operation failed")
 		return
 	}
 
 	ctx := r.Context()
 	tx, err := s.conn.Begin(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to start transaction")
+		writeError(w, r, http.StatusInternalServerError, "failed to start transaction")
 		return
 	}
 	defer tx.Rollback(ctx)
@@ -388,27 +335,28 @@ func (s *Server) UndoPromotion(w http.ResponseWriter, r *http.Request) {
 
 	err = qtx.UndoPromoteStudents(r.Context(), parsedTermID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to undo student promotion")
+		writeError(w, r, http.StatusInternalServerError, "failed to undo student promotion")
 		slog.Error("failed to undo student promotion", "error", err.Error())
 		return
 	}
 
-	err = tx.Commit(ctx)
+	undone, err := qtx.ShowLastPromotion(ctx)
 	if err != nil {
-		w.Header().Set("Content-Type", "text/html")
-		_, _ = w.Write([]byte(`
-				<div id="popover" class="custom-popover show" style="background-color: #dc2626;">
-						<span>❌ Undo operation could not be processed, Try again</span>
-					</div>
-					<script>
-						setTimeout(() => {
-							document.getElementById('popover').classList.add('hide');
-							setTimeout(() => document.getElementById('popover').remove(), 500);
-						}, 3000);
-					</script>
-				`))
+		writeError(w, r, http.StatusInternalServerError, "failed to undo student promotion")
+		slog.Error("failed to find promotion event", "error", err.Error())
+		return
+	}
+
+	if err := recordAudit(r, qtx, auditUpdate, auditPromotion, parsedTermID, previousPromotion, undone); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to undo student promotion")
+		slog.Error("failed to write audit log", "error", err.Error())
 		return
+	}
 
+	err = tx.Commit(ctx)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Undo operation could not be processed, Try again")
+		return
 	}
 
 	if r.Header.Get("HX-Request") != "" {
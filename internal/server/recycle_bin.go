@@ -0,0 +1,193 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+
+	"school_management_system/cmd/web/dashboard/recyclebin"
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// recycleBinPolicy controls how long soft deleted records are kept before they are purged.
+type recycleBinPolicy struct {
+	Retention     time.Duration
+	PurgeInterval time.Duration
+}
+
+// loadRecycleBinPolicy reads the recycle bin retention from the environment, falling back to defaults.
+func loadRecycleBinPolicy() recycleBinPolicy {
+	policy := recycleBinPolicy{
+		Retention:     30 * 24 * time.Hour,
+		PurgeInterval: time.Hour,
+	}
+
+	if v, err := strconv.Atoi(os.Getenv("RECYCLE_BIN_RETENTION_DAYS")); err == nil && v > 0 {
+		policy.Retention = time.Duration(v) * 24 * time.Hour
+	}
+
+	return policy
+}
+
+// ShowRecycleBin lists the soft deleted students, users, classes and subjects.
+func (s *Server) ShowRecycleBin(w http.ResponseWriter, r *http.Request) {
+	items, err := s.queries.ListRecycleBin(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get recycle bin")
+		slog.Error("failed to list recycle bin", "error", err.Error())
+		return
+	}
+
+	s.renderComponent(w, r, recyclebin.RecycleBin(items, s.recycleBin.Retention))
+}
+
+// RestoreDeleted takes a student, user, class or subject out of the recycle bin.
+func (s *Server) RestoreDeleted(w http.ResponseWriter, r *http.Request) {
+	entityType := r.PathValue("type")
+	entityID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid id")
+		return
+	}
+
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		restored, after, err := restoreEntity(r.Context(), qtx, entityType, entityID)
+		if err != nil {
+			return err
+		}
+		if restored == 0 {
+			return NewAppError(http.StatusNotFound, "nothing to restore")
+		}
+
+		return recordAudit(r, qtx, auditRestore, entityType, entityID, nil, after)
+	})
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	s.ShowRecycleBin(w, r)
+}
+
+// restoreEntity clears the deleted mark on one record and returns how many rows changed along
+// with the restored record for the audit log. A record whose phone number or name is in use again
+// can't be restored.
+func restoreEntity(ctx context.Context, qtx *database.Queries, entityType string, id uuid.UUID) (int64, any, error) {
+	var (
+		restored int64
+		after    any
+		err      error
+	)
+
+	switch entityType {
+	case auditStudent:
+		if restored, err = qtx.RestoreStudent(ctx, id); err == nil && restored > 0 {
+			after, err = qtx.GetStudent(ctx, id)
+		}
+	case auditUser:
+		if restored, err = qtx.RestoreUser(ctx, id); err == nil && restored > 0 {
+			after, err = qtx.GetUserDetails(ctx, id)
+		}
+	case auditClass:
+		if restored, err = qtx.RestoreClass(ctx, id); err == nil && restored > 0 {
+			after, err = qtx.GetClass(ctx, id)
+		}
+	case auditSubject:
+		if restored, err = qtx.RestoreSubject(ctx, id); err == nil && restored > 0 {
+			after, err = qtx.GetSubject(ctx, id)
+		}
+	default:
+		return 0, nil, NewAppError(http.StatusNotFound, "unknown record type")
+	}
+
+	// Deleted records give up their phone number or name, which a live record may have taken since.
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+		return 0, nil, NewAppError(http.StatusConflict,
+			fmt.Sprintf("another %s now has the same name or phone number, change it before restoring this one", entityType))
+	}
+
+	return restored, after, err
+}
+
+// runRecycleBinPurge purges expired records from the recycle bin every PurgeInterval until ctx is done.
+func (s *Server) runRecycleBinPurge(ctx context.Context) {
+	ticker := time.NewTicker(s.recycleBin.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.purgeRecycleBin(ctx, time.Now()); err != nil {
+			slog.Error("failed to purge recycle bin", "error", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeRecycleBin hard deletes records that were soft deleted longer than the retention period ago,
+// along with guardians left without a student. Each purged record is written to the audit log.
+func (s *Server) purgeRecycleBin(ctx context.Context, now time.Time) error {
+	cutoff := pgtype.Timestamptz{Time: now.Add(-s.recycleBin.Retention), Valid: true}
+
+	return s.withTx(ctx, func(qtx *database.Queries) error {
+		purges := []struct {
+			entityType string
+			purge      func(context.Context, pgtype.Timestamptz) ([]uuid.UUID, error)
+		}{
+			{auditSubject, qtx.PurgeSubjects},
+			{auditStudent, qtx.PurgeStudents},
+			{auditClass, qtx.PurgeClasses},
+			{auditUser, qtx.PurgeUsers},
+		}
+
+		for _, p := range purges {
+			ids, err := p.purge(ctx, cutoff)
+			if err != nil {
+				return err
+			}
+			if err := recordPurges(ctx, qtx, p.entityType, ids); err != nil {
+				return err
+			}
+		}
+
+		ids, err := qtx.PurgeOrphanGuardians(ctx)
+		if err != nil {
+			return err
+		}
+
+		return recordPurges(ctx, qtx, auditGuardian, ids)
+	})
+}
+
+// recordPurges writes an audit log entry without a user for each purged record.
+func recordPurges(ctx context.Context, qtx *database.Queries, entityType string, ids []uuid.UUID) error {
+	for _, id := range ids {
+		params := database.CreateAuditLogParams{
+			Action:     auditPurge,
+			EntityType: entityType,
+			EntityID:   pgtype.UUID{Bytes: id, Valid: true},
+		}
+		if err := qtx.CreateAuditLog(ctx, params); err != nil {
+			return err
+		}
+	}
+
+	if len(ids) > 0 {
+		slog.Info("purged records from the recycle bin", "entity_type", entityType, "count", len(ids))
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+// recycle_bin_test.go
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestLoadRecycleBinPolicy(t *testing.T) {
+	t.Setenv("RECYCLE_BIN_RETENTION_DAYS", "")
+	if got := loadRecycleBinPolicy().Retention; got != 30*24*time.Hour {
+		t.Errorf("expected a 30 day default retention; got %v", got)
+	}
+
+	t.Setenv("RECYCLE_BIN_RETENTION_DAYS", "7")
+	if got := loadRecycleBinPolicy().Retention; got != 7*24*time.Hour {
+		t.Errorf("expected a 7 day retention; got %v", got)
+	}
+
+	t.Setenv("RECYCLE_BIN_RETENTION_DAYS", "-1")
+	if got := loadRecycleBinPolicy().Retention; got != 30*24*time.Hour {
+		t.Errorf("expected an invalid retention to fall back to the default; got %v", got)
+	}
+}
+
+func TestRestoreEntity(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	qtx := database.New(mockConn)
+	classID := uuid.New()
+
+	mockConn.ExpectExec("UPDATE classes").WithArgs(classID).WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+	mockConn.ExpectQuery("SELECT").WithArgs(classID).
+		WillReturnRows(pgxmock.NewRows([]string{"class_id", "name", "deleted_at", "deleted_by"}).
+			AddRow(classID, "Form 1", pgtype.Timestamptz{}, pgtype.UUID{}))
+
+	restored, after, err := restoreEntity(context.Background(), qtx, auditClass, classID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if restored != 1 {
+		t.Errorf("expected one restored row; got %d", restored)
+	}
+	if class, ok := after.(database.Class); !ok || class.Name != "Form 1" {
+		t.Errorf("expected the restored class; got %#v", after)
+	}
+
+	// A record that is not in the recycle bin is not fetched.
+	mockConn.ExpectExec("UPDATE classes").WithArgs(classID).WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+	if restored, _, err := restoreEntity(context.Background(), qtx, auditClass, classID); err != nil || restored != 0 {
+		t.Errorf("expected nothing restored; got %d, %v", restored, err)
+	}
+
+	// A class whose name was taken again while it was deleted can't come back.
+	mockConn.ExpectExec("UPDATE classes").WithArgs(classID).WillReturnError(&pgconn.PgError{Code: "23505"})
+
+	var appErr *AppError
+	if _, _, err := restoreEntity(context.Background(), qtx, auditClass, classID); !errors.As(err, &appErr) || appErr.Status != http.StatusConflict {
+		t.Errorf("expected a conflict restoring a class whose name is taken; got %v", err)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+
+	if _, _, err := restoreEntity(context.Background(), qtx, "grade", classID); !errors.As(err, &appErr) || appErr.Status != http.StatusNotFound {
+		t.Errorf("expected not found for an unknown type; got %v", err)
+	}
+}
@@ -1,15 +1,19 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"time"
 
 	"school_management_system/cmd/web/dashboard/remarks"
 	"school_management_system/internal/database"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
@@ -42,13 +46,13 @@ func GroupRemarksByClass(rows []database.ListRemarksByClassRow) []remarks.Groupe
 func (s *Server) StudentsRemarks(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(userContextKey).(User)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "user not authenticated")
+		writeError(w, r, http.StatusUnauthorized, "user not authenticated")
 		return
 	}
 
 	term, err := s.getCachedTerm()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "active current term not set")
+		writeError(w, r, http.StatusInternalServerError, "active current term not set")
 		slog.Error(err.Error())
 		return
 	}
@@ -60,7 +64,7 @@ func (s *Server) StudentsRemarks(w http.ResponseWriter, r *http.Request) {
 
 	remarksData, err := s.queries.ListRemarksByClass(r.Context(), params)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to get remarks")
+		writeError(w, r, http.StatusInternalServerError, "failed to get remarks")
 		slog.Error("failed to get remarks data", "error", err.Error())
 		return
 	}
@@ -70,19 +74,45 @@ func (s *Server) StudentsRemarks(w http.ResponseWriter, r *http.Request) {
 	s.renderComponent(w, r, remarks.RemarksPage(groupedData))
 }
 
+// remarksLockedBy are the approval statuses of report cards that stop their remarks from changing.
+// Submitted report cards still take the headteacher's remarks, which approving them needs.
+var remarksLockedBy = []string{reportApproved, reportPublished}
+
+// checkRemarkUnlocked returns a conflict if the report card of any class the student was in for the
+// term is approved or published. The approvals stay locked until the transaction ends, so they can't
+// be approved while the remark is saved.
+func checkRemarkUnlocked(ctx context.Context, qtx *database.Queries, studentID, termID uuid.UUID) error {
+	classIDs, err := qtx.ListStudentTermClassIDs(ctx, database.ListStudentTermClassIDsParams{StudentID: studentID, TermID: termID})
+	if err != nil {
+		return err
+	}
+
+	for _, classID := range classIDs {
+		approval, err := qtx.LockReportApproval(ctx, database.LockReportApprovalParams{ClassID: classID, TermID: termID})
+		if err != nil {
+			return err
+		}
+		if slices.Contains(remarksLockedBy, approval.Status) {
+			return NewAppError(http.StatusConflict, "Remarks can't change while the report cards are "+approval.Status)
+		}
+	}
+
+	return nil
+}
+
 // SubmitRemarksHandler processes the form submission from the remarks page.
 // It expects form fields: student_ids[], class_teacher_remarks[], head_teacher_remarks[].
 // The active termID is looked up within the handler.
 func (s *Server) SubmitRemarks(w http.ResponseWriter, r *http.Request) {
 	term, err := s.getCachedTerm()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "active current term not set")
+		writeError(w, r, http.StatusInternalServerError, "active current term not set")
 		slog.Error(err.Error())
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusBadRequest, "invalid form submission")
+		writeError(w, r, http.StatusBadRequest, "invalid form submission")
 		return
 	}
 
@@ -91,65 +121,78 @@ func (s *Server) SubmitRemarks(w http.ResponseWriter, r *http.Request) {
 	headTeacherRemarks := r.Form["head_teacher_remarks[]"]
 
 	if len(studentIDs) != len(classTeacherRemarks) || len(studentIDs) != len(headTeacherRemarks) {
-		writeError(w, http.StatusBadRequest, "inconsistent form data")
+		writeError(w, r, http.StatusBadRequest, "inconsistent form data")
 		return
 	}
 
-	for i, sid := range studentIDs {
-		studentID, err := uuid.Parse(sid)
-		if err != nil {
-			slog.Error("invalid student id", "id", sid, "error", err.Error())
-			continue
-		}
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		for i, sid := range studentIDs {
+			studentID, err := uuid.Parse(sid)
+			if err != nil {
+				slog.Error("invalid student id", "id", sid, "error", err.Error())
+				continue
+			}
 
-		params := database.UpsertRemarkParams{
-			StudentID: studentID,
-			TermID:    term.TermID,
-			ContentClassTeacher: pgtype.Text{
-				String: classTeacherRemarks[i],
-				Valid:  true,
-			},
-			ContentHeadTeacher: pgtype.Text{
-				String: headTeacherRemarks[i],
-				Valid:  true,
-			},
-		}
+			// The remark being replaced is kept in the audit log.
+			action := auditUpdate
+			var before any
+			existing, err := qtx.GetRemark(r.Context(), database.GetRemarkParams{StudentID: studentID, TermID: term.TermID})
+			switch {
+			case errors.Is(err, pgx.ErrNoRows):
+				action = auditCreate
+			case err != nil:
+				return fmt.Errorf("get remark for student %s: %w", sid, err)
+			default:
+				before = existing
+			}
 
-		_, err = s.queries.UpsertRemark(r.Context(), params)
-		if err != nil {
-			slog.Error("failed to upsert remark for student", "id", sid, "error", err.Error())
-			if r.Header.Get("HX-Request") != "" {
-				w.Header().Set("Content-Type", "text/html")
-				_, _ = w.Write([]byte(`
-					<div id="popover" class="custom-popover show" style="background-color: #dc2626;">
-						<span>❌ Failed to save some remarks</span>
-					</div>
-					<script>
-						setTimeout(() => {
-							document.getElementById('popover').classList.add('hide');
-							setTimeout(() => document.getElementById('popover').remove(), 500);
-						}, 3000);
-					</script>
-				`))
-				return
+			// The form sends the remarks of every class the user teaches, so unchanged ones are
+			// skipped rather than held against report cards that can no longer change.
+			if existing.ContentClassTeacher.String == classTeacherRemarks[i] && existing.ContentHeadTeacher.String == headTeacherRemarks[i] {
+				continue
+			}
+
+			if err := checkRemarkUnlocked(r.Context(), qtx, studentID, term.TermID); err != nil {
+				return err
+			}
+
+			params := database.UpsertRemarkParams{
+				StudentID: studentID,
+				TermID:    term.TermID,
+				ContentClassTeacher: pgtype.Text{
+					String: classTeacherRemarks[i],
+					Valid:  true,
+				},
+				ContentHeadTeacher: pgtype.Text{
+					String: headTeacherRemarks[i],
+					Valid:  true,
+				},
+			}
+
+			remark, err := qtx.UpsertRemark(r.Context(), params)
+			if err != nil {
+				return fmt.Errorf("upsert remark for student %s: %w", sid, err)
+			}
+
+			if err := recordAudit(r, qtx, action, auditRemark, remark.RemarksID, before, remark); err != nil {
+				return err
 			}
 		}
+
+		return nil
+	})
+	if err != nil {
+		var appErr *AppError
+		if !errors.As(err, &appErr) {
+			err = NewAppError(http.StatusInternalServerError, "Failed to save remarks").Wrap(err)
+		}
+		writeAppError(w, r, err)
+		return
 	}
 
 	if r.Header.Get("HX-Request") != "" {
-		w.Header().Set("Content-Type", "text/html")
-		_, _ = w.Write([]byte(`
-			<div id="popover" class="custom-popover show" style="background-color: #16a34a;">
-				<span>✅ Remarks saved successfully</span>
-			</div>
-			<script>
-				setTimeout(() => {
-					document.getElementById('popover').classList.add('hide');
-					setTimeout(() => document.getElementById('popover').remove(), 500);
-				}, 3000);
-				window.location.reload()
-			</script>
-		`))
+		w.Header().Set("HX-Refresh", "true")
+		writeSuccess(w, r, "Remarks saved successfully")
 		return
 	}
 
@@ -160,7 +203,7 @@ func (s *Server) SubmitRemarks(w http.ResponseWriter, r *http.Request) {
 func (s *Server) StudentsDisciplinary(w http.ResponseWriter, r *http.Request) {
 	disciplineData, err := s.queries.ListDisciplinaryRecords(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to get disciplinary data")
+		writeError(w, r, http.StatusInternalServerError, "failed to get disciplinary data")
 		slog.Error("failed to get disciplinary data", "error", err.Error())
 		return
 	}
@@ -196,7 +239,7 @@ func (s *Server) SearchStudents(w http.ResponseWriter, r *http.Request) {
 
 	studentes, err := s.queries.SearchStudentsByName(r.Context(), parsedSearch)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to find student")
+		writeError(w, r, http.StatusInternalServerError, "failed to find student")
 		slog.Error("failed to find student", "error", err.Error())
 		return
 	}
@@ -208,7 +251,7 @@ func (s *Server) SearchStudents(w http.ResponseWriter, r *http.Request) {
 func (s *Server) SubmitDisplinaryRecord(w http.ResponseWriter, r *http.Request) {
 	err := r.ParseForm()
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid form submission")
+		writeError(w, r, http.StatusBadRequest, "Invalid form submission")
 		slog.Error("failed to parse form", "error", err.Error())
 		return
 	}
@@ -216,14 +259,14 @@ func (s *Server) SubmitDisplinaryRecord(w http.ResponseWriter, r *http.Request)
 	// Extract form values
 	studentID, err := uuid.Parse(r.FormValue("student_id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid student ID")
+		writeError(w, r, http.StatusBadRequest, "Invalid student ID")
 		slog.Error("invalid student ID", "error", err.Error())
 		return
 	}
 
 	date, err := time.Parse("2006-01-02", r.FormValue("date"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid date format")
+		writeError(w, r, http.StatusBadRequest, "Invalid date format")
 		slog.Error("invalid date format", "error", err.Error())
 		return
 	}
@@ -234,21 +277,21 @@ func (s *Server) SubmitDisplinaryRecord(w http.ResponseWriter, r *http.Request)
 
 	reportedBy, ok := r.Context().Value(userContextKey).(User)
 	if !ok {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Info("failed to get user ID")
 		return
 	}
 
 	term, err := s.queries.GetCurrentTerm(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to get current term", "error", err.Error())
 		return
 	}
 
 	reportedByBytes, err := reportedBy.UserID.MarshalBinary()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to marshal graduate class UUID to bytes")
+		writeError(w, r, http.StatusInternalServerError, "failed to marshal graduate class UUID to bytes")
 		return
 	}
 
@@ -262,9 +305,16 @@ func (s *Server) SubmitDisplinaryRecord(w http.ResponseWriter, r *http.Request)
 		Notes:       pgtype.Text{String: notes, Valid: notes != ""},
 	}
 
-	_, err = s.queries.UpsertDisciplinaryRecord(r.Context(), record)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		saved, err := qtx.UpsertDisciplinaryRecord(r.Context(), record)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditUpdate, auditDiscipline, saved.DisciplineID, nil, saved)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to save disciplinary record")
+		writeError(w, r, http.StatusInternalServerError, "Failed to save disciplinary record")
 		slog.Error("failed to insert disciplinary record", "error", err.Error())
 		return
 	}
@@ -0,0 +1,55 @@
+// remarks_test.go
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestCheckRemarkUnlocked(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	qtx := database.New(mockConn)
+	studentID, termID := uuid.New(), uuid.New()
+	before, after := uuid.New(), uuid.New()
+	approvalColumns := []string{"class_id", "term_id", "status", "comment", "updated_by", "updated_at"}
+
+	expectApprovals := func(statuses ...string) {
+		mockConn.ExpectQuery("ListStudentTermClassIDs").WithArgs(studentID, termID).
+			WillReturnRows(pgxmock.NewRows([]string{"class_id"}).AddRow(before).AddRow(after))
+		for i, status := range statuses {
+			classID := []uuid.UUID{before, after}[i]
+			mockConn.ExpectQuery("LockReportApproval").WithArgs(classID, termID).WillReturnRows(pgxmock.NewRows(approvalColumns).
+				AddRow(classID, termID, status, pgtype.Text{}, pgtype.UUID{}, pgtype.Timestamptz{}))
+		}
+	}
+
+	// Submitted report cards still take the headteacher's remarks.
+	expectApprovals(reportDraft, reportSubmitted)
+	if err := checkRemarkUnlocked(context.Background(), qtx, studentID, termID); err != nil {
+		t.Errorf("expected remarks of submitted report cards to be open; got %v", err)
+	}
+
+	// A student who moved class mid-term is held to the report cards of both classes.
+	expectApprovals(reportReturned, reportApproved)
+	var appErr *AppError
+	if err := checkRemarkUnlocked(context.Background(), qtx, studentID, termID); !errors.As(err, &appErr) || appErr.Status != http.StatusConflict {
+		t.Errorf("expected a conflict changing the remark of approved report cards; got %v", err)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
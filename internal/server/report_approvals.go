@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strings"
+
+	"school_management_system/cmd/web/dashboard/reports"
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Approval statuses of a class's report cards for a term. Report cards start as drafts.
+const (
+	reportDraft     = "draft"
+	reportSubmitted = "submitted"
+	reportApproved  = "approved"
+	reportReturned  = "returned"
+	reportPublished = "published"
+)
+
+// reportApprovalAction is a step of the report card approval workflow.
+type reportApprovalAction struct {
+	Permission string
+	From       []string
+	To         string
+}
+
+// reportApprovalActions are the steps of the approval workflow by name. The class teacher submits the
+// report cards; the headteacher approves or returns them with a comment, then publishes them.
+// Published report cards can still be returned to correct them.
+var reportApprovalActions = map[string]reportApprovalAction{
+	"submit":  {Permission: "reports.submit", From: []string{reportDraft, reportReturned}, To: reportSubmitted},
+	"approve": {Permission: "reports.approve", From: []string{reportSubmitted}, To: reportApproved},
+	"return":  {Permission: "reports.approve", From: []string{reportSubmitted, reportApproved, reportPublished}, To: reportReturned},
+	"publish": {Permission: "reports.approve", From: []string{reportApproved}, To: reportPublished},
+}
+
+// reportApprovalAudit is an approval as recorded in the audit log. Its entity is the class, so the
+// term is named alongside term_id to tell apart the approvals of the class's terms.
+type reportApprovalAudit struct {
+	database.ReportApproval
+	Term string `json:"term"`
+}
+
+// reportApproval returns the approval of a class's report cards for a term, or a draft if there is none yet.
+func reportApproval(ctx context.Context, q *database.Queries, classID, termID uuid.UUID) (database.ReportApproval, error) {
+	approval, err := q.GetReportApproval(ctx, database.GetReportApprovalParams{ClassID: classID, TermID: termID})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.ReportApproval{ClassID: classID, TermID: termID, Status: reportDraft}, nil
+	}
+
+	return approval, err
+}
+
+// canDownloadReportCards reports whether user may download report cards with the given approval status:
+// once they are published, or before that if user approves report cards and so has to review them.
+func canDownloadReportCards(user User, status string) bool {
+	return status == reportPublished || user.Can("reports.approve")
+}
+
+// applyReportApproval takes the named approval step on the report cards of a class for a term and
+// returns their new approval. Report cards are submitted once every one has the class teacher's
+// remark, and approved or published once every one has the headteacher's remark.
+func applyReportApproval(r *http.Request, qtx *database.Queries, user User, classID, termID uuid.UUID, name, comment string) (database.ReportApproval, error) {
+	action, ok := reportApprovalActions[name]
+	if !ok {
+		return database.ReportApproval{}, ValidationError("invalid approval step", map[string]string{
+			"action": "must be submit, approve, return or publish",
+		})
+	}
+	if !user.Can(action.Permission) {
+		return database.ReportApproval{}, NewAppError(http.StatusForbidden, "forbidden")
+	}
+	if name == "return" && comment == "" {
+		return database.ReportApproval{}, ValidationError("returned report cards need a comment", map[string]string{
+			"comment": "say what the class teacher needs to change",
+		})
+	}
+
+	if name == "submit" {
+		teaches, err := qtx.IsClassTeacher(r.Context(), database.IsClassTeacherParams{ClassID: classID, TeacherID: user.UserID})
+		if err != nil {
+			return database.ReportApproval{}, err
+		}
+		if !teaches {
+			return database.ReportApproval{}, NewAppError(http.StatusForbidden, "only the class teacher can submit these report cards")
+		}
+	}
+
+	before, err := qtx.LockReportApproval(r.Context(), database.LockReportApprovalParams{ClassID: classID, TermID: termID})
+	if err != nil {
+		return database.ReportApproval{}, err
+	}
+	if !slices.Contains(action.From, before.Status) {
+		return database.ReportApproval{}, NewAppError(http.StatusConflict,
+			fmt.Sprintf("report cards that are %s cannot be %s", before.Status, action.To))
+	}
+
+	counts, err := qtx.CountMissingReportRemarks(r.Context(), database.CountMissingReportRemarksParams{ClassID: classID, TermID: termID})
+	if err != nil {
+		return database.ReportApproval{}, err
+	}
+	if counts.ReportCards == 0 {
+		return database.ReportApproval{}, NewAppError(http.StatusNotFound, "no report cards for this class and term")
+	}
+	switch {
+	case name == "submit" && counts.MissingClassTeacher > 0:
+		return database.ReportApproval{}, NewAppError(http.StatusUnprocessableEntity,
+			fmt.Sprintf("report cards without the class teacher's remark: %d", counts.MissingClassTeacher))
+	case (name == "approve" || name == "publish") && counts.MissingHeadTeacher > 0:
+		return database.ReportApproval{}, NewAppError(http.StatusUnprocessableEntity,
+			fmt.Sprintf("report cards without the headteacher's remark: %d", counts.MissingHeadTeacher))
+	}
+
+	after, err := qtx.UpsertReportApproval(r.Context(), database.UpsertReportApprovalParams{
+		ClassID:   classID,
+		TermID:    termID,
+		Status:    action.To,
+		Comment:   pgtype.Text{String: comment, Valid: comment != ""},
+		UpdatedBy: pgtype.UUID{Bytes: user.UserID, Valid: true},
+	})
+	if err != nil {
+		return database.ReportApproval{}, err
+	}
+
+	term, err := qtx.GetTerm(r.Context(), termID)
+	if err != nil {
+		return database.ReportApproval{}, err
+	}
+	termName := term.AcademicYear + " " + term.AcademicTerm
+
+	return after, recordAudit(r, qtx, auditUpdate, auditReportApproval, classID,
+		reportApprovalAudit{before, termName}, reportApprovalAudit{after, termName})
+}
+
+// UpdateReportApproval takes an approval step on the report cards of a class for the selected term
+// and renders the class's report cards again. The step is the action form field; returning report
+// cards needs a comment.
+func (s *Server) UpdateReportApproval(w http.ResponseWriter, r *http.Request) {
+	classID, err := uuid.Parse(r.PathValue("classID"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid class ID")
+		return
+	}
+
+	termID, err := s.reportTermID(r)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	user, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "user not authenticated")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "failed to parse form")
+		return
+	}
+
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		_, err := applyReportApproval(r, qtx, user, classID, termID, r.FormValue("action"), strings.TrimSpace(r.FormValue("comment")))
+		return err
+	})
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	s.renderClassReports(w, r, classID, termID)
+}
+
+// renderClassReports renders the report cards of a class for a term with their approval status and
+// the approval steps the user can take.
+func (s *Server) renderClassReports(w http.ResponseWriter, r *http.Request, classID, termID uuid.UUID) {
+	class, err := s.queries.GetClass(r.Context(), classID)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "class not found")
+		return
+	}
+
+	classReports, err := s.queries.ListStudentReportCards(r.Context(), database.ListStudentReportCardsParams{
+		ClassID: classID,
+		TermID:  termID,
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve report cards")
+		slog.Error("failed to retrieve report cards", "error", err.Error())
+		return
+	}
+
+	approval, err := reportApproval(r.Context(), s.queries, classID, termID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve report card approval")
+		slog.Error("failed to retrieve report card approval", "error", err.Error())
+		return
+	}
+
+	user, _ := r.Context().Value(userContextKey).(User)
+	canSubmit := false
+	if user.Can("reports.submit") {
+		canSubmit, err = s.queries.IsClassTeacher(r.Context(), database.IsClassTeacherParams{ClassID: classID, TeacherID: user.UserID})
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, "failed to retrieve class teacher")
+			slog.Error("failed to check class teacher", "error", err.Error())
+			return
+		}
+	}
+
+	s.renderComponent(w, r, reports.ClassReportTable(class.Name, classReports, reports.Approval{
+		ClassID:     classID,
+		TermID:      termID,
+		Status:      approval.Status,
+		Comment:     approval.Comment.String,
+		CanSubmit:   canSubmit,
+		CanApprove:  user.Can("reports.approve"),
+		CanDownload: canDownloadReportCards(user, approval.Status),
+	}))
+}
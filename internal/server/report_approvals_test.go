@@ -0,0 +1,125 @@
+// report_approvals_test.go
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+func TestApplyReportApproval(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	qtx := database.New(mockConn)
+	req := httptest.NewRequest("POST", "/reports/class/approval", nil)
+	classID, termID := uuid.New(), uuid.New()
+	classTeacher := User{UserID: uuid.New(), Permissions: []string{"reports.view", "reports.submit"}}
+	headTeacher := User{UserID: uuid.New(), Permissions: []string{"reports.view", "reports.approve"}}
+	approvalColumns := []string{"class_id", "term_id", "status", "comment", "updated_by", "updated_at"}
+	countColumns := []string{"report_cards", "missing_class_teacher", "missing_head_teacher"}
+
+	// The class teacher submits a draft once every report card has their remark.
+	mockConn.ExpectQuery("select exists").WithArgs(classID, classTeacher.UserID).
+		WillReturnRows(pgxmock.NewRows([]string{"exists"}).AddRow(true))
+	mockConn.ExpectQuery("LockReportApproval").WithArgs(classID, termID).WillReturnRows(pgxmock.NewRows(approvalColumns).
+		AddRow(classID, termID, reportDraft, pgtype.Text{}, pgtype.UUID{}, pgtype.Timestamptz{}))
+	mockConn.ExpectQuery("SELECT").WithArgs(classID, termID).
+		WillReturnRows(pgxmock.NewRows(countColumns).AddRow(int32(30), int32(0), int32(30)))
+	mockConn.ExpectQuery("INSERT INTO report_approvals").
+		WithArgs(classID, termID, reportSubmitted, pgtype.Text{}, pgtype.UUID{Bytes: classTeacher.UserID, Valid: true}).
+		WillReturnRows(pgxmock.NewRows(approvalColumns).
+			AddRow(classID, termID, reportSubmitted, pgtype.Text{}, pgtype.UUID{Bytes: classTeacher.UserID, Valid: true}, pgtype.Timestamptz{}))
+	mockConn.ExpectQuery("FROM term").WithArgs(termID).
+		WillReturnRows(pgxmock.NewRows([]string{"term_id", "academic_year_id", "academic_year", "academic_term", "previous_term_id", "opening_date", "closing_date", "report_weight"}).
+			AddRow(termID, uuid.New(), "2025/2026", "Term 2", pgtype.UUID{}, pgtype.Date{}, pgtype.Date{}, numericFromFloat(1)))
+	// The audit entry of the class names the term, so the history of each term can be told apart.
+	mockConn.ExpectExec("INSERT INTO audit_log").
+		WithArgs(pgxmock.AnyArg(), auditUpdate, auditReportApproval, pgtype.UUID{Bytes: classID, Valid: true},
+			auditTermData{termID}, auditTermData{termID}, pgxmock.AnyArg()).
+		WillReturnResult(pgxmock.NewResult("INSERT", 1))
+
+	approval, err := applyReportApproval(req, qtx, classTeacher, classID, termID, "submit", "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if approval.Status != reportSubmitted {
+		t.Errorf("expected submitted report cards; got %q", approval.Status)
+	}
+
+	// Draft report cards cannot be published before they are approved.
+	mockConn.ExpectQuery("LockReportApproval").WithArgs(classID, termID).WillReturnRows(pgxmock.NewRows(approvalColumns).
+		AddRow(classID, termID, reportDraft, pgtype.Text{}, pgtype.UUID{}, pgtype.Timestamptz{}))
+
+	var appErr *AppError
+	if _, err := applyReportApproval(req, qtx, headTeacher, classID, termID, "publish", ""); !errors.As(err, &appErr) || appErr.Status != http.StatusConflict {
+		t.Errorf("expected a conflict publishing a draft; got %v", err)
+	}
+
+	// Submitted report cards are not approved while the headteacher's remarks are missing.
+	mockConn.ExpectQuery("LockReportApproval").WithArgs(classID, termID).WillReturnRows(pgxmock.NewRows(approvalColumns).
+		AddRow(classID, termID, reportSubmitted, pgtype.Text{}, pgtype.UUID{}, pgtype.Timestamptz{}))
+	mockConn.ExpectQuery("SELECT").WithArgs(classID, termID).
+		WillReturnRows(pgxmock.NewRows(countColumns).AddRow(int32(30), int32(0), int32(2)))
+
+	if _, err := applyReportApproval(req, qtx, headTeacher, classID, termID, "approve", ""); !errors.As(err, &appErr) || appErr.Status != http.StatusUnprocessableEntity {
+		t.Errorf("expected missing remarks to be rejected; got %v", err)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+
+	if _, err := applyReportApproval(req, qtx, classTeacher, classID, termID, "approve", ""); !errors.As(err, &appErr) || appErr.Status != http.StatusForbidden {
+		t.Errorf("expected a class teacher to be forbidden from approving; got %v", err)
+	}
+	if _, err := applyReportApproval(req, qtx, headTeacher, classID, termID, "return", ""); !errors.As(err, &appErr) || appErr.Fields["comment"] == "" {
+		t.Errorf("expected returning without a comment to be rejected; got %v", err)
+	}
+	if _, err := applyReportApproval(req, qtx, headTeacher, classID, termID, "archive", ""); !errors.As(err, &appErr) || appErr.Fields["action"] == "" {
+		t.Errorf("expected an unknown step to be rejected; got %v", err)
+	}
+}
+
+func TestCanDownloadReportCards(t *testing.T) {
+	teacher := User{Permissions: []string{"reports.view"}}
+	headTeacher := User{Permissions: []string{"reports.view", "reports.approve"}}
+
+	if canDownloadReportCards(teacher, reportApproved) {
+		t.Error("expected unpublished report cards to be withheld")
+	}
+	if !canDownloadReportCards(teacher, reportPublished) {
+		t.Error("expected published report cards to be downloadable")
+	}
+	if !canDownloadReportCards(headTeacher, reportSubmitted) {
+		t.Error("expected the headteacher to download report cards under review")
+	}
+}
+
+// auditTermData matches audit data recording the approval of a term.
+type auditTermData struct{ termID uuid.UUID }
+
+func (a auditTermData) Match(v any) bool {
+	data, ok := v.([]byte)
+	if !ok {
+		return false
+	}
+
+	var approval struct {
+		TermID uuid.UUID `json:"term_id"`
+		Term   string    `json:"term"`
+	}
+	return json.Unmarshal(data, &approval) == nil && approval.TermID == a.termID && approval.Term == "2025/2026 Term 2"
+}
@@ -1,101 +1,105 @@
 package server
 
 import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net/http"
-	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
 
 	"school_management_system/cmd/web/dashboard/reports"
 	"school_management_system/internal/database"
+	"school_management_system/internal/grading"
 
 	"github.com/go-pdf/fpdf"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
 )
 
-// getClassroomData groups students by class and returns structured data.
-func getClassroomData(students []database.ListStudentsRow) []reports.ClassRoomData {
-	classMap := make(map[string]*reports.ClassRoomData)
-
-	for _, student := range students {
-		className := student.Classname.String
-
-		if _, ok := classMap[className]; !ok {
-			classID, err := uuid.FromBytes(student.ClassID.Bytes[:])
-			if err != nil {
-				slog.Error("failed to parse class ID while getting classRoomData", "error", err.Error())
-				continue
-			}
-			classMap[className] = &reports.ClassRoomData{
-				ClassID:   classID,
-				ClassName: className,
-				Students:  []database.ListStudentsRow{},
-			}
+// reportTermID returns the term whose report cards are requested: the term_id query parameter,
+// or the current term when there is none.
+func (s *Server) reportTermID(r *http.Request) (uuid.UUID, error) {
+	if v := r.URL.Query().Get("term_id"); v != "" {
+		termID, err := uuid.Parse(v)
+		if err != nil {
+			return uuid.Nil, NewAppError(http.StatusBadRequest, "invalid term").WithField("term_id", "must be a term ID")
 		}
-
-		classMap[className].Students = append(classMap[className].Students, student)
+		return termID, nil
 	}
 
-	classRooms := make([]reports.ClassRoomData, 0, len(classMap))
-	for _, classData := range classMap {
-		classRooms = append(classRooms, *classData)
+	term, err := s.getCachedTerm()
+	if err != nil {
+		return uuid.Nil, NewAppError(http.StatusNotFound, "no active term, choose a term").Wrap(err)
 	}
 
-	sort.Slice(classRooms, func(i, j int) bool {
-		return classRooms[i].ClassName < classRooms[j].ClassName
-	})
-
-	return classRooms
+	return term.TermID, nil
 }
 
-// ShowClassReports renders a report for a specific class.
+// ShowClassReports renders the report cards of a class for the selected term with their approval status.
 func (s *Server) ShowClassReports(w http.ResponseWriter, r *http.Request) {
 	classID, err := uuid.Parse(r.PathValue("classID"))
 	if err != nil {
-		http.Error(w, "Invalid class ID", http.StatusBadRequest)
+		writeError(w, r, http.StatusBadRequest, "Invalid class ID")
 		return
 	}
 
-	students, err := s.queries.ListStudents(r.Context())
+	termID, err := s.reportTermID(r)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to retrieve students")
-		slog.Error("failed to retrieve students", "error", err.Error())
+		writeAppError(w, r, err)
 		return
 	}
 
-	classRooms := getClassroomData(students)
+	s.renderClassReports(w, r, classID, termID)
+}
 
-	for _, classData := range classRooms {
-		if classData.ClassID == classID {
-			classReports, err := s.queries.ListStudentReportCards(r.Context(), classData.ClassID)
-			if err != nil {
-				writeError(w, http.StatusNotFound, "grades for this class not found")
-			}
-			s.renderComponent(w, r, reports.ClassReportTable(classData, classReports))
+// ShowStudentsReports renders the term selector and the classes graded in the selected term.
+func (s *Server) ShowStudentsReports(w http.ResponseWriter, r *http.Request) {
+	terms, err := s.queries.ListAllTerms(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve terms")
+		slog.Error("failed to retrieve terms", "error", err.Error())
+		return
+	}
+
+	termID, err := s.reportTermID(r)
+	if err != nil {
+		if len(terms) == 0 {
+			writeAppError(w, r, err)
 			return
 		}
+		termID = terms[0].TermID
 	}
 
-	writeError(w, http.StatusNotFound, "class not found or has no students")
-}
-
-// ShowStudentsReports renders all students grouped by class.
-func (s *Server) ShowStudentsReports(w http.ResponseWriter, r *http.Request) {
-	students, err := s.queries.ListStudents(r.Context())
+	classRooms, err := s.queries.ListReportCardClasses(r.Context(), termID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to retrieve students")
-		slog.Error("failed to retrieve students", "error", err.Error())
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve classes")
+		slog.Error("failed to retrieve report card classes", "error", err.Error())
 		return
 	}
 
-	classRooms := getClassroomData(students)
-	s.renderComponent(w, r, reports.ReportsList(classRooms))
+	s.renderComponent(w, r, reports.ReportsList(terms, termID, classRooms))
 }
 
 // createStudentReportPdf helper function creates a pdf file with student results, and teachers remarks
-func createStudentReportPdf(term CachedTerm, student database.GetStudentReportCardRow, studentSubjects []database.ListSubjectsRow) (string, *fpdf.Fpdf) {
+func createStudentReportPdf(student database.GetStudentReportCardRow, studentSubjects []database.ListSubjectsRow, scale grading.Scale) (string, *fpdf.Fpdf) {
 	pdf := fpdf.New(fpdf.OrientationPortrait, "mm", "A4", "")
+	addReportCardPage(pdf, student, studentSubjects, scale)
 
+	fileName := student.StudentNo
+
+	return fileName, pdf
+}
+
+// addReportCardPage adds a page with a student's results and teachers remarks to pdf.
+// Scores are graded with scale; a subject without a remark shows its grade's descriptor instead.
+func addReportCardPage(pdf *fpdf.Fpdf, student database.GetStudentReportCardRow, studentSubjects []database.ListSubjectsRow, scale grading.Scale) {
 	pdf.AddPage()
 	pdf.SetFont("Arial", "B", 18)
 	pdf.CellFormat(190, 10, "Student Report Card", "", 0, "C", false, 0, "")
@@ -107,30 +111,62 @@ func createStudentReportPdf(term CachedTerm, student database.GetStudentReportCa
 	pdf.Ln(8)
 	pdf.Cell(190, 10, fmt.Sprintf("Name: %s %s %s", student.FirstName, student.MiddleName.String, student.LastName))
 	pdf.Ln(8)
-	pdf.Cell(190, 10, fmt.Sprintf("Class: %s (%s)", student.ClassName, term.AcademicTerm))
+	pdf.Cell(190, 10, fmt.Sprintf("Class: %s", student.ClassName))
+	pdf.Ln(8)
+	pdf.Cell(190, 10, fmt.Sprintf("Term: %s, %s", student.TermName, student.AcademicYearName))
 	pdf.Ln(12)
 
 	// Table Headers
 	pdf.SetFont("Arial", "B", 12)
 	pdf.SetFillColor(200, 200, 200)
-	pdf.CellFormat(60, 10, "Subject", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(40, 10, "Grade", "1", 0, "C", true, 0, "")
-	pdf.CellFormat(60, 10, "Remarks", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(55, 10, "Subject", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(25, 10, "Score", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(20, 10, "Grade", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(25, 10, "Position", "1", 0, "C", true, 0, "")
+	pdf.CellFormat(65, 10, "Remarks", "1", 0, "C", true, 0, "")
 	pdf.Ln(-1)
 
 	// Table Content
 	pdf.SetFont("Arial", "", 12)
-	for subject, grade := range student.Grades {
-		for _, studentSubj := range studentSubjects {
-			if studentSubj.SubjectID == subject {
-				pdf.CellFormat(60, 10, studentSubj.Subjectname, "1", 0, "L", false, 0, "")
-				pdf.CellFormat(40, 10, fmt.Sprintf("%.2f", grade.Score), "1", 0, "R", false, 0, "")
-				pdf.CellFormat(60, 10, fmt.Sprintf("%s", grade.Remark), "1", 0, "C", false, 0, "")
-				pdf.Ln(-1)
+	// Subjects are listed in the class's order, skipping those the student was not graded in.
+	for _, studentSubj := range studentSubjects {
+		grade, ok := student.Grades[studentSubj.SubjectID]
+		if !ok {
+			continue
+		}
+
+		letter, remark := "-", grade.Remark
+		if band, ok := scale.Grade(grade.Score); ok {
+			letter = band.Letter
+			if strings.TrimSpace(remark) == "" {
+				remark = band.Descriptor
 			}
 		}
+
+		pdf.CellFormat(55, 10, studentSubj.Subjectname, "1", 0, "L", false, 0, "")
+		pdf.CellFormat(25, 10, fmt.Sprintf("%.2f", grade.Score), "1", 0, "R", false, 0, "")
+		pdf.CellFormat(20, 10, letter, "1", 0, "C", false, 0, "")
+		pdf.CellFormat(25, 10, reportPosition(grade.Position), "1", 0, "C", false, 0, "")
+		pdf.CellFormat(65, 10, remark, "1", 0, "C", false, 0, "")
+		pdf.Ln(-1)
 	}
 
+	// Totals and class position
+	total, _ := student.TotalScore.Float64Value()
+	mean, _ := student.MeanScore.Float64Value()
+	pdf.SetFont("Arial", "B", 12)
+	pdf.CellFormat(55, 10, "Total", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(25, 10, fmt.Sprintf("%.2f", total.Float64), "1", 0, "R", true, 0, "")
+	pdf.CellFormat(110, 10, "", "1", 0, "C", true, 0, "")
+	pdf.Ln(-1)
+	pdf.CellFormat(55, 10, "Average", "1", 0, "L", true, 0, "")
+	pdf.CellFormat(25, 10, fmt.Sprintf("%.2f", mean.Float64), "1", 0, "R", true, 0, "")
+	pdf.CellFormat(20, 10, scale.Letter(mean.Float64), "1", 0, "C", true, 0, "")
+	pdf.CellFormat(90, 10, "", "1", 0, "C", true, 0, "")
+	pdf.Ln(12)
+	pdf.Cell(190, 10, fmt.Sprintf("Position in Class: %s out of %d", reportPosition(int(student.ClassPosition)), student.ClassSize))
+	pdf.Ln(4)
+
 	// Add remarks section
 	pdf.Ln(10)
 	pdf.SetFont("Arial", "B", 12)
@@ -152,50 +188,349 @@ func createStudentReportPdf(term CachedTerm, student database.GetStudentReportCa
 	pdf.Cell(80, 10, "Class Teacher Signature: ______________")
 	pdf.Ln(10)
 	pdf.Cell(80, 10, "Head Teacher Signature: ______________")
+}
 
-	fileName := student.StudentNo
+// reportPosition formats a class or subject position, or "-" if there is none.
+func reportPosition(position int) string {
+	if position <= 0 {
+		return "-"
+	}
 
-	return fileName, pdf
+	return strconv.Itoa(position)
+}
+
+// safeFileName replaces every character of name that is not a letter, digit, '-' or '_' with '_',
+// so it can be used as a download's file name.
+func safeFileName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' {
+			return r
+		}
+		return '_'
+	}, name)
+}
+
+// reportCardsReleased reports whether the user may download the report cards of a class for a term,
+// writing an error response if not. Report cards are released once published.
+func (s *Server) reportCardsReleased(w http.ResponseWriter, r *http.Request, classID, termID uuid.UUID) bool {
+	approval, err := reportApproval(r.Context(), s.queries, classID, termID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to retrieve report card approval")
+		slog.Error("Failed to get report card approval", "error", err.Error())
+		return false
+	}
+
+	user, _ := r.Context().Value(userContextKey).(User)
+	if !canDownloadReportCards(user, approval.Status) {
+		writeError(w, r, http.StatusForbidden, "report cards for this class have not been published")
+		return false
+	}
+
+	return true
 }
 
-// GenerateStudentReportCard generates a PDF report card for a student
+// GenerateStudentReportCard generates a PDF report card for a student. The class_id query parameter
+// picks the class for a student who was in more than one during the term.
 func (s *Server) GenerateStudentReportCard(w http.ResponseWriter, r *http.Request) {
 	studentID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "Invalid student ID")
+		writeError(w, r, http.StatusBadRequest, "Invalid student ID")
 		return
 	}
 
+	termID, err := s.reportTermID(r)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	// A student who moved class during the term has a report card in each class.
+	var classID pgtype.UUID
+	if v := r.URL.Query().Get("class_id"); v != "" {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			writeAppError(w, r, NewAppError(http.StatusBadRequest, "invalid class").WithField("class_id", "must be a class ID"))
+			return
+		}
+		classID = pgtype.UUID{Bytes: id, Valid: true}
+	}
+
 	// Fetch student report data
-	student, err := s.queries.GetStudentReportCard(r.Context(), studentID)
+	student, err := s.queries.GetStudentReportCard(r.Context(), database.GetStudentReportCardParams{
+		StudentID: studentID,
+		TermID:    termID,
+		ClassID:   classID,
+	})
 	if err != nil {
-		writeError(w, http.StatusNotFound, "Student not found")
-		slog.Error("Student not found", "error", err.Error())
+		writeError(w, r, http.StatusNotFound, "Report card not found")
+		slog.Error("Report card not found", "error", err.Error())
+		return
+	}
+
+	if !s.reportCardsReleased(w, r, student.ClassID, termID) {
 		return
 	}
 
 	studentSubjects, err := s.queries.ListSubjects(r.Context(), student.ClassID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to retrieve student's subjects")
+		writeError(w, r, http.StatusInternalServerError, "Failed to retrieve student's subjects")
 		slog.Error("Failed to get student's subjects", "error", err.Error())
 		return
 	}
 
-	term, err := s.getCachedTerm()
+	scale, err := s.classGradingScale(r.Context(), student.ClassID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
-		slog.Error("Failed to retrive current academic term", "error", err.Error())
+		writeError(w, r, http.StatusInternalServerError, "Failed to retrieve grading scale")
+		slog.Error("Failed to get grading scale", "error", err.Error())
 		return
 	}
 
-	fileName, reportCard := createStudentReportPdf(term, student, studentSubjects)
+	fileName, reportCard := createStudentReportPdf(student, studentSubjects, scale)
 
 	// Serve PDF as response
 	w.Header().Set("Content-Type", "application/pdf")
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.pdf", fileName))
 	err = reportCard.Output(w)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to generate PDF")
+		writeError(w, r, http.StatusInternalServerError, "Failed to generate PDF")
 		slog.Error("PDF Generation Error:", "error", err.Error())
 	}
 }
+
+const (
+	// reportCardWorkers bounds how many report cards are rendered at the same time.
+	reportCardWorkers = 4
+	// reportCardPDFLimit is the most report cards downloaded as one PDF. The combined PDF is laid out
+	// in memory on a single goroutine, so larger classes are downloaded as a ZIP instead.
+	reportCardPDFLimit = 60
+	// reportCardWriteTimeout is how long writing each report card of a bulk download may take.
+	// The deadline is pushed back after every card so large classes outlast the server's WriteTimeout.
+	reportCardWriteTimeout = 30 * time.Second
+)
+
+// renderedReportCard is a report card PDF rendered by renderReportCards.
+type renderedReportCard struct {
+	FileName string
+	PDF      []byte
+	Err      error
+}
+
+// reportCardFromListRow converts a class report card row to the row used to render a single report card.
+func reportCardFromListRow(row database.ListStudentReportCardsRow) database.GetStudentReportCardRow {
+	return database.GetStudentReportCardRow{
+		StudentID:          row.StudentID,
+		StudentNo:          row.StudentNo,
+		LastName:           row.LastName,
+		FirstName:          row.FirstName,
+		MiddleName:         row.MiddleName,
+		ClassID:            row.ClassID,
+		ClassName:          row.ClassName,
+		TermID:             row.TermID,
+		TermName:           row.TermName,
+		AcademicYearName:   row.AcademicYearName,
+		Grades:             row.Grades,
+		TotalScore:         row.TotalScore,
+		MeanScore:          row.MeanScore,
+		ClassPosition:      row.ClassPosition,
+		ClassSize:          row.ClassSize,
+		ClassTeacherRemark: row.ClassTeacherRemark,
+		HeadTeacherRemark:  row.HeadTeacherRemark,
+	}
+}
+
+// renderReportCards renders a PDF per student on a pool of workers and sends them on the returned
+// channel in the order of students. At most workers cards are rendered ahead of the reader.
+// Rendering stops when ctx is done; the channel is closed once every card was sent or ctx is done.
+func renderReportCards(ctx context.Context, students []database.GetStudentReportCardRow, subjects []database.ListSubjectsRow, scale grading.Scale, workers int) <-chan renderedReportCard {
+	out := make(chan renderedReportCard)
+	pending := make(chan chan renderedReportCard, workers)
+	jobs := make(chan int)
+	results := make([]chan renderedReportCard, len(students))
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				fileName, pdf := createStudentReportPdf(students[i], subjects, scale)
+
+				var buf bytes.Buffer
+				err := pdf.Output(&buf)
+				results[i] <- renderedReportCard{FileName: fileName + ".pdf", PDF: buf.Bytes(), Err: err}
+			}
+		}()
+	}
+
+	// Hand out the students in order. pending holds the result of every card handed out and not
+	// yet read, so its capacity keeps the workers from running too far ahead.
+	go func() {
+		defer close(pending)
+		defer close(jobs)
+		for i := range students {
+			results[i] = make(chan renderedReportCard, 1)
+			select {
+			case pending <- results[i]:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	go func() {
+		defer close(out)
+		defer wg.Wait()
+		for result := range pending {
+			select {
+			case card := <-result:
+				select {
+				case out <- card:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// DownloadClassReportCards downloads the report cards of every student in a class for the selected term,
+// either as one PDF with a page per student (format=pdf, the default) or as a ZIP of PDFs named by
+// student number (format=zip). Classes with more than reportCardPDFLimit report cards can only be
+// downloaded as a ZIP. Only students with a class teacher's remark are included, the same as the
+// download buttons on the class report table, and only once the class's report cards are published.
+func (s *Server) DownloadClassReportCards(w http.ResponseWriter, r *http.Request) {
+	classID, err := uuid.Parse(r.PathValue("classID"))
+	if err != nil {
+		writeError(w, r, http.StatusBadRequest, "Invalid class ID")
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format == "" {
+		format = "pdf"
+	}
+	if format != "pdf" && format != "zip" {
+		writeAppError(w, r, NewAppError(http.StatusBadRequest, "invalid format").WithField("format", "must be pdf or zip"))
+		return
+	}
+
+	termID, err := s.reportTermID(r)
+	if err != nil {
+		writeAppError(w, r, err)
+		return
+	}
+
+	if !s.reportCardsReleased(w, r, classID, termID) {
+		return
+	}
+
+	classReports, err := s.queries.ListStudentReportCards(r.Context(), database.ListStudentReportCardsParams{
+		ClassID: classID,
+		TermID:  termID,
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to retrieve report cards")
+		slog.Error("failed to retrieve report cards", "error", err.Error())
+		return
+	}
+
+	var students []database.GetStudentReportCardRow
+	for _, report := range classReports {
+		if strings.TrimSpace(report.ClassTeacherRemark.String) != "" {
+			students = append(students, reportCardFromListRow(report))
+		}
+	}
+	if len(students) == 0 {
+		writeError(w, r, http.StatusNotFound, "no report cards are ready for this class")
+		return
+	}
+	if format == "pdf" && len(students) > reportCardPDFLimit {
+		msg := fmt.Sprintf("classes with more than %d report cards can only be downloaded as a ZIP", reportCardPDFLimit)
+		writeAppError(w, r, NewAppError(http.StatusUnprocessableEntity, msg).WithField("format", "must be zip"))
+		return
+	}
+
+	subjects, err := s.queries.ListSubjects(r.Context(), classID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to retrieve class subjects")
+		slog.Error("Failed to get class subjects", "error", err.Error())
+		return
+	}
+
+	scale, err := s.classGradingScale(r.Context(), classID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "Failed to retrieve grading scale")
+		slog.Error("Failed to get grading scale", "error", err.Error())
+		return
+	}
+
+	fileName := safeFileName(fmt.Sprintf("%s_%s_%s_report_cards", students[0].ClassName, students[0].AcademicYearName, students[0].TermName))
+
+	rc := http.NewResponseController(w)
+	extendDeadline := func() {
+		if err := rc.SetWriteDeadline(time.Now().Add(reportCardWriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			slog.Error("failed to extend write deadline", "error", err.Error())
+		}
+	}
+
+	if format == "pdf" {
+		// fpdf documents can't be merged, so the combined PDF is laid out page by page in one
+		// document, which reportCardPDFLimit keeps small.
+		pdf := fpdf.New(fpdf.OrientationPortrait, "mm", "A4", "")
+		for _, student := range students {
+			if r.Context().Err() != nil {
+				return
+			}
+			addReportCardPage(pdf, student, subjects, scale)
+		}
+
+		extendDeadline()
+		w.Header().Set("Content-Type", "application/pdf")
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.pdf", fileName))
+		if err := pdf.Output(w); err != nil {
+			slog.Error("PDF Generation Error:", "error", err.Error())
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/zip")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.zip", fileName))
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+
+	zw := zip.NewWriter(w)
+	for card := range renderReportCards(ctx, students, subjects, scale, reportCardWorkers) {
+		if card.Err != nil {
+			slog.Error("failed to render report card", "file", card.FileName, "error", card.Err.Error())
+			return
+		}
+
+		extendDeadline()
+		f, err := zw.Create(card.FileName)
+		if err == nil {
+			_, err = f.Write(card.PDF)
+		}
+		if err == nil {
+			err = zw.Flush()
+		}
+		if err != nil {
+			slog.Error("failed to write report card archive", "error", err.Error())
+			return
+		}
+		_ = rc.Flush()
+	}
+
+	if err := zw.Close(); err != nil {
+		slog.Error("failed to write report card archive", "error", err.Error())
+	}
+}
@@ -0,0 +1,91 @@
+// reports_test.go
+package server
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"school_management_system/internal/cache"
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func TestReportTermID(t *testing.T) {
+	s := &Server{cache: cache.New[string, any]()}
+	currentTermID, pastTermID := uuid.New(), uuid.New()
+
+	var appErr *AppError
+	req := httptest.NewRequest("GET", "/reports/reportcards", nil)
+	if _, err := s.reportTermID(req); !errors.As(err, &appErr) || appErr.Status != http.StatusNotFound {
+		t.Errorf("expected not found without an active term; got %v", err)
+	}
+
+	s.cache.Set(string(academicTermKey), CachedTerm{TermID: currentTermID})
+	if got, err := s.reportTermID(req); err != nil || got != currentTermID {
+		t.Errorf("expected the current term; got %v, %v", got, err)
+	}
+
+	req = httptest.NewRequest("GET", "/reports/reportcards?term_id="+pastTermID.String(), nil)
+	if got, err := s.reportTermID(req); err != nil || got != pastTermID {
+		t.Errorf("expected the requested term; got %v, %v", got, err)
+	}
+
+	req = httptest.NewRequest("GET", "/reports/reportcards?term_id=last", nil)
+	if _, err := s.reportTermID(req); !errors.As(err, &appErr) || appErr.Status != http.StatusBadRequest {
+		t.Errorf("expected bad request for an invalid term; got %v", err)
+	}
+}
+
+func TestRenderReportCards_KeepsStudentOrder(t *testing.T) {
+	var students []database.GetStudentReportCardRow
+	for _, no := range []string{"STU-001", "STU-002", "STU-003", "STU-004", "STU-005", "STU-006"} {
+		students = append(students, database.GetStudentReportCardRow{StudentNo: no, ClassName: "Form 1"})
+	}
+
+	var got []string
+	for card := range renderReportCards(context.Background(), students, nil, nil, 2) {
+		if card.Err != nil {
+			t.Fatalf("failed to render %s: %v", card.FileName, card.Err)
+		}
+		if !bytes.HasPrefix(card.PDF, []byte("%PDF")) {
+			t.Errorf("expected %s to be a PDF", card.FileName)
+		}
+		got = append(got, card.FileName)
+	}
+
+	if len(got) != len(students) {
+		t.Fatalf("expected %d report cards; got %d", len(students), len(got))
+	}
+	for i, student := range students {
+		if got[i] != student.StudentNo+".pdf" {
+			t.Errorf("expected %s.pdf at position %d; got %s", student.StudentNo, i, got[i])
+		}
+	}
+}
+
+func TestRenderReportCards_StopsWhenCancelled(t *testing.T) {
+	students := make([]database.GetStudentReportCardRow, 20)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cards := renderReportCards(ctx, students, nil, nil, 2)
+	<-cards
+	cancel()
+
+	// The channel must be closed once the workers notice the cancellation.
+	for range cards {
+	}
+}
+
+func TestReportPosition(t *testing.T) {
+	if got := reportPosition(3); got != "3" {
+		t.Errorf("expected 3; got %q", got)
+	}
+	if got := reportPosition(0); got != "-" {
+		t.Errorf("expected a dash without a position; got %q", got)
+	}
+}
@@ -0,0 +1,321 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+
+	"school_management_system/cmd/web/dashboard/userlist"
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// rolesManagePermission is the permission needed to change roles and permissions.
+// The admin role always keeps it so nobody can lock themselves out of this page.
+const rolesManagePermission = "roles.manage"
+
+// ShowRolePermissions renders every role with the permissions it grants.
+func (s *Server) ShowRolePermissions(w http.ResponseWriter, r *http.Request) {
+	roles, err := s.queries.ListRoles(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get roles")
+		slog.Error("failed to list roles", "error", err.Error())
+		return
+	}
+
+	permissions, err := s.queries.ListPermissions(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get permissions")
+		slog.Error("failed to list permissions", "error", err.Error())
+		return
+	}
+
+	rolePermissions, err := s.queries.ListRolePermissions(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get permissions")
+		slog.Error("failed to list role permissions", "error", err.Error())
+		return
+	}
+
+	granted := make(map[uuid.UUID]map[uuid.UUID]bool)
+	for _, rp := range rolePermissions {
+		if granted[rp.RoleID] == nil {
+			granted[rp.RoleID] = make(map[uuid.UUID]bool)
+		}
+		granted[rp.RoleID][rp.PermissionID] = true
+	}
+
+	s.renderComponent(w, r, userlist.RolePermissions(roles, permissions, granted))
+}
+
+// EditRolePermissions replaces the permissions granted by a role with the ones ticked in the form.
+// Changes apply to signed in users from their next request.
+func (s *Server) EditRolePermissions(w http.ResponseWriter, r *http.Request) {
+	roleID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid role id")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	permissionIDs, err := parseUUIDs(r.Form["permission"])
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid permission id")
+		return
+	}
+
+	roles, err := s.queries.ListRoles(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get roles")
+		slog.Error("failed to list roles", "error", err.Error())
+		return
+	}
+
+	var role database.Role
+	for _, rl := range roles {
+		if rl.RoleID == roleID {
+			role = rl
+		}
+	}
+	if role.RoleID == uuid.Nil {
+		writeError(w, r, http.StatusNotFound, "role not found")
+		return
+	}
+
+	permissions, err := s.queries.ListPermissions(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get permissions")
+		slog.Error("failed to list permissions", "error", err.Error())
+		return
+	}
+
+	known := make(map[uuid.UUID]string, len(permissions))
+	for _, p := range permissions {
+		known[p.PermissionID] = p.Name
+	}
+
+	keepsRolesManage := false
+	for _, id := range permissionIDs {
+		name, ok := known[id]
+		if !ok {
+			writeError(w, r, http.StatusUnprocessableEntity, "invalid permission id")
+			return
+		}
+		if name == rolesManagePermission {
+			keepsRolesManage = true
+		}
+	}
+
+	if role.Name == "admin" && !keepsRolesManage {
+		writeError(w, r, http.StatusUnprocessableEntity, "The admin role must keep the roles.manage permission")
+		return
+	}
+
+	tx, err := s.conn.Begin(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	defer tx.Rollback(r.Context())
+	qtx := s.queries.WithTx(tx)
+
+	rolePermissions, err := qtx.ListRolePermissions(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to list role permissions", "error", err.Error())
+		return
+	}
+
+	before := rolePermissionsAudit{Role: role.Name}
+	for _, rp := range rolePermissions {
+		if rp.RoleID == roleID {
+			before.Permissions = append(before.Permissions, known[rp.PermissionID])
+		}
+	}
+
+	after := rolePermissionsAudit{Role: role.Name}
+	for _, id := range permissionIDs {
+		after.Permissions = append(after.Permissions, known[id])
+	}
+
+	if err := qtx.DeleteRolePermissions(r.Context(), roleID); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to clear role permissions", "error", err.Error())
+		return
+	}
+
+	for _, permissionID := range permissionIDs {
+		params := database.AddRolePermissionParams{
+			RoleID:       roleID,
+			PermissionID: permissionID,
+		}
+
+		if err := qtx.AddRolePermission(r.Context(), params); err != nil {
+			writeError(w, r, http.StatusInternalServerError, "internal server error")
+			slog.Error("failed to add role permission", "error", err.Error())
+			return
+		}
+	}
+
+	if err := recordAudit(r, qtx, auditUpdate, auditRole, roleID, before, after); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to write audit log", "error", err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	writeSuccess(w, r, "Permissions updated")
+}
+
+// ShowUserRoles renders the modal for giving a user roles on top of their primary role.
+func (s *Server) ShowUserRoles(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid user id")
+		return
+	}
+
+	user, err := s.queries.GetUserDetails(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "user not found")
+		slog.Error("user not found", "error", err.Error())
+		return
+	}
+
+	roles, err := s.queries.ListRoles(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get roles")
+		slog.Error("failed to list roles", "error", err.Error())
+		return
+	}
+
+	userRoles, err := s.queries.ListUserRoles(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get roles")
+		slog.Error("failed to list user roles", "error", err.Error())
+		return
+	}
+
+	assigned := make(map[uuid.UUID]bool, len(userRoles))
+	for _, role := range userRoles {
+		assigned[role.RoleID] = true
+	}
+
+	s.renderComponent(w, r, userlist.UserRolesModal(user, roles, assigned))
+}
+
+// EditUserRoles replaces a user's additional roles with the ones ticked in the form.
+// The primary role is changed from the edit user form and always stays assigned.
+func (s *Server) EditUserRoles(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid user id")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	roleIDs, err := parseUUIDs(r.Form["role"])
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid role id")
+		return
+	}
+
+	tx, err := s.conn.Begin(r.Context())
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	defer tx.Rollback(r.Context())
+	qtx := s.queries.WithTx(tx)
+
+	before, err := qtx.ListUserRoles(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to list user roles", "error", err.Error())
+		return
+	}
+
+	if err := qtx.DeleteSecondaryUserRoles(r.Context(), userID); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to clear user roles", "error", err.Error())
+		return
+	}
+
+	for _, roleID := range roleIDs {
+		params := database.AddUserRoleParams{
+			UserID: userID,
+			RoleID: roleID,
+		}
+
+		if err := qtx.AddUserRole(r.Context(), params); err != nil {
+			writeError(w, r, http.StatusInternalServerError, "internal server error")
+			slog.Error("failed to add user role", "error", err.Error())
+			return
+		}
+	}
+
+	after, err := qtx.ListUserRoles(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to list user roles", "error", err.Error())
+		return
+	}
+
+	if err := recordAudit(r, qtx, auditUpdate, auditUser, userID, userRolesAudit{Roles: before}, userRolesAudit{Roles: after}); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to write audit log", "error", err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "" {
+		w.Header().Set("HX-Redirect", "/dashboard/userlist")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	http.Redirect(w, r, "/dashboard/userlist", http.StatusFound)
+}
+
+// rolePermissionsAudit is how a role's permissions are recorded in the audit log.
+type rolePermissionsAudit struct {
+	Role        string   `json:"role"`
+	Permissions []string `json:"permissions"`
+}
+
+// userRolesAudit is how a user's roles are recorded in the audit log.
+type userRolesAudit struct {
+	Roles []database.ListUserRolesRow `json:"roles"`
+}
+
+// parseUUIDs parses every value in a repeated form field.
+func parseUUIDs(values []string) ([]uuid.UUID, error) {
+	ids := make([]uuid.UUID, 0, len(values))
+	for _, v := range values {
+		id, err := uuid.Parse(v)
+		if err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
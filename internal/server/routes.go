@@ -27,7 +27,7 @@ func (s *Server) RegisterRoutes() http.Handler {
 	r.Use(cors.Handler(cors.Options{
 		AllowedOrigins:   []string{os.Getenv("DOMAIN")},
 		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS", "PATCH"},
-		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type"},
+		AllowedHeaders:   []string{"Accept", "Authorization", "Content-Type", "X-CSRF-Token"},
 		AllowCredentials: true,
 		MaxAge:           300,
 	}))
@@ -38,14 +38,21 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	// PUBLIC ROUTES
 	r.Group(func(r chi.Router) {
+		r.Use(csrfProtect)
+
 		r.Get("/", templ.Handler(web.Home()).ServeHTTP)
 		r.With(s.RedirectIfAuthenticated).Get("/login", templ.Handler(web.Login()).ServeHTTP)
 		r.Post("/login", s.LoginHandler)
+		r.Get("/login/2fa", s.ShowLoginTOTP)
+		r.Post("/login/2fa", s.VerifyLoginTOTP)
+		r.Get("/reset", templ.Handler(web.ResetPassword()).ServeHTTP)
+		r.Post("/reset", s.ResetPasswordWithCode)
 	})
 
 	// AUTHENTICATED USER ROUTES
 	r.Group(func(r chi.Router) {
 		r.Use(s.AuthMiddleware)
+		r.Use(csrfProtect)
 
 		r.Get("/profile", s.userProfile)
 		r.Get("/logout/confirm", s.LogoutConfirmHandler)
@@ -55,8 +62,9 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	// USER MANAGEMENT (ADMIN)
 	r.Route("/users", func(r chi.Router) {
-		r.Use(s.AuthMiddleware)
-		r.Use(s.RequireRoles("admin"))
+		r.Use(s.APIAuthMiddleware)
+		r.Use(csrfProtect)
+		r.Use(s.RequirePermission("users.manage"))
 
 		// Registration routes
 		r.Get("/create", s.showCreateUserPage)
@@ -70,14 +78,61 @@ func (s *Server) RegisterRoutes() http.Handler {
 		r.Get("/{id}/delete", s.ShowDeleteConfirmation)
 		r.Delete("/{id}", s.DeleteUser)
 
+		// Session routes
+		r.Get("/{id}/sessions", s.ShowUserSessionsForAdmin)
+		r.Delete("/{id}/sessions", s.SignOutEverywhere)
+
+		// Password reset routes
+		r.Post("/{id}/reset-code", s.IssueResetCode)
+		r.Delete("/{id}/2fa", s.ResetTwoFactor)
+
+		// Role routes
+		r.With(s.RequirePermission("roles.manage")).Get("/{id}/roles", s.ShowUserRoles)
+		r.With(s.RequirePermission("roles.manage")).Put("/{id}/roles", s.EditUserRoles)
+
+		// Lockout routes
+		r.Get("/locked", s.ShowLockedAccounts)
+		r.Put("/{id}/unlock", s.UnlockUser)
+
 		// Download route
 		r.Get("/download", s.userDownload)
 	})
 
+	// ROLES AND PERMISSIONS (ADMIN)
+	r.Route("/roles", func(r chi.Router) {
+		r.Use(s.APIAuthMiddleware)
+		r.Use(csrfProtect)
+		r.Use(s.RequirePermission("roles.manage"))
+
+		r.Get("/", s.ShowRolePermissions)
+		r.Put("/{id}/permissions", s.EditRolePermissions)
+	})
+
+	// AUDIT LOG (ADMIN)
+	r.Route("/audit", func(r chi.Router) {
+		r.Use(s.APIAuthMiddleware)
+		r.Use(csrfProtect)
+		r.Use(s.RequirePermission("audit.view"))
+
+		r.Get("/", s.ShowAuditLog)
+		r.Get("/export", s.ExportAuditLog)
+	})
+
+	// RECYCLE BIN (ADMIN)
+	r.Route("/recycle-bin", func(r chi.Router) {
+		r.Use(s.APIAuthMiddleware)
+		r.Use(csrfProtect)
+		r.Use(s.RequirePermission("recycle_bin.manage"))
+
+		r.Get("/", s.ShowRecycleBin)
+		r.Put("/{type}/{id}/restore", s.RestoreDeleted)
+	})
+
 	// DASHBOARD
 	r.Route("/dashboard", func(r chi.Router) {
-		r.Use(s.AuthMiddleware)
-		r.Use(s.RequireRoles("admin", "teacher", "classteacher", "headteacher", "accountant"))
+		r.Use(s.APIAuthMiddleware)
+		r.Use(csrfProtect)
+		r.Use(s.RequirePermission("dashboard.view"))
 		r.Get("/academics", s.GetAcademicsDetails)
 		r.Get("/assigned_classes", s.getAssignedClasses)
 		r.Get("/", s.Dashboard)
@@ -91,8 +146,9 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	// ACADEMIC ADMINISTRATION (ADMIN)
 	r.Route("/academics", func(r chi.Router) {
-		r.Use(s.AuthMiddleware)
-		r.Use(s.RequireRoles("admin"))
+		r.Use(s.APIAuthMiddleware)
+		r.Use(csrfProtect)
+		r.Use(s.RequirePermission("academics.manage"))
 
 		r.Get("/years", s.ListAcademicYears)
 		r.Get("/create", s.ShowCreateAcademicYear)
@@ -105,6 +161,10 @@ func (s *Server) RegisterRoutes() http.Handler {
 		r.Get("/terms/{id}/edit", s.ShowEditAcademicTerm)
 		r.Put("/terms/{id}", s.EditTerm)
 		r.Get("/year/{id}/terms", s.ListTerms)
+		r.Get("/terms/{id}/grade-entry", s.ShowGradeEntry)
+		r.Post("/terms/{id}/grade-entry/windows", s.SaveGradeEntryWindow)
+		r.Delete("/grade-entry/windows/{id}", s.DeleteGradeEntryWindow)
+		r.Post("/terms/{id}/grade-entry/unlocks", s.UnlockGradeEntry)
 
 		r.Put("/years/{id}/toggle", s.setActiveYear)
 		r.Put("/terms/{id}/toggle/{academicYearStatus}", s.setActiveTerm)
@@ -124,6 +184,8 @@ func (s *Server) RegisterRoutes() http.Handler {
 		r.Get("/subjects/{id}/edit", s.ShowEditSubject)
 		r.Put("/subjects/{id}", s.EditSubject)
 		r.Delete("/subjects/{id}", s.DeleteSubject)
+		r.Get("/subjects/{id}/components", s.ShowAssessmentComponents)
+		r.Put("/subjects/{id}/components", s.SaveAssessmentComponents)
 
 		// classteacher routes
 		r.Get("/classteacher/{class_id}", s.showClassTeachers)
@@ -140,10 +202,26 @@ func (s *Server) RegisterRoutes() http.Handler {
 		r.Delete("/assignments/{id}", s.DeleteAssignment)
 	})
 
+	// GRADING SCALES (ADMIN)
+	r.Route("/grading", func(r chi.Router) {
+		r.Use(s.APIAuthMiddleware)
+		r.Use(csrfProtect)
+		r.Use(s.RequirePermission("grading.manage"))
+
+		r.Get("/", s.ShowGradingScales)
+		r.Get("/create", s.ShowCreateGradingScale)
+		r.Post("/", s.CreateGradingScale)
+		r.Get("/{id}/edit", s.ShowEditGradingScale)
+		r.Put("/{id}", s.EditGradingScale)
+		r.Delete("/{id}", s.DeleteGradingScale)
+		r.Put("/classes/{classID}", s.AssignClassGradingScale)
+	})
+
 	// STUDENT MANAGEMENT (ADMIN)
 	r.Route("/students", func(r chi.Router) {
-		r.Use(s.AuthMiddleware)
-		r.Use(s.RequireRoles("admin"))
+		r.Use(s.APIAuthMiddleware)
+		r.Use(csrfProtect)
+		r.Use(s.RequirePermission("students.manage"))
 
 		r.Get("/", s.ListStudents)
 		r.Get("/create", s.ShowCreateStudent)
@@ -157,8 +235,9 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	// STUDENT'S GUARDIAN(ADMIN, CLASS TEACHER, HEADTEACHER)
 	r.Route("/guardians", func(r chi.Router) {
-		r.Use(s.AuthMiddleware)
-		r.Use(s.RequireRoles("admin", "classteacher", "headteacher"))
+		r.Use(s.APIAuthMiddleware)
+		r.Use(csrfProtect)
+		r.Use(s.RequirePermission("guardians.manage"))
 		r.Post("/search", s.SearchGuardian)
 		r.Get("/", s.ListGuardians)
 		r.Get("/{id}/edit", s.ShowEditGuardian)
@@ -167,26 +246,33 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	// ACADEMIC RECORDS
 	r.Route("/grades", func(r chi.Router) {
-		r.Use(s.AuthMiddleware)
-		r.Use(s.RequireRoles("teacher", "classteacher"))
+		r.Use(s.APIAuthMiddleware)
+		r.Use(csrfProtect)
+		r.Use(s.RequirePermission("grades.submit"))
 		r.Get("/myclasses", s.MyClasses)
 		r.Get("/form/{classID}", s.GetClassForm)
 		r.Post("/submit", s.SubmitGrades)
+		r.Get("/template/{classID}", s.DownloadGradeTemplate)
+		r.Post("/import/{classID}", s.ImportGrades)
+		r.Post("/import/{classID}/confirm", s.ConfirmGradeImport)
 		r.Get("/", s.ListGrades)
+		r.Get("/{id}/history", s.ShowGradeHistory)
 	})
 
 	// Remarks
 	r.Route("/remarks", func(r chi.Router) {
-		r.Use(s.AuthMiddleware)
-		r.Use(s.RequireRoles("classteacher", "headteacher"))
+		r.Use(s.APIAuthMiddleware)
+		r.Use(csrfProtect)
+		r.Use(s.RequirePermission("remarks.submit"))
 		r.Get("/", s.StudentsRemarks)
 		r.Post("/submit", s.SubmitRemarks)
 	})
 
 	// Discipline
 	r.Route("/discipline", func(r chi.Router) {
-		r.Use(s.AuthMiddleware)
-		r.Use(s.RequireRoles("headteacher", "classteacher"))
+		r.Use(s.APIAuthMiddleware)
+		r.Use(csrfProtect)
+		r.Use(s.RequirePermission("discipline.manage"))
 
 		r.Get("/", s.StudentsDisciplinary)
 		r.Get("/new", s.ShowDisciplineForm)
@@ -196,18 +282,26 @@ func (s *Server) RegisterRoutes() http.Handler {
 
 	// Reports
 	r.Route("/reports", func(r chi.Router) {
-		r.Use(s.AuthMiddleware)
-		r.Use(s.RequireRoles("headteacher", "classteacher"))
+		r.Use(s.APIAuthMiddleware)
+		r.Use(csrfProtect)
+		r.Use(s.RequirePermission("reports.view"))
 
 		r.Get("/reportcards", s.ShowStudentsReports)
 		r.Get("/class/{classID}", s.ShowClassReports)
+		r.Get("/class/{classID}/download", s.DownloadClassReportCards)
+		r.Post("/class/{classID}/approval", s.UpdateReportApproval)
 		r.Get("/reportcards/{id}/download", s.GenerateStudentReportCard)
+		r.Get("/annual", s.ShowAnnualReports)
+		r.Get("/annual/class/{classID}", s.ShowClassAnnualReports)
+		r.Get("/annual/{id}/download", s.GenerateAnnualReportCard)
+		r.Get("/transcripts/{id}/download", s.GenerateStudentTranscript)
 	})
 
 	// Promotions
 	r.Route("/promotions", func(r chi.Router) {
-		r.Use(s.AuthMiddleware)
-		r.Use(s.RequireRoles("admin"))
+		r.Use(s.APIAuthMiddleware)
+		r.Use(csrfProtect)
+		r.Use(s.RequirePermission("promotions.manage"))
 		r.Get("/", s.ShowPromotionPage)
 		r.Get("/create", s.ShowSetupPromotionPage)
 		r.Post("/create", s.SubmitPromotions)
@@ -223,33 +317,87 @@ func (s *Server) RegisterRoutes() http.Handler {
 	// Graduates
 	r.Route("/graduates", func(r chi.Router) {
 		r.Use(s.AuthMiddleware)
+		r.Use(csrfProtect)
 		r.Get("/", s.ShowGraduatePage)
 		r.Post("/", s.ShowGraduatesList)
 	})
 
 	r.Route("/fees", func(r chi.Router) {
-		r.Use(s.AuthMiddleware)
-		r.Use(s.RequireRoles("accountant"))
+		r.Use(s.APIAuthMiddleware)
+		r.Use(csrfProtect)
+		r.Use(s.RequirePermission("fees.view"))
 
 		r.Get("/structure", s.ShowSetTuition)
-		r.Post("/structure", s.SetFeesStructure)
+		r.With(s.RequirePermission("fees.edit")).Post("/structure", s.SetFeesStructure)
 
 		r.Get("/", s.ShowFeesList)
 		r.Get("/class/{classID}", s.ShowClassFees)
 
 		r.Get("/details", s.GetFees)
 
-		r.Post("/create", s.SaveFeesRecord)
+		r.With(s.RequirePermission("fees.edit")).Post("/create", s.SaveFeesRecord)
 		r.Get("/create/{classID}", s.ShowCreateFeesRecordForStudent)
 
 		r.Get("/{feesID}/edit", s.ShowEditFeesRecord)
-		r.Put("/edit/{feesID}", s.EditFeesRecord)
+		r.With(s.RequirePermission("fees.edit")).Put("/edit/{feesID}", s.EditFeesRecord)
 	})
 
 	r.Route("/settings", func(r chi.Router) {
 		r.Use(s.AuthMiddleware)
+		r.Use(csrfProtect)
 		r.Get("/user", s.ShowUserSettings)
 		r.Put("/user", s.EditUserProfile)
+		r.Get("/user/2fa", s.ShowTwoFactorSettings)
+		r.Post("/user/2fa", s.EnableTwoFactor)
+		r.Post("/user/2fa/recovery-codes", s.RegenerateRecoveryCodes)
+		r.Post("/user/2fa/disable", s.DisableTwoFactor)
+		r.Get("/password", s.ShowChangePassword)
+		r.Put("/password", s.ChangePassword)
+		r.Get("/sessions", s.ShowUserSessions)
+		r.Delete("/sessions/{id}", s.RevokeSession)
+		r.Get("/tokens", s.ShowAPITokens)
+		r.Post("/tokens", s.CreateAPIToken)
+		r.Delete("/tokens/{id}", s.RevokeAPIToken)
+	})
+
+	// API DOCUMENTATION
+	r.Get("/api/openapi.json", s.OpenAPISpec)
+	r.Get("/api/docs", templ.Handler(web.APIDocs()).ServeHTTP)
+
+	// JSON API (API TOKENS ONLY)
+	r.Route("/api/v1", func(r chi.Router) {
+		r.Use(s.APITokenAuth)
+		r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+			writeAPIError(w, http.StatusNotFound, "not_found", "no such endpoint")
+		})
+		r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+			writeAPIError(w, http.StatusMethodNotAllowed, "method_not_allowed", "method not allowed")
+		})
+
+		r.With(s.APIRequirePermission("users.manage")).Get("/users", s.apiListUsers)
+		r.With(s.APIRequirePermission("users.manage")).Get("/users/{id}", s.apiGetUser)
+
+		r.With(s.APIRequirePermission("students.manage")).Get("/students", s.apiListStudents)
+		r.With(s.APIRequirePermission("students.manage")).Get("/students/{id}", s.apiGetStudent)
+
+		r.With(s.APIRequirePermission("guardians.manage")).Get("/guardians", s.apiListGuardians)
+		r.With(s.APIRequirePermission("guardians.manage")).Get("/guardians/{id}", s.apiGetGuardian)
+
+		r.With(s.APIRequirePermission("academics.manage")).Get("/classes", s.apiListClasses)
+		r.With(s.APIRequirePermission("academics.manage")).Get("/classes/{id}", s.apiGetClass)
+		r.With(s.APIRequirePermission("academics.manage")).Get("/subjects", s.apiListSubjects)
+		r.With(s.APIRequirePermission("academics.manage")).Get("/subjects/{id}", s.apiGetSubject)
+		r.With(s.APIRequirePermission("academics.manage")).Get("/terms", s.apiListTerms)
+		r.With(s.APIRequirePermission("academics.manage")).Get("/terms/current", s.apiGetCurrentTerm)
+
+		r.With(s.APIRequirePermission("grades.submit")).Get("/grades", s.apiListGrades)
+
+		r.With(s.APIRequirePermission("fees.view")).Get("/fees", s.apiListFees)
+		r.With(s.APIRequirePermission("fees.view")).Get("/fees/{id}", s.apiGetFeesRecord)
+
+		r.With(s.APIRequirePermission("remarks.submit")).Get("/remarks", s.apiListRemarks)
+
+		r.With(s.APIRequirePermission("discipline.manage")).Get("/discipline", s.apiListDiscipline)
 	})
 
 	return r
@@ -48,6 +48,12 @@ func TestRoutes(t *testing.T) {
 			target:         "/login",
 			expectedStatus: http.StatusOK,
 		},
+		{
+			name:           "Password reset GET",
+			method:         http.MethodGet,
+			target:         "/reset",
+			expectedStatus: http.StatusOK,
+		},
 		{
 			name:           "Non-existent route returns 404",
 			method:         http.MethodGet,
@@ -132,6 +138,12 @@ func TestProtectedRoutes(t *testing.T) {
 			target:         "/discipline",
 			expectedStatus: http.StatusUnauthorized,
 		},
+		{
+			name:           "Audit log require auth",
+			method:         http.MethodGet,
+			target:         "/audit",
+			expectedStatus: http.StatusUnauthorized,
+		},
 		{
 			name:           "Reports require auth",
 			method:         http.MethodGet,
@@ -162,6 +174,12 @@ func TestProtectedRoutes(t *testing.T) {
 			target:         "/settings/user",
 			expectedStatus: http.StatusUnauthorized,
 		},
+		{
+			name:           "Session management require auth",
+			method:         http.MethodGet,
+			target:         "/settings/sessions",
+			expectedStatus: http.StatusUnauthorized,
+		},
 	}
 
 	for _, tt := range protectedEndpoints {
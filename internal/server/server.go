@@ -4,7 +4,6 @@ import (
 	"context"
 	"database/sql"
 	"embed"
-	"encoding/hex"
 	"fmt"
 	"log/slog"
 	"net/http"
@@ -13,6 +12,7 @@ import (
 	"time"
 
 	"school_management_system/internal/cache"
+	"school_management_system/internal/cookies"
 	"school_management_system/internal/database"
 
 	"github.com/google/uuid"
@@ -26,11 +26,16 @@ import (
 )
 
 type Server struct {
-	queries   *database.Queries
-	conn      *pgxpool.Pool
-	cache     *cache.Cache[string, any]
-	SecretKey []byte
-	port      int
+	queries    *database.Queries
+	conn       *pgxpool.Pool
+	cache      *cache.Cache[string, any]
+	Keys       *cookies.Keyring
+	port       int
+	throttle   loginThrottle
+	passwords  passwordPolicy
+	twoFactor  twoFactorPolicy
+	recycleBin recycleBinPolicy
+	proxies    trustedProxies
 }
 
 //go:embed sql/schema/*.sql
@@ -71,9 +76,10 @@ func NewServer() (*Server, *http.Server) {
 	validateEnvVars()
 	setUpMigration()
 
-	SecretKey, err := hex.DecodeString(os.Getenv("RANDOM_HEX"))
+	keys, err := cookies.ParseKeyring(os.Getenv("RANDOM_HEX"), os.Getenv("RANDOM_HEX_PREVIOUS"))
 	if err != nil {
-		slog.Error(err.Error())
+		slog.Error("Unable to load cookie secret keys", "error", err.Error())
+		os.Exit(1)
 	}
 
 	ctx := context.Background()
@@ -89,15 +95,21 @@ func NewServer() (*Server, *http.Server) {
 	appCache := cache.New[string, any]()
 
 	appServer := &Server{
-		port:      port,
-		conn:      conn,
-		queries:   generatedQeries,
-		cache:     appCache,
-		SecretKey: SecretKey,
+		port:       port,
+		conn:       conn,
+		queries:    generatedQeries,
+		cache:      appCache,
+		Keys:       keys,
+		throttle:   loadLoginThrottle(),
+		passwords:  loadPasswordPolicy(),
+		twoFactor:  loadTwoFactorPolicy(),
+		recycleBin: loadRecycleBinPolicy(),
+		proxies:    loadTrustedProxies(),
 	}
 
 	appServer.setUpCache(ctx)
 	appServer.createSuperUser(ctx)
+	go appServer.runRecycleBinPurge(ctx)
 
 	// Declare Server config
 	httpserver := &http.Server{
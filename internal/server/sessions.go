@@ -0,0 +1,129 @@
+package server
+
+import (
+	"log/slog"
+	"net/http"
+
+	"school_management_system/cmd/web/dashboard/userlist"
+	"school_management_system/cmd/web/settings"
+	"school_management_system/internal/database"
+
+	"github.com/google/uuid"
+)
+
+// ShowUserSessions lists the devices the logged in user is currently signed in on.
+func (s *Server) ShowUserSessions(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "not logged in")
+		return
+	}
+
+	currentSessionID, _ := r.Context().Value(sessionIDKey).(uuid.UUID)
+
+	sessions, err := s.queries.ListUserSessions(r.Context(), user.UserID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get sessions")
+		slog.Error("failed to list user sessions", "error", err.Error())
+		return
+	}
+
+	s.renderComponent(w, r, settings.UserSessions(sessions, currentSessionID))
+}
+
+// RevokeSession signs the logged in user out of a single device.
+// Revoking the current session behaves like a logout.
+func (s *Server) RevokeSession(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "not logged in")
+		return
+	}
+
+	sessionID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid session id")
+		return
+	}
+
+	params := database.DeleteUserSessionParams{
+		SessionID: sessionID,
+		UserID:    user.UserID,
+	}
+
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		if err := qtx.DeleteUserSession(r.Context(), params); err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditDelete, auditSession, sessionID, params, nil)
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to revoke session")
+		slog.Error("failed to revoke session", "error", err.Error())
+		return
+	}
+
+	if currentSessionID, _ := r.Context().Value(sessionIDKey).(uuid.UUID); currentSessionID == sessionID {
+		if r.Header.Get("HX-Request") != "" {
+			w.Header().Set("HX-Redirect", "/login")
+			w.WriteHeader(http.StatusOK)
+			return
+		}
+
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	s.ShowUserSessions(w, r)
+}
+
+// ShowUserSessionsForAdmin renders the sessions a user currently holds so an admin can review them.
+func (s *Server) ShowUserSessionsForAdmin(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid user id")
+		return
+	}
+
+	user, err := s.queries.GetUserDetails(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "user not found")
+		slog.Error("user not found", "error", err.Error())
+		return
+	}
+
+	sessions, err := s.queries.ListUserSessions(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get sessions")
+		slog.Error("failed to list user sessions", "error", err.Error())
+		return
+	}
+
+	s.renderComponent(w, r, userlist.UserSessionsModal(user, sessions))
+}
+
+// SignOutEverywhere revokes every session held by a user.
+func (s *Server) SignOutEverywhere(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid user id")
+		return
+	}
+
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		if err := qtx.DeleteUserSessions(r.Context(), userID); err != nil {
+			return err
+		}
+
+		after := map[string]any{"sessions": 0}
+		return recordAudit(r, qtx, auditUpdate, auditUser, userID, nil, after)
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to revoke sessions")
+		slog.Error("failed to revoke user sessions", "error", err.Error())
+		return
+	}
+
+	s.ShowUserSessionsForAdmin(w, r)
+}
@@ -15,14 +15,14 @@ import (
 func (s *Server) ShowUserSettings(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(userContextKey).(User)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "not logged in")
+		writeError(w, r, http.StatusUnauthorized, "not logged in")
 		slog.Error("user not logged in, failed to read userID from userContextKey")
 		return
 	}
 
 	userDetails, err := s.queries.GetUserDetails(r.Context(), user.UserID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to fetch user")
+		writeError(w, r, http.StatusInternalServerError, "failed to fetch user")
 		slog.Error("failed to fetch user", "UserID", user.UserID, "error", err.Error())
 		return
 	}
@@ -35,13 +35,13 @@ func (s *Server) ShowUserSettings(w http.ResponseWriter, r *http.Request) {
 func (s *Server) EditUserProfile(w http.ResponseWriter, r *http.Request) {
 	user, ok := r.Context().Value(userContextKey).(User)
 	if !ok {
-		writeError(w, http.StatusUnauthorized, "not logged in")
+		writeError(w, r, http.StatusUnauthorized, "not logged in")
 		slog.Error("user not logged in, failed to read userID from userContextKey")
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusBadRequest, "Bad Request")
+		writeError(w, r, http.StatusBadRequest, "Bad Request")
 		return
 	}
 
@@ -54,43 +54,26 @@ func (s *Server) EditUserProfile(w http.ResponseWriter, r *http.Request) {
 	newPassword := r.FormValue("new_password")
 	confirmPassword := r.FormValue("confirm_password")
 
-	if len(strings.TrimSpace(currentPassword)) > 0 {
+	if len(strings.TrimSpace(newPassword)) > 0 {
+		if err := s.passwords.Validate(newPassword, phoneNumber); err != nil {
+			writeError(w, r, http.StatusUnprocessableEntity, err.Error())
+			return
+		}
+
 		if confirmPassword != newPassword {
-			w.Header().Set("Content-Type", "text/html")
-			_, _ = w.Write([]byte(`
-					<div id="popover" class="custom-popover show" style="background-color: #dc2626;">
-						<span>❌ New Password does not match confirmed password</span>
-					</div>
-					<script>
-						setTimeout(() => {
-							document.getElementById('popover').classList.add('hide');
-							setTimeout(() => document.getElementById('popover').remove(), 500);
-						}, 3000);
-					</script>
-				`))
+			writeError(w, r, http.StatusUnprocessableEntity, "New Password does not match confirmed password")
 			return
 
 		}
 		userDetails, err := s.queries.GetUserDetails(r.Context(), user.UserID)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, "failed to get user")
+			writeError(w, r, http.StatusInternalServerError, "failed to get user")
 			slog.Error("failed to get user", "error", err.Error())
 			return
 		}
 
 		if err := bcrypt.CompareHashAndPassword([]byte(userDetails.Password), []byte(currentPassword)); err != nil {
-			w.Header().Set("Content-Type", "text/html")
-			_, _ = w.Write([]byte(`
-					<div id="popover" class="custom-popover show" style="background-color: #dc2626;">
-						<span>❌ Incorrect Current Password</span>
-					</div>
-					<script>
-						setTimeout(() => {
-							document.getElementById('popover').classList.add('hide');
-							setTimeout(() => document.getElementById('popover').remove(), 500);
-						}, 3000);
-					</script>
-				`))
+			writeError(w, r, http.StatusUnprocessableEntity, "Incorrect Current Password")
 			return
 		}
 
@@ -105,13 +88,20 @@ func (s *Server) EditUserProfile(w http.ResponseWriter, r *http.Request) {
 
 	tx, err := s.conn.Begin(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
 	defer tx.Rollback(r.Context())
 	qtx := s.queries.WithTx(tx)
 
+	before, err := qtx.GetUserDetails(r.Context(), user.UserID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get user")
+		slog.Error("failed to get user", "error", err.Error())
+		return
+	}
+
 	updateInfo := database.EditMyProfileParams{
 		UserID:      user.UserID,
 		FirstName:   firstName,
@@ -123,14 +113,14 @@ func (s *Server) EditUserProfile(w http.ResponseWriter, r *http.Request) {
 
 	err = qtx.EditMyProfile(r.Context(), updateInfo)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
 	if len(strings.TrimSpace(newPassword)) > 0 {
 		hashedPassword, err := hashPassword(newPassword)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, "internal server error")
+			writeError(w, r, http.StatusInternalServerError, "internal server error")
 			return
 		}
 
@@ -141,30 +131,138 @@ func (s *Server) EditUserProfile(w http.ResponseWriter, r *http.Request) {
 
 		err = qtx.EditMyPassword(r.Context(), changePasswdParams)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, "internal server error")
+			writeError(w, r, http.StatusInternalServerError, "internal server error")
 			slog.Error("failed to change password", ":", err.Error())
 			return
 		}
 	}
 
-	tx.Commit(r.Context())
+	after, err := qtx.GetUserDetails(r.Context(), user.UserID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to get user", "error", err.Error())
+		return
+	}
+
+	if err := recordAudit(r, qtx, auditUpdate, auditUser, user.UserID, before, after); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to write audit log", "error", err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to commit transaction", "error", err.Error())
+		return
+	}
 
 	if r.Header.Get("HX-Request") != "" {
-		w.Header().Set("Content-Type", "text/html")
-		_, _ = w.Write([]byte(`
-			<div id="popover" class="custom-popover show" style="background-color: #16a34a;">
-				<span>✅ Profile updated successfully</span>
-			</div>
-			<script>
-				setTimeout(() => {
-					document.getElementById('popover').classList.add('hide');
-					setTimeout(() => document.getElementById('popover').remove(), 500);
-				}, 3000);
-				window.location.reload()
-			</script>
-		`))
+		w.Header().Set("HX-Refresh", "true")
+		writeSuccess(w, r, "Profile updated successfully")
 		return
 	}
 
 	http.Redirect(w, r, "/dashboard", http.StatusFound)
 }
+
+// ShowChangePassword renders the change password form.
+// Users whose password was issued by an admin are redirected here until they pick their own.
+func (s *Server) ShowChangePassword(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "not logged in")
+		return
+	}
+
+	userDetails, err := s.queries.GetUserDetails(r.Context(), user.UserID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to fetch user")
+		slog.Error("failed to fetch user", "UserID", user.UserID, "error", err.Error())
+		return
+	}
+
+	s.renderComponent(w, r, settings.ChangePassword(userDetails.MustChangePassword, s.passwords.MinLength))
+}
+
+// ChangePassword replaces the logged in user's password after checking the current one
+// and the password policy, clearing any pending forced change.
+func (s *Server) ChangePassword(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "not logged in")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	currentPassword := r.FormValue("current_password")
+	newPassword := r.FormValue("new_password")
+	confirmPassword := r.FormValue("confirm_password")
+
+	userDetails, err := s.queries.GetUserDetails(r.Context(), user.UserID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get user")
+		slog.Error("failed to get user", "error", err.Error())
+		return
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(userDetails.Password), []byte(currentPassword)); err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "Incorrect Current Password")
+		return
+	}
+
+	if newPassword != confirmPassword {
+		writeError(w, r, http.StatusUnprocessableEntity, "New Password does not match confirmed password")
+		return
+	}
+
+	if newPassword == currentPassword {
+		writeError(w, r, http.StatusUnprocessableEntity, "New Password must be different from the current password")
+		return
+	}
+
+	if err := s.passwords.Validate(newPassword, userDetails.PhoneNumber.String); err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+
+	hashedPassword, err := hashPassword(newPassword)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		return
+	}
+
+	changePasswdParams := database.EditMyPasswordParams{
+		UserID:   user.UserID,
+		Password: string(hashedPassword),
+	}
+
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		if err := qtx.EditMyPassword(r.Context(), changePasswdParams); err != nil {
+			return err
+		}
+
+		after, err := qtx.GetUserDetails(r.Context(), user.UserID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditUpdate, auditUser, user.UserID, userDetails, after)
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to change password", "error", err.Error())
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "" {
+		w.Header().Set("HX-Redirect", "/dashboard")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	http.Redirect(w, r, "/dashboard", http.StatusSeeOther)
+}
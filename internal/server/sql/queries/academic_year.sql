@@ -24,8 +24,8 @@ DELETE FROM academic_year
 WHERE academic_year_id = $1;
 
 -- name: CreateTerm :one
-INSERT INTO term (academic_year_id, name, start_date, end_date) 
-VALUES ($1, $2, $3, $4)
+INSERT INTO term (academic_year_id, name, start_date, end_date, report_weight) 
+VALUES ($1, $2, $3, $4, $5)
 ON CONFLICT ON CONSTRAINT term_name_on_academic_year DO NOTHING 
 RETURNING term_id;
 
@@ -38,7 +38,8 @@ academic_year.active AS status,
 term.name AS Academic_Term,
 term.start_date AS Opening_date,
 term.end_date AS Closing_date,
-term.active
+term.active,
+term.report_weight
 FROM term
 INNER JOIN academic_year
 ON
@@ -54,7 +55,8 @@ academic_year.name AS Academic_Year,
 term.name AS Academic_Term,
 term.previous_term_id,
 term.start_date AS Opening_date,
-term.end_date AS Closing_date
+term.end_date AS Closing_date,
+term.report_weight
 FROM term
 INNER JOIN academic_year
 ON
@@ -65,7 +67,8 @@ WHERE term_id = $1;
 UPDATE term 
 SET name = COALESCE($2, name),
 start_date = COALESCE($3, start_date),
-end_date = COALESCE($4, end_date)
+end_date = COALESCE($4, end_date),
+report_weight = COALESCE($5, report_weight)
 WHERE term_id = $1;
 
 -- name: DeleteTerm :exec
@@ -131,3 +134,28 @@ SELECT
 FROM term t
 WHERE t.active = TRUE
 LIMIT 1;
+
+-- name: ListAllTerms :many
+SELECT
+    term.term_id,
+    academic_year.name AS Academic_Year,
+    term.name AS Academic_Term,
+    term.active
+FROM term
+INNER JOIN academic_year
+ON
+term.academic_year_id = academic_year.academic_year_id
+ORDER BY term.start_date DESC;
+
+-- name: ListAllAcademicYears :many
+SELECT * FROM academic_year
+ORDER BY start_date DESC;
+
+-- name: ListAcademicYearTerms :many
+SELECT
+    term_id,
+    name AS Academic_Term,
+    report_weight
+FROM term
+WHERE academic_year_id = $1
+ORDER BY start_date;
@@ -0,0 +1,48 @@
+-- name: GetAnnualReportCard :one
+SELECT * FROM annual_report_cards_view
+WHERE student_id = $1
+AND academic_year_id = $2
+LIMIT 1;
+
+-- name: ListAnnualReportCards :many
+SELECT * FROM annual_report_cards_view
+WHERE class_id = $1
+AND academic_year_id = $2
+ORDER BY class_position, last_name, first_name;
+
+-- name: ListAnnualReportCardClasses :many
+SELECT DISTINCT
+    class_id,
+    class_name
+FROM annual_report_cards_view
+WHERE academic_year_id = $1
+ORDER BY class_name;
+
+-- name: ListStudentTranscript :many
+SELECT * FROM annual_report_cards_view
+WHERE student_id = $1
+ORDER BY academic_year_start, class_name;
+
+-- name: ListStudentClassHistory :many
+-- The class a student was last in during each academic year, oldest first. Promotions record the
+-- class a student left in every term they were promoted from; student_classes holds the current one.
+WITH enrolments AS (
+    SELECT sphd.class_id, ph.stored_term_id AS term_id
+    FROM student_promotion_history_details sphd
+    JOIN promotion_history ph ON sphd.promotion_history_id = ph.promotion_history_id
+    WHERE sphd.student_id = $1
+      AND ph.is_undone = FALSE
+    UNION ALL
+    SELECT sc.class_id, sc.term_id
+    FROM student_classes sc
+    WHERE sc.student_id = $1
+)
+SELECT DISTINCT ON (ay.start_date, ay.academic_year_id)
+    ay.academic_year_id,
+    ay.name AS academic_year_name,
+    c.name AS class_name
+FROM enrolments e
+JOIN term t ON e.term_id = t.term_id
+JOIN academic_year ay ON t.academic_year_id = ay.academic_year_id
+JOIN classes c ON e.class_id = c.class_id
+ORDER BY ay.start_date, ay.academic_year_id, t.start_date DESC;
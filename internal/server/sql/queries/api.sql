@@ -0,0 +1,317 @@
+-- name: CountAPIClasses :one
+SELECT COUNT(*) FROM classes
+WHERE name NOT ILIKE 'Graduates - %'
+AND deleted_at IS NULL
+AND (sqlc.narg('search')::text IS NULL OR strpos(lower(name), lower(sqlc.narg('search'))) > 0);
+
+-- name: CountAPIDiscipline :one
+SELECT COUNT(*)
+FROM discipline_records dr
+INNER JOIN students s ON dr.student_id = s.student_id
+INNER JOIN term t ON dr.term_id = t.term_id
+WHERE s.deleted_at IS NULL
+AND (sqlc.narg('search')::text IS NULL OR strpos(lower(concat_ws(' ', s.first_name, s.middle_name, s.last_name, dr.description)), lower(sqlc.narg('search'))) > 0);
+
+-- name: CountAPIFees :one
+SELECT COUNT(*)
+FROM fee_structure fs
+INNER JOIN term t ON fs.term_id = t.term_id
+INNER JOIN classes c ON fs.class_id = c.class_id
+LEFT JOIN student_classes sc
+    ON fs.class_id = sc.class_id
+LEFT JOIN students s ON sc.student_id = s.student_id
+LEFT JOIN fees f
+    ON fs.fee_structure_id = f.fee_structure_id
+    AND s.student_id = f.student_id
+WHERE t.term_id = sqlc.arg('term_id')
+AND c.deleted_at IS NULL
+AND s.deleted_at IS NULL
+AND (sqlc.narg('class_id')::uuid IS NULL OR fs.class_id = sqlc.narg('class_id'))
+AND (sqlc.narg('status')::text IS NULL OR COALESCE(f.status, 'OVERDUE') = sqlc.narg('status'));
+
+-- name: CountAPIGrades :one
+SELECT COUNT(*)
+FROM student_grades_view
+WHERE class_id IN (
+    SELECT a.class_id
+    FROM assignments a
+    JOIN classes c ON c.class_id = a.class_id
+    WHERE a.teacher_id = sqlc.arg('teacher_id')
+    AND c.deleted_at IS NULL
+)
+AND (sqlc.narg('class_id')::uuid IS NULL OR class_id = sqlc.narg('class_id'))
+AND (sqlc.narg('student_id')::uuid IS NULL OR student_id = sqlc.narg('student_id'));
+
+-- name: CountAPIGuardians :one
+SELECT COUNT(*)
+FROM students s
+INNER JOIN student_guardians sg ON s.student_id = sg.student_id
+INNER JOIN guardians g ON sg.guardian_id = g.guardian_id
+WHERE s.deleted_at IS NULL
+AND (sqlc.narg('search')::text IS NULL OR strpos(lower(concat_ws(' ', g.guardian_name, g.phone_number_1, g.phone_number_2, s.first_name, s.last_name)), lower(sqlc.narg('search'))) > 0);
+
+-- name: CountAPIRemarks :one
+SELECT COUNT(*)
+FROM student_classes sc
+INNER JOIN students s
+    ON sc.student_id = s.student_id
+INNER JOIN classes c
+    ON sc.class_id = c.class_id
+INNER JOIN class_teachers ct
+    ON sc.class_id = ct.class_id
+INNER JOIN term t
+    ON sc.term_id = t.term_id
+WHERE ct.teacher_id = sqlc.arg('user_id')
+AND sc.term_id = sqlc.arg('term_id')
+AND s.deleted_at IS NULL
+AND (sqlc.narg('search')::text IS NULL OR strpos(lower(concat_ws(' ', s.first_name, s.middle_name, s.last_name, s.student_no)), lower(sqlc.narg('search'))) > 0);
+
+-- name: CountAPIStudents :one
+SELECT COUNT(DISTINCT students.student_id)
+FROM students
+INNER JOIN academic_year
+    ON students.academic_year_id = academic_year.academic_year_id
+LEFT OUTER JOIN student_classes
+    ON students.student_id = student_classes.student_id
+WHERE students.deleted_at IS NULL
+AND (sqlc.narg('class_id')::uuid IS NULL OR student_classes.class_id = sqlc.narg('class_id'))
+AND (sqlc.narg('status')::text IS NULL OR students.status = sqlc.narg('status'))
+AND (sqlc.narg('search')::text IS NULL OR strpos(lower(concat_ws(' ', students.first_name, students.middle_name, students.last_name, students.student_no)), lower(sqlc.narg('search'))) > 0);
+
+-- name: CountAPISubjects :one
+SELECT COUNT(*)
+FROM subjects
+INNER JOIN classes
+    ON subjects.class_id = classes.class_id
+WHERE subjects.deleted_at IS NULL
+AND classes.deleted_at IS NULL
+AND (sqlc.narg('class_id')::uuid IS NULL OR subjects.class_id = sqlc.narg('class_id'))
+AND (sqlc.narg('search')::text IS NULL OR strpos(lower(concat_ws(' ', subjects.name, classes.name)), lower(sqlc.narg('search'))) > 0);
+
+-- name: CountAPITerms :one
+SELECT COUNT(*)
+FROM term
+INNER JOIN academic_year
+    ON term.academic_year_id = academic_year.academic_year_id
+WHERE (sqlc.narg('academic_year_id')::uuid IS NULL OR academic_year.academic_year_id = sqlc.narg('academic_year_id'))
+AND (sqlc.narg('active')::boolean IS NULL OR term.active = sqlc.narg('active'));
+
+-- name: CountAPIUsers :one
+SELECT COUNT(*)
+FROM users
+INNER JOIN roles ON users.role_id = roles.role_id
+WHERE users.deleted_at IS NULL
+AND (sqlc.narg('role')::text IS NULL OR roles.name = sqlc.narg('role'))
+AND (sqlc.narg('search')::text IS NULL OR strpos(lower(concat_ws(' ', users.first_name, users.last_name, users.user_no)), lower(sqlc.narg('search'))) > 0);
+
+-- name: ListAPIClasses :many
+SELECT * FROM classes
+WHERE name NOT ILIKE 'Graduates - %'
+AND deleted_at IS NULL
+AND (sqlc.narg('search')::text IS NULL OR strpos(lower(name), lower(sqlc.narg('search'))) > 0)
+ORDER BY name, class_id
+LIMIT sqlc.arg('row_limit') OFFSET sqlc.arg('row_offset');
+
+-- name: ListAPIDiscipline :many
+SELECT
+    dr.discipline_id,
+    s.last_name,
+    s.middle_name,
+    s.first_name,
+    dr.date,
+    dr.description AS offense,
+    dr.action_taken,
+    dr.notes,
+    t.name AS term_name,
+    u.last_name AS reporter_last_name,
+    u.first_name AS reporter_first_name
+FROM discipline_records dr
+INNER JOIN students s ON dr.student_id = s.student_id
+LEFT JOIN users u ON dr.reported_by = u.user_id
+INNER JOIN term t ON dr.term_id = t.term_id
+WHERE s.deleted_at IS NULL
+AND (sqlc.narg('search')::text IS NULL OR strpos(lower(concat_ws(' ', s.first_name, s.middle_name, s.last_name, dr.description)), lower(sqlc.narg('search'))) > 0)
+ORDER BY dr.date DESC, dr.discipline_id
+LIMIT sqlc.arg('row_limit') OFFSET sqlc.arg('row_offset');
+
+-- name: ListAPIFees :many
+SELECT
+    f.fees_id,
+    s.student_id,
+    s.last_name,
+    s.first_name,
+    s.middle_name,
+    t.name AS AcademicTerm,
+    c.name AS ClassName,
+    fs.class_id,
+    fs.required AS TuitionAmount,
+    COALESCE(f.paid, 0.00) AS PaidAmount,
+    COALESCE(f.arrears, 0.00) AS Arrears,
+    COALESCE(f.status, 'OVERDUE') AS Status,
+    c.class_id AS ClassID,
+    fs.fee_structure_id,
+    t.term_id
+FROM fee_structure fs
+INNER JOIN term t ON fs.term_id = t.term_id
+INNER JOIN classes c ON fs.class_id = c.class_id
+LEFT JOIN student_classes sc
+    ON fs.class_id = sc.class_id
+LEFT JOIN students s ON sc.student_id = s.student_id
+LEFT JOIN fees f
+    ON fs.fee_structure_id = f.fee_structure_id
+    AND s.student_id = f.student_id
+WHERE t.term_id = sqlc.arg('term_id')
+AND c.deleted_at IS NULL
+AND s.deleted_at IS NULL
+AND (sqlc.narg('class_id')::uuid IS NULL OR fs.class_id = sqlc.narg('class_id'))
+AND (sqlc.narg('status')::text IS NULL OR COALESCE(f.status, 'OVERDUE') = sqlc.narg('status'))
+ORDER BY c.name, s.last_name, s.first_name, s.student_id
+LIMIT sqlc.arg('row_limit') OFFSET sqlc.arg('row_offset');
+
+-- name: ListAPIGrades :many
+-- ListAPIGrades only lists the classes the teacher is assigned to teach a subject in.
+SELECT *
+FROM student_grades_view
+WHERE class_id IN (
+    SELECT a.class_id
+    FROM assignments a
+    JOIN classes c ON c.class_id = a.class_id
+    WHERE a.teacher_id = sqlc.arg('teacher_id')
+    AND c.deleted_at IS NULL
+)
+AND (sqlc.narg('class_id')::uuid IS NULL OR class_id = sqlc.narg('class_id'))
+AND (sqlc.narg('student_id')::uuid IS NULL OR student_id = sqlc.narg('student_id'))
+ORDER BY class_name, student_no, student_id
+LIMIT sqlc.arg('row_limit') OFFSET sqlc.arg('row_offset');
+
+-- name: ListAPIGuardians :many
+SELECT
+    g.guardian_id,
+    s.first_name AS student_first_name,
+    s.last_name AS student_last_name,
+    g.guardian_name AS guardian_name,
+    g.phone_number_1,
+    g.phone_number_2,
+    g.gender AS guardian_gender,
+    g.profession AS guardian_profession
+FROM students s
+INNER JOIN student_guardians sg ON s.student_id = sg.student_id
+INNER JOIN guardians g ON sg.guardian_id = g.guardian_id
+WHERE s.deleted_at IS NULL
+AND (sqlc.narg('search')::text IS NULL OR strpos(lower(concat_ws(' ', g.guardian_name, g.phone_number_1, g.phone_number_2, s.first_name, s.last_name)), lower(sqlc.narg('search'))) > 0)
+ORDER BY s.last_name, s.first_name, s.student_id, g.guardian_id
+LIMIT sqlc.arg('row_limit') OFFSET sqlc.arg('row_offset');
+
+-- name: ListAPIRemarks :many
+-- ListAPIRemarks only lists the classes the user is class teacher of.
+SELECT
+  c.name AS class_name,
+  s.student_no,
+  s.student_id,
+  s.last_name,
+  s.first_name,
+  s.middle_name,
+  t.name AS academic_term,
+  r.remarks_id,
+  r.content_class_teacher AS class_teacher_remarks,
+  r.content_head_teacher AS head_teacher_remarks,
+  r.updated_at
+FROM student_classes sc
+INNER JOIN students s
+    ON sc.student_id = s.student_id
+INNER JOIN classes c
+    ON sc.class_id = c.class_id
+INNER JOIN class_teachers ct
+    ON sc.class_id = ct.class_id
+INNER JOIN term t
+    ON sc.term_id = t.term_id
+LEFT JOIN remarks r
+    ON s.student_id = r.student_id
+    AND r.term_id = sc.term_id
+WHERE ct.teacher_id = sqlc.arg('user_id')
+AND sc.term_id = sqlc.arg('term_id')
+AND s.deleted_at IS NULL
+AND (sqlc.narg('search')::text IS NULL OR strpos(lower(concat_ws(' ', s.first_name, s.middle_name, s.last_name, s.student_no)), lower(sqlc.narg('search'))) > 0)
+ORDER BY c.name, s.last_name, s.first_name, s.student_id
+LIMIT sqlc.arg('row_limit') OFFSET sqlc.arg('row_offset');
+
+-- name: ListAPIStudents :many
+-- ListAPIStudents lists students in several classes once, under the class the filter matched when there is one.
+SELECT DISTINCT ON (students.student_id)
+    students.student_id,
+    students.student_no,
+    students.last_name,
+    students.middle_name,
+    students.first_name,
+    students.gender,
+    students.date_of_birth,
+    students.status,
+    academic_year.name AS AcademicYear,
+    student_classes.class_id,
+    classes.name AS ClassName
+FROM students
+INNER JOIN academic_year
+    ON students.academic_year_id = academic_year.academic_year_id
+LEFT OUTER JOIN student_classes
+    ON students.student_id = student_classes.student_id
+LEFT OUTER JOIN classes
+    ON student_classes.class_id = classes.class_id
+WHERE students.deleted_at IS NULL
+AND (sqlc.narg('class_id')::uuid IS NULL OR student_classes.class_id = sqlc.narg('class_id'))
+AND (sqlc.narg('status')::text IS NULL OR students.status = sqlc.narg('status'))
+AND (sqlc.narg('search')::text IS NULL OR strpos(lower(concat_ws(' ', students.first_name, students.middle_name, students.last_name, students.student_no)), lower(sqlc.narg('search'))) > 0)
+ORDER BY students.student_id, classes.name
+LIMIT sqlc.arg('row_limit') OFFSET sqlc.arg('row_offset');
+
+-- name: ListAPISubjects :many
+SELECT
+    subjects.subject_id AS SubjectID,
+    subjects.class_id AS ClassID,
+    subjects.name AS SubjectName,
+    classes.name AS ClassName
+FROM subjects
+INNER JOIN classes
+    ON subjects.class_id = classes.class_id
+WHERE subjects.deleted_at IS NULL
+AND classes.deleted_at IS NULL
+AND (sqlc.narg('class_id')::uuid IS NULL OR subjects.class_id = sqlc.narg('class_id'))
+AND (sqlc.narg('search')::text IS NULL OR strpos(lower(concat_ws(' ', subjects.name, classes.name)), lower(sqlc.narg('search'))) > 0)
+ORDER BY subjects.name, subjects.subject_id
+LIMIT sqlc.arg('row_limit') OFFSET sqlc.arg('row_offset');
+
+-- name: ListAPITerms :many
+SELECT
+    term.term_id,
+    academic_year.academic_year_id,
+    academic_year.name AS Academic_Year,
+    academic_year.active AS status,
+    term.name AS Academic_Term,
+    term.start_date AS Opening_date,
+    term.end_date AS Closing_date,
+    term.active,
+    term.report_weight
+FROM term
+INNER JOIN academic_year
+    ON term.academic_year_id = academic_year.academic_year_id
+WHERE (sqlc.narg('academic_year_id')::uuid IS NULL OR academic_year.academic_year_id = sqlc.narg('academic_year_id'))
+AND (sqlc.narg('active')::boolean IS NULL OR term.active = sqlc.narg('active'))
+ORDER BY term.start_date DESC, term.term_id
+LIMIT sqlc.arg('row_limit') OFFSET sqlc.arg('row_offset');
+
+-- name: ListAPIUsers :many
+SELECT
+    users.user_id,
+    users.user_no,
+    users.last_name,
+    users.first_name,
+    users.gender,
+    users.email,
+    users.phone_number,
+    roles.name AS role
+FROM users
+INNER JOIN roles ON users.role_id = roles.role_id
+WHERE users.deleted_at IS NULL
+AND (sqlc.narg('role')::text IS NULL OR roles.name = sqlc.narg('role'))
+AND (sqlc.narg('search')::text IS NULL OR strpos(lower(concat_ws(' ', users.first_name, users.last_name, users.user_no)), lower(sqlc.narg('search'))) > 0)
+ORDER BY users.last_name, users.user_id
+LIMIT sqlc.arg('row_limit') OFFSET sqlc.arg('row_offset');
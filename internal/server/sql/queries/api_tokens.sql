@@ -0,0 +1,52 @@
+-- name: CreateAPIToken :one
+INSERT INTO api_tokens (user_id, name, kind, token_hash, token_prefix, scopes, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING api_token_id;
+
+-- name: GetAPIToken :one
+SELECT
+  api_tokens.api_token_id,
+  api_tokens.user_id,
+  roles.name AS role,
+  ARRAY(
+    SELECT r.name FROM user_roles ur
+    INNER JOIN roles r ON ur.role_id = r.role_id
+    WHERE ur.user_id = api_tokens.user_id
+    ORDER BY r.name
+  )::text[] AS roles,
+  ARRAY(
+    SELECT DISTINCT p.name FROM user_roles ur
+    INNER JOIN role_permissions rp ON ur.role_id = rp.role_id
+    INNER JOIN permissions p ON rp.permission_id = p.permission_id
+    WHERE ur.user_id = api_tokens.user_id
+      AND p.name = ANY(api_tokens.scopes)
+    ORDER BY p.name
+  )::text[] AS permissions,
+  users.must_change_password,
+  users.totp_enabled_at IS NOT NULL AS totp_enabled
+FROM api_tokens
+INNER JOIN users
+  ON api_tokens.user_id = users.user_id
+  AND users.deleted_at IS NULL
+INNER JOIN roles
+  ON users.role_id = roles.role_id
+WHERE api_tokens.token_hash = $1
+  AND api_tokens.revoked_at IS NULL
+  AND (api_tokens.expires_at IS NULL OR api_tokens.expires_at > CURRENT_TIMESTAMP);
+
+-- name: ListUserAPITokens :many
+SELECT api_token_id, name, kind, token_prefix, scopes, expires_at, last_used_at, last_used_ip, created_at
+FROM api_tokens
+WHERE user_id = $1 AND revoked_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: RevokeAPIToken :execrows
+UPDATE api_tokens
+  SET revoked_at = CURRENT_TIMESTAMP
+WHERE api_token_id = @api_token_id AND user_id = @user_id AND revoked_at IS NULL;
+
+-- name: TouchAPIToken :exec
+UPDATE api_tokens
+  SET last_used_at = CURRENT_TIMESTAMP,
+  last_used_ip = @last_used_ip
+WHERE api_token_id = @api_token_id;
@@ -0,0 +1,68 @@
+-- name: ListSubjectAssessmentComponents :many
+SELECT * FROM assessment_components
+WHERE subject_id = $1 AND term_id = $2
+ORDER BY position;
+
+-- name: ListClassAssessmentComponents :many
+SELECT ac.*
+FROM assessment_components ac
+INNER JOIN subjects s ON ac.subject_id = s.subject_id
+WHERE s.class_id = $1 AND ac.term_id = $2
+ORDER BY ac.subject_id, ac.position;
+
+-- name: UpsertAssessmentComponent :one
+INSERT INTO assessment_components (subject_id, term_id, name, weight, position)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (subject_id, term_id, name)
+DO UPDATE SET
+    weight = EXCLUDED.weight,
+    position = EXCLUDED.position
+RETURNING *;
+
+-- name: DeleteRemovedAssessmentComponents :exec
+DELETE FROM assessment_components
+WHERE subject_id = @subject_id
+AND term_id = @term_id
+AND NOT (name = ANY(@names::text[]));
+
+-- name: ListClassAssessmentScores :many
+SELECT a.*
+FROM assessment_scores a
+INNER JOIN assessment_components ac ON a.component_id = ac.component_id
+INNER JOIN subjects s ON ac.subject_id = s.subject_id
+WHERE s.class_id = $1 AND ac.term_id = $2;
+
+-- name: GetAssessmentScore :one
+SELECT * FROM assessment_scores
+WHERE component_id = $1 AND student_id = $2;
+
+-- name: UpsertAssessmentScore :one
+INSERT INTO assessment_scores (component_id, student_id, score)
+VALUES ($1, $2, $3)
+ON CONFLICT (component_id, student_id)
+DO UPDATE SET score = EXCLUDED.score
+RETURNING *;
+
+-- name: RecomputeSubjectGrades :many
+-- Sets the score of each existing grade for a subject and term to the weighted average of the
+-- student's component scores, counting missing component scores as zero. Returns the grades whose
+-- score changed.
+UPDATE grades g
+SET score = ROUND(final.score, 2)
+FROM (
+    SELECT
+        a.student_id,
+        SUM(a.score * ac.weight) / (
+            SELECT SUM(weight) FROM assessment_components
+            WHERE subject_id = @subject_id AND term_id = @term_id
+        ) AS score
+    FROM assessment_scores a
+    INNER JOIN assessment_components ac ON a.component_id = ac.component_id
+    WHERE ac.subject_id = @subject_id AND ac.term_id = @term_id
+    GROUP BY a.student_id
+) final
+WHERE g.student_id = final.student_id
+AND g.subject_id = @subject_id
+AND g.term_id = @term_id
+AND g.score IS DISTINCT FROM ROUND(final.score, 2)
+RETURNING g.grade_id, g.student_id, g.subject_id, g.term_id, g.score, g.remark;
@@ -0,0 +1,26 @@
+-- name: CreateAuditLog :exec
+INSERT INTO audit_log (actor_user_id, action, entity_type, entity_id, before, after, request_id)
+VALUES ($1, $2, $3, $4, $5, $6, $7);
+
+-- name: ListAuditLog :many
+SELECT
+    audit_log.audit_log_id,
+    audit_log.actor_user_id,
+    COALESCE(users.first_name || ' ' || users.last_name, '')::text AS actor_name,
+    COALESCE(users.user_no, '')::text AS actor_user_no,
+    audit_log.action,
+    audit_log.entity_type,
+    audit_log.entity_id,
+    audit_log.before,
+    audit_log.after,
+    audit_log.request_id,
+    audit_log.created_at
+FROM audit_log
+LEFT JOIN users ON users.user_id = audit_log.actor_user_id
+WHERE (sqlc.narg('actor_user_id')::uuid IS NULL OR audit_log.actor_user_id = sqlc.narg('actor_user_id'))
+  AND (sqlc.narg('entity_type')::text IS NULL OR audit_log.entity_type = sqlc.narg('entity_type'))
+  AND (sqlc.narg('entity_id')::uuid IS NULL OR audit_log.entity_id = sqlc.narg('entity_id'))
+  AND (sqlc.narg('from_time')::timestamptz IS NULL OR audit_log.created_at >= sqlc.narg('from_time'))
+  AND (sqlc.narg('to_time')::timestamptz IS NULL OR audit_log.created_at < sqlc.narg('to_time'))
+ORDER BY audit_log.created_at DESC
+LIMIT sqlc.arg('row_limit');
@@ -7,6 +7,7 @@ select
 from users u
 join roles r on u.role_id = r.role_id
 and r.name = 'classteacher'
+where u.deleted_at is null
 order by u.last_name;
 
 -- name: UpSertClassTeacher :one
@@ -29,6 +30,8 @@ select
 from class_teachers ct
 join users u on ct.teacher_id = u.user_id
 join classes c on ct.class_id = c.class_id
+where u.deleted_at is null
+and c.deleted_at is null
 order by c.name;
 
 -- name: GetClassTeacher :one
@@ -41,7 +44,9 @@ select
 from class_teachers ct
 join users u on ct.teacher_id = u.user_id
 join classes c on ct.class_id = c.class_id
-where c.class_id = $1;
+where c.class_id = $1
+and u.deleted_at is null
+and c.deleted_at is null;
 
 -- name: RemoveClassTeacher :exec
 WITH updated_user AS (
@@ -56,3 +61,9 @@ WITH updated_user AS (
 delete
     from class_teachers
 where teacher_id = (select user_id from updated_user);
+
+-- name: IsClassTeacher :one
+select exists (
+    select 1 from class_teachers
+    where class_id = $1 and teacher_id = $2
+);
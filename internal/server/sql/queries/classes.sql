@@ -1,29 +1,43 @@
--- name: CreateClass :exec
+-- name: CreateClass :one
 INSERT INTO classes (name)
 VALUES ($1)
-ON CONFLICT (name) DO NOTHING
+ON CONFLICT (name) WHERE deleted_at IS NULL DO NOTHING
 RETURNING *;
 
 -- name: ListClasses :many
 SELECT * FROM classes
 WHERE name NOT ILIKE 'Graduates - %'
+AND deleted_at IS NULL
 ORDER BY name;
 
 -- name: GetClass :one
-SELECT * FROM classes WHERE class_id = $1;
+SELECT * FROM classes WHERE class_id = $1 AND deleted_at IS NULL;
 
 -- name: EditClass :exec
 UPDATE classes
 SET name = COALESCE($2, name)
 WHERE class_id = $1;
 
--- name: DeleteClass :exec
-DELETE FROM classes WHERE class_id = $1;
+-- name: SoftDeleteClass :execrows
+UPDATE classes
+SET deleted_at = CURRENT_TIMESTAMP,
+deleted_by = $2
+WHERE class_id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreClass :execrows
+UPDATE classes
+SET deleted_at = NULL,
+deleted_by = NULL
+WHERE class_id = $1 AND deleted_at IS NOT NULL;
+
+-- name: PurgeClasses :many
+DELETE FROM classes
+WHERE deleted_at < $1
+RETURNING class_id;
 
 -- name: SetUpClassPromotions :many
 select 
-    c.class_id,
-    c.name
+    c.*
 from classes c
 inner join academic_year ay
 on c.class_id = ay.graduate_class_id
@@ -32,8 +46,8 @@ and ay.active = true
 union 
 
 select
-    class_id,
-    name
+    *
 from classes
 where name not ilike 'Graduates - %'
+and deleted_at is null
 order by name;
@@ -25,4 +25,5 @@ FROM discipline_records dr
 INNER JOIN students s ON dr.student_id = s.student_id
 LEFT JOIN users u ON dr.reported_by = u.user_id
 INNER JOIN term t ON dr.term_id = t.term_id
+WHERE s.deleted_at IS NULL
 ORDER BY dr.date DESC;
@@ -10,6 +10,10 @@ INSERT INTO fees (fee_structure_id, student_id, paid)
 VALUES ($1, $2, $3)
 RETURNING *;
 
+-- name: GetFee :one
+SELECT * FROM fees
+WHERE fees_id = $1;
+
 -- name: GetFeeStructurePerTermForStudent :one
 SELECT 
     fs.fee_structure_id,
@@ -41,7 +45,8 @@ FROM students s
 INNER JOIN student_classes sc ON s.student_id = sc.student_id
 INNER JOIN term t ON sc.term_id = t.term_id
 INNER JOIN classes c ON sc.class_id = c.class_id
-WHERE c.class_id = $1 AND t.active = TRUE;
+WHERE c.class_id = $1 AND t.active = TRUE
+AND s.deleted_at IS NULL;
 
 -- name: GetStudentPreviousFeeRecord :one
 SELECT
@@ -113,9 +118,11 @@ LEFT JOIN students s ON sc.student_id = s.student_id
 LEFT JOIN fees f
     ON fs.fee_structure_id = f.fee_structure_id
     AND s.student_id = f.student_id
-WHERE t.term_id = $1;
+WHERE t.term_id = $1
+AND c.deleted_at IS NULL
+AND s.deleted_at IS NULL;
     
--- name: EditFeesRecord :exec
+-- name: EditFeesRecord :one
 UPDATE fees
     SET paid = paid + $2
 WHERE fees_id = $1
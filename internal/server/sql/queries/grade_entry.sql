@@ -0,0 +1,72 @@
+-- name: GetGradeEntryWindow :one
+SELECT * FROM grade_entry_windows WHERE window_id = $1;
+
+-- name: GetGradeEntryWindowByScope :one
+SELECT * FROM grade_entry_windows
+WHERE term_id = $1
+AND class_id IS NOT DISTINCT FROM $2
+AND subject_id IS NOT DISTINCT FROM $3;
+
+-- name: UpsertGradeEntryWindow :one
+INSERT INTO grade_entry_windows (term_id, class_id, subject_id, opens_at, closes_at, created_by)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT ON CONSTRAINT grade_entry_windows_scope DO UPDATE
+SET opens_at = EXCLUDED.opens_at,
+    closes_at = EXCLUDED.closes_at,
+    created_by = EXCLUDED.created_by,
+    created_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteGradeEntryWindow :exec
+DELETE FROM grade_entry_windows WHERE window_id = $1;
+
+-- name: ListTermGradeEntryWindows :many
+-- Lists the grade entry windows of a term, the whole-term window first, then by class and subject.
+SELECT
+    w.window_id,
+    w.class_id,
+    w.subject_id,
+    COALESCE(c.name, '')::text AS class_name,
+    COALESCE(sub.name, '')::text AS subject_name,
+    w.opens_at,
+    w.closes_at
+FROM grade_entry_windows w
+LEFT JOIN classes c ON w.class_id = c.class_id
+LEFT JOIN subjects sub ON w.subject_id = sub.subject_id
+WHERE w.term_id = $1
+ORDER BY c.name NULLS FIRST, sub.name NULLS FIRST;
+
+-- name: ListClassGradeEntryWindows :many
+-- Lists the grade entry windows that apply to a class in a term: the whole-term window and the
+-- class's own windows.
+SELECT * FROM grade_entry_windows
+WHERE term_id = $1
+AND (class_id IS NULL OR class_id = $2);
+
+-- name: CreateGradeEntryUnlock :one
+INSERT INTO grade_entry_unlocks (term_id, class_id, subject_id, reason, unlocked_by, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: ListActiveGradeEntryUnlocks :many
+SELECT * FROM grade_entry_unlocks
+WHERE term_id = $1
+AND class_id = $2
+AND expires_at > CURRENT_TIMESTAMP;
+
+-- name: ListTermGradeEntryUnlocks :many
+-- Lists the grade entry unlocks of a term with who made them, newest first.
+SELECT
+    u.unlock_id,
+    c.name AS class_name,
+    COALESCE(sub.name, '')::text AS subject_name,
+    u.reason,
+    COALESCE(users.first_name || ' ' || users.last_name, '')::text AS unlocked_by_name,
+    u.unlocked_at,
+    u.expires_at
+FROM grade_entry_unlocks u
+JOIN classes c ON u.class_id = c.class_id
+LEFT JOIN subjects sub ON u.subject_id = sub.subject_id
+LEFT JOIN users ON u.unlocked_by = users.user_id
+WHERE u.term_id = $1
+ORDER BY u.unlocked_at DESC;
@@ -0,0 +1,32 @@
+-- name: CreateGradeRevision :exec
+INSERT INTO grade_revisions (grade_id, score, remark, source, changed_by)
+VALUES ($1, $2, $3, $4, $5);
+
+-- name: GetGradeRevisionSubject :one
+-- Names the student, subject and term of a grade for its revision timeline, with the class of the subject.
+SELECT
+    g.grade_id,
+    g.subject_id,
+    sub.class_id,
+    s.first_name || ' ' || s.last_name AS student_name,
+    sub.name AS subject_name,
+    t.name AS term_name
+FROM grades g
+JOIN students s ON g.student_id = s.student_id
+JOIN subjects sub ON g.subject_id = sub.subject_id
+JOIN term t ON g.term_id = t.term_id
+WHERE g.grade_id = $1;
+
+-- name: ListGradeRevisions :many
+-- Lists the revisions of a grade with who made them, newest first.
+SELECT
+    r.revision_id,
+    r.score,
+    r.remark,
+    r.source,
+    COALESCE(users.first_name || ' ' || users.last_name, '')::text AS changed_by_name,
+    r.changed_at
+FROM grade_revisions r
+LEFT JOIN users ON r.changed_by = users.user_id
+WHERE r.grade_id = $1
+ORDER BY r.changed_at DESC;
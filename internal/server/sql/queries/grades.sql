@@ -16,6 +16,10 @@ FROM virtual_classroom vc
 WHERE vc.teacher_id = $1
 ORDER BY vc.class_name, vc.student_no;
 
+-- name: GetGrade :one
+SELECT * FROM grades
+WHERE student_id = $1 AND subject_id = $2 AND term_id = $3;
+
 -- name: UpsertGrade :one
 INSERT INTO grades (student_id, subject_id, term_id, score, remark)
 VALUES ($1, $2, $3, $4, $5)
@@ -40,9 +44,29 @@ LEFT JOIN grades g
   ON g.student_id = s.student_id 
   AND g.subject_id = subj.subject_id 
   AND g.term_id = sc.term_id
-WHERE sc.class_id = $1;
+WHERE sc.class_id = $1
+AND s.deleted_at IS NULL
+AND subj.deleted_at IS NULL;
 
 -- name: ListGrades :many
 SELECT *
 FROM student_grades_view
 ORDER BY class_name, student_no;
+
+-- name: ListTeacherSubjectIDs :many
+-- ListTeacherSubjectIDs returns the subjects a teacher is assigned to teach in a class.
+SELECT a.subject_id
+FROM assignments a
+JOIN subjects s ON s.subject_id = a.subject_id
+WHERE a.teacher_id = $1
+AND a.class_id = $2
+AND s.deleted_at IS NULL;
+
+-- name: ListEnrolledStudentIDs :many
+-- ListEnrolledStudentIDs returns the students enrolled in a class for a term.
+SELECT sc.student_id
+FROM student_classes sc
+JOIN students s ON s.student_id = sc.student_id
+WHERE sc.class_id = $1
+AND sc.term_id = $2
+AND s.deleted_at IS NULL;
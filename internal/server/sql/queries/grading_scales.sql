@@ -0,0 +1,77 @@
+-- name: CreateGradingScale :one
+INSERT INTO grading_scales (name)
+VALUES ($1)
+RETURNING *;
+
+-- name: GetGradingScale :one
+SELECT * FROM grading_scales WHERE grading_scale_id = $1;
+
+-- name: ListGradingScales :many
+SELECT * FROM grading_scales
+ORDER BY name;
+
+-- name: EditGradingScale :one
+UPDATE grading_scales
+SET name = $2
+WHERE grading_scale_id = $1
+RETURNING *;
+
+-- name: DeleteGradingScale :exec
+DELETE FROM grading_scales WHERE grading_scale_id = $1;
+
+-- name: CreateGradingBand :one
+INSERT INTO grading_scale_bands (grading_scale_id, min_score, max_score, letter, descriptor)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: DeleteGradingBands :exec
+DELETE FROM grading_scale_bands WHERE grading_scale_id = $1;
+
+-- name: ListGradingBands :many
+SELECT * FROM grading_scale_bands
+ORDER BY grading_scale_id, min_score DESC;
+
+-- name: ListClassGradingScales :many
+SELECT
+    c.class_id,
+    c.name AS class_name,
+    cgs.grading_scale_id
+FROM classes c
+LEFT JOIN class_grading_scales cgs ON c.class_id = cgs.class_id
+WHERE c.deleted_at IS NULL
+AND c.name NOT ILIKE 'Graduates - %'
+ORDER BY c.name;
+
+-- name: GetClassGradingScale :one
+SELECT * FROM class_grading_scales WHERE class_id = $1;
+
+-- name: SetClassGradingScale :exec
+INSERT INTO class_grading_scales (class_id, grading_scale_id)
+VALUES ($1, $2)
+ON CONFLICT (class_id) DO UPDATE
+SET grading_scale_id = EXCLUDED.grading_scale_id;
+
+-- name: RemoveClassGradingScale :exec
+DELETE FROM class_grading_scales WHERE class_id = $1;
+
+-- name: ListClassGradingBands :many
+SELECT
+    cgs.class_id,
+    b.min_score,
+    b.max_score,
+    b.letter,
+    b.descriptor
+FROM class_grading_scales cgs
+INNER JOIN grading_scale_bands b ON cgs.grading_scale_id = b.grading_scale_id
+ORDER BY cgs.class_id, b.min_score DESC;
+
+-- name: GetClassGradingBands :many
+SELECT
+    b.min_score,
+    b.max_score,
+    b.letter,
+    b.descriptor
+FROM class_grading_scales cgs
+INNER JOIN grading_scale_bands b ON cgs.grading_scale_id = b.grading_scale_id
+WHERE cgs.class_id = $1
+ORDER BY b.min_score DESC;
@@ -3,6 +3,7 @@ INSERT INTO classes (name) VALUES ($1) RETURNING *;
 
 -- name: ListGraduatesByAcademicYear :many
 SELECT
+  s.student_id,
   s.student_no,
   s.first_name,
   s.middle_name,
@@ -16,5 +17,6 @@ JOIN term t ON sc.term_id = t.term_id
 JOIN academic_year ay ON t.academic_year_id = ay.academic_year_id
 LEFT JOIN classes c ON sc.class_id = c.class_id
 WHERE s.graduated = TRUE
+  AND s.deleted_at IS NULL
   AND ay.academic_year_id = $1
   AND c.name ILIKE 'Graduates - %';
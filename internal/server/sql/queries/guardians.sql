@@ -36,6 +36,7 @@ SELECT
 FROM students s
 INNER JOIN student_guardians sg ON s.student_id = sg.student_id
 INNER JOIN guardians g ON sg.guardian_id = g.guardian_id
+WHERE s.deleted_at IS NULL
 ORDER BY s.last_name, s.first_name;
 
 -- name: SearchStudentGuardian :many
@@ -51,8 +52,17 @@ SELECT
 FROM students s
 INNER JOIN student_guardians sg ON s.student_id = sg.student_id
 INNER JOIN guardians g ON sg.guardian_id = g.guardian_id
-WHERE s.first_name ILIKE $1 OR s.last_name ILIKE $1
+WHERE (s.first_name ILIKE $1 OR s.last_name ILIKE $1)
+AND s.deleted_at IS NULL
 ORDER BY s.last_name, s.first_name;
 
 -- name: DeleteGuardian :exec
 DELETE FROM guardians WHERE guardian_id = $1;
+
+-- name: PurgeOrphanGuardians :many
+DELETE FROM guardians
+WHERE NOT EXISTS (
+    SELECT 1 FROM student_guardians sg
+    WHERE sg.guardian_id = guardians.guardian_id
+)
+RETURNING guardian_id;
@@ -1,10 +1,12 @@
 -- name: GetTotalUsers :one
 SELECT COUNT(*) AS total_users
-FROM users;
+FROM users
+WHERE deleted_at IS NULL;
 
 -- name: GetTotalStudents :one
 SELECT COUNT(*) AS total_students
-FROM students;
+FROM students
+WHERE deleted_at IS NULL;
 
 -- name: GetTotalFeesPaid :one
 SELECT COALESCE(SUM(paid), 0) AS total_fees_paid
@@ -13,6 +15,7 @@ FROM fees;
 -- name: GetStudentGenderBreakdown :many
 SELECT gender, COUNT(*) AS total_students
 FROM students
+WHERE deleted_at IS NULL
 GROUP BY gender;
 
 -- name: GetTotalGuardians :one
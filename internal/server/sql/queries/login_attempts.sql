@@ -0,0 +1,26 @@
+-- name: CreateLoginAttempt :exec
+INSERT INTO login_attempts (identifier, user_id, ip_address, outcome)
+VALUES ($1, $2, $3, $4);
+
+-- name: CountRecentFailedLoginsByIP :one
+SELECT
+    COUNT(*) AS failures,
+    MAX(attempted_at)::timestamptz AS last_failed_at
+FROM login_attempts
+WHERE ip_address = $1
+  AND outcome = 'invalid_credentials'
+  AND attempted_at > $2;
+
+-- name: ListRecentLoginAttempts :many
+SELECT
+    login_attempts.attempt_id,
+    login_attempts.identifier,
+    login_attempts.ip_address,
+    login_attempts.outcome,
+    login_attempts.attempted_at,
+    users.first_name,
+    users.last_name
+FROM login_attempts
+LEFT JOIN users ON login_attempts.user_id = users.user_id
+ORDER BY login_attempts.attempted_at DESC
+LIMIT $1;
@@ -0,0 +1,26 @@
+-- name: DeleteUnusedResetCodes :exec
+DELETE FROM password_reset_codes
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: CreateResetCode :one
+INSERT INTO password_reset_codes (user_id, code_hash, expires_at, created_by)
+VALUES ($1, $2, $3, $4)
+RETURNING reset_code_id;
+
+-- name: ConsumeResetCode :one
+UPDATE password_reset_codes
+    set used_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+  AND code_hash = $2
+  AND used_at IS NULL
+  AND expires_at > CURRENT_TIMESTAMP
+RETURNING reset_code_id;
+
+-- name: ResetPassword :exec
+UPDATE users
+    set password = $2,
+    must_change_password = FALSE,
+    failed_login_attempts = 0,
+    last_failed_login_at = NULL,
+    locked_until = NULL
+WHERE user_id = $1;
@@ -0,0 +1,35 @@
+-- name: ListPermissions :many
+SELECT permission_id, name, description FROM permissions
+ORDER BY name;
+
+-- name: ListRoles :many
+SELECT role_id, name, description FROM roles
+ORDER BY name;
+
+-- name: ListRolePermissions :many
+SELECT role_id, permission_id FROM role_permissions;
+
+-- name: AddRolePermission :exec
+INSERT INTO role_permissions (role_id, permission_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteRolePermissions :exec
+DELETE FROM role_permissions
+WHERE role_id = $1;
+
+-- name: ListUserRoles :many
+SELECT roles.role_id, roles.name FROM user_roles
+INNER JOIN roles ON user_roles.role_id = roles.role_id
+WHERE user_roles.user_id = $1
+ORDER BY roles.name;
+
+-- name: AddUserRole :exec
+INSERT INTO user_roles (user_id, role_id)
+VALUES ($1, $2)
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteSecondaryUserRoles :exec
+DELETE FROM user_roles
+WHERE user_roles.user_id = $1
+    AND user_roles.role_id <> (SELECT users.role_id FROM users WHERE users.user_id = $1);
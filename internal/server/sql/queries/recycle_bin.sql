@@ -0,0 +1,26 @@
+-- name: ListRecycleBin :many
+SELECT
+    bin.entity_type,
+    bin.entity_id,
+    bin.name,
+    bin.deleted_at,
+    COALESCE(users.first_name || ' ' || users.last_name, '')::text AS deleted_by_name
+FROM (
+    SELECT 'student'::text AS entity_type, student_id AS entity_id,
+        (first_name || ' ' || last_name)::text AS name, deleted_at, deleted_by
+    FROM students WHERE deleted_at IS NOT NULL
+    UNION ALL
+    SELECT 'user'::text, user_id, (first_name || ' ' || last_name)::text, deleted_at, deleted_by
+    FROM users WHERE deleted_at IS NOT NULL
+    UNION ALL
+    SELECT 'class'::text, class_id, name::text, deleted_at, deleted_by
+    FROM classes WHERE deleted_at IS NOT NULL
+    UNION ALL
+    SELECT 'subject'::text, subjects.subject_id, (subjects.name || ' (' || classes.name || ')')::text,
+        subjects.deleted_at, subjects.deleted_by
+    FROM subjects
+    INNER JOIN classes ON subjects.class_id = classes.class_id
+    WHERE subjects.deleted_at IS NOT NULL
+) AS bin
+LEFT JOIN users ON users.user_id = bin.deleted_by
+ORDER BY bin.deleted_at DESC;
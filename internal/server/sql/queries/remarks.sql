@@ -7,6 +7,12 @@ ON CONFLICT (student_id, term_id) DO UPDATE
       updated_at           = CURRENT_TIMESTAMP
 RETURNING *;
 
+-- name: GetRemark :one
+-- GetRemark locks a student's remark for the term until the transaction ends.
+SELECT * FROM remarks
+WHERE student_id = $1 AND term_id = $2
+FOR UPDATE;
+
 
 -- name: ListRemarksByClass :many
 SELECT
@@ -36,4 +42,5 @@ INNER JOIN term t
 LEFT JOIN remarks r 
     ON s.student_id = r.student_id 
    AND sc.term_id = $2
+WHERE s.deleted_at IS NULL
 ORDER BY c.name, s.last_name, s.first_name;
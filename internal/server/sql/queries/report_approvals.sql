@@ -0,0 +1,37 @@
+-- name: GetReportApproval :one
+SELECT * FROM report_approvals
+WHERE class_id = $1 AND term_id = $2;
+
+-- name: LockReportApproval :one
+-- LockReportApproval returns the approval of a class's report cards for a term, adding a draft one
+-- if there is none, and locks it until the transaction ends. Grade and remark changes take the lock
+-- before checking the status, and approval steps before changing it.
+INSERT INTO report_approvals (class_id, term_id)
+VALUES ($1, $2)
+ON CONFLICT (class_id, term_id) DO UPDATE
+SET status = report_approvals.status
+RETURNING *;
+
+-- name: UpsertReportApproval :one
+INSERT INTO report_approvals (class_id, term_id, status, comment, updated_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (class_id, term_id) DO UPDATE
+SET status = EXCLUDED.status,
+    comment = EXCLUDED.comment,
+    updated_by = EXCLUDED.updated_by,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: CountMissingReportRemarks :one
+-- Counts the report cards of a class for a term, and those still missing the class teacher's or
+-- the headteacher's remark.
+SELECT
+    COUNT(*)::int AS report_cards,
+    COUNT(*) FILTER (WHERE COALESCE(TRIM(r.content_class_teacher), '') = '')::int AS missing_class_teacher,
+    COUNT(*) FILTER (WHERE COALESCE(TRIM(r.content_head_teacher), '') = '')::int AS missing_head_teacher
+FROM term_report_cards_view rc
+LEFT JOIN remarks r
+    ON r.student_id = rc.student_id
+    AND r.term_id = rc.term_id
+WHERE rc.class_id = $1
+AND rc.term_id = $2;
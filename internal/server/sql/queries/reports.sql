@@ -1,34 +1,71 @@
 -- name: GetStudentReportCard :one
+-- GetStudentReportCard returns a student's report card for a term in a class, or in the first of
+-- their classes by name when no class is given and they moved class during the term.
 SELECT 
-    sgv.student_id,
-    sgv.student_no,
-    sgv.last_name,
-    sgv.first_name,
-    sgv.middle_name,
-    sgv.class_id,
-    sgv.class_name,
-    sgv.grades,
+    rc.student_id,
+    rc.student_no,
+    rc.last_name,
+    rc.first_name,
+    rc.middle_name,
+    rc.class_id,
+    rc.class_name,
+    rc.term_id,
+    rc.term_name,
+    rc.academic_year_name,
+    rc.grades,
+    rc.total_score,
+    rc.mean_score,
+    rc.class_position,
+    rc.class_size,
     r.content_class_teacher AS class_teacher_remark,
     r.content_head_teacher AS head_teacher_remark
-FROM student_grades_view sgv
+FROM term_report_cards_view rc
 LEFT JOIN remarks r 
-    ON r.student_id = sgv.student_id 
-WHERE sgv.student_id = $1;
-
+    ON r.student_id = rc.student_id 
+    AND r.term_id = rc.term_id
+WHERE rc.student_id = $1
+AND rc.term_id = $2
+AND (sqlc.narg('class_id')::uuid IS NULL OR rc.class_id = sqlc.narg('class_id'))
+ORDER BY rc.class_name, rc.class_id
+LIMIT 1;
 
 -- name: ListStudentReportCards :many
 SELECT 
-    sgv.student_id,
-    sgv.student_no,
-    sgv.last_name,
-    sgv.first_name,
-    sgv.middle_name,
-    sgv.class_id,
-    sgv.class_name,
-    sgv.grades,
+    rc.student_id,
+    rc.student_no,
+    rc.last_name,
+    rc.first_name,
+    rc.middle_name,
+    rc.gender,
+    rc.status,
+    rc.class_id,
+    rc.class_name,
+    rc.term_id,
+    rc.term_name,
+    rc.academic_year_name,
+    rc.grades,
+    rc.total_score,
+    rc.mean_score,
+    rc.class_position,
+    rc.class_size,
     r.content_class_teacher AS class_teacher_remark,
     r.content_head_teacher AS head_teacher_remark
-FROM student_grades_view sgv
+FROM term_report_cards_view rc
 LEFT JOIN remarks r 
-    ON r.student_id = sgv.student_id 
-ORDER BY sgv.class_id = $1;
+    ON r.student_id = rc.student_id 
+    AND r.term_id = rc.term_id
+WHERE rc.class_id = $1
+AND rc.term_id = $2
+ORDER BY rc.class_position, rc.last_name, rc.first_name;
+
+-- name: ListReportCardClasses :many
+SELECT DISTINCT
+    rc.class_id,
+    rc.class_name,
+    COALESCE(ra.status, 'draft')::VARCHAR AS approval_status
+FROM term_report_cards_view rc
+LEFT JOIN report_approvals ra
+    ON ra.class_id = rc.class_id
+    AND ra.term_id = rc.term_id
+WHERE rc.term_id = $1
+ORDER BY rc.class_name;
@@ -1,31 +1,64 @@
 -- name: CreateSession :exec
-INSERT INTO sessions (session_id, user_id, expires) 
-  VALUES ($1, $2, $3)
-  ON CONFLICT (user_id) 
-DO UPDATE
-  SET
-    session_id = EXCLUDED.session_id,
-    expires = EXCLUDED.expires;
+INSERT INTO sessions (session_id, user_id, expires, user_agent, ip_address)
+  VALUES ($1, $2, $3, $4, $5);
 
 -- name: GetSession :one
-SELECT 
+SELECT
   sessions.user_id,
   sessions.session_id,
   roles.name AS role,
-  sessions.expires
+  sessions.expires,
+  users.must_change_password,
+  users.totp_enabled_at IS NOT NULL AS totp_enabled,
+  ARRAY(
+    SELECT r.name FROM user_roles ur
+    INNER JOIN roles r ON ur.role_id = r.role_id
+    WHERE ur.user_id = sessions.user_id
+    ORDER BY r.name
+  )::text[] AS roles,
+  ARRAY(
+    SELECT DISTINCT p.name FROM user_roles ur
+    INNER JOIN role_permissions rp ON ur.role_id = rp.role_id
+    INNER JOIN permissions p ON rp.permission_id = p.permission_id
+    WHERE ur.user_id = sessions.user_id
+    ORDER BY p.name
+  )::text[] AS permissions
 FROM sessions
 INNER JOIN users
   ON sessions.user_id = users.user_id
-INNER JOIN roles 
+  AND users.deleted_at IS NULL
+INNER JOIN roles
   ON users.role_id = roles.role_id
 WHERE session_id = $1;
 
 -- name: RefreshSession :exec
 UPDATE sessions
-  SET expires = COALESCE($2, expires),
-  session_id = COALESCE($3, session_id)
-WHERE user_id = $1;
+  SET expires = @expires,
+  session_id = @new_session_id,
+  last_seen_at = CURRENT_TIMESTAMP
+WHERE session_id = @session_id;
+
+-- name: TouchSession :exec
+UPDATE sessions
+  SET last_seen_at = CURRENT_TIMESTAMP
+WHERE session_id = $1
+  AND last_seen_at < CURRENT_TIMESTAMP - INTERVAL '5 minutes';
+
+-- name: ListUserSessions :many
+SELECT session_id, user_agent, ip_address, created_at, last_seen_at, expires
+FROM sessions
+WHERE user_id = $1
+  AND expires > CURRENT_TIMESTAMP
+ORDER BY last_seen_at DESC;
 
 -- name: DeleteSession :exec
 DELETE FROM sessions
+WHERE session_id = $1;
+
+-- name: DeleteUserSession :exec
+DELETE FROM sessions
+WHERE session_id = $1 AND user_id = $2;
+
+-- name: DeleteUserSessions :exec
+DELETE FROM sessions
 WHERE user_id = $1;
@@ -9,6 +9,7 @@ WHERE user_id = $1;
 
 -- name: EditMyPassword :exec
 UPDATE users
-    set password = COALESCE($2, password)
+    set password = COALESCE($2, password),
+    must_change_password = FALSE
 WHERE user_id = $1;
 
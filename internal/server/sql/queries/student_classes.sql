@@ -18,3 +18,9 @@ WHERE student_id = $1;
 
 -- name: DeleteStudentClasses :exec
 DELETE FROM student_classes WHERE student_class_id = $1;
+
+-- name: ListStudentTermClassIDs :many
+-- ListStudentTermClassIDs returns the classes a student was in during a term.
+SELECT DISTINCT class_id FROM student_classes
+WHERE student_id = $1 AND term_id = $2
+ORDER BY class_id;
@@ -55,7 +55,8 @@ LEFT OUTER JOIN student_classes
     ON students.student_id = student_classes.student_id
 LEFT OUTER JOIN classes
     ON student_classes.class_id = classes.class_id
-WHERE students.student_id = $1;
+WHERE students.student_id = $1
+AND students.deleted_at IS NULL;
 
 -- name: ListStudents :many
 SELECT DISTINCT ON (students.student_id)
@@ -77,6 +78,7 @@ LEFT OUTER JOIN student_classes
     ON students.student_id = student_classes.student_id
 LEFT OUTER JOIN classes
     ON student_classes.class_id = classes.class_id
+WHERE students.deleted_at IS NULL
 ORDER BY students.student_id, students.last_name ASC;
 
 -- name: EditStudent :exec
@@ -88,8 +90,22 @@ SET last_name = COALESCE($2, last_name),
     date_of_birth = COALESCE($5, date_of_birth)
 WHERE student_id = $1;
 
--- name: DeleteStudent :exec
-DELETE FROM students WHERE student_id = $1;
+-- name: SoftDeleteStudent :execrows
+UPDATE students
+SET deleted_at = CURRENT_TIMESTAMP,
+deleted_by = $2
+WHERE student_id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreStudent :execrows
+UPDATE students
+SET deleted_at = NULL,
+deleted_by = NULL
+WHERE student_id = $1 AND deleted_at IS NOT NULL;
+
+-- name: PurgeStudents :many
+DELETE FROM students
+WHERE deleted_at < $1
+RETURNING student_id;
 
 -- name: SearchStudentsByName :many
 SELECT 
@@ -99,5 +115,6 @@ SELECT
     last_name
 FROM students
 WHERE 
-    first_name ILIKE $1 OR last_name ILIKE $1
+    (first_name ILIKE $1 OR last_name ILIKE $1)
+    AND deleted_at IS NULL
 ORDER BY last_name, first_name;
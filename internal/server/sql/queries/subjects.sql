@@ -1,11 +1,12 @@
--- name: CreateSubject :exec
+-- name: CreateSubject :one
 INSERT INTO
     subjects (class_id, name)
 VALUES ($1, $2)
-ON CONFLICT ON CONSTRAINT unique_subject_name_per_class DO NOTHING;
+ON CONFLICT (class_id, name) WHERE deleted_at IS NULL DO NOTHING
+RETURNING *;
 
 -- name: GetSubject :one
-SELECT * FROM subjects WHERE subject_id = $1;
+SELECT * FROM subjects WHERE subject_id = $1 AND deleted_at IS NULL;
 
 -- name: ListAllSubjects :many
 SELECT
@@ -16,6 +17,8 @@ SELECT
 FROM subjects
 INNER JOIN classes
     ON subjects.class_id = classes.class_id
+WHERE subjects.deleted_at IS NULL
+AND classes.deleted_at IS NULL
 ORDER BY subjects.name;
 
 -- name: ListSubjects :many
@@ -27,6 +30,7 @@ FROM subjects
 INNER JOIN classes
     ON subjects.class_id = classes.class_id
 WHERE classes.class_id = $1
+AND subjects.deleted_at IS NULL
 ORDER BY subjects.name;
 
 -- name: EditSubject :exec
@@ -34,5 +38,19 @@ UPDATE subjects
 SET name = COALESCE($2, name)
 WHERE subject_id = $1;
 
--- name: DeleteSubject :exec
-DELETE FROM subjects WHERE subject_id = $1;
+-- name: SoftDeleteSubject :execrows
+UPDATE subjects
+SET deleted_at = CURRENT_TIMESTAMP,
+deleted_by = $2
+WHERE subject_id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreSubject :execrows
+UPDATE subjects
+SET deleted_at = NULL,
+deleted_by = NULL
+WHERE subject_id = $1 AND deleted_at IS NOT NULL;
+
+-- name: PurgeSubjects :many
+DELETE FROM subjects
+WHERE deleted_at < $1
+RETURNING subject_id;
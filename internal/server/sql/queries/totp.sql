@@ -0,0 +1,46 @@
+-- name: GetUserTOTP :one
+SELECT totp_secret, totp_enabled_at, totp_last_counter FROM users
+WHERE user_id = $1;
+
+-- name: SetTOTPSecret :exec
+UPDATE users
+    SET totp_secret = $2
+WHERE user_id = $1 AND totp_enabled_at IS NULL;
+
+-- name: EnableTOTP :exec
+UPDATE users
+    SET totp_enabled_at = CURRENT_TIMESTAMP,
+    totp_last_counter = $2
+WHERE user_id = $1 AND totp_secret IS NOT NULL;
+
+-- name: DisableTOTP :exec
+UPDATE users
+    SET totp_secret = NULL,
+    totp_enabled_at = NULL,
+    totp_last_counter = 0
+WHERE user_id = $1;
+
+-- name: UseTOTPCounter :execrows
+UPDATE users
+    SET totp_last_counter = @counter
+WHERE user_id = @user_id AND totp_last_counter < @counter;
+
+-- name: CreateRecoveryCode :exec
+INSERT INTO totp_recovery_codes (user_id, code_hash)
+VALUES ($1, $2);
+
+-- name: ConsumeRecoveryCode :one
+UPDATE totp_recovery_codes
+    SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = $1
+    AND code_hash = $2
+    AND used_at IS NULL
+RETURNING recovery_code_id;
+
+-- name: CountUnusedRecoveryCodes :one
+SELECT COUNT(*) FROM totp_recovery_codes
+WHERE user_id = $1 AND used_at IS NULL;
+
+-- name: DeleteRecoveryCodes :exec
+DELETE FROM totp_recovery_codes
+WHERE user_id = $1;
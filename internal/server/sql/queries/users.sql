@@ -1,5 +1,5 @@
 -- name: CreateUser :one
-INSERT INTO users (first_name, last_name, phone_number, email, gender, password, role_id)
+INSERT INTO users (first_name, last_name, phone_number, email, gender, password, role_id, must_change_password)
 VALUES (
     $1,
     $2,
@@ -7,9 +7,10 @@ VALUES (
     $4,
     $5,
     $6,
-    (SELECT role_id FROM roles WHERE name = $7)
+    (SELECT role_id FROM roles WHERE name = $7),
+    $8
 )
-ON CONFLICT (phone_number) DO NOTHING
+ON CONFLICT (phone_number) WHERE deleted_at IS NULL DO NOTHING
 RETURNING *;
 
 -- name: GetUserDetails :one
@@ -22,6 +23,7 @@ SELECT
     users.email, 
     users.phone_number,
     users.password, 
+    users.must_change_password,
     roles.name AS role
 FROM 
     users
@@ -30,15 +32,16 @@ INNER JOIN
 ON 
     users.role_id = roles.role_id
 WHERE 
-    users.user_id = $1;
+    users.user_id = $1
+    AND users.deleted_at IS NULL;
 
 -- name: GetUserByPhone :one
-SELECT password, user_id FROM users 
-WHERE phone_number = $1;
+SELECT password, user_id, failed_login_attempts, last_failed_login_at, locked_until, totp_enabled_at IS NOT NULL AS totp_enabled FROM users 
+WHERE phone_number = $1 AND deleted_at IS NULL;
 
 -- name: GetUserByUsername :one
-SELECT password, user_id FROM users
-WHERE user_no = $1;
+SELECT password, user_id, failed_login_attempts, last_failed_login_at, locked_until, totp_enabled_at IS NOT NULL AS totp_enabled FROM users
+WHERE user_no = $1 AND deleted_at IS NULL;
 
 -- name: ListUsers :many
 SELECT
@@ -53,6 +56,7 @@ SELECT
     roles.name AS role
 FROM users
 INNER JOIN roles ON users.role_id = roles.role_id
+WHERE users.deleted_at IS NULL
 ORDER BY last_name;
 
 -- name: EditUser :one
@@ -68,9 +72,70 @@ RETURNING user_id;
 
 -- name: EditPassword :exec
 UPDATE users
-    set password = COALESCE($2, password)
+    set password = COALESCE($2, password),
+    must_change_password = TRUE
 WHERE user_id = $1;
 
--- name: DeleteUser :exec
+-- name: SoftDeleteUser :execrows
+UPDATE users
+SET deleted_at = CURRENT_TIMESTAMP,
+deleted_by = $2
+WHERE user_id = $1 AND deleted_at IS NULL;
+
+-- name: RestoreUser :execrows
+UPDATE users
+SET deleted_at = NULL,
+deleted_by = NULL
+WHERE user_id = $1 AND deleted_at IS NOT NULL;
+
+-- name: PurgeUsers :many
 DELETE FROM users
+WHERE deleted_at < $1
+RETURNING user_id;
+
+-- name: ReleaseFailedLogin :exec
+-- Takes back a failure reserved for an attempt that turned out right, and the lockout it may have caused.
+UPDATE users
+    set failed_login_attempts = GREATEST(failed_login_attempts - 1, 0),
+    locked_until = CASE
+        WHEN failed_login_attempts - 1 >= @max_failures::int THEN locked_until
+        ELSE NULL
+    END
+WHERE user_id = @user_id;
+
+-- name: ReserveFailedLogin :one
+-- Counts a failure before the password or code is checked. Nothing is counted if another attempt
+-- was counted since failed_login_attempts was read, so parallel guesses can't all pass one backoff check.
+UPDATE users
+    set failed_login_attempts = failed_login_attempts + 1,
+    last_failed_login_at = CURRENT_TIMESTAMP,
+    locked_until = CASE
+        WHEN failed_login_attempts + 1 >= @max_failures::int THEN @locked_until::timestamptz
+        ELSE locked_until
+    END
+WHERE user_id = @user_id AND failed_login_attempts = @failed_login_attempts
+RETURNING failed_login_attempts, locked_until;
+
+-- name: ResetFailedLogins :exec
+UPDATE users
+    set failed_login_attempts = 0,
+    last_failed_login_at = NULL,
+    locked_until = NULL
 WHERE user_id = $1;
+
+-- name: ListLockedUsers :many
+SELECT
+    users.user_id,
+    users.user_no,
+    users.last_name,
+    users.first_name,
+    users.failed_login_attempts,
+    users.last_failed_login_at,
+    users.locked_until,
+    roles.name AS role
+FROM users
+INNER JOIN roles ON users.role_id = roles.role_id
+WHERE users.deleted_at IS NULL
+  AND (users.failed_login_attempts > 0
+   OR users.locked_until > CURRENT_TIMESTAMP)
+ORDER BY users.locked_until DESC NULLS LAST, users.last_failed_login_at DESC;
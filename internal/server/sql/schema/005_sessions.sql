@@ -0,0 +1,29 @@
+-- +goose Up
+
+-- Allow a user to hold one session per device instead of a single session.
+ALTER TABLE sessions DROP CONSTRAINT IF EXISTS sessions_user_id_key;
+
+ALTER TABLE sessions
+    ADD COLUMN user_agent TEXT,
+    ADD COLUMN ip_address VARCHAR(45),
+    ADD COLUMN created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    ADD COLUMN last_seen_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX idx_sessions_user_id ON sessions(user_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_sessions_user_id;
+
+-- Keep only the most recently used session per user before restoring the unique constraint.
+DELETE FROM sessions s
+USING sessions newer
+WHERE s.user_id = newer.user_id
+  AND (s.last_seen_at, s.session_id) < (newer.last_seen_at, newer.session_id);
+
+ALTER TABLE sessions
+    DROP COLUMN IF EXISTS last_seen_at,
+    DROP COLUMN IF EXISTS created_at,
+    DROP COLUMN IF EXISTS ip_address,
+    DROP COLUMN IF EXISTS user_agent;
+
+ALTER TABLE sessions ADD CONSTRAINT sessions_user_id_key UNIQUE (user_id);
@@ -0,0 +1,28 @@
+-- +goose Up
+
+-- Track consecutive failed logins on the account itself so lockouts survive restarts.
+ALTER TABLE users
+    ADD COLUMN failed_login_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN last_failed_login_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN locked_until TIMESTAMP WITH TIME ZONE;
+
+-- LOGIN ATTEMPTS TABLE
+CREATE TABLE IF NOT EXISTS login_attempts (
+    attempt_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    identifier VARCHAR(50) NOT NULL,
+    user_id UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    ip_address VARCHAR(45) NOT NULL,
+    outcome VARCHAR(20) NOT NULL CHECK (outcome IN ('success', 'invalid_credentials', 'throttled', 'locked')),
+    attempted_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_login_attempts_ip_address ON login_attempts(ip_address, attempted_at);
+CREATE INDEX idx_login_attempts_attempted_at ON login_attempts(attempted_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS login_attempts;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS locked_until,
+    DROP COLUMN IF EXISTS last_failed_login_at,
+    DROP COLUMN IF EXISTS failed_login_attempts;
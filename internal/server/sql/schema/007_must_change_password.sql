@@ -0,0 +1,9 @@
+-- +goose Up
+
+-- Accounts created or reset by an admin must pick their own password on next login.
+ALTER TABLE users
+    ADD COLUMN must_change_password BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE users
+    DROP COLUMN IF EXISTS must_change_password;
@@ -0,0 +1,18 @@
+-- +goose Up
+
+-- PASSWORD RESET CODES TABLE
+-- Codes are issued by an admin, stored as SHA-256 hashes and can be used once before they expire.
+CREATE TABLE IF NOT EXISTS password_reset_codes (
+    reset_code_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_password_reset_codes_user_id ON password_reset_codes(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS password_reset_codes;
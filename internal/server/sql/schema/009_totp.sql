@@ -0,0 +1,29 @@
+-- +goose Up
+
+-- TWO-FACTOR AUTHENTICATION
+-- totp_secret is set when a user starts enrolling and only takes effect once totp_enabled_at is set.
+-- totp_last_counter is the last accepted time step, so a code cannot be replayed.
+ALTER TABLE users
+    ADD COLUMN totp_secret VARCHAR(64),
+    ADD COLUMN totp_enabled_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN totp_last_counter BIGINT NOT NULL DEFAULT 0;
+
+-- TOTP RECOVERY CODES TABLE
+-- Single use codes for signing in without the authenticator app, stored as SHA-256 hashes.
+CREATE TABLE IF NOT EXISTS totp_recovery_codes (
+    recovery_code_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_totp_recovery_codes_user_id ON totp_recovery_codes(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS totp_recovery_codes;
+
+ALTER TABLE users
+    DROP COLUMN IF EXISTS totp_last_counter,
+    DROP COLUMN IF EXISTS totp_enabled_at,
+    DROP COLUMN IF EXISTS totp_secret;
@@ -0,0 +1,113 @@
+-- +goose Up
+
+-- PERMISSIONS TABLE
+-- Routes are guarded by permission names rather than role names, so access can change without a release.
+CREATE TABLE IF NOT EXISTS permissions (
+    permission_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) UNIQUE NOT NULL,
+    description TEXT
+);
+
+-- ROLE PERMISSIONS TABLE
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id UUID NOT NULL REFERENCES roles(role_id) ON DELETE CASCADE,
+    permission_id UUID NOT NULL REFERENCES permissions(permission_id) ON DELETE CASCADE,
+    PRIMARY KEY (role_id, permission_id)
+);
+
+-- USER ROLES TABLE
+-- users.role_id stays as the primary role shown around the app; user_roles holds every role a user has,
+-- the primary one included.
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    role_id UUID NOT NULL REFERENCES roles(role_id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, role_id)
+);
+
+CREATE INDEX idx_user_roles_role_id ON user_roles(role_id);
+
+INSERT INTO permissions (name, description)
+VALUES
+    ('dashboard.view', 'View the dashboard'),
+    ('users.manage', 'Create, edit and delete users, their sessions and credentials'),
+    ('roles.manage', 'Change which permissions each role has and assign extra roles to users'),
+    ('academics.manage', 'Manage academic years, terms, classes, subjects and teacher assignments'),
+    ('students.manage', 'Create, edit and delete students'),
+    ('guardians.manage', 'View and edit guardians'),
+    ('grades.submit', 'Enter grades for assigned classes'),
+    ('remarks.submit', 'Write report card remarks'),
+    ('discipline.manage', 'Record disciplinary cases'),
+    ('reports.view', 'View and download report cards'),
+    ('promotions.manage', 'Set up and run class promotions'),
+    ('fees.view', 'View fees records and income'),
+    ('fees.edit', 'Set the fees structure and record payments')
+ON CONFLICT (name) DO NOTHING;
+
+-- Each role gets the permissions matching the routes it could reach before.
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.role_id, permissions.permission_id
+FROM (
+    VALUES
+        ('admin', 'dashboard.view'),
+        ('admin', 'users.manage'),
+        ('admin', 'roles.manage'),
+        ('admin', 'academics.manage'),
+        ('admin', 'students.manage'),
+        ('admin', 'guardians.manage'),
+        ('admin', 'promotions.manage'),
+        ('teacher', 'dashboard.view'),
+        ('teacher', 'grades.submit'),
+        ('classteacher', 'dashboard.view'),
+        ('classteacher', 'guardians.manage'),
+        ('classteacher', 'grades.submit'),
+        ('classteacher', 'remarks.submit'),
+        ('classteacher', 'discipline.manage'),
+        ('classteacher', 'reports.view'),
+        ('headteacher', 'dashboard.view'),
+        ('headteacher', 'guardians.manage'),
+        ('headteacher', 'remarks.submit'),
+        ('headteacher', 'discipline.manage'),
+        ('headteacher', 'reports.view'),
+        ('accountant', 'dashboard.view'),
+        ('accountant', 'fees.view'),
+        ('accountant', 'fees.edit')
+) AS seed(role_name, permission_name)
+JOIN roles ON roles.name = seed.role_name
+JOIN permissions ON permissions.name = seed.permission_name
+ON CONFLICT DO NOTHING;
+
+INSERT INTO user_roles (user_id, role_id)
+SELECT user_id, role_id FROM users
+ON CONFLICT DO NOTHING;
+
+-- Keep the primary role in user_roles when a user is created or their primary role changes.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION fn_sync_primary_role()
+RETURNS trigger AS $function$
+BEGIN
+    IF TG_OP = 'UPDATE' AND OLD.role_id IS DISTINCT FROM NEW.role_id THEN
+        DELETE FROM user_roles
+        WHERE user_id = NEW.user_id AND role_id = OLD.role_id;
+    END IF;
+
+    INSERT INTO user_roles (user_id, role_id)
+    VALUES (NEW.user_id, NEW.role_id)
+    ON CONFLICT DO NOTHING;
+
+    RETURN NEW;
+END;
+$function$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trg_sync_primary_role
+AFTER INSERT OR UPDATE OF role_id ON users
+FOR EACH ROW
+EXECUTE FUNCTION fn_sync_primary_role();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_sync_primary_role ON users;
+DROP FUNCTION IF EXISTS fn_sync_primary_role();
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
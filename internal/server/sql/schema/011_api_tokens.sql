@@ -0,0 +1,26 @@
+-- +goose Up
+
+-- API TOKENS TABLE
+-- Bearer tokens for scripts and other systems. Only the SHA-256 hash of a token is stored;
+-- token_prefix keeps its first characters so users can tell tokens apart.
+-- A token can only use the permissions listed in scopes that its owner still holds.
+-- Personal tokens act for their owner; service tokens are created by user managers for integrations.
+CREATE TABLE IF NOT EXISTS api_tokens (
+    api_token_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(10) NOT NULL DEFAULT 'personal' CHECK (kind IN ('personal', 'service')),
+    token_hash VARCHAR(64) NOT NULL UNIQUE,
+    token_prefix VARCHAR(16) NOT NULL,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    expires_at TIMESTAMP WITH TIME ZONE,
+    last_used_at TIMESTAMP WITH TIME ZONE,
+    last_used_ip VARCHAR(45),
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_api_tokens_user_id ON api_tokens(user_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS api_tokens;
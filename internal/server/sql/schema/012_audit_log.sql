@@ -0,0 +1,36 @@
+-- +goose Up
+
+-- AUDIT LOG TABLE
+-- One row per change to school data, written in the same transaction as the change.
+-- before and after hold the entity as JSON; before is NULL for creates and after is NULL for deletes.
+-- request_id matches the id chi's RequestID middleware puts in the access log.
+CREATE TABLE IF NOT EXISTS audit_log (
+    audit_log_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    actor_user_id UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID,
+    before JSONB,
+    after JSONB,
+    request_id VARCHAR(100) NOT NULL DEFAULT '',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_audit_log_created_at ON audit_log(created_at);
+CREATE INDEX idx_audit_log_actor_user_id ON audit_log(actor_user_id);
+CREATE INDEX idx_audit_log_entity ON audit_log(entity_type, entity_id);
+
+INSERT INTO permissions (name, description)
+VALUES ('audit.view', 'View and export the audit log')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.role_id, permissions.permission_id
+FROM roles
+JOIN permissions ON permissions.name = 'audit.view'
+WHERE roles.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'audit.view';
+DROP TABLE IF EXISTS audit_log;
@@ -0,0 +1,171 @@
+-- +goose Up
+
+-- SOFT DELETE
+-- Deleting a student, user, class or subject only stamps deleted_at/deleted_by, so the row and everything
+-- hanging off it (grades, fees, assignments...) can be restored from the recycle bin.
+-- Rows are hard deleted by the purge job once they are older than the retention period.
+ALTER TABLE students
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN deleted_by UUID REFERENCES users(user_id) ON DELETE SET NULL;
+
+ALTER TABLE users
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN deleted_by UUID REFERENCES users(user_id) ON DELETE SET NULL;
+
+ALTER TABLE classes
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN deleted_by UUID REFERENCES users(user_id) ON DELETE SET NULL;
+
+ALTER TABLE subjects
+    ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE,
+    ADD COLUMN deleted_by UUID REFERENCES users(user_id) ON DELETE SET NULL;
+
+CREATE INDEX idx_students_deleted_at ON students(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_users_deleted_at ON users(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_classes_deleted_at ON classes(deleted_at) WHERE deleted_at IS NOT NULL;
+CREATE INDEX idx_subjects_deleted_at ON subjects(deleted_at) WHERE deleted_at IS NOT NULL;
+
+CREATE OR REPLACE VIEW student_grades_view AS
+SELECT
+    s.student_id,
+    s.student_no,
+    s.last_name,
+    s.first_name,
+    s.middle_name,
+    c.class_id,
+    c.name AS class_name,
+    jsonb_object_agg(
+        sub.subject_id,
+        jsonb_build_object(
+            'grade_id', g.grade_id,
+            'score', g.score,
+            'remark', g.remark
+        )
+    ) AS grades
+FROM students s
+JOIN student_classes sc ON s.student_id = sc.student_id
+JOIN classes c ON sc.class_id = c.class_id
+JOIN subjects sub ON sc.class_id = sub.class_id
+LEFT JOIN grades g ON s.student_id = g.student_id
+                   AND sub.subject_id = g.subject_id
+                   AND sc.term_id = g.term_id
+WHERE s.deleted_at IS NULL
+  AND c.deleted_at IS NULL
+  AND sub.deleted_at IS NULL
+GROUP BY s.student_id, s.student_no, s.last_name, s.first_name, s.middle_name, c.name, c.class_id;
+
+CREATE OR REPLACE VIEW virtual_classroom AS
+SELECT
+    st_cl.student_id,
+    st.student_no,
+    st.first_name || ' ' || COALESCE(st.middle_name || ' ', '') || st.last_name AS student_name,
+    s.subject_id,
+    s.name AS subject_name,
+    c.class_id,
+    c.name AS class_name,
+    u.user_id AS teacher_id,
+    u.first_name || ' ' || u.last_name AS teacher_name,
+    t.term_id,
+    t.name AS term_name,
+    t.academic_year_id
+FROM student_classes st_cl
+JOIN students st
+  ON st_cl.student_id = st.student_id
+JOIN assignments a
+  ON st_cl.class_id = a.class_id
+JOIN subjects s
+  ON a.subject_id = s.subject_id
+JOIN classes c
+  ON a.class_id = c.class_id
+JOIN users u
+  ON a.teacher_id = u.user_id
+JOIN term t
+  ON st_cl.term_id = t.term_id
+WHERE st.deleted_at IS NULL
+  AND s.deleted_at IS NULL
+  AND c.deleted_at IS NULL
+  AND u.deleted_at IS NULL;
+
+INSERT INTO permissions (name, description)
+VALUES ('recycle_bin.manage', 'View and restore deleted students, users, classes and subjects')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.role_id, permissions.permission_id
+FROM roles
+JOIN permissions ON permissions.name = 'recycle_bin.manage'
+WHERE roles.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'recycle_bin.manage';
+
+-- Soft deleted rows would reappear once the columns are gone, so they are removed for good.
+DELETE FROM students WHERE deleted_at IS NOT NULL;
+DELETE FROM subjects WHERE deleted_at IS NOT NULL;
+DELETE FROM classes WHERE deleted_at IS NOT NULL;
+DELETE FROM users WHERE deleted_at IS NOT NULL;
+
+CREATE OR REPLACE VIEW student_grades_view AS
+SELECT
+    s.student_id,
+    s.student_no,
+    s.last_name,
+    s.first_name,
+    s.middle_name,
+    c.class_id,
+    c.name AS class_name,
+    jsonb_object_agg(
+        sub.subject_id,
+        jsonb_build_object(
+            'grade_id', g.grade_id,
+            'score', g.score,
+            'remark', g.remark
+        )
+    ) AS grades
+FROM students s
+JOIN student_classes sc ON s.student_id = sc.student_id
+JOIN classes c ON sc.class_id = c.class_id
+JOIN subjects sub ON sc.class_id = sub.class_id
+LEFT JOIN grades g ON s.student_id = g.student_id
+                   AND sub.subject_id = g.subject_id
+                   AND sc.term_id = g.term_id
+GROUP BY s.student_id, s.student_no, s.last_name, s.first_name, s.middle_name, c.name, c.class_id;
+
+CREATE OR REPLACE VIEW virtual_classroom AS
+SELECT
+    st_cl.student_id,
+    st.student_no,
+    st.first_name || ' ' || COALESCE(st.middle_name || ' ', '') || st.last_name AS student_name,
+    s.subject_id,
+    s.name AS subject_name,
+    c.class_id,
+    c.name AS class_name,
+    u.user_id AS teacher_id,
+    u.first_name || ' ' || u.last_name AS teacher_name,
+    t.term_id,
+    t.name AS term_name,
+    t.academic_year_id
+FROM student_classes st_cl
+JOIN students st
+  ON st_cl.student_id = st.student_id
+JOIN assignments a
+  ON st_cl.class_id = a.class_id
+JOIN subjects s
+  ON a.subject_id = s.subject_id
+JOIN classes c
+  ON a.class_id = c.class_id
+JOIN users u
+  ON a.teacher_id = u.user_id
+JOIN term t
+  ON st_cl.term_id = t.term_id;
+
+DROP INDEX IF EXISTS idx_subjects_deleted_at;
+DROP INDEX IF EXISTS idx_classes_deleted_at;
+DROP INDEX IF EXISTS idx_users_deleted_at;
+DROP INDEX IF EXISTS idx_students_deleted_at;
+
+ALTER TABLE subjects DROP COLUMN IF EXISTS deleted_by, DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE classes DROP COLUMN IF EXISTS deleted_by, DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE users DROP COLUMN IF EXISTS deleted_by, DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE students DROP COLUMN IF EXISTS deleted_by, DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,43 @@
+-- +goose Up
+
+-- TERM REPORT CARDS
+-- student_classes only holds a student's current class and term, so past report cards are built from
+-- the grades themselves: one row per student, class and term they were graded in. The class is the
+-- class of the graded subjects, which is where the student sat that term.
+CREATE OR REPLACE VIEW term_report_cards_view AS
+SELECT
+    s.student_id,
+    s.student_no,
+    s.last_name,
+    s.first_name,
+    s.middle_name,
+    s.gender,
+    s.status,
+    c.class_id,
+    c.name AS class_name,
+    t.term_id,
+    t.name AS term_name,
+    ay.academic_year_id,
+    ay.name AS academic_year_name,
+    jsonb_object_agg(
+        sub.subject_id,
+        jsonb_build_object(
+            'grade_id', g.grade_id,
+            'score', g.score,
+            'remark', g.remark
+        )
+    ) AS grades
+FROM grades g
+JOIN students s ON g.student_id = s.student_id
+JOIN subjects sub ON g.subject_id = sub.subject_id
+JOIN classes c ON sub.class_id = c.class_id
+JOIN term t ON g.term_id = t.term_id
+JOIN academic_year ay ON t.academic_year_id = ay.academic_year_id
+WHERE s.deleted_at IS NULL
+  AND sub.deleted_at IS NULL
+  AND c.deleted_at IS NULL
+GROUP BY s.student_id, s.student_no, s.last_name, s.first_name, s.middle_name, s.gender, s.status,
+    c.class_id, c.name, t.term_id, t.name, ay.academic_year_id, ay.name;
+
+-- +goose Down
+DROP VIEW IF EXISTS term_report_cards_view;
@@ -0,0 +1,51 @@
+-- +goose Up
+
+-- GRADING SCALES
+-- A grading scale turns raw scores into letter grades, e.g. 80-100 = A "Excellent".
+-- Bands are inclusive at both ends and may not overlap within a scale.
+CREATE TABLE IF NOT EXISTS grading_scales (
+    grading_scale_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    name VARCHAR(100) NOT NULL UNIQUE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS grading_scale_bands (
+    band_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    grading_scale_id UUID NOT NULL REFERENCES grading_scales(grading_scale_id) ON DELETE CASCADE,
+    min_score NUMERIC(5, 2) NOT NULL,
+    max_score NUMERIC(5, 2) NOT NULL,
+    letter VARCHAR(5) NOT NULL,
+    descriptor VARCHAR(100) NOT NULL DEFAULT '',
+    CONSTRAINT band_score_range CHECK (min_score >= 0 AND min_score <= max_score),
+    CONSTRAINT unique_band_letter_per_scale UNIQUE (grading_scale_id, letter),
+    CONSTRAINT band_no_overlap EXCLUDE USING gist (
+        grading_scale_id WITH =,
+        numrange(min_score, max_score, '[]') WITH &&
+    )
+);
+
+CREATE INDEX idx_grading_scale_bands_grading_scale_id ON grading_scale_bands(grading_scale_id);
+
+-- CLASS GRADING SCALES
+-- The scale used for a class level. Classes without one show raw scores and accept 0-100.
+CREATE TABLE IF NOT EXISTS class_grading_scales (
+    class_id UUID PRIMARY KEY REFERENCES classes(class_id) ON DELETE CASCADE,
+    grading_scale_id UUID NOT NULL REFERENCES grading_scales(grading_scale_id) ON DELETE CASCADE
+);
+
+INSERT INTO permissions (name, description)
+VALUES ('grading.manage', 'Create grading scales and assign them to classes')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.role_id, permissions.permission_id
+FROM roles
+JOIN permissions ON permissions.name = 'grading.manage'
+WHERE roles.name = 'admin'
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE name = 'grading.manage';
+DROP TABLE IF EXISTS class_grading_scales;
+DROP TABLE IF EXISTS grading_scale_bands;
+DROP TABLE IF EXISTS grading_scales;
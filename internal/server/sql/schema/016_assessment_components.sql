@@ -0,0 +1,33 @@
+-- +goose Up
+
+-- ASSESSMENT COMPONENTS
+-- The weighted parts of a subject's final score in a term, e.g. continuous assessment 30,
+-- mid-term test 20 and end-of-term exam 50. A subject without components is graded with one score.
+CREATE TABLE IF NOT EXISTS assessment_components (
+    component_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    subject_id UUID NOT NULL REFERENCES subjects(subject_id) ON DELETE CASCADE,
+    term_id UUID NOT NULL REFERENCES term(term_id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    weight NUMERIC(5, 2) NOT NULL CHECK (weight > 0 AND weight <= 100),
+    position INTEGER NOT NULL DEFAULT 0,
+    CONSTRAINT unique_component_per_subject_term UNIQUE (subject_id, term_id, name)
+);
+
+CREATE INDEX idx_assessment_components_term_id ON assessment_components(term_id);
+
+-- ASSESSMENT SCORES
+-- A student's score in one component. The weighted average of a student's component scores
+-- is saved as their grades.score, so reports and student_grades_view are unchanged.
+CREATE TABLE IF NOT EXISTS assessment_scores (
+    assessment_score_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    component_id UUID NOT NULL REFERENCES assessment_components(component_id) ON DELETE CASCADE,
+    student_id UUID NOT NULL REFERENCES students(student_id) ON DELETE CASCADE,
+    score NUMERIC(5, 2) NOT NULL CHECK (score >= 0),
+    CONSTRAINT unique_assessment_score UNIQUE (component_id, student_id)
+);
+
+CREATE INDEX idx_assessment_scores_student_id ON assessment_scores(student_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS assessment_scores;
+DROP TABLE IF EXISTS assessment_components;
@@ -0,0 +1,103 @@
+-- +goose Up
+
+-- TERM REPORT CARDS WITH RANKINGS
+-- Adds each student's total and mean score, their position in the class and the class size, and the
+-- student's position in each subject under the grade's "position" key. Positions use RANK(), so
+-- students with the same score share a position and the next position is skipped (1, 2, 2, 4).
+DROP VIEW IF EXISTS term_report_cards_view;
+
+CREATE VIEW term_report_cards_view AS
+WITH ranked_grades AS (
+    SELECT
+        g.grade_id,
+        g.student_id,
+        g.subject_id,
+        g.term_id,
+        g.score,
+        g.remark,
+        sub.class_id,
+        RANK() OVER (PARTITION BY g.subject_id, g.term_id ORDER BY g.score DESC)::int AS subject_position
+    FROM grades g
+    JOIN students s ON g.student_id = s.student_id
+    JOIN subjects sub ON g.subject_id = sub.subject_id
+    WHERE s.deleted_at IS NULL
+      AND sub.deleted_at IS NULL
+),
+report_cards AS (
+    SELECT
+        s.student_id,
+        s.student_no,
+        s.last_name,
+        s.first_name,
+        s.middle_name,
+        s.gender,
+        s.status,
+        c.class_id,
+        c.name AS class_name,
+        t.term_id,
+        t.name AS term_name,
+        ay.academic_year_id,
+        ay.name AS academic_year_name,
+        jsonb_object_agg(
+            rg.subject_id,
+            jsonb_build_object(
+                'grade_id', rg.grade_id,
+                'score', rg.score,
+                'remark', rg.remark,
+                'position', rg.subject_position
+            )
+        ) AS grades,
+        SUM(rg.score) AS total_score,
+        ROUND(AVG(rg.score), 2) AS mean_score
+    FROM ranked_grades rg
+    JOIN students s ON rg.student_id = s.student_id
+    JOIN classes c ON rg.class_id = c.class_id
+    JOIN term t ON rg.term_id = t.term_id
+    JOIN academic_year ay ON t.academic_year_id = ay.academic_year_id
+    WHERE c.deleted_at IS NULL
+    GROUP BY s.student_id, s.student_no, s.last_name, s.first_name, s.middle_name, s.gender, s.status,
+        c.class_id, c.name, t.term_id, t.name, ay.academic_year_id, ay.name
+)
+SELECT
+    rc.*,
+    RANK() OVER (PARTITION BY rc.class_id, rc.term_id ORDER BY rc.total_score DESC)::int AS class_position,
+    COUNT(*) OVER (PARTITION BY rc.class_id, rc.term_id)::int AS class_size
+FROM report_cards rc;
+
+-- +goose Down
+DROP VIEW IF EXISTS term_report_cards_view;
+
+CREATE VIEW term_report_cards_view AS
+SELECT
+    s.student_id,
+    s.student_no,
+    s.last_name,
+    s.first_name,
+    s.middle_name,
+    s.gender,
+    s.status,
+    c.class_id,
+    c.name AS class_name,
+    t.term_id,
+    t.name AS term_name,
+    ay.academic_year_id,
+    ay.name AS academic_year_name,
+    jsonb_object_agg(
+        sub.subject_id,
+        jsonb_build_object(
+            'grade_id', g.grade_id,
+            'score', g.score,
+            'remark', g.remark
+        )
+    ) AS grades
+FROM grades g
+JOIN students s ON g.student_id = s.student_id
+JOIN subjects sub ON g.subject_id = sub.subject_id
+JOIN classes c ON sub.class_id = c.class_id
+JOIN term t ON g.term_id = t.term_id
+JOIN academic_year ay ON t.academic_year_id = ay.academic_year_id
+WHERE s.deleted_at IS NULL
+  AND sub.deleted_at IS NULL
+  AND c.deleted_at IS NULL
+GROUP BY s.student_id, s.student_no, s.last_name, s.first_name, s.middle_name, s.gender, s.status,
+    c.class_id, c.name, t.term_id, t.name, ay.academic_year_id, ay.name;
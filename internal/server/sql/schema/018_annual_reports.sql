@@ -0,0 +1,78 @@
+-- +goose Up
+
+-- TERM REPORT WEIGHTS
+-- How much each term counts towards the annual report. Weights are relative, so terms weighted
+-- 1, 1 and 2 count for a quarter, a quarter and a half of the year.
+ALTER TABLE term ADD COLUMN report_weight NUMERIC(5, 2) NOT NULL DEFAULT 1
+    CONSTRAINT term_report_weight_positive CHECK (report_weight > 0);
+
+-- ANNUAL REPORT CARDS
+-- One row per student per academic year and class. A subject's annual score is the weighted
+-- average of its term scores, over the terms the student was graded in; its "terms" key holds
+-- the term scores by term. Totals, means and positions work as in term_report_cards_view.
+CREATE VIEW annual_report_cards_view AS
+WITH annual_grades AS (
+    SELECT
+        g.student_id,
+        sub.class_id,
+        sub.subject_id,
+        sub.name AS subject_name,
+        t.academic_year_id,
+        ROUND(SUM(g.score * t.report_weight) / SUM(t.report_weight), 2) AS score,
+        jsonb_object_agg(t.term_id, g.score) AS term_scores
+    FROM grades g
+    JOIN students s ON g.student_id = s.student_id
+    JOIN subjects sub ON g.subject_id = sub.subject_id
+    JOIN term t ON g.term_id = t.term_id
+    WHERE s.deleted_at IS NULL
+      AND sub.deleted_at IS NULL
+    GROUP BY g.student_id, sub.class_id, sub.subject_id, sub.name, t.academic_year_id
+),
+ranked_grades AS (
+    SELECT
+        ag.*,
+        RANK() OVER (PARTITION BY ag.subject_id, ag.academic_year_id ORDER BY ag.score DESC)::int AS subject_position
+    FROM annual_grades ag
+),
+report_cards AS (
+    SELECT
+        s.student_id,
+        s.student_no,
+        s.last_name,
+        s.first_name,
+        s.middle_name,
+        s.gender,
+        s.status,
+        c.class_id,
+        c.name AS class_name,
+        ay.academic_year_id,
+        ay.name AS academic_year_name,
+        ay.start_date AS academic_year_start,
+        jsonb_object_agg(
+            rg.subject_id,
+            jsonb_build_object(
+                'subject', rg.subject_name,
+                'score', rg.score,
+                'position', rg.subject_position,
+                'terms', rg.term_scores
+            )
+        ) AS grades,
+        SUM(rg.score) AS total_score,
+        ROUND(AVG(rg.score), 2) AS mean_score
+    FROM ranked_grades rg
+    JOIN students s ON rg.student_id = s.student_id
+    JOIN classes c ON rg.class_id = c.class_id
+    JOIN academic_year ay ON rg.academic_year_id = ay.academic_year_id
+    WHERE c.deleted_at IS NULL
+    GROUP BY s.student_id, s.student_no, s.last_name, s.first_name, s.middle_name, s.gender, s.status,
+        c.class_id, c.name, ay.academic_year_id, ay.name, ay.start_date
+)
+SELECT
+    rc.*,
+    RANK() OVER (PARTITION BY rc.class_id, rc.academic_year_id ORDER BY rc.total_score DESC)::int AS class_position,
+    COUNT(*) OVER (PARTITION BY rc.class_id, rc.academic_year_id)::int AS class_size
+FROM report_cards rc;
+
+-- +goose Down
+DROP VIEW IF EXISTS annual_report_cards_view;
+ALTER TABLE term DROP COLUMN IF EXISTS report_weight;
@@ -0,0 +1,47 @@
+-- +goose Up
+
+-- REPORT APPROVALS
+-- The report cards of a class for a term are submitted by its class teacher, then approved or
+-- returned with a comment by the headteacher, and finally published. A class without a row is a
+-- draft. Report cards can only be downloaded once published, except by those who approve them.
+CREATE TABLE IF NOT EXISTS report_approvals (
+    class_id UUID NOT NULL REFERENCES classes(class_id) ON DELETE CASCADE,
+    term_id UUID NOT NULL REFERENCES term(term_id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'draft'
+        CHECK (status IN ('draft', 'submitted', 'approved', 'returned', 'published')),
+    comment TEXT,
+    updated_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (class_id, term_id)
+);
+
+CREATE INDEX idx_report_approvals_term_id ON report_approvals(term_id);
+
+-- Report cards of past terms were already handed out, so they stay downloadable.
+INSERT INTO report_approvals (class_id, term_id, status)
+SELECT DISTINCT rc.class_id, rc.term_id, 'published'
+FROM term_report_cards_view rc
+JOIN term t ON rc.term_id = t.term_id
+WHERE t.active = FALSE
+ON CONFLICT DO NOTHING;
+
+INSERT INTO permissions (name, description)
+VALUES
+    ('reports.submit', 'Submit the report cards of classes you teach for approval'),
+    ('reports.approve', 'Approve, return and publish report cards')
+ON CONFLICT (name) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT roles.role_id, permissions.permission_id
+FROM (
+    VALUES
+        ('classteacher', 'reports.submit'),
+        ('headteacher', 'reports.approve')
+) AS seed(role_name, permission_name)
+JOIN roles ON roles.name = seed.role_name
+JOIN permissions ON permissions.name = seed.permission_name
+ON CONFLICT DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE name IN ('reports.submit', 'reports.approve');
+DROP TABLE IF EXISTS report_approvals;
@@ -0,0 +1,39 @@
+-- +goose Up
+
+-- GRADE ENTRY WINDOWS
+-- When grades can be entered for a term. A window applies to the whole term, to one class, or to
+-- one subject of a class; the most specific window wins. Terms without a window stay open.
+CREATE TABLE IF NOT EXISTS grade_entry_windows (
+    window_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    term_id UUID NOT NULL REFERENCES term(term_id) ON DELETE CASCADE,
+    class_id UUID REFERENCES classes(class_id) ON DELETE CASCADE,
+    subject_id UUID REFERENCES subjects(subject_id) ON DELETE CASCADE,
+    opens_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    closes_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT grade_entry_windows_order CHECK (closes_at > opens_at),
+    CONSTRAINT grade_entry_windows_subject_class CHECK (subject_id IS NULL OR class_id IS NOT NULL),
+    CONSTRAINT grade_entry_windows_scope UNIQUE NULLS NOT DISTINCT (term_id, class_id, subject_id)
+);
+
+-- GRADE ENTRY UNLOCKS
+-- Reopens grade entry for a class, or one subject of it, until expires_at, whatever its window
+-- says. The reason is kept so changes to closed results can be accounted for.
+CREATE TABLE IF NOT EXISTS grade_entry_unlocks (
+    unlock_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    term_id UUID NOT NULL REFERENCES term(term_id) ON DELETE CASCADE,
+    class_id UUID NOT NULL REFERENCES classes(class_id) ON DELETE CASCADE,
+    subject_id UUID REFERENCES subjects(subject_id) ON DELETE CASCADE,
+    reason TEXT NOT NULL CHECK (TRIM(reason) <> ''),
+    unlocked_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    unlocked_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    CONSTRAINT grade_entry_unlocks_order CHECK (expires_at > unlocked_at)
+);
+
+CREATE INDEX idx_grade_entry_unlocks_term_class ON grade_entry_unlocks(term_id, class_id);
+
+-- +goose Down
+DROP TABLE IF EXISTS grade_entry_unlocks;
+DROP TABLE IF EXISTS grade_entry_windows;
@@ -0,0 +1,42 @@
+-- +goose Up
+
+-- GRADE REVISIONS
+-- Every score and remark a grade has had, newest last. A revision is added whenever a grade is
+-- created or its score or remark changes: when a teacher submits grades ('entry') or when changed
+-- assessment components recompute the score ('recompute'). Grades that existed before revisions
+-- were kept start with an 'existing' revision without an author.
+CREATE TABLE IF NOT EXISTS grade_revisions (
+    revision_id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    grade_id UUID NOT NULL REFERENCES grades(grade_id) ON DELETE CASCADE,
+    score NUMERIC(5, 2) NOT NULL,
+    remark TEXT,
+    source VARCHAR(20) NOT NULL CHECK (source IN ('entry', 'recompute', 'existing')),
+    changed_by UUID REFERENCES users(user_id) ON DELETE SET NULL,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_grade_revisions_grade_id ON grade_revisions(grade_id, changed_at);
+
+-- Revisions are append-only. They are only removed with their grade.
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION fn_grade_revisions_append_only()
+RETURNS trigger AS $function$
+BEGIN
+    RAISE EXCEPTION 'grade revisions cannot be changed';
+END;
+$function$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER trg_grade_revisions_append_only
+BEFORE UPDATE ON grade_revisions
+FOR EACH ROW
+EXECUTE FUNCTION fn_grade_revisions_append_only();
+
+INSERT INTO grade_revisions (grade_id, score, remark, source)
+SELECT grade_id, score, remark, 'existing'
+FROM grades;
+
+-- +goose Down
+DROP TRIGGER IF EXISTS trg_grade_revisions_append_only ON grade_revisions;
+DROP FUNCTION IF EXISTS fn_grade_revisions_append_only();
+DROP TABLE IF EXISTS grade_revisions;
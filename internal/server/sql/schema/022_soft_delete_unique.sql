@@ -0,0 +1,35 @@
+-- +goose Up
+
+-- UNIQUE NAMES OF LIVE RECORDS
+-- A soft deleted user, class or subject no longer holds on to its phone number or name, so they can
+-- be used again while it sits in the recycle bin. Restoring it fails while a live record has them.
+ALTER TABLE users DROP CONSTRAINT IF EXISTS users_phone_number_key;
+CREATE UNIQUE INDEX users_phone_number_key ON users(phone_number) WHERE deleted_at IS NULL;
+
+ALTER TABLE classes DROP CONSTRAINT IF EXISTS classes_name_key;
+CREATE UNIQUE INDEX classes_name_key ON classes(name) WHERE deleted_at IS NULL;
+
+ALTER TABLE subjects DROP CONSTRAINT IF EXISTS unique_subject_name_per_class;
+CREATE UNIQUE INDEX unique_subject_name_per_class ON subjects(class_id, name) WHERE deleted_at IS NULL;
+
+-- +goose Down
+-- Soft deleted records sharing a phone number or name with another record are removed for good,
+-- or the constraints could not be added back.
+DELETE FROM users u
+WHERE u.deleted_at IS NOT NULL
+  AND EXISTS (SELECT 1 FROM users o WHERE o.phone_number = u.phone_number AND o.user_id <> u.user_id);
+DELETE FROM classes c
+WHERE c.deleted_at IS NOT NULL
+  AND EXISTS (SELECT 1 FROM classes o WHERE o.name = c.name AND o.class_id <> c.class_id);
+DELETE FROM subjects s
+WHERE s.deleted_at IS NOT NULL
+  AND EXISTS (SELECT 1 FROM subjects o WHERE o.class_id = s.class_id AND o.name = s.name AND o.subject_id <> s.subject_id);
+
+DROP INDEX IF EXISTS unique_subject_name_per_class;
+ALTER TABLE subjects ADD CONSTRAINT unique_subject_name_per_class UNIQUE (class_id, name);
+
+DROP INDEX IF EXISTS classes_name_key;
+ALTER TABLE classes ADD CONSTRAINT classes_name_key UNIQUE (name);
+
+DROP INDEX IF EXISTS users_phone_number_key;
+ALTER TABLE users ADD CONSTRAINT users_phone_number_key UNIQUE (phone_number);
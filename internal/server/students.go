@@ -24,21 +24,21 @@ import (
 func (s *Server) ShowCreateStudent(w http.ResponseWriter, r *http.Request) {
 	term, err := s.getCachedTerm()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("Failed to retrive current academic term", "error", err.Error())
 		return
 	}
 
 	academicYear, err := s.getCachedYear()
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("Failed to retrive current academic year", "error", err.Error())
 		return
 	}
 
 	classes, err := s.queries.ListClasses(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("internal server error", "message", err.Error())
 		return
 	}
@@ -164,7 +164,7 @@ func createStudentClass(ctx context.Context, qtx *database.Queries, classID, aca
 // creates a student and guardian.
 func (s *Server) CreateStudent(w http.ResponseWriter, r *http.Request) {
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "failed to parse form")
+		writeError(w, r, http.StatusUnprocessableEntity, "failed to parse form")
 		return
 	}
 
@@ -182,13 +182,13 @@ func (s *Server) CreateStudent(w http.ResponseWriter, r *http.Request) {
 	profession := r.FormValue("profession")
 
 	if yearPlusTerm == "" || classID == "" || firstName == "" || lastName == "" || gender == "" || dateOfBirth == "" || guardianName == "" || phoneOne == "" || guardianGender == "" {
-		writeError(w, http.StatusBadRequest, "missing some fields")
+		writeError(w, r, http.StatusBadRequest, "missing some fields")
 		return
 	}
 
 	parts := strings.Split(yearPlusTerm, "=")
 	if len(parts) != 2 {
-		writeError(w, http.StatusBadRequest, "invalid subject and class selection")
+		writeError(w, r, http.StatusBadRequest, "invalid subject and class selection")
 		return
 	}
 
@@ -198,20 +198,20 @@ func (s *Server) CreateStudent(w http.ResponseWriter, r *http.Request) {
 	// Start of transaction
 	tx, err := s.conn.Begin(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 	defer tx.Rollback(r.Context())
 	qtx := s.queries.WithTx(tx)
 	studentID, err := insertStudent(r.Context(), qtx, academicYearID, firstName, lastName, middleName, gender, dateOfBirth)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("internal server error", "message", err.Error())
 		return
 	}
 	guardianID, err := insertGuardian(r.Context(), qtx, guardianName, phoneOne, phoneTwo, guardianGender, profession)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("internal server error", "message", err.Error())
 		return
 	}
@@ -223,19 +223,36 @@ func (s *Server) CreateStudent(w http.ResponseWriter, r *http.Request) {
 
 	err = qtx.LinkStudentGuardian(r.Context(), params)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("internal server error", "message", err.Error())
 		return
 	}
 
 	err = createStudentClass(r.Context(), qtx, classID, academicTermID, studentID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to create student class", "message", err.Error())
 		return
 	}
 
-	tx.Commit(r.Context())
+	student, err := qtx.GetStudent(r.Context(), studentID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to get student", "message", err.Error())
+		return
+	}
+
+	if err := recordAudit(r, qtx, auditCreate, auditStudent, studentID, nil, student); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to write audit log", "message", err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to commit transaction", "message", err.Error())
+		return
+	}
 	// end of transaction
 
 	if r.Header.Get("HX-Request") != "" {
@@ -250,7 +267,7 @@ func (s *Server) CreateStudent(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ListStudents(w http.ResponseWriter, r *http.Request) {
 	studentsList, err := s.queries.ListStudents(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("Failed to retrieve students list", "msg", err.Error())
 		return
 	}
@@ -263,19 +280,19 @@ func (s *Server) ListStudents(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ShowEditStudent(w http.ResponseWriter, r *http.Request) {
 	studentID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid student id")
+		writeError(w, r, http.StatusBadRequest, "invalid student id")
 		return
 	}
 
 	student, err := s.queries.GetStudent(r.Context(), studentID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
 	classes, err := s.queries.ListClasses(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 	s.renderComponent(w, r, students.EditStudentModal(student, classes))
@@ -285,7 +302,7 @@ func (s *Server) ShowEditStudent(w http.ResponseWriter, r *http.Request) {
 func (s *Server) EditStudent(w http.ResponseWriter, r *http.Request) {
 	studentID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "invalid student id")
+		writeError(w, r, http.StatusBadRequest, "invalid student id")
 		return
 	}
 
@@ -297,13 +314,13 @@ func (s *Server) EditStudent(w http.ResponseWriter, r *http.Request) {
 	dateOfBirth := r.FormValue("date_of_birth")
 
 	if classID == "" || firstName == "" || lastName == "" || gender == "" || dateOfBirth == "" {
-		writeError(w, http.StatusBadRequest, "missing some fields")
+		writeError(w, r, http.StatusBadRequest, "missing some fields")
 		return
 	}
 
 	parsedClassID, err := uuid.Parse(classID)
 	if err != nil {
-		writeError(w, http.StatusBadRequest, "wrong form values")
+		writeError(w, r, http.StatusBadRequest, "wrong form values")
 		slog.Error("failed to parsed classID", "message", err.Error())
 		return
 	}
@@ -319,12 +336,19 @@ func (s *Server) EditStudent(w http.ResponseWriter, r *http.Request) {
 	// Start transaction
 	tx, err := s.conn.Begin(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
 	defer tx.Rollback(r.Context())
 	qtx := s.queries.WithTx(tx)
+
+	before, err := qtx.GetStudent(r.Context(), studentID)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "student not found")
+		return
+	}
+
 	params := database.EditStudentParams{
 		StudentID:  studentID,
 		LastName:   caser.String(lastName),
@@ -334,7 +358,7 @@ func (s *Server) EditStudent(w http.ResponseWriter, r *http.Request) {
 	}
 	err = qtx.EditStudent(r.Context(), params)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to update student", "message", err.Error())
 		return
 	}
@@ -345,12 +369,29 @@ func (s *Server) EditStudent(w http.ResponseWriter, r *http.Request) {
 	}
 	err = qtx.EditStudentClasses(r.Context(), classParams)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to change student class", "message", err.Error())
 		return
 	}
 
-	tx.Commit(r.Context())
+	after, err := qtx.GetStudent(r.Context(), studentID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to get student", "message", err.Error())
+		return
+	}
+
+	if err := recordAudit(r, qtx, auditUpdate, auditStudent, studentID, before, after); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to write audit log", "message", err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to commit transaction", "message", err.Error())
+		return
+	}
 	// end transaction
 
 	if r.Header.Get("HX-Request") != "" {
@@ -367,47 +408,34 @@ func (s *Server) ShowDeleteStudent(w http.ResponseWriter, r *http.Request) {
 	s.renderComponent(w, r, students.DeleteStudentModal(studentID))
 }
 
-// DeleteStudent handler method removes a student from the database
+// DeleteStudent handler method moves a student to the recycle bin.
+// The student's guardians are kept so they come back on restore; the purge job removes orphaned ones.
 func (s *Server) DeleteStudent(w http.ResponseWriter, r *http.Request) {
 	studentID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "wrong student id")
+		writeError(w, r, http.StatusUnprocessableEntity, "wrong student id")
 		return
 	}
 
-	tx, err := s.conn.Begin(r.Context())
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
-		return
-	}
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		student, err := qtx.GetStudent(r.Context(), studentID)
+		if err != nil {
+			return err
+		}
 
-	defer tx.Rollback(r.Context())
-	qtx := s.queries.WithTx(tx)
-	guardian, err := qtx.GetStudentGuardianCount(r.Context(), studentID)
-	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
-		slog.Error("failed to get guardian,", "message", err.Error())
-		return
-	}
+		params := database.SoftDeleteStudentParams{StudentID: studentID, DeletedBy: requestUserID(r)}
+		if _, err := qtx.SoftDeleteStudent(r.Context(), params); err != nil {
+			return err
+		}
 
-	err = qtx.DeleteStudent(r.Context(), studentID)
+		return recordAudit(r, qtx, auditDelete, auditStudent, studentID, student, nil)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("failed to delete student", "message", err.Error())
 		return
 	}
 
-	if guardian.Count <= 1 {
-		err = qtx.DeleteGuardian(r.Context(), guardian.GuardianID)
-		if err != nil {
-			writeError(w, http.StatusInternalServerError, "internal server error")
-			slog.Error("failed to delete guardian", ":", err.Error())
-			return
-		}
-	}
-
-	tx.Commit(r.Context())
-
 	if r.Header.Get("HX-Request") != "" {
 		w.Header().Set("HX-Redirect", "/students")
 		w.WriteHeader(http.StatusOK)
@@ -475,7 +503,7 @@ func createStudentsPdf(students []database.ListStudentsRow) (string, *fpdf.Fpdf)
 func (s *Server) studentsDownload(w http.ResponseWriter, r *http.Request) {
 	students, err := s.queries.ListStudents(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to get students")
+		writeError(w, r, http.StatusInternalServerError, "failed to get students")
 		slog.Error("internal server error, failed to get students list", "error", err.Error())
 		return
 	}
@@ -487,7 +515,7 @@ func (s *Server) studentsDownload(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.pdf", fileName))
 	err = studentsPDF.Output(w)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to generate PDF")
+		writeError(w, r, http.StatusInternalServerError, "Failed to generate PDF")
 		slog.Error("PDF Generation Error:", "error", err.Error())
 	}
 }
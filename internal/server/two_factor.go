@@ -0,0 +1,572 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"school_management_system/cmd/web"
+	"school_management_system/cmd/web/settings"
+	"school_management_system/internal/cookies"
+	"school_management_system/internal/database"
+	"school_management_system/internal/totp"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// pendingLoginCookie carries a user who passed the password step on to /login/2fa.
+	pendingLoginCookie = "pending_login"
+	pendingLoginTTL    = 5 * time.Minute
+	recoveryCodeCount  = 10
+)
+
+// twoFactorPolicy holds the two-factor authentication settings.
+type twoFactorPolicy struct {
+	// RequiredRoles lists the roles that cannot use the dashboard until they have enrolled.
+	RequiredRoles map[string]bool
+	// Issuer is the name authenticator apps show next to the account.
+	Issuer string
+	// Skew is the number of 30 second steps either side of now a code is accepted for.
+	Skew int
+	// Now returns the current time; tests replace it with a fixed clock.
+	Now func() time.Time
+}
+
+// loadTwoFactorPolicy reads the roles that must use two-factor authentication from TOTP_REQUIRED_ROLES,
+// a comma separated list such as "admin,accountant".
+func loadTwoFactorPolicy() twoFactorPolicy {
+	policy := twoFactorPolicy{
+		RequiredRoles: make(map[string]bool),
+		Issuer:        os.Getenv("PROJECT_NAME"),
+		Skew:          1,
+		Now:           time.Now,
+	}
+
+	for _, role := range strings.Split(os.Getenv("TOTP_REQUIRED_ROLES"), ",") {
+		if role = strings.TrimSpace(role); role != "" {
+			policy.RequiredRoles[role] = true
+		}
+	}
+
+	return policy
+}
+
+// Required reports whether a user holding any of the given roles must enroll in two-factor authentication.
+func (p twoFactorPolicy) Required(roles ...string) bool {
+	for _, role := range roles {
+		if p.RequiredRoles[role] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p twoFactorPolicy) now() time.Time {
+	if p.Now == nil {
+		return time.Now()
+	}
+
+	return p.Now()
+}
+
+// pendingLogin is a user who has passed the password step but not yet the second factor.
+type pendingLogin struct {
+	UserID     uuid.UUID
+	Identifier string
+}
+
+// writePendingLogin stores the half finished login in a short lived encrypted cookie scoped to /login.
+func (s *Server) writePendingLogin(w http.ResponseWriter, identifier string, userID uuid.UUID, now time.Time) error {
+	expires := now.Add(pendingLoginTTL)
+
+	cookie := http.Cookie{
+		Name:     pendingLoginCookie,
+		Value:    fmt.Sprintf("%s|%d|%s", userID, expires.Unix(), identifier),
+		Path:     "/login",
+		MaxAge:   int(pendingLoginTTL.Seconds()),
+		HttpOnly: true,
+		Secure:   os.Getenv("ENV") == "production",
+		SameSite: http.SameSiteStrictMode,
+	}
+
+	return s.Keys.WriteEncrypted(w, cookie)
+}
+
+// readPendingLogin returns the pending login from the request, failing if it is missing, tampered with or expired.
+func (s *Server) readPendingLogin(r *http.Request, now time.Time) (pendingLogin, error) {
+	value, _, err := s.Keys.ReadEncrypted(r, pendingLoginCookie)
+	if err != nil {
+		return pendingLogin{}, err
+	}
+
+	parts := strings.SplitN(value, "|", 3)
+	if len(parts) != 3 {
+		return pendingLogin{}, cookies.ErrInvalidValue
+	}
+
+	userID, err := uuid.Parse(parts[0])
+	if err != nil {
+		return pendingLogin{}, cookies.ErrInvalidValue
+	}
+
+	expires, err := strconv.ParseInt(parts[1], 10, 64)
+	if err != nil || now.Unix() > expires {
+		return pendingLogin{}, cookies.ErrInvalidValue
+	}
+
+	return pendingLogin{UserID: userID, Identifier: parts[2]}, nil
+}
+
+func clearPendingLogin(w http.ResponseWriter) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     pendingLoginCookie,
+		Value:    "",
+		Path:     "/login",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteStrictMode,
+	})
+}
+
+// isTOTPCode reports whether code looks like an authenticator code rather than a recovery code.
+func isTOTPCode(code string) bool {
+	if len(code) != totp.Digits {
+		return false
+	}
+
+	return strings.IndexFunc(code, func(r rune) bool { return r < '0' || r > '9' }) == -1
+}
+
+// verifySecondFactor checks an authenticator code or, failing that shape, a recovery code.
+// Accepted authenticator codes cannot be used again and recovery codes are used up.
+func (s *Server) verifySecondFactor(ctx context.Context, q *database.Queries, userID uuid.UUID, code string, now time.Time) (bool, error) {
+	code = strings.TrimSpace(code)
+
+	if isTOTPCode(strings.ReplaceAll(code, " ", "")) {
+		userTOTP, err := q.GetUserTOTP(ctx, userID)
+		if err != nil {
+			return false, err
+		}
+
+		if !userTOTP.TotpEnabledAt.Valid || !userTOTP.TotpSecret.Valid {
+			return false, nil
+		}
+
+		counter, err := totp.Validate(userTOTP.TotpSecret.String, code, now, s.twoFactor.Skew)
+		if err != nil {
+			return false, nil
+		}
+
+		used, err := q.UseTOTPCounter(ctx, database.UseTOTPCounterParams{Counter: counter, UserID: userID})
+		if err != nil {
+			return false, err
+		}
+
+		return used == 1, nil
+	}
+
+	_, err := q.ConsumeRecoveryCode(ctx, database.ConsumeRecoveryCodeParams{
+		UserID:   userID,
+		CodeHash: hashResetCode(code),
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// createRecoveryCodes replaces a user's recovery codes and returns the new ones in plain text.
+// Recovery codes share the reset code format, so hashResetCode normalises them the same way.
+func createRecoveryCodes(ctx context.Context, q *database.Queries, userID uuid.UUID) ([]string, error) {
+	if err := q.DeleteRecoveryCodes(ctx, userID); err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		code, err := generateResetCode()
+		if err != nil {
+			return nil, err
+		}
+
+		params := database.CreateRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: hashResetCode(code),
+		}
+
+		if err := q.CreateRecoveryCode(ctx, params); err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+// ShowLoginTOTP renders the second login step for a user who has passed the password step.
+func (s *Server) ShowLoginTOTP(w http.ResponseWriter, r *http.Request) {
+	if _, err := s.readPendingLogin(r, s.twoFactor.now()); err != nil {
+		http.Redirect(w, r, "/login", http.StatusFound)
+		return
+	}
+
+	if err := web.LoginTOTP().Render(r.Context(), w); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to render login totp page", "error", err.Error())
+	}
+}
+
+// VerifyLoginTOTP completes a login by checking the authenticator or recovery code.
+// Wrong codes count towards the same backoff and lockout as wrong passwords.
+func (s *Server) VerifyLoginTOTP(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "bad request")
+		return
+	}
+
+	now := s.twoFactor.now()
+	ip := s.clientIP(r)
+
+	pending, err := s.readPendingLogin(r, now)
+	if err != nil {
+		writeError(w, r, http.StatusUnauthorized, "your sign in has expired, please log in again")
+		return
+	}
+
+	user, err := s.getUserByIdentifier(r.Context(), pending.Identifier)
+	if err != nil || user.UserID != pending.UserID {
+		clearPendingLogin(w)
+		writeError(w, r, http.StatusUnauthorized, "your sign in has expired, please log in again")
+		return
+	}
+
+	wait, locked, err := s.reserveLoginAttempt(r.Context(), user, now)
+	if errors.Is(err, errAccountLocked) {
+		s.recordLoginAttempt(r.Context(), pending.Identifier, user.UserID, ip, loginLocked)
+		writeError(w, r, http.StatusLocked, "account locked after too many failed attempts, try again later or contact an administrator")
+		return
+	}
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to record failed login", "error", err.Error())
+		return
+	}
+	if wait > 0 {
+		s.recordLoginAttempt(r.Context(), pending.Identifier, user.UserID, ip, loginThrottled)
+		writeTooManyAttempts(w, r, wait)
+		return
+	}
+
+	ok, err := s.verifySecondFactor(r.Context(), s.queries, user.UserID, r.FormValue("code"), now)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to verify second factor", "error", err.Error())
+		return
+	}
+
+	if !ok {
+		s.recordLoginAttempt(r.Context(), pending.Identifier, user.UserID, ip, loginInvalidCredentials)
+		if locked {
+			clearPendingLogin(w)
+			writeError(w, r, http.StatusLocked, "account locked after too many failed attempts, try again later or contact an administrator")
+			return
+		}
+
+		writeError(w, r, http.StatusUnauthorized, "invalid authentication code")
+		return
+	}
+
+	clearPendingLogin(w)
+	s.completeLogin(w, r, pending.Identifier, user, ip)
+}
+
+// ShowTwoFactorSettings renders the two-factor section of the user settings page.
+// Users who have not enrolled get a secret to add to their authenticator app.
+func (s *Server) ShowTwoFactorSettings(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "not logged in")
+		return
+	}
+
+	userTOTP, err := s.queries.GetUserTOTP(r.Context(), user.UserID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to fetch two-factor settings")
+		slog.Error("failed to fetch two-factor settings", "UserID", user.UserID, "error", err.Error())
+		return
+	}
+
+	required := s.twoFactor.Required(user.Roles...)
+
+	if userTOTP.TotpEnabledAt.Valid {
+		remaining, err := s.queries.CountUnusedRecoveryCodes(r.Context(), user.UserID)
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, "failed to fetch two-factor settings")
+			slog.Error("failed to count recovery codes", "error", err.Error())
+			return
+		}
+
+		s.renderComponent(w, r, settings.TwoFactorEnabled(userTOTP.TotpEnabledAt.Time, remaining, required))
+		return
+	}
+
+	// Keep an unconfirmed secret across page loads so a half finished enrollment still works.
+	secret := userTOTP.TotpSecret.String
+	if !userTOTP.TotpSecret.Valid {
+		secret, err = totp.GenerateSecret()
+		if err != nil {
+			writeError(w, r, http.StatusInternalServerError, "internal server error")
+			slog.Error("failed to generate totp secret", "error", err.Error())
+			return
+		}
+
+		params := database.SetTOTPSecretParams{
+			UserID:     user.UserID,
+			TotpSecret: pgtype.Text{String: secret, Valid: true},
+		}
+
+		if err := s.queries.SetTOTPSecret(r.Context(), params); err != nil {
+			writeError(w, r, http.StatusInternalServerError, "internal server error")
+			slog.Error("failed to store totp secret", "error", err.Error())
+			return
+		}
+	}
+
+	userDetails, err := s.queries.GetUserDetails(r.Context(), user.UserID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to fetch user")
+		slog.Error("failed to fetch user", "UserID", user.UserID, "error", err.Error())
+		return
+	}
+
+	uri := totp.ProvisioningURI(s.twoFactor.Issuer, userDetails.UserNo, secret)
+
+	s.renderComponent(w, r, settings.TwoFactorSetup(secret, uri, required))
+}
+
+// EnableTwoFactor confirms enrollment with a code from the authenticator app
+// and shows the user their recovery codes once.
+func (s *Server) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "not logged in")
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Bad Request")
+		return
+	}
+
+	userTOTP, err := s.queries.GetUserTOTP(r.Context(), user.UserID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to fetch two-factor settings")
+		slog.Error("failed to fetch two-factor settings", "UserID", user.UserID, "error", err.Error())
+		return
+	}
+
+	if userTOTP.TotpEnabledAt.Valid {
+		writeError(w, r, http.StatusUnprocessableEntity, "Two-factor authentication is already enabled")
+		return
+	}
+
+	if !userTOTP.TotpSecret.Valid {
+		writeError(w, r, http.StatusUnprocessableEntity, "Reload the page to start setting up two-factor authentication")
+		return
+	}
+
+	counter, err := totp.Validate(userTOTP.TotpSecret.String, r.FormValue("code"), s.twoFactor.now(), s.twoFactor.Skew)
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "Invalid authentication code, check the time on your phone and try again")
+		return
+	}
+
+	params := database.EnableTOTPParams{
+		UserID:          user.UserID,
+		TotpLastCounter: counter,
+	}
+
+	var codes []string
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		if err := qtx.EnableTOTP(r.Context(), params); err != nil {
+			return fmt.Errorf("enable totp: %w", err)
+		}
+
+		codes, err = createRecoveryCodes(r.Context(), qtx, user.UserID)
+		if err != nil {
+			return fmt.Errorf("create recovery codes: %w", err)
+		}
+
+		after, err := qtx.GetUserTOTP(r.Context(), user.UserID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditUpdate, auditUser, user.UserID, userTOTP, after)
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to enable totp", "error", err.Error())
+		return
+	}
+
+	s.renderComponent(w, r, settings.RecoveryCodesModal(codes))
+}
+
+// RegenerateRecoveryCodes replaces the user's recovery codes after checking their password.
+func (s *Server) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "not logged in")
+		return
+	}
+
+	if !s.checkCurrentPassword(w, r, user.UserID) {
+		return
+	}
+
+	userTOTP, err := s.queries.GetUserTOTP(r.Context(), user.UserID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to fetch two-factor settings")
+		slog.Error("failed to fetch two-factor settings", "UserID", user.UserID, "error", err.Error())
+		return
+	}
+
+	if !userTOTP.TotpEnabledAt.Valid {
+		writeError(w, r, http.StatusUnprocessableEntity, "Two-factor authentication is not enabled")
+		return
+	}
+
+	var codes []string
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		codes, err = createRecoveryCodes(r.Context(), qtx, user.UserID)
+		if err != nil {
+			return err
+		}
+
+		after := map[string]any{"recovery_codes": len(codes)}
+		return recordAudit(r, qtx, auditUpdate, auditUser, user.UserID, nil, after)
+	})
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to create recovery codes", "error", err.Error())
+		return
+	}
+
+	s.renderComponent(w, r, settings.RecoveryCodesModal(codes))
+}
+
+// DisableTwoFactor turns two-factor authentication off after checking the user's password.
+// Users whose role requires two-factor authentication cannot turn it off.
+func (s *Server) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	user, ok := r.Context().Value(userContextKey).(User)
+	if !ok {
+		writeError(w, r, http.StatusUnauthorized, "not logged in")
+		return
+	}
+
+	if s.twoFactor.Required(user.Roles...) {
+		writeError(w, r, http.StatusUnprocessableEntity, "Two-factor authentication is required for your role")
+		return
+	}
+
+	if !s.checkCurrentPassword(w, r, user.UserID) {
+		return
+	}
+
+	if err := s.disableTwoFactor(r, user.UserID); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to disable totp", "error", err.Error())
+		return
+	}
+
+	if r.Header.Get("HX-Request") != "" {
+		w.Header().Set("HX-Redirect", "/settings/user")
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	http.Redirect(w, r, "/settings/user", http.StatusSeeOther)
+}
+
+// ResetTwoFactor lets an admin remove two-factor authentication from a user who lost their device
+// and their recovery codes. The user has to enroll again if their role requires it.
+func (s *Server) ResetTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid user id")
+		return
+	}
+
+	if err := s.disableTwoFactor(r, userID); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to reset totp", "error", err.Error())
+		return
+	}
+
+	writeSuccess(w, r, "Two-factor authentication reset")
+}
+
+func (s *Server) disableTwoFactor(r *http.Request, userID uuid.UUID) error {
+	ctx := r.Context()
+	return s.withTx(ctx, func(qtx *database.Queries) error {
+		before, err := qtx.GetUserTOTP(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		if err := qtx.DisableTOTP(ctx, userID); err != nil {
+			return err
+		}
+
+		if err := qtx.DeleteRecoveryCodes(ctx, userID); err != nil {
+			return err
+		}
+
+		after, err := qtx.GetUserTOTP(ctx, userID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditUpdate, auditUser, userID, before, after)
+	})
+}
+
+// checkCurrentPassword compares the current_password form value with the user's password,
+// writing an error popover and returning false if it does not match.
+func (s *Server) checkCurrentPassword(w http.ResponseWriter, r *http.Request, userID uuid.UUID) bool {
+	if err := r.ParseForm(); err != nil {
+		writeError(w, r, http.StatusBadRequest, "Bad Request")
+		return false
+	}
+
+	userDetails, err := s.queries.GetUserDetails(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "failed to get user")
+		slog.Error("failed to get user", "error", err.Error())
+		return false
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(userDetails.Password), []byte(r.FormValue("current_password"))); err != nil {
+		writeError(w, r, http.StatusUnprocessableEntity, "Incorrect Current Password")
+		return false
+	}
+
+	return true
+}
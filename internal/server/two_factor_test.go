@@ -0,0 +1,135 @@
+// two_factor_test.go
+package server
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"school_management_system/internal/database"
+	"school_management_system/internal/totp"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"github.com/pashagolub/pgxmock/v4"
+)
+
+// fixedNow is the clock used by the two-factor tests so codes are deterministic.
+var fixedNow = time.Unix(1111111111, 0)
+
+const testTOTPSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestLoadTwoFactorPolicy(t *testing.T) {
+	t.Setenv("TOTP_REQUIRED_ROLES", "admin, accountant,")
+
+	policy := loadTwoFactorPolicy()
+	if !policy.Required("admin") || !policy.Required("accountant") {
+		t.Errorf("expected admin and accountant to require two-factor authentication; got %v", policy.RequiredRoles)
+	}
+	if policy.Required("teacher") {
+		t.Error("expected teacher not to require two-factor authentication")
+	}
+}
+
+func TestPendingLogin_RoundTripAndExpiry(t *testing.T) {
+	s := &Server{Keys: testKeyring()}
+	userID := uuid.New()
+
+	rec := httptest.NewRecorder()
+	if err := s.writePendingLogin(rec, "USR-2025-00001", userID, fixedNow); err != nil {
+		t.Fatalf("failed to write pending login: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/login/2fa", nil)
+	for _, c := range rec.Result().Cookies() {
+		req.AddCookie(c)
+	}
+
+	pending, err := s.readPendingLogin(req, fixedNow.Add(time.Minute))
+	if err != nil {
+		t.Fatalf("expected pending login to be readable; got %v", err)
+	}
+	if pending.UserID != userID || pending.Identifier != "USR-2025-00001" {
+		t.Errorf("unexpected pending login %+v", pending)
+	}
+
+	if _, err := s.readPendingLogin(req, fixedNow.Add(pendingLoginTTL+time.Second)); err == nil {
+		t.Error("expected pending login to expire")
+	}
+}
+
+func TestVerifySecondFactor_TOTPCodeIsSingleUse(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	queries := database.New(mockConn)
+	s := &Server{twoFactor: twoFactorPolicy{Skew: 1, Now: func() time.Time { return fixedNow }}}
+	userID := uuid.New()
+
+	code, err := totp.Code(testTOTPSecret, fixedNow)
+	if err != nil {
+		t.Fatalf("failed to generate code: %v", err)
+	}
+
+	totpRows := func() *pgxmock.Rows {
+		return pgxmock.NewRows([]string{"totp_secret", "totp_enabled_at", "totp_last_counter"}).
+			AddRow(
+				pgtype.Text{String: testTOTPSecret, Valid: true},
+				pgtype.Timestamptz{Time: fixedNow.Add(-time.Hour), Valid: true},
+				int64(0),
+			)
+	}
+
+	mockConn.ExpectQuery("SELECT totp_secret").WithArgs(userID).WillReturnRows(totpRows())
+	mockConn.ExpectExec("UPDATE users").
+		WithArgs(totp.Counter(fixedNow), userID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 1))
+
+	ok, err := s.verifySecondFactor(context.Background(), queries, userID, code, fixedNow)
+	if err != nil || !ok {
+		t.Fatalf("expected code to be accepted; got ok=%v err=%v", ok, err)
+	}
+
+	// The same code again finds the counter already used.
+	mockConn.ExpectQuery("SELECT totp_secret").WithArgs(userID).WillReturnRows(totpRows())
+	mockConn.ExpectExec("UPDATE users").
+		WithArgs(totp.Counter(fixedNow), userID).
+		WillReturnResult(pgxmock.NewResult("UPDATE", 0))
+
+	ok, err = s.verifySecondFactor(context.Background(), queries, userID, code, fixedNow)
+	if err != nil || ok {
+		t.Fatalf("expected replayed code to be rejected; got ok=%v err=%v", ok, err)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
+
+func TestVerifySecondFactor_RecoveryCode(t *testing.T) {
+	mockConn, err := pgxmock.NewConn()
+	if err != nil {
+		t.Fatalf("failed to create pgxmock connection: %v", err)
+	}
+	defer mockConn.Close(context.Background())
+
+	s := &Server{}
+	userID := uuid.New()
+
+	mockConn.ExpectQuery("UPDATE totp_recovery_codes").
+		WithArgs(userID, hashResetCode("ABCD-EF23")).
+		WillReturnRows(pgxmock.NewRows([]string{"recovery_code_id"}).AddRow(uuid.New()))
+
+	ok, err := s.verifySecondFactor(context.Background(), database.New(mockConn), userID, "abcd-ef23", fixedNow)
+	if err != nil || !ok {
+		t.Fatalf("expected recovery code to be accepted; got ok=%v err=%v", ok, err)
+	}
+
+	if err := mockConn.ExpectationsWereMet(); err != nil {
+		t.Errorf("unfulfilled expectations: %s", err)
+	}
+}
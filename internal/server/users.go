@@ -38,12 +38,12 @@ func hashPassword(password string) ([]byte, error) {
 // An endpoint to create a new user account
 func (s *Server) Register(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		writeError(w, r, http.StatusMethodNotAllowed, "method not allowed")
 		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "failed to parse form")
+		writeError(w, r, http.StatusUnprocessableEntity, "failed to parse form")
 		return
 	}
 
@@ -55,7 +55,7 @@ func (s *Server) Register(w http.ResponseWriter, r *http.Request) {
 	role := r.FormValue("role") // Role name
 
 	if firstName == "" || lastName == "" || phoneNumber == "" || gender == "" || role == "" {
-		writeError(w, http.StatusBadRequest, "all fields except email are required")
+		writeError(w, r, http.StatusBadRequest, "all fields except email are required")
 		return
 	}
 
@@ -63,13 +63,13 @@ func (s *Server) Register(w http.ResponseWriter, r *http.Request) {
 	password, err := generateNumericPassword()
 	if err != nil {
 		slog.Error("Failed to generate password")
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
 	hashedPassword, err := hashPassword(password)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
@@ -82,18 +82,27 @@ func (s *Server) Register(w http.ResponseWriter, r *http.Request) {
 
 	caser := cases.Title(language.English)
 	user := database.CreateUserParams{
-		FirstName:   caser.String(firstName),
-		LastName:    caser.String(lastName),
-		PhoneNumber: pgtype.Text{String: phoneNumber, Valid: true},
-		Email:       emailValue,
-		Gender:      gender,
-		Password:    string(hashedPassword),
-		Name:        role,
+		FirstName:          caser.String(firstName),
+		LastName:           caser.String(lastName),
+		PhoneNumber:        pgtype.Text{String: phoneNumber, Valid: true},
+		Email:              emailValue,
+		Gender:             gender,
+		Password:           string(hashedPassword),
+		Name:               role,
+		MustChangePassword: true,
 	}
 
-	newUser, err := s.queries.CreateUser(r.Context(), user)
+	var newUser database.User
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		newUser, err = qtx.CreateUser(r.Context(), user)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditCreate, auditUser, newUser.UserID, nil, newUser)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Info("Failed to create user", "message:", err.Error())
 		return
 	}
@@ -148,7 +157,7 @@ func (s *Server) userProfile(w http.ResponseWriter, r *http.Request) {
 			status = http.StatusInternalServerError
 			errorMessage = "internal server error"
 		}
-		writeError(w, status, errorMessage)
+		writeError(w, r, status, errorMessage)
 		return
 	}
 
@@ -161,7 +170,7 @@ func (s *Server) userProfile(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ListUsers(w http.ResponseWriter, r *http.Request) {
 	userList, err := s.queries.ListUsers(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "Internal Server Error")
+		writeError(w, r, http.StatusInternalServerError, "Internal Server Error")
 		return
 	}
 	contents := userlist.UsersList(userList)
@@ -172,13 +181,13 @@ func (s *Server) ListUsers(w http.ResponseWriter, r *http.Request) {
 func (s *Server) ShowEditUserForm(w http.ResponseWriter, r *http.Request) {
 	userID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid user id")
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid user id")
 		return
 	}
 
 	user, err := s.queries.GetUserDetails(r.Context(), userID)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		slog.Error("User not found", "message:", err.Error())
 		return
 	}
@@ -192,11 +201,12 @@ func (s *Server) ShowEditUserForm(w http.ResponseWriter, r *http.Request) {
 func (s *Server) EditUser(w http.ResponseWriter, r *http.Request) {
 	userID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "invalid user id")
+		writeError(w, r, http.StatusUnprocessableEntity, "invalid user id")
+		return
 	}
 
 	if err := r.ParseForm(); err != nil {
-		writeError(w, http.StatusBadRequest, "Bad Request")
+		writeError(w, r, http.StatusBadRequest, "Bad Request")
 		return
 	}
 
@@ -217,13 +227,19 @@ func (s *Server) EditUser(w http.ResponseWriter, r *http.Request) {
 
 	tx, err := s.conn.Begin(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
 	defer tx.Rollback(r.Context())
 	qtx := s.queries.WithTx(tx)
 
+	before, err := qtx.GetUserDetails(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, http.StatusNotFound, "user not found")
+		return
+	}
+
 	updateInfo := database.EditUserParams{
 		UserID:      userID,
 		FirstName:   firstName,
@@ -236,7 +252,7 @@ func (s *Server) EditUser(w http.ResponseWriter, r *http.Request) {
 
 	editedUserID, err := qtx.EditUser(r.Context(), updateInfo)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	} else {
 		err = qtx.RemoveClassTeacher(r.Context(), editedUserID)
@@ -248,7 +264,7 @@ func (s *Server) EditUser(w http.ResponseWriter, r *http.Request) {
 	if len(strings.TrimSpace(password)) > 0 {
 		hashedPassword, err := hashPassword(password)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, "internal server error")
+			writeError(w, r, http.StatusInternalServerError, "internal server error")
 			return
 		}
 
@@ -259,13 +275,37 @@ func (s *Server) EditUser(w http.ResponseWriter, r *http.Request) {
 
 		err = qtx.EditPassword(r.Context(), changePasswdParams)
 		if err != nil {
-			writeError(w, http.StatusInternalServerError, "internal server error")
+			writeError(w, r, http.StatusInternalServerError, "internal server error")
 			slog.Error("failed to change password", ":", err.Error())
 			return
 		}
+
+		// A password reset by an admin signs the user out everywhere.
+		if err := qtx.DeleteUserSessions(r.Context(), userID); err != nil {
+			writeError(w, r, http.StatusInternalServerError, "internal server error")
+			slog.Error("failed to revoke sessions", "error", err.Error())
+			return
+		}
 	}
 
-	tx.Commit(r.Context())
+	after, err := qtx.GetUserDetails(r.Context(), userID)
+	if err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to get user", "error", err.Error())
+		return
+	}
+
+	if err := recordAudit(r, qtx, auditUpdate, auditUser, userID, before, after); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to write audit log", "error", err.Error())
+		return
+	}
+
+	if err := tx.Commit(r.Context()); err != nil {
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
+		slog.Error("failed to commit transaction", "error", err.Error())
+		return
+	}
 
 	if r.Header.Get("HX-Request") != "" {
 		w.Header().Set("HX-Redirect", "/dashboard/userlist")
@@ -284,17 +324,29 @@ func (s *Server) ShowDeleteConfirmation(w http.ResponseWriter, r *http.Request)
 
 // DeleteUser handler
 // Accepts an id parameter
-// deletes a user from the database
+// moves a user to the recycle bin
 func (s *Server) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	userID, err := uuid.Parse(r.PathValue("id"))
 	if err != nil {
-		writeError(w, http.StatusUnprocessableEntity, "failed to parse user id")
+		writeError(w, r, http.StatusUnprocessableEntity, "failed to parse user id")
 		return
 	}
 
-	err = s.queries.DeleteUser(r.Context(), userID)
+	err = s.withTx(r.Context(), func(qtx *database.Queries) error {
+		before, err := qtx.GetUserDetails(r.Context(), userID)
+		if err != nil {
+			return err
+		}
+
+		params := database.SoftDeleteUserParams{UserID: userID, DeletedBy: requestUserID(r)}
+		if _, err := qtx.SoftDeleteUser(r.Context(), params); err != nil {
+			return err
+		}
+
+		return recordAudit(r, qtx, auditDelete, auditUser, userID, before, nil)
+	})
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "internal server error")
+		writeError(w, r, http.StatusInternalServerError, "internal server error")
 		return
 	}
 
@@ -364,7 +416,7 @@ func createUsersPdf(users []database.ListUsersRow) (string, *fpdf.Fpdf) {
 func (s *Server) userDownload(w http.ResponseWriter, r *http.Request) {
 	users, err := s.queries.ListUsers(r.Context())
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "failed to get users")
+		writeError(w, r, http.StatusInternalServerError, "failed to get users")
 		slog.Error("internal server error, failed to get user list", "error", err.Error())
 		return
 	}
@@ -376,7 +428,7 @@ func (s *Server) userDownload(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.pdf", fileName))
 	err = usersPDF.Output(w)
 	if err != nil {
-		writeError(w, http.StatusInternalServerError, "Failed to generate PDF")
+		writeError(w, r, http.StatusInternalServerError, "Failed to generate PDF")
 		slog.Error("PDF Generation Error:", "error", err.Error())
 	}
 }
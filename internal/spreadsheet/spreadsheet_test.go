@@ -0,0 +1,104 @@
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"Student No", "Student Name", "Mathematics"},
+		{"STU-2025-00001", "Banda, Chikondi", "62.5"},
+		{"STU-2025-00002", `Phiri "Tom" <Jr> & Co`, ""},
+	}
+
+	for _, format := range []Format{CSV, XLSX} {
+		var buf bytes.Buffer
+		if err := Write(&buf, format, rows); err != nil {
+			t.Fatalf("%s: unexpected error writing: %v", format, err)
+		}
+
+		got, err := Read(&buf, format)
+		if err != nil {
+			t.Fatalf("%s: unexpected error reading: %v", format, err)
+		}
+
+		want := rows
+		if format == XLSX {
+			// Trailing empty cells are dropped from XLSX rows.
+			want = [][]string{rows[0], rows[1], rows[2][:2]}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: expected %q; got %q", format, want, got)
+		}
+	}
+}
+
+// TestReadXLSX_SharedStrings reads a sheet as spreadsheet programs save it: shared strings,
+// numbers, a skipped row and cells without their empty neighbours.
+func TestReadXLSX_SharedStrings(t *testing.T) {
+	parts := map[string]string{
+		"xl/workbook.xml":            `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Marks" sheetId="1" r:id="rId3"/></sheets></workbook>`,
+		"xl/_rels/workbook.xml.rels": `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId3" Target="worksheets/marks.xml"/></Relationships>`,
+		"xl/sharedStrings.xml":       `<sst xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><si><t>Student No</t></si><si><r><t>Maths</t></r><r><t>!</t></r></si></sst>`,
+		"xl/worksheets/marks.xml": `<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>` +
+			`<row r="1"><c r="A1" t="s"><v>0</v></c><c r="C1" t="s"><v>1</v></c></row>` +
+			`<row r="3"><c r="A3" t="str"><v>STU-1</v></c><c r="C3"><v>71.25</v></c></row>` +
+			`</sheetData></worksheet>`,
+	}
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range parts {
+		f, _ := zw.Create(name)
+		_, _ = f.Write([]byte(content))
+	}
+	_ = zw.Close()
+
+	got, err := Read(&buf, XLSX)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := [][]string{{"Student No", "", "Maths!"}, nil, {"STU-1", "", "71.25"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q; got %q", want, got)
+	}
+}
+
+func TestRead_Invalid(t *testing.T) {
+	if _, err := Read(bytes.NewReader([]byte("not a zip")), XLSX); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("expected an invalid file; got %v", err)
+	}
+	if _, err := Read(bytes.NewReader([]byte("a,\"b\n")), CSV); !errors.Is(err, ErrInvalidFile) {
+		t.Errorf("expected an invalid file; got %v", err)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	for name, want := range map[string]Format{"csv": CSV, "XLSX": XLSX, "Form 1 marks.xlsx": XLSX, "marks.CSV": CSV} {
+		if got, err := ParseFormat(name); err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+
+	for _, name := range []string{"", "pdf", "marks.xls"} {
+		if _, err := ParseFormat(name); !errors.Is(err, ErrUnsupportedFormat) {
+			t.Errorf("expected %q to be unsupported; got %v", name, err)
+		}
+	}
+}
+
+func TestColumnName(t *testing.T) {
+	for index, want := range map[int]string{0: "A", 25: "Z", 26: "AA", 27: "AB", 701: "ZZ", 702: "AAA"} {
+		if got := columnName(index); got != want {
+			t.Errorf("columnName(%d) = %q; want %q", index, got, want)
+		}
+		if got := columnIndex(want + "7"); got != index {
+			t.Errorf("columnIndex(%q) = %d; want %d", want+"7", got, index)
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package tests
 
 import (
+	"encoding/base64"
 	"net/http"
 	"net/http/cookiejar"
 	"net/http/httptest"
@@ -8,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"school_management_system/internal/csrf"
 	"school_management_system/internal/server"
 
 	"github.com/stretchr/testify/require"
@@ -19,11 +21,34 @@ type LoginInfo struct {
 	Password   string
 }
 
+// csrfTransport sends the CSRF token from the cookie jar back in the request header,
+// the way the pages do for HTMX requests.
+type csrfTransport struct {
+	jar *cookiejar.Jar
+}
+
+func (t *csrfTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !csrf.Safe(req.Method) && req.Header.Get(csrf.HeaderName) == "" {
+		for _, cookie := range t.jar.Cookies(req.URL) {
+			if cookie.Name != csrf.CookieName {
+				continue
+			}
+			if token, err := base64.URLEncoding.DecodeString(cookie.Value); err == nil {
+				req = req.Clone(req.Context())
+				req.Header.Set(csrf.HeaderName, string(token))
+			}
+		}
+	}
+
+	return http.DefaultTransport.RoundTrip(req)
+}
+
 // InitialiseClient function sets up the test server client
 func InitialiseClient(cookieJar *cookiejar.Jar) *http.Client {
 	// define a test client
 	client := &http.Client{
-		Jar: cookieJar,
+		Jar:       cookieJar,
+		Transport: &csrfTransport{jar: cookieJar},
 		CheckRedirect: func(req *http.Request, via []*http.Request) error {
 			return http.ErrUseLastResponse
 		},
@@ -56,6 +81,12 @@ func LoginHelper(t *testing.T, ts *httptest.Server, login *LoginInfo) (*http.Req
 	formData.Set("password", login.Password)
 
 	cookieJar, _ := cookiejar.New(nil)
+
+	// load the login page first so the jar holds a CSRF token for the login request
+	resp, err := InitialiseClient(cookieJar).Get(ts.URL + "/login")
+	require.NoError(t, err)
+	resp.Body.Close()
+
 	req, err := http.NewRequest(http.MethodPost, ts.URL+"/login", strings.NewReader(formData.Encode()))
 
 	require.NoError(t, err)
@@ -0,0 +1,125 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA-1 seed used by the test vectors in RFC 6238 appendix B.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+// Test against the RFC 6238 SHA-1 test vectors.
+func TestHOTP_RFC6238Vectors(t *testing.T) {
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	key := []byte("12345678901234567890")
+	for _, v := range vectors {
+		got := hotp(key, Counter(time.Unix(v.unix, 0)), 8)
+		if got != v.code {
+			t.Errorf("at %d: expected %s, got %s", v.unix, v.code, got)
+		}
+	}
+}
+
+// Six digit codes are the last six digits of the RFC vectors.
+func TestCode(t *testing.T) {
+	code, err := Code(rfcSecret, time.Unix(59, 0))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+	if code != "287082" {
+		t.Errorf("expected 287082, got %s", code)
+	}
+
+	code, err = Code(strings.ToLower(rfcSecret), time.Unix(1111111109, 0))
+	if err != nil {
+		t.Fatalf("expected lower case secret to be accepted, got %v", err)
+	}
+	if code != "081804" {
+		t.Errorf("expected 081804, got %s", code)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+
+	code, err := Code(rfcSecret, now)
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	counter, err := Validate(rfcSecret, code, now, 1)
+	if err != nil {
+		t.Fatalf("expected current code to be valid, got %v", err)
+	}
+	if counter != Counter(now) {
+		t.Errorf("expected counter %d, got %d", Counter(now), counter)
+	}
+
+	// A code from the previous step is accepted within the skew window.
+	previous, _ := Code(rfcSecret, now.Add(-Period))
+	counter, err = Validate(rfcSecret, previous, now, 1)
+	if err != nil {
+		t.Fatalf("expected previous code to be valid, got %v", err)
+	}
+	if counter != Counter(now)-1 {
+		t.Errorf("expected counter %d, got %d", Counter(now)-1, counter)
+	}
+
+	// ... but not without skew, nor once it is two steps old.
+	if _, err := Validate(rfcSecret, previous, now, 0); err != ErrInvalidCode {
+		t.Errorf("expected ErrInvalidCode without skew, got %v", err)
+	}
+	old, _ := Code(rfcSecret, now.Add(-2*Period))
+	if _, err := Validate(rfcSecret, old, now, 1); err != ErrInvalidCode {
+		t.Errorf("expected ErrInvalidCode for old code, got %v", err)
+	}
+
+	if _, err := Validate(rfcSecret, "12345", now, 1); err != ErrInvalidCode {
+		t.Errorf("expected ErrInvalidCode for short code, got %v", err)
+	}
+	if _, err := Validate("not base32!", code, now, 1); err != ErrInvalidSecret {
+		t.Errorf("expected ErrInvalidSecret, got %v", err)
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	key, err := decodeSecret(secret)
+	if err != nil {
+		t.Fatalf("generated secret does not decode: %v", err)
+	}
+	if len(key) != secretSize {
+		t.Errorf("expected %d byte secret, got %d", secretSize, len(key))
+	}
+
+	other, _ := GenerateSecret()
+	if secret == other {
+		t.Error("expected two generated secrets to differ")
+	}
+}
+
+func TestProvisioningURI(t *testing.T) {
+	uri := ProvisioningURI("Sunrise School", "USR-2025-00001", "JBSWY3DPEHPK3PXP")
+
+	expected := "otpauth://totp/Sunrise%20School:USR-2025-00001?algorithm=SHA1&digits=6&issuer=Sunrise+School&period=30&secret=JBSWY3DPEHPK3PXP"
+	if uri != expected {
+		t.Errorf("expected %s, got %s", expected, uri)
+	}
+}